@@ -15,6 +15,7 @@
 package backend
 
 import (
+	"crypto/sha256"
 	"fmt"
 
 	accountsTypes "github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/types"
@@ -30,6 +31,8 @@ import (
 // - regular: for unified accounts
 // - split: for the individual accounts split from a unified account, if the keystore does not support unified accounts, such as the BitBox01.
 // - erc20: for ERC20 token accounts
+// - multisig: for multisig accounts shared with cosigners
+// - watchonly: for watch-only accounts added from an xpub or descriptors
 
 // regularAccountCode returns an account code based on a keystore root fingerprint, a coin code and
 // an account number.
@@ -48,3 +51,30 @@ func splitAccountCode(parentCode accountsTypes.Code, scriptType signing.ScriptTy
 func Erc20AccountCode(ethereumAccountCode accountsTypes.Code, tokenCode string) accountsTypes.Code {
 	return accountsTypes.Code(fmt.Sprintf("%s-%s", ethereumAccountCode, tokenCode))
 }
+
+// multisigAccountCode returns an account code for a multisig account, based on the root fingerprint
+// of our keystore, a coin code and a hash of the multisig wallet policy, so that the same keystore
+// can take part in multiple multisig wallets.
+func multisigAccountCode(
+	rootFingerprint []byte, coinCode coin.Code, multisig *signing.BitcoinMultisig) accountsTypes.Code {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s;%d", multisig.ScriptType, multisig.Threshold)
+	for _, keyInfo := range multisig.KeyInfos {
+		fmt.Fprintf(hash, ";%s", keyInfo.Encode())
+	}
+	return accountsTypes.Code(fmt.Sprintf(
+		"v0-%x-%s-multisig-%x", rootFingerprint, coinCode, hash.Sum(nil)[:8]))
+}
+
+// watchonlyAccountCode returns an account code for a watch-only account added from an xpub or
+// descriptors, based on the root fingerprint, a coin code and a hash of the signing configurations,
+// as the same root fingerprint can be used for multiple watch-only accounts.
+func watchonlyAccountCode(
+	rootFingerprint []byte, coinCode coin.Code, signingConfigurations signing.Configurations) accountsTypes.Code {
+	hash := sha256.New()
+	for _, signingConfiguration := range signingConfigurations {
+		fmt.Fprintf(hash, "%s;%s;", signingConfiguration.ScriptType(), signingConfiguration.ExtendedPublicKey())
+	}
+	return accountsTypes.Code(fmt.Sprintf(
+		"v0-%x-%s-watchonly-%x", rootFingerprint, coinCode, hash.Sum(nil)[:8]))
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/addresses"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/blockchain"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/silentpayments"
 	coinpkg "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/config"
@@ -496,7 +497,7 @@ func findHiddenAccount(
 	var result *config.Account
 
 	for _, account := range accountsConfig.Accounts {
-		if coinCode != account.CoinCode {
+		if coinCode != account.CoinCode || account.IsMultisig() {
 			continue
 		}
 		if !account.SigningConfigurations.ContainsRootFingerprint(rootFingerprint) {
@@ -526,7 +527,7 @@ func nextAccountNumber(coinCode coinpkg.Code, keystore keystore.Keystore, accoun
 	}
 	nextAccountNumber := uint16(0)
 	for _, account := range accountsConfig.Accounts {
-		if coinCode != account.CoinCode {
+		if coinCode != account.CoinCode || account.IsMultisig() {
 			continue
 		}
 		if !account.SigningConfigurations.ContainsRootFingerprint(rootFingerprint) {
@@ -665,6 +666,150 @@ func (backend *Backend) SetTokenActive(accountCode accountsTypes.Code, tokenCode
 	return nil
 }
 
+// SetAccountGapLimits sets the minimum gap limits used to scan the addresses of a BTC or LTC
+// account. If `gapLimits` is nil, the default gap limits are used again. The accounts are
+// reinitialized so the new gap limits are applied.
+func (backend *Backend) SetAccountGapLimits(accountCode accountsTypes.Code, gapLimits *config.GapLimits) error {
+	err := backend.config.ModifyAccountsConfig(func(accountsConfig *config.AccountsConfig) error {
+		acct := accountsConfig.Lookup(accountCode)
+		if acct == nil {
+			return errp.Newf("Could not find account %s", accountCode)
+		}
+		switch acct.CoinCode {
+		case coinpkg.CodeBTC, coinpkg.CodeTBTC, coinpkg.CodeRBTC, coinpkg.CodeLTC, coinpkg.CodeTLTC:
+		default:
+			return errp.Newf("Gap limits are not supported for %s accounts", acct.CoinCode)
+		}
+		if gapLimits != nil {
+			gapLimitsCopy := *gapLimits
+			gapLimits = &gapLimitsCopy
+		}
+		acct.GapLimits = gapLimits
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	backend.ReinitializeAccounts()
+	return nil
+}
+
+// SetAccountDustProtection configures the detection of dust attacks for a BTC or LTC account. If
+// `dustProtection` is nil, the default dust threshold is used and dust outputs are not quarantined.
+func (backend *Backend) SetAccountDustProtection(
+	accountCode accountsTypes.Code, dustProtection *config.DustProtection) error {
+	err := backend.config.ModifyAccountsConfig(func(accountsConfig *config.AccountsConfig) error {
+		acct := accountsConfig.Lookup(accountCode)
+		if acct == nil {
+			return errp.Newf("Could not find account %s", accountCode)
+		}
+		switch acct.CoinCode {
+		case coinpkg.CodeBTC, coinpkg.CodeTBTC, coinpkg.CodeRBTC, coinpkg.CodeLTC, coinpkg.CodeTLTC:
+		default:
+			return errp.Newf("Dust protection is not supported for %s accounts", acct.CoinCode)
+		}
+		if dustProtection != nil {
+			dustProtectionCopy := *dustProtection
+			dustProtection = &dustProtectionCopy
+		}
+		acct.DustProtection = dustProtection
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	backend.ReinitializeAccounts()
+	return nil
+}
+
+// SetAccountSilentPayments enables or disables receiving silent payments (BIP-352) for a BTC
+// account. Enabling requires the keystore of the account to be connected, as the scan key is
+// derived from it. Only blocks after the current tip are scanned for incoming payments. The
+// accounts are reinitialized so the change is applied.
+func (backend *Backend) SetAccountSilentPayments(accountCode accountsTypes.Code, enabled bool) error {
+	acct := backend.config.AccountsConfig().Lookup(accountCode)
+	if acct == nil {
+		return errp.Newf("Could not find account %s", accountCode)
+	}
+	var silentPayments *config.SilentPayments
+	if enabled {
+		if acct.SilentPayments != nil {
+			return nil
+		}
+		var err error
+		silentPayments, err = backend.silentPaymentsConfig(acct)
+		if err != nil {
+			return err
+		}
+	}
+	err := backend.config.ModifyAccountsConfig(func(accountsConfig *config.AccountsConfig) error {
+		acct := accountsConfig.Lookup(accountCode)
+		if acct == nil {
+			return errp.Newf("Could not find account %s", accountCode)
+		}
+		acct.SilentPayments = silentPayments
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	backend.ReinitializeAccounts()
+	return nil
+}
+
+// silentPaymentsConfig derives the silent payment keys of the account using the connected
+// keystore. The accountsAndKeystoreLock must not be held when calling this function.
+func (backend *Backend) silentPaymentsConfig(acct *config.Account) (*config.SilentPayments, error) {
+	switch acct.CoinCode {
+	case coinpkg.CodeBTC, coinpkg.CodeTBTC, coinpkg.CodeRBTC:
+	default:
+		return nil, errp.Newf("Silent payments are not supported for %s accounts", acct.CoinCode)
+	}
+	if acct.IsMultisig() {
+		return nil, errp.New("Silent payments are not supported for multisig accounts")
+	}
+	defer backend.accountsAndKeystoreLock.RLock()()
+	if backend.keystore == nil {
+		return nil, errp.New("The keystore of the account must be connected")
+	}
+	rootFingerprint, err := backend.keystore.RootFingerprint()
+	if err != nil {
+		return nil, err
+	}
+	if !acct.SigningConfigurations.ContainsRootFingerprint(rootFingerprint) {
+		return nil, errp.New("The keystore of the account must be connected")
+	}
+	coin, err := backend.Coin(acct.CoinCode)
+	if err != nil {
+		return nil, err
+	}
+	btcCoin, ok := coin.(*btc.Coin)
+	if !ok {
+		return nil, errp.New("unexpected coin type")
+	}
+	signingConfiguration := acct.SigningConfigurations[0]
+	accountNumber, err := signingConfiguration.AccountNumber()
+	if err != nil {
+		return nil, err
+	}
+	coinType := signingConfiguration.AbsoluteKeypath().ToUInt32()[1]
+	keypath := silentpayments.AccountKeypath(coinType, accountNumber)
+	scanKey, spendXpub, err := backend.keystore.SilentPaymentKeys(coin, keypath)
+	if err != nil {
+		return nil, err
+	}
+	birthHeight := 0
+	if headers := btcCoin.Headers(); headers != nil {
+		birthHeight = headers.TipHeight()
+	}
+	return &config.SilentPayments{
+		Configuration: signing.NewBitcoinConfiguration(
+			signing.ScriptTypeP2TR, rootFingerprint, keypath.Child(0, signing.Hardened), spendXpub),
+		ScanKey:     scanKey.Serialize(),
+		BirthHeight: birthHeight,
+	}, nil
+}
+
 // RenameAccount renames an account in the accounts database.
 func (backend *Backend) RenameAccount(accountCode accountsTypes.Code, name string) error {
 	if name == "" {
@@ -750,6 +895,7 @@ func (backend *Backend) addAccount(account accounts.Interface) {
 		})
 		if event.Subject == string(accountsTypes.EventSyncDone) {
 			backend.notifyNewTxs(account)
+			go backend.notifyDustOutputs(account)
 			go backend.checkAccountUsed(account)
 		}
 	})
@@ -787,6 +933,11 @@ func (backend *Backend) createAndAddAccount(coin coinpkg.Coin, persistedConfig *
 			keystoreName := ""
 			persistedKeystore, err := backend.config.AccountsConfig().LookupKeystore(accountRootFingerprint)
 			if err == nil {
+				if persistedKeystore.Synthetic {
+					// The account was added from an xpub or descriptors and there is no keystore to
+					// connect to.
+					return nil, errp.WithStack(keystore.ErrCannotSign)
+				}
 				keystoreName = persistedKeystore.Name
 			}
 			var ks keystore.Keystore
@@ -981,9 +1132,13 @@ func (backend *Backend) persistAccount(account config.Account, accountsConfig *c
 		}
 		if account.CoinCode == account2.CoinCode {
 			// We detect a duplicate account (subaccount in a unified account) if any of the
-			// configurations is already present.
+			// configurations is already present. Multisig accounts share our xpub among multisig
+			// wallets with different cosigners, so they are only detected by their account code.
 			for _, config := range account.SigningConfigurations {
 				for _, config2 := range account2.SigningConfigurations {
+					if config.BitcoinMultisig != nil || config2.BitcoinMultisig != nil {
+						continue
+					}
 					if config.ExtendedPublicKey().String() == config2.ExtendedPublicKey().String() {
 						return errp.WithStack(errAccountAlreadyExists)
 					}
@@ -1283,6 +1438,9 @@ func (backend *Backend) maybeAddP2TR(keystore keystore.Keystore, accounts []*con
 		return nil
 	}
 	for _, account := range accounts {
+		if account.IsMultisig() {
+			continue
+		}
 		if account.CoinCode == coinpkg.CodeBTC ||
 			account.CoinCode == coinpkg.CodeTBTC ||
 			account.CoinCode == coinpkg.CodeRBTC {
@@ -1302,7 +1460,9 @@ func (backend *Backend) maybeAddP2TR(keystore keystore.Keystore, accounts []*con
 				}
 				accountNumber, err := account.SigningConfigurations[0].AccountNumber()
 				if err != nil {
-					return err
+					// Watch-only accounts added from an xpub or descriptors can have a
+					// non-standard keypath.
+					continue
 				}
 				keypath := signing.NewAbsoluteKeypathFromUint32(
 					86+hdkeychain.HardenedKeyStart,
@@ -1443,7 +1603,7 @@ func (backend *Backend) maybeAddHiddenUnusedAccounts() {
 		maxAccountNumber := -1
 		var maxAccount *config.Account
 		for _, accountConfig := range cfg.Accounts {
-			if coinCode != accountConfig.CoinCode {
+			if coinCode != accountConfig.CoinCode || accountConfig.IsMultisig() {
 				continue
 			}
 			if !accountConfig.SigningConfigurations.ContainsRootFingerprint(rootFingerprint) {
@@ -48,11 +48,20 @@ type PaymentRequest struct {
 	Signature     []byte
 }
 
+// TxRecipient is a recipient of a transaction.
+type TxRecipient struct {
+	Address string
+	Amount  coin.SendAmount
+}
+
 // TxProposalArgs are the arguments needed when creating a tx proposal.
 type TxProposalArgs struct {
 	RecipientAddress string
 	Amount           coin.SendAmount
-	FeeTargetCode    FeeTargetCode
+	// AdditionalRecipients are paid in the same transaction as RecipientAddress (batch payment). At
+	// most one of all recipients can use send-all, receiving the remaining funds.
+	AdditionalRecipients []TxRecipient
+	FeeTargetCode        FeeTargetCode
 	// Only applies if FeeTargetCode == Custom. It is provided in sat/vB for BTC/LTC and Gwei for ETH.
 	CustomFee string
 	// Option to always use the highest fee rate without specifying FeeTargetCode or CustomFee
@@ -60,6 +69,21 @@ type TxProposalArgs struct {
 	SelectedUTXOs  map[wire.OutPoint]struct{}
 	Note           string
 	PaymentRequest *PaymentRequest
+	// LockTime is the absolute locktime of the transaction: the transaction can't be mined before
+	// this block height, or before this Unix timestamp if it is 500000000 or higher. If 0, no
+	// locktime is requested. Only supported for Bitcoin and Litecoin.
+	LockTime uint32
+	// Payjoin is the BIP-78 payjoin endpoint of the receiver, e.g. the `pj` parameter of a BIP-21
+	// payment URI. If not empty, the receiver is asked to add their inputs to the transaction. Only
+	// supported for Bitcoin and Litecoin.
+	Payjoin string
+}
+
+// Recipients returns all recipients of the transaction, starting with RecipientAddress.
+func (args *TxProposalArgs) Recipients() []TxRecipient {
+	return append(
+		[]TxRecipient{{Address: args.RecipientAddress, Amount: args.Amount}},
+		args.AdditionalRecipients...)
 }
 
 // Interface is the API of a Account.
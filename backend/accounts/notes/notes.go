@@ -32,6 +32,9 @@ type Data struct {
 
 	// a map of transaction ID to transaction note.
 	TransactionNotes map[string]string `json:"transactions"`
+	// a set of outpoints (`<txid>:<index>`) of outputs which are frozen, i.e. excluded from being
+	// spent.
+	FrozenOutputs map[string]bool `json:"frozenOutputs,omitempty"`
 }
 
 // read deserializes the json files into notes. If the file does not exist yet, no error is
@@ -119,6 +122,33 @@ func (notes *Notes) TxNote(txID string) string {
 	return notes.data.TransactionNotes[txID]
 }
 
+// SetOutputFrozen marks the output with the given outpoint (`<txid>:<index>`) as frozen or
+// unfrozen. Unfrozen outputs are deleted from the file, since `OutputFrozen()` returns false anyway
+// if there is no entry. Returns whether the flag was modified.
+func (notes *Notes) SetOutputFrozen(outPoint string, frozen bool) (bool, error) {
+	notes.dataMu.Lock()
+	defer notes.dataMu.Unlock()
+
+	if notes.data.FrozenOutputs == nil {
+		notes.data.FrozenOutputs = map[string]bool{}
+	}
+	changed := notes.data.FrozenOutputs[outPoint] != frozen
+	if frozen {
+		notes.data.FrozenOutputs[outPoint] = true
+	} else {
+		delete(notes.data.FrozenOutputs, outPoint)
+	}
+	return changed, write(notes.data, notes.filename)
+}
+
+// OutputFrozen returns true if the output with the given outpoint (`<txid>:<index>`) is frozen.
+func (notes *Notes) OutputFrozen(outPoint string) bool {
+	notes.dataMu.RLock()
+	defer notes.dataMu.RUnlock()
+
+	return notes.data.FrozenOutputs[outPoint]
+}
+
 // Data retrieves all stored notes. You must not modify the returned object.
 func (notes *Notes) Data() *Data {
 	notes.dataMu.RLock()
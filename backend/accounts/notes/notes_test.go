@@ -78,6 +78,44 @@ func TestNotesPersisted(t *testing.T) {
 	require.Equal(t, "", notes.TxNote("some-tx-id"))
 }
 
+func TestFrozenOutputs(t *testing.T) {
+	filename := test.TstTempFile("account-notes")
+	notes, err := LoadNotes(filename)
+	require.NoError(t, err)
+
+	require.False(t, notes.OutputFrozen("tx-id-1:0"))
+
+	changed, err := notes.SetOutputFrozen("tx-id-1:0", true)
+	require.NoError(t, err)
+	require.True(t, changed)
+
+	changed, err = notes.SetOutputFrozen("tx-id-1:0", true)
+	require.NoError(t, err)
+	require.False(t, changed)
+
+	_, err = notes.SetOutputFrozen("tx-id-2:1", true)
+	require.NoError(t, err)
+	require.True(t, notes.OutputFrozen("tx-id-1:0"))
+	require.False(t, notes.OutputFrozen("tx-id-1:1"))
+
+	// Reload notes.
+	notes, err = LoadNotes(filename)
+	require.NoError(t, err)
+	require.True(t, notes.OutputFrozen("tx-id-1:0"))
+	require.True(t, notes.OutputFrozen("tx-id-2:1"))
+
+	changed, err = notes.SetOutputFrozen("tx-id-2:1", false)
+	require.NoError(t, err)
+	require.True(t, changed)
+	require.Equal(t,
+		&Data{
+			FrozenOutputs: map[string]bool{
+				"tx-id-1:0": true,
+			},
+		},
+		notes.Data())
+}
+
 // TestMaxLen checks that notes that are too long are rejected.
 func TestMaxLen(t *testing.T) {
 	filename := test.TstTempFile("account-notes")
@@ -48,7 +48,8 @@ const (
 	// for the respective coin.
 	TxStatusComplete TxStatus = "complete"
 	// TxStatusFailed means the tx is confirmed but considered failed, e.g. a ETH transaction with a
-	// too low gas limit.
+	// too low gas limit, or a BTC transaction which was cancelled by a conflicting transaction that
+	// confirmed.
 	TxStatusFailed TxStatus = "failed"
 )
 
@@ -120,6 +121,12 @@ type TransactionData struct {
 	// Weight is the tx weight.
 	Weight           int64
 	CreatedTimestamp *time.Time
+	// ReplacesTxID is the ID of the unconfirmed transaction this transaction replaces by paying a
+	// higher fee (RBF), or empty if it is not a replacement.
+	ReplacesTxID string
+	// ReplacedByTxID is the ID of the confirmed transaction which cancelled this transaction by
+	// spending its inputs back to the wallet, or empty if it was not cancelled.
+	ReplacedByTxID string
 
 	// --- Fields only used for ETH follow
 
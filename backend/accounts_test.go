@@ -30,12 +30,14 @@ import (
 	coinpkg "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/config"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/keystore"
 	keystoremock "github.com/BitBoxSwiss/bitbox-wallet-app/backend/keystore/mocks"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/keystore/software"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/rates"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/util/test"
+	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/btcsuite/btcd/btcutil/hdkeychain"
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/sirupsen/logrus"
@@ -1058,6 +1060,76 @@ func TestRenameAccount(t *testing.T) {
 	require.Equal(t, "renamed", b.config.AccountsConfig().Lookup("v0-55555555-btc-0").Name)
 }
 
+func TestSetAccountGapLimits(t *testing.T) {
+	b := newBackend(t, testnetDisabled, regtestDisabled)
+	defer b.Close()
+	b.registerKeystore(makeBitBox02Multi())
+
+	gapLimits := &config.GapLimits{Receive: 100, Change: 30}
+	require.NoError(t, b.SetAccountGapLimits("v0-55555555-btc-0", gapLimits))
+	require.Equal(t, gapLimits, b.config.AccountsConfig().Lookup("v0-55555555-btc-0").GapLimits)
+	require.Equal(t, gapLimits, b.Accounts().lookup("v0-55555555-btc-0").Config().Config.GapLimits)
+
+	require.NoError(t, b.SetAccountGapLimits("v0-55555555-btc-0", nil))
+	require.Nil(t, b.config.AccountsConfig().Lookup("v0-55555555-btc-0").GapLimits)
+
+	require.Error(t, b.SetAccountGapLimits("v0-55555555-eth-0", gapLimits))
+	require.Error(t, b.SetAccountGapLimits("unknown", gapLimits))
+}
+
+func TestSetAccountDustProtection(t *testing.T) {
+	b := newBackend(t, testnetDisabled, regtestDisabled)
+	defer b.Close()
+	b.registerKeystore(makeBitBox02Multi())
+
+	dustProtection := &config.DustProtection{Threshold: 2000, Quarantine: true}
+	require.NoError(t, b.SetAccountDustProtection("v0-55555555-btc-0", dustProtection))
+	require.Equal(t, dustProtection, b.config.AccountsConfig().Lookup("v0-55555555-btc-0").DustProtection)
+	require.Equal(t, dustProtection, b.Accounts().lookup("v0-55555555-btc-0").Config().Config.DustProtection)
+	require.NoError(t, b.SetAccountDustProtection("v0-55555555-ltc-0", dustProtection))
+
+	require.NoError(t, b.SetAccountDustProtection("v0-55555555-btc-0", nil))
+	require.Nil(t, b.config.AccountsConfig().Lookup("v0-55555555-btc-0").DustProtection)
+
+	require.Error(t, b.SetAccountDustProtection("v0-55555555-eth-0", dustProtection))
+	require.Error(t, b.SetAccountDustProtection("unknown", dustProtection))
+}
+
+func TestSetAccountSilentPayments(t *testing.T) {
+	b := newBackend(t, testnetDisabled, regtestDisabled)
+	defer b.Close()
+	ks := makeBitBox02Multi()
+	ks.SilentPaymentKeysFunc = keystoreHelper1().SilentPaymentKeys
+	b.registerKeystore(ks)
+
+	require.NoError(t, b.SetAccountSilentPayments("v0-55555555-btc-0", true))
+	silentPayments := b.config.AccountsConfig().Lookup("v0-55555555-btc-0").SilentPayments
+	require.NotNil(t, silentPayments)
+	require.Equal(t, "m/352'/0'/0'/0'", silentPayments.Configuration.AbsoluteKeypath().Encode())
+	require.Equal(t, signing.ScriptTypeP2TR, silentPayments.Configuration.ScriptType())
+	require.Len(t, silentPayments.ScanKey, 32)
+	require.Equal(t, silentPayments, b.Accounts().lookup("v0-55555555-btc-0").Config().Config.SilentPayments)
+	require.Len(t, ks.SilentPaymentKeysCalls(), 1)
+
+	// Enabling again keeps the keys and birth height.
+	require.NoError(t, b.SetAccountSilentPayments("v0-55555555-btc-0", true))
+	require.Len(t, ks.SilentPaymentKeysCalls(), 1)
+
+	require.NoError(t, b.SetAccountSilentPayments("v0-55555555-btc-0", false))
+	require.Nil(t, b.config.AccountsConfig().Lookup("v0-55555555-btc-0").SilentPayments)
+
+	require.Error(t, b.SetAccountSilentPayments("v0-55555555-ltc-0", true))
+	require.Error(t, b.SetAccountSilentPayments("v0-55555555-eth-0", true))
+	require.Error(t, b.SetAccountSilentPayments("unknown", true))
+
+	// The keystore does not support silent payments.
+	ks.SilentPaymentKeysFunc = func(coinpkg.Coin, signing.AbsoluteKeypath) (*btcec.PrivateKey, *hdkeychain.ExtendedKey, error) {
+		return nil, nil, errp.WithStack(keystore.ErrUnsupportedFeature)
+	}
+	require.ErrorIs(t, b.SetAccountSilentPayments("v0-55555555-btc-0", true), keystore.ErrUnsupportedFeature)
+	require.Nil(t, b.config.AccountsConfig().Lookup("v0-55555555-btc-0").SilentPayments)
+}
+
 func TestMaybeAddHiddenUnusedAccounts(t *testing.T) {
 	b := newBackend(t, testnetDisabled, regtestDisabled)
 	defer b.Close()
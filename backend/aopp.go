@@ -23,6 +23,8 @@ import (
 
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
 	accountsTypes "github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/types"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/addresses"
 	coinpkg "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/keystore"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
@@ -68,6 +70,7 @@ var aoppBTCScriptTypeMap = map[string]signing.ScriptType{
 	"p2pkh":  signing.ScriptTypeP2PKH,
 	"p2wpkh": signing.ScriptTypeP2WPKH,
 	"p2sh":   signing.ScriptTypeP2WPKHP2SH,
+	"p2tr":   signing.ScriptTypeP2TR,
 }
 
 type account struct {
@@ -370,17 +373,44 @@ func (backend *Backend) aoppChooseAccount(code accountsTypes.Code) {
 	}
 	switch account.Coin().Code() {
 	case coinpkg.CodeBTC:
-		sig, err := backend.keystore.SignBTCMessage(
-			[]byte(backend.aopp.Message),
-			addr.AbsoluteKeypath(),
-			account.Config().Config.SigningConfigurations[signingConfigIdx].ScriptType(),
-		)
+		scriptType := account.Config().Config.SigningConfigurations[signingConfigIdx].ScriptType()
+		var sig []byte
+		var err error
+		if scriptType == signing.ScriptTypeP2TR {
+			// Legacy message signatures are not defined for taproot, so a BIP-322 signature is
+			// used.
+			btcCoin, ok := account.Coin().(*btc.Coin)
+			accountAddress, ok2 := addr.(*addresses.AccountAddress)
+			if !ok || !ok2 {
+				log.Error("unexpected coin or address type")
+				backend.aoppSetError(errAOPPUnknown)
+				return
+			}
+			sig, _, err = btc.SignMessageBIP322(
+				backend.keystore,
+				btcCoin,
+				accountAddress,
+				[]byte(backend.aopp.Message),
+				btc.BIP322FormatSimple,
+			)
+		} else {
+			sig, err = backend.keystore.SignBTCMessage(
+				[]byte(backend.aopp.Message),
+				addr.AbsoluteKeypath(),
+				scriptType,
+			)
+		}
 		if err != nil {
-			if firmware.IsErrorAbort(err) {
+			if firmware.IsErrorAbort(err) || errp.Cause(err) == keystore.ErrSigningAborted {
 				log.WithError(err).Error("user aborted msg signing")
 				backend.aoppSetError(errAOPPSigningAborted)
 				return
 			}
+			if errp.Cause(err) == keystore.ErrUnsupportedFeature {
+				log.WithError(err).Error("keystore does not support BIP-322 signatures")
+				backend.aoppSetError(errAOPPUnsupportedFormat)
+				return
+			}
 			log.WithError(err).Error("signing error")
 			backend.aoppSetError(errAOPPUnknown)
 			return
@@ -15,6 +15,7 @@
 package backend
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -25,9 +26,11 @@ import (
 	accountsTypes "github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/types"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc"
 	coinpkg "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/keystore"
 	keystoremock "github.com/BitBoxSwiss/bitbox-wallet-app/backend/keystore/mocks"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/keystore/software"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/util/test"
 	"github.com/BitBoxSwiss/bitbox02-api-go/api/firmware"
 	"github.com/btcsuite/btcd/btcutil/hdkeychain"
@@ -294,6 +297,45 @@ func TestAOPPSuccess(t *testing.T) {
 		require.Equal(t, aoppStateSuccess, b.AOPP().State)
 	})
 
+	// Taproot addresses are signed using BIP-322.
+	t.Run("p2tr", func(t *testing.T) {
+		b := newBackend(t, testnetDisabled, regtestDisabled)
+		defer b.Close()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				Address   string `json:"address"`
+				Signature string `json:"signature"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			result, err := b.VerifyMessage(coinpkg.CodeBTC, body.Address, dummyMsg, body.Signature, false)
+			require.NoError(t, err)
+			require.True(t, result.Valid)
+			require.Equal(t, MessageSignatureFormatBIP322Simple, result.Format)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		params := defaultParams()
+		params.Set("format", "p2tr")
+		params.Set("callback", server.URL)
+		b.HandleURI(uriPrefix + params.Encode())
+		b.AOPPApprove()
+		ks := makeKeystore(t, scriptTypeRef(signing.ScriptTypeP2TR), keystoreHelper.ExtendedPublicKey)
+		ks.SignTransactionFunc = keystoreHelper.SignTransaction
+		b.registerKeystore(ks)
+		require.Equal(t, aoppStateSuccess, b.AOPP().State)
+		require.Equal(t, "bc1pyezv4xh2tlfm0a3dznswx4qm6k27y34mmvvxeaj6ank6e7wnh79qhfaxqw", b.AOPP().Address)
+
+		// The keystore does not support BIP-322 signatures.
+		ks.SignTransactionFunc = func(interface{}) error {
+			return errp.WithStack(keystore.ErrUnsupportedFeature)
+		}
+		b.HandleURI(uriPrefix + params.Encode())
+		b.AOPPApprove()
+		require.Equal(t, aoppStateError, b.AOPP().State)
+		require.Equal(t, errAOPPUnsupportedFormat, b.AOPP().ErrorCode)
+	})
+
 	// Keystore is already registered before the AOPP request.
 	t.Run("user-approve", func(t *testing.T) {
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -34,6 +34,7 @@ import (
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/addresses"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/blockchain"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/electrum"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/silentpayments"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/types"
 	coinpkg "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth"
@@ -112,6 +113,9 @@ type event string
 const (
 	// eventNewTxs is emitted when the user should be notified of new transactions.
 	eventNewTxs event = "new-txs"
+	// eventDustOutputs is emitted when the user should be notified of dust outputs, which are
+	// likely part of a dust attack.
+	eventDustOutputs event = "dust-outputs"
 )
 
 type deviceEvent struct {
@@ -203,6 +207,8 @@ type Backend struct {
 	connectKeystore connectKeystore
 
 	aopp AOPP
+	// paymentURI is the payment request of the last handled payment URI, nil if there is none.
+	paymentURI *PaymentURI
 
 	// makeBtcAccount creates a BTC account. In production this is `btc.NewAccount`, but can be
 	// overridden in unit tests for mocking.
@@ -341,6 +347,33 @@ func (backend *Backend) notifyNewTxs(account accounts.Interface) {
 	}
 }
 
+// notifyDustOutputs flags the dust outputs newly received by a BTC or LTC account and notifies the
+// user about them, see btc.Account.FlagDustOutputs().
+func (backend *Backend) notifyDustOutputs(account accounts.Interface) {
+	btcAccount, ok := account.(*btc.Account)
+	if !ok {
+		return
+	}
+	dustOutputs, err := btcAccount.FlagDustOutputs()
+	if err != nil {
+		backend.log.WithError(err).Error("error flagging dust outputs")
+		return
+	}
+	if len(dustOutputs) != 0 {
+		dustProtection := account.Config().Config.DustProtection
+		backend.Notify(observable.Event{
+			Subject: string(eventDustOutputs),
+			Action:  action.Replace,
+			Object: map[string]interface{}{
+				"count":       len(dustOutputs),
+				"accountName": account.Config().Config.Name,
+				"accountCode": account.Config().Config.Code,
+				"quarantined": dustProtection != nil && dustProtection.Quarantine,
+			},
+		})
+	}
+}
+
 // Config returns the app config.
 func (backend *Backend) Config() *config.Config {
 	return backend.config
@@ -441,6 +474,21 @@ func (backend *Backend) defaultProdServers(code coinpkg.Code) []*config.ServerIn
 	}
 }
 
+// tweakIndexURL returns the URL of the server used to scan for silent payments, or an empty string
+// if receiving silent payments is not enabled for the coin.
+func (backend *Backend) tweakIndexURL(code coinpkg.Code) string {
+	switch code {
+	case coinpkg.CodeBTC:
+		return backend.config.AppConfig().Backend.BTC.TweakIndexURL
+	case coinpkg.CodeTBTC:
+		return backend.config.AppConfig().Backend.TBTC.TweakIndexURL
+	case coinpkg.CodeRBTC:
+		return backend.config.AppConfig().Backend.RBTC.TweakIndexURL
+	default:
+		return ""
+	}
+}
+
 func defaultDevServers(code coinpkg.Code) []*config.ServerInfo {
 	// O=Shift Crypto, CN=ShiftCrypto DEV R1
 	// Serial: f67ab2bc7470c90ce027ce778a274384
@@ -541,6 +589,11 @@ func (backend *Backend) Coin(code coinpkg.Code) (coinpkg.Coin, error) {
 	default:
 		return nil, errp.Newf("unknown coin code %s", code)
 	}
+	if btcCoin, ok := coin.(*btc.Coin); ok {
+		if tweakIndexURL := backend.tweakIndexURL(code); tweakIndexURL != "" {
+			btcCoin.SetTweakIndex(silentpayments.NewBlindBitOracle(tweakIndexURL, backend.httpClient))
+		}
+	}
 	backend.coins[code] = coin
 	coin.Observe(backend.Notify)
 	return coin, nil
@@ -711,6 +764,7 @@ func (backend *Backend) registerKeystore(keystore keystore.Keystore) {
 		keystoreCfg := accountsConfig.GetOrAddKeystore(fingerprint)
 		keystoreCfg.Name = keystoreName
 		keystoreCfg.LastConnected = time.Now()
+		keystoreCfg.Synthetic = false
 		return nil
 	}
 
@@ -926,9 +980,9 @@ func (backend *Backend) Banners() *banners.Banners {
 	return backend.banners
 }
 
-// HandleURI handles an external URI click for registered protocols, e.g. 'aopp:?...' URIs.  The uri
-// param can be any string, as it is potentially passed without any validation from the calling
-// platform.
+// HandleURI handles an external URI click for registered protocols, e.g. 'aopp:?...' or
+// 'bitcoin:...' URIs.  The uri param can be any string, as it is potentially passed without any
+// validation from the calling platform.
 func (backend *Backend) HandleURI(uri string) {
 	u, err := url.Parse(uri)
 	if err != nil {
@@ -938,6 +992,10 @@ func (backend *Backend) HandleURI(uri string) {
 	switch u.Scheme {
 	case "aopp":
 		backend.handleAOPP(*u)
+	case "bitcoin", "litecoin":
+		backend.handleBIP21(*u)
+	case "ethereum":
+		backend.handleEIP681(*u)
 	default:
 		backend.log.Warningf("Unknown URI scheme: %s", uri)
 	}
@@ -982,6 +1040,9 @@ func (backend *Backend) SetWatchonly(rootFingerprint []byte, watchonly bool) err
 		if err != nil {
 			return err
 		}
+		if ks.Synthetic && !watchonly {
+			return errp.New("The accounts of this keystore can only be watched")
+		}
 		ks.Watchonly = watchonly
 		return nil
 	})
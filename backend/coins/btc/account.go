@@ -23,6 +23,7 @@ import (
 	"path"
 	"sort"
 	"sync/atomic"
+	"time"
 
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
 	accountsTypes "github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/types"
@@ -68,6 +69,15 @@ type subaccount struct {
 	changeAddresses      *addresses.AddressChain
 }
 
+// unusedChangeAddress returns the first unused change address of the subaccount.
+func (subacc subaccount) unusedChangeAddress() (*addresses.AccountAddress, error) {
+	unusedAddresses, err := subacc.changeAddresses.GetUnused()
+	if err != nil {
+		return nil, err
+	}
+	return unusedAddresses[0], nil
+}
+
 type subaccounts []subaccount
 
 func (sa subaccounts) signingConfigurations() signing.Configurations {
@@ -104,6 +114,9 @@ type Account struct {
 	// if not nil, SendTx() will sign and send this transaction. Set by TxProposal().
 	activeTxProposal     *maketx.TxProposal
 	activeTxProposalLock locker.Locker
+	// if not nil, SendSweep() will broadcast this signed transaction. Set by SweepProposal(). Also
+	// protected by activeTxProposalLock.
+	activeSweepTx *wire.MsgTx
 
 	// Access this only via getMinRelayFeeRate(). sat/kB.
 	minRelayFeeRate     *btcutil.Amount
@@ -115,6 +128,24 @@ type Account struct {
 
 	fatalError atomic.Bool
 
+	// Serializes DeepRescan() calls.
+	deepRescanLock locker.Locker
+
+	// Serializes broadcasting and removing scheduled transactions, see broadcastScheduledTxs().
+	scheduledTxsLock        locker.Locker
+	unsubscribeHeadersEvent func()
+
+	// Not nil if receiving silent payments is enabled, see silentpayments.go.
+	silentPayments *silentPaymentKeys
+	// Addresses of the outputs received through silent payments, by script hash.
+	silentPaymentAddresses     map[blockchain.ScriptHashHex]*addresses.AccountAddress
+	silentPaymentAddressesLock locker.Locker
+	// Serializes scanSilentPayments() calls.
+	silentPaymentsScanLock locker.Locker
+
+	// Serializes FlagDustOutputs() calls.
+	dustOutputsLock locker.Locker
+
 	closed bool
 
 	log *logrus.Entry
@@ -181,7 +212,8 @@ func (account *Account) defaultGapLimits(signingConfiguration *signing.Configura
 
 // gapLimits gets the gap limits as stored in the account configuration, and defaults to
 // `defaultGapLimits()` if there is no configuration or the configuration limits are smaller than
-// the default limits.
+// the default limits. The gap limits configured in the persisted account config (`config.Account`)
+// are used as minimums.
 //
 // The default gap limits can be different for each subaccount (e.g. legacy script type needs a
 // higher gap limit), but if the gap limits are forced and stored (user wants higher gap limits),
@@ -209,6 +241,14 @@ func (account *Account) gapLimits(
 		if err != nil {
 			return types.GapLimits{}, err
 		}
+		if configLimits := account.Config().Config.GapLimits; configLimits != nil {
+			if limits.Receive < configLimits.Receive {
+				limits.Receive = configLimits.Receive
+			}
+			if limits.Change < configLimits.Change {
+				limits.Change = configLimits.Change
+			}
+		}
 		if limits.Receive < defaultLimits.Receive {
 			if account.forceGapLimits != nil { // log only when it's interesting
 				account.log.Infof("receive gap limit increased to minimum of %d", defaultLimits.Receive)
@@ -324,6 +364,12 @@ func (account *Account) Initialize() error {
 	account.transactions = transactions.NewTransactions(
 		account.coin.Net(), account.db, theHeaders, account.Synchronizer,
 		account.coin.Blockchain(), account.notifier, account.log)
+	if silentPayments := account.Config().Config.SilentPayments; silentPayments != nil {
+		if err := account.initializeSilentPayments(silentPayments); err != nil {
+			return err
+		}
+	}
+	account.unsubscribeHeadersEvent = theHeaders.SubscribeEvent(account.onHeadersEvent)
 
 	for _, signingConfiguration := range signingConfigurations {
 
@@ -344,6 +390,10 @@ func (account *Account) Initialize() error {
 	}
 
 	go account.ensureAddresses()
+	if account.silentPayments != nil {
+		account.subscribeSilentPaymentAddresses()
+		go account.scanSilentPayments()
+	}
 
 	return account.BaseAccount.Initialize(accountIdentifier)
 }
@@ -387,6 +437,11 @@ func (account *Account) Info() *accounts.Info {
 		if isInsuredAccount && !isNativeSegwit {
 			continue
 		}
+		if subacc.signingConfiguration.BitcoinMultisig != nil {
+			// The cosigner xpubs are shown as they were imported.
+			signingConfigurations = append(signingConfigurations, subacc.signingConfiguration)
+			continue
+		}
 		xpub := subacc.signingConfiguration.ExtendedPublicKey()
 		if xpub.IsPrivate() {
 			panic("xpub can't be private")
@@ -431,6 +486,9 @@ func (account *Account) Close() {
 	// TODO: deregister from json RPC client. The client can be closed when no account uses
 	// the client any longer.
 	account.ResetSynced()
+	if account.unsubscribeHeadersEvent != nil {
+		account.unsubscribeHeadersEvent()
+	}
 	if account.transactions != nil {
 		account.transactions.Close()
 	}
@@ -462,9 +520,10 @@ func (account *Account) Notifier() accounts.Notifier {
 
 // feeTargets fetches the available fees. For mainnet BTC it uses mempool.space estimation.
 //
-// For the other coins or in case mempool.space is not available it fallbacks on Bitcoin Core.
-// The minimum relay fee is used as a last resource fallback in case also Bitcoin Core is
-// unavailable.
+// For the other coins or in case mempool.space is not available, the fees are estimated from the
+// fee histogram of the mempool of the Electrum server, falling back to Bitcoin Core's estimation
+// if the histogram is not available. The minimum relay fee is used as a last resource fallback in
+// case also Bitcoin Core is unavailable.
 func (account *Account) feeTargets() FeeTargets {
 	// for mainnet BTC we fetch mempool.space fees, as they should be more reliable.
 	var mempoolFees *accounts.MempoolSpaceFees
@@ -481,8 +540,8 @@ func (account *Account) feeTargets() FeeTargets {
 	var feeTargets FeeTargets
 	if mempoolFees != nil {
 		feeTargets = FeeTargets{
-			{blocks: 3, code: accounts.FeeTargetCodeMempoolHour},
-			{blocks: 2, code: accounts.FeeTargetCodeMempoolHalfHour},
+			{blocks: 6, code: accounts.FeeTargetCodeMempoolHour},
+			{blocks: 3, code: accounts.FeeTargetCodeMempoolHalfHour},
 			{blocks: 1, code: accounts.FeeTargetCodeMempoolFastest},
 		}
 	} else {
@@ -500,40 +559,107 @@ func (account *Account) feeTargets() FeeTargets {
 		minRelayFeeRate = &minRelayFeeRateVal
 	}
 
+	if mempoolFees != nil {
+		for _, feeTarget := range feeTargets {
+			account.setFeeRate(
+				feeTarget, mempoolFees.GetFeeRate(feeTarget.code), FeeEstimateConfidenceHigh, minRelayFeeRate)
+		}
+		return feeTargets
+	}
+
+	histogram := account.mempoolFeeHistogram()
 	for _, feeTarget := range feeTargets {
-		var feeRatePerKb btcutil.Amount
+		account.estimateFeeRate(feeTarget, histogram, minRelayFeeRate)
+	}
+	return feeTargets
+}
 
-		if mempoolFees != nil {
-			feeRatePerKb = mempoolFees.GetFeeRate(feeTarget.code)
-		} else {
-			// If mempool.space fees are not available, we fallback on Bitcoin Core estimation.
-			// If even that one is not available, we just offer the min relay fee.
-			feeRatePerKb, err = account.coin.Blockchain().EstimateFee(feeTarget.blocks)
-			if err != nil {
-				if account.coin.Code() != coin.CodeTLTC {
-					account.log.WithField("fee-target", feeTarget.blocks).
-						Warning("Fee could not be estimated. Taking the minimum relay fee instead")
-				}
-				if minRelayFeeRate == nil {
-					account.log.WithField("fee-target", feeTarget.blocks).
-						Warning("Minimum relay fee could not be determined")
-					continue
-				}
-				feeRatePerKb = *minRelayFeeRate
-			}
+// mempoolFeeHistogram fetches the fee histogram of the mempool from the server. nil is returned if
+// it is not available, e.g. if the server does not support it.
+func (account *Account) mempoolFeeHistogram() blockchain.FeeHistogram {
+	histogram, err := account.coin.Blockchain().MempoolFeeHistogram()
+	if err != nil {
+		account.log.WithError(err).Warning("Fee histogram not available")
+		return nil
+	}
+	return histogram
+}
+
+// estimateFeeRate populates the fee rate of the fee target. If the fee histogram is available, the
+// fee rate is estimated from it. If the mempool does not fill the targeted blocks, the minimum
+// relay fee rate is enough to be confirmed in time.
+//
+// If the fee histogram is not available, we fallback on Bitcoin Core estimation. If even that one
+// is not available, we just offer the min relay fee.
+func (account *Account) estimateFeeRate(
+	feeTarget *FeeTarget, histogram blockchain.FeeHistogram, minRelayFeeRate *btcutil.Amount) {
+	if histogram != nil {
+		feeRatePerKb, ok := feeRateFromHistogram(histogram, feeTarget.blocks)
+		if ok {
+			account.setFeeRate(feeTarget, feeRatePerKb, FeeEstimateConfidenceHigh, minRelayFeeRate)
+			return
 		}
-		// If the minrelayfee is available the estimated fee rate is smaller than the minrelayfee,
-		// we use the minrelayfee instead. If the minrelayfee is unknown, we leave the fee
-		// estimation as is, hoping it will be enough for a transaction to get relayed.
-		if minRelayFeeRate != nil && feeRatePerKb < *minRelayFeeRate {
-			feeRatePerKb = *minRelayFeeRate
+		if minRelayFeeRate != nil {
+			account.setFeeRate(feeTarget, *minRelayFeeRate, FeeEstimateConfidenceMedium, minRelayFeeRate)
+			return
 		}
-		feeTarget.feeRatePerKb = &feeRatePerKb
-		account.log.WithFields(logrus.Fields{"blocks": feeTarget.blocks,
-			"fee-rate-per-kb": feeRatePerKb}).Debug("Fee estimate per kb")
 	}
+	feeRatePerKb, err := account.coin.Blockchain().EstimateFee(feeTarget.blocks)
+	if err == nil {
+		account.setFeeRate(feeTarget, feeRatePerKb, FeeEstimateConfidenceMedium, minRelayFeeRate)
+		return
+	}
+	if account.coin.Code() != coin.CodeTLTC {
+		account.log.WithField("fee-target", feeTarget.blocks).
+			Warning("Fee could not be estimated. Taking the minimum relay fee instead")
+	}
+	if minRelayFeeRate == nil {
+		account.log.WithField("fee-target", feeTarget.blocks).
+			Warning("Minimum relay fee could not be determined")
+		return
+	}
+	account.setFeeRate(feeTarget, *minRelayFeeRate, FeeEstimateConfidenceLow, minRelayFeeRate)
+}
 
-	return feeTargets
+// setFeeRate populates the fee rate of the fee target, along with its confidence and the expected
+// time until confirmation.
+func (account *Account) setFeeRate(
+	feeTarget *FeeTarget,
+	feeRatePerKb btcutil.Amount,
+	confidence FeeEstimateConfidence,
+	minRelayFeeRate *btcutil.Amount,
+) {
+	// If the minrelayfee is available the estimated fee rate is smaller than the minrelayfee,
+	// we use the minrelayfee instead. If the minrelayfee is unknown, we leave the fee
+	// estimation as is, hoping it will be enough for a transaction to get relayed.
+	if minRelayFeeRate != nil && feeRatePerKb < *minRelayFeeRate {
+		feeRatePerKb = *minRelayFeeRate
+	}
+	feeTarget.feeRatePerKb = &feeRatePerKb
+	feeTarget.confidence = confidence
+	feeTarget.eta = time.Duration(feeTarget.blocks) * account.coin.Net().TargetTimePerBlock
+	account.log.WithFields(logrus.Fields{"blocks": feeTarget.blocks,
+		"fee-rate-per-kb": feeRatePerKb, "confidence": confidence}).Debug("Fee estimate per kb")
+}
+
+// FeeEstimate estimates the fee rate needed for a transaction to be confirmed within the given
+// number of blocks. Unlike the fee targets, it does not use mempool.space, so arbitrary targets
+// can be estimated locally from the fee histogram of the Electrum server.
+func (account *Account) FeeEstimate(blocks int) (*FeeTarget, error) {
+	if blocks < 1 || blocks > 1008 {
+		return nil, errp.Newf("Invalid block target %d", blocks)
+	}
+	var minRelayFeeRate *btcutil.Amount
+	minRelayFeeRateVal, err := account.getMinRelayFeeRate()
+	if err == nil {
+		minRelayFeeRate = &minRelayFeeRateVal
+	}
+	feeTarget := &FeeTarget{blocks: blocks, code: accounts.FeeTargetCodeCustom}
+	account.estimateFeeRate(feeTarget, account.mempoolFeeHistogram(), minRelayFeeRate)
+	if feeTarget.feeRatePerKb == nil {
+		return nil, errp.New("Fee could not be estimated")
+	}
+	return feeTarget, nil
 }
 
 // FeeTargets returns the fee targets and the default fee target.
@@ -689,6 +815,78 @@ func (account *Account) ensureAddresses() {
 	}
 }
 
+// DeepRescan scans the addresses of the account with temporarily raised gap limits, to discover
+// funds on addresses beyond the regular gap limits, e.g. if another wallet skipped many addresses.
+// The gap limits are capped at maxGapLimit. If used addresses are found beyond the regular gap
+// limits, the gap limits needed to find them are persisted so that they are also found in future
+// syncs. The gap limits in effect after the rescan are returned.
+func (account *Account) DeepRescan(gapLimits types.GapLimits) (types.GapLimits, error) {
+	if !account.isInitialized() {
+		return types.GapLimits{}, errp.New("account not initialized")
+	}
+	defer account.deepRescanLock.Lock()()
+	if gapLimits.Receive > maxGapLimit {
+		gapLimits.Receive = maxGapLimit
+	}
+	if gapLimits.Change > maxGapLimit {
+		gapLimits.Change = maxGapLimit
+	}
+	account.log.Infof("deep rescan: receive=%d, change=%d", gapLimits.Receive, gapLimits.Change)
+
+	account.ResetSynced()
+	for _, subacc := range account.subaccounts {
+		subacc.receiveAddresses.SetGapLimit(int(gapLimits.Receive))
+		subacc.changeAddresses.SetGapLimit(int(gapLimits.Change))
+	}
+	account.ensureAddresses()
+	account.Synchronizer.WaitSynchronized()
+
+	required := types.GapLimits{}
+	for _, subacc := range account.subaccounts {
+		receive, err := subacc.receiveAddresses.RequiredGapLimit()
+		if err != nil {
+			return types.GapLimits{}, err
+		}
+		change, err := subacc.changeAddresses.RequiredGapLimit()
+		if err != nil {
+			return types.GapLimits{}, err
+		}
+		required.Receive = max(required.Receive, uint16(receive))
+		required.Change = max(required.Change, uint16(change))
+	}
+
+	err := transactions.DBUpdate(account.db, func(dbTx transactions.DBTxInterface) error {
+		limits, err := dbTx.GapLimits()
+		if err != nil {
+			return err
+		}
+		if required.Receive <= limits.Receive && required.Change <= limits.Change {
+			return nil
+		}
+		limits.Receive = max(limits.Receive, required.Receive)
+		limits.Change = max(limits.Change, required.Change)
+		account.log.Infof("deep rescan found addresses beyond the gap limits, persisting gap limits: "+
+			"receive=%d, change=%d", limits.Receive, limits.Change)
+		return dbTx.PutGapLimits(limits)
+	})
+	if err != nil {
+		return types.GapLimits{}, err
+	}
+
+	result := types.GapLimits{}
+	for _, subacc := range account.subaccounts {
+		limits, err := account.gapLimits(subacc.signingConfiguration)
+		if err != nil {
+			return types.GapLimits{}, err
+		}
+		subacc.receiveAddresses.SetGapLimit(int(limits.Receive))
+		subacc.changeAddresses.SetGapLimit(int(limits.Change))
+		result.Receive = max(result.Receive, limits.Receive)
+		result.Change = max(result.Change, limits.Change)
+	}
+	return result, nil
+}
+
 func (account *Account) subscribeAddress(address *addresses.AccountAddress) {
 	account.coin.Blockchain().ScriptHashSubscribe(
 		account.Synchronizer.IncRequestsCounter,
@@ -854,6 +1052,9 @@ type SpendableOutput struct {
 	OutPoint wire.OutPoint
 	Address  *addresses.AccountAddress
 	IsChange bool
+	// Frozen is true if the coin is excluded from automatic coin selection and send-all, see
+	// SetOutputFrozen().
+	Frozen bool
 }
 
 // SpendableOutputs returns the utxo set, sorted by the value descending.
@@ -875,11 +1076,33 @@ func (account *Account) SpendableOutputs() ([]*SpendableOutput, error) {
 				SpendableOutput: txOut,
 				Address:         account.GetAddress(scriptHashHex),
 				IsChange:        account.IsChange(scriptHashHex),
+				Frozen:          account.OutputFrozen(outPoint),
 			})
 	}
 	return sortByAddresses(result), nil
 }
 
+// SetOutputFrozen freezes or unfreezes the output with the given outpoint. Frozen outputs are
+// stored in the account notes and are not spent unless they are explicitly selected using coin
+// control.
+func (account *Account) SetOutputFrozen(outPoint wire.OutPoint, frozen bool) error {
+	if _, err := account.Notes().SetOutputFrozen(outPoint.String(), frozen); err != nil {
+		return err
+	}
+	// Prompt refresh.
+	account.Notify(observable.Event{
+		Subject: string(accountsTypes.EventStatusChanged),
+		Action:  action.Reload,
+		Object:  nil,
+	})
+	return nil
+}
+
+// OutputFrozen returns true if the output with the given outpoint is frozen.
+func (account *Account) OutputFrozen(outPoint wire.OutPoint) bool {
+	return account.Notes().OutputFrozen(outPoint.String())
+}
+
 // VerifyExtendedPublicKey verifies an account's public key. Returns false, nil if no secure output
 // exists.
 //
@@ -939,22 +1162,15 @@ func SignBTCAddress(account accounts.Interface, message string, scriptType signi
 			account.Coin().Code())
 	}
 
-	unused := account.GetUnusedReceiveAddresses()
-	// Use the format hint to get a compatible address
-	if len(scriptType) == 0 {
-		scriptType = signing.ScriptTypeP2WPKH
-	}
-	signingConfigIdx := account.Config().Config.SigningConfigurations.FindScriptType(scriptType)
-	if signingConfigIdx == -1 {
-		err := errp.Newf("Unsupported format: %s", scriptType)
+	addr, err := unusedAddressForScriptType(account, scriptType)
+	if err != nil {
 		return "", "", err
 	}
-	addr := unused[signingConfigIdx].Addresses[0]
 
 	sig, err := keystore.SignBTCMessage(
 		[]byte(message),
 		addr.AbsoluteKeypath(),
-		account.Config().Config.SigningConfigurations[signingConfigIdx].ScriptType(),
+		addr.AccountConfiguration.ScriptType(),
 	)
 	if err != nil {
 		return "", "", err
@@ -962,3 +1178,54 @@ func SignBTCAddress(account accounts.Interface, message string, scriptType signi
 
 	return addr.EncodeForHumans(), base64.StdEncoding.EncodeToString(sig), nil
 }
+
+// SignBTCAddressBIP322 is like SignBTCAddress, but creates a BIP-322 signature in the given format,
+// which works for all script types including taproot. See SignMessageBIP322.
+//
+// Returned values:
+//
+//	#1: is the first unused address corresponding to the account and the script type identified by the input values.
+//	#2: base64 encoding of the BIP-322 signature.
+//	#3: the format of the signature, which is always `full` for P2PKH addresses.
+//	#4: is an optional error that could be generated during the execution of the function.
+func SignBTCAddressBIP322(
+	account accounts.Interface, message string, scriptType signing.ScriptType, format BIP322Format,
+) (string, string, BIP322Format, error) {
+	keystore, err := account.Config().ConnectKeystore()
+	if err != nil {
+		return "", "", "", err
+	}
+	coin, ok := account.Coin().(*Coin)
+	if !ok {
+		return "", "", "", errp.New("BIP-322 signatures are only supported for BTC based accounts")
+	}
+	addr, err := unusedAddressForScriptType(account, scriptType)
+	if err != nil {
+		return "", "", "", err
+	}
+	sig, format, err := SignMessageBIP322(keystore, coin, addr, []byte(message), format)
+	if err != nil {
+		return "", "", "", err
+	}
+	return addr.EncodeForHumans(), base64.StdEncoding.EncodeToString(sig), format, nil
+}
+
+// unusedAddressForScriptType returns the first unused receive address of the given script type.
+// If the script type is empty, native segwit is used as a fallback.
+func unusedAddressForScriptType(
+	account accounts.Interface, scriptType signing.ScriptType) (*addresses.AccountAddress, error) {
+	unused := account.GetUnusedReceiveAddresses()
+	// Use the format hint to get a compatible address
+	if len(scriptType) == 0 {
+		scriptType = signing.ScriptTypeP2WPKH
+	}
+	signingConfigIdx := account.Config().Config.SigningConfigurations.FindScriptType(scriptType)
+	if signingConfigIdx == -1 {
+		return nil, errp.Newf("Unsupported format: %s", scriptType)
+	}
+	addr, ok := unused[signingConfigIdx].Addresses[0].(*addresses.AccountAddress)
+	if !ok {
+		return nil, errp.New("Unexpected address type")
+	}
+	return addr, nil
+}
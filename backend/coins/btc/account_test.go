@@ -24,8 +24,11 @@ import (
 	"time"
 
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/addresses"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/blockchain"
 	blockchainMock "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/blockchain/mocks"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/transactions"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/types"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/config"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/keystore"
@@ -92,6 +95,7 @@ func mockAccount(t *testing.T, accountConfig *config.Account) *Account {
 		&accounts.AccountConfig{
 			Config:          accountConfig,
 			DBFolder:        dbFolder,
+			NotesFolder:     test.TstTempDir("btc-notesfolder"),
 			RateUpdater:     nil,
 			GetNotifier:     func(signing.Configurations) accounts.Notifier { return nil },
 			GetSaveFilename: func(suggestedFilename string) string { return suggestedFilename },
@@ -125,6 +129,62 @@ func TestAccount(t *testing.T) {
 	require.Equal(t, []*SpendableOutput{}, spendableOutputs)
 }
 
+func TestAccountConfigGapLimits(t *testing.T) {
+	account := mockAccount(t, nil)
+	account.Config().Config.GapLimits = &config.GapLimits{Receive: 30, Change: 3}
+	require.NoError(t, account.Initialize())
+	require.Eventually(t, account.Synced, time.Second, time.Millisecond*200)
+
+	// The configured receive gap limit is above the default and used. The configured change gap
+	// limit is below the default of 6, which is used instead.
+	receiveAddresses, err := account.subaccounts[0].receiveAddresses.GetUnused()
+	require.NoError(t, err)
+	require.Len(t, receiveAddresses, 30)
+	changeAddresses, err := account.subaccounts[0].changeAddresses.GetUnused()
+	require.NoError(t, err)
+	require.Len(t, changeAddresses, 6)
+}
+
+func TestDeepRescan(t *testing.T) {
+	account := mockAccount(t, nil)
+	_, err := account.DeepRescan(types.GapLimits{Receive: 100, Change: 20})
+	require.Error(t, err)
+	require.NoError(t, account.Initialize())
+	require.Eventually(t, account.Synced, time.Second, time.Millisecond*200)
+
+	// Mark a receive address far beyond the regular gap limit of 20 as used.
+	chain := addresses.NewAddressChain(
+		account.subaccounts[0].signingConfiguration, account.coin.Net(), 50, false,
+		func(*addresses.AccountAddress) (bool, error) { return false, nil }, account.log)
+	chainAddresses, err := chain.EnsureAddresses()
+	require.NoError(t, err)
+	usedAddress := chainAddresses[45]
+	require.NoError(t, transactions.DBUpdate(account.db, func(dbTx transactions.DBTxInterface) error {
+		return dbTx.PutAddressHistory(usedAddress.PubkeyScriptHashHex(), blockchain.TxHistory{
+			{Height: 10, TXHash: blockchain.TXHash{1}},
+		})
+	}))
+	require.Nil(t, account.subaccounts[0].receiveAddresses.LookupByScriptHashHex(
+		usedAddress.PubkeyScriptHashHex()))
+
+	gapLimits, err := account.DeepRescan(types.GapLimits{Receive: 100, Change: 20})
+	require.NoError(t, err)
+	require.Equal(t, types.GapLimits{Receive: 46, Change: 6}, gapLimits)
+	require.True(t, account.Synced())
+	require.NotNil(t, account.subaccounts[0].receiveAddresses.LookupByScriptHashHex(
+		usedAddress.PubkeyScriptHashHex()))
+
+	// The gap limit needed to find the address is persisted.
+	persisted, err := transactions.DBView(account.db, func(dbTx transactions.DBTxInterface) (types.GapLimits, error) {
+		return dbTx.GapLimits()
+	})
+	require.NoError(t, err)
+	require.Equal(t, uint16(46), persisted.Receive)
+	limits, err := account.gapLimits(account.subaccounts[0].signingConfiguration)
+	require.NoError(t, err)
+	require.Equal(t, gapLimits, limits)
+}
+
 func TestInsuredAccountAddresses(t *testing.T) {
 	net := &chaincfg.TestNet3Params
 
@@ -202,6 +262,20 @@ func TestSignAddress(t *testing.T) {
 
 }
 
+func TestDescriptors(t *testing.T) {
+	account := mockAccount(t, nil)
+	descriptors, err := account.Descriptors()
+	require.NoError(t, err)
+	require.Len(t, descriptors, 2)
+	// The xpub is encoded for testnet.
+	xpub, err := account.Config().Config.SigningConfigurations[0].ExtendedPublicKey().CloneWithVersion(
+		chaincfg.TestNet3Params.HDPublicKeyID[:])
+	require.NoError(t, err)
+	require.Regexp(t, `^tpub`, xpub.String())
+	require.Regexp(t, `^\Qwpkh([01020304/84'/1'/0']`+xpub.String()+`/0/*)\E#[a-z0-9]{8}$`, descriptors[0])
+	require.Regexp(t, `^\Qwpkh([01020304/84'/1'/0']`+xpub.String()+`/1/*)\E#[a-z0-9]{8}$`, descriptors[1])
+}
+
 func TestIsChange(t *testing.T) {
 	account := mockAccount(t, nil)
 	require.NoError(t, account.Initialize())
@@ -15,9 +15,13 @@
 package addresses
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"fmt"
+	"sort"
 
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/blockchain"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/silentpayments"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/types"
 	ourbtcutil "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/util"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
@@ -25,6 +29,7 @@ import (
 	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/btcsuite/btcd/btcec/v2/schnorr"
 	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
@@ -38,12 +43,21 @@ type AccountAddress struct {
 
 	// AccountConfiguration is the account level configuration from which this address was derived.
 	AccountConfiguration *signing.Configuration
-	// publicKey is the public key of a single-sig address.
+	// publicKey is the public key of a single-sig address, or our own public key of a multisig
+	// address.
 	publicKey  *btcec.PublicKey
 	Derivation types.Derivation
 
 	// redeemScript stores the redeem script of a BIP16 P2SH output or nil if address type is P2PKH.
 	redeemScript []byte
+	// witnessScript stores the multisig script of a P2WSH output or nil for single-sig addresses.
+	witnessScript []byte
+	// cosignerPublicKeys are the public keys of all cosigners of a multisig address, in the order of
+	// the key infos of the multisig configuration.
+	cosignerPublicKeys []*btcec.PublicKey
+	// silentPaymentTweak is the BIP-352 tweak of an output received through a silent payment, or
+	// nil for addresses derived from the account's xpub.
+	silentPaymentTweak []byte
 
 	log *logrus.Entry
 }
@@ -65,17 +79,24 @@ func NewAccountAddress(
 
 	var address btcutil.Address
 	var redeemScript []byte
+	var witnessScript []byte
+	var cosignerPublicKeys []*btcec.PublicKey
 	relativeKeypath := signing.NewEmptyRelativeKeypath().
 		Child(derivation.SimpleChainIndex(), signing.NonHardened).
 		Child(derivation.AddressIndex, signing.NonHardened)
-	derivedXpub, err := relativeKeypath.Derive(accountConfiguration.ExtendedPublicKey())
-	if err != nil {
-		log.WithError(err).Panic("Failed to derive xpub.")
-	}
-	publicKey, err := derivedXpub.ECPubKey()
-	if err != nil {
-		log.WithError(err).Panic("Failed to convert an extended public key to a normal public key.")
+	derivePublicKey := func(xpub *hdkeychain.ExtendedKey) *btcec.PublicKey {
+		derivedXpub, err := relativeKeypath.Derive(xpub)
+		if err != nil {
+			log.WithError(err).Panic("Failed to derive xpub.")
+		}
+		publicKey, err := derivedXpub.ECPubKey()
+		if err != nil {
+			log.WithError(err).Panic("Failed to convert an extended public key to a normal public key.")
+		}
+		return publicKey
 	}
+	publicKey := derivePublicKey(accountConfiguration.ExtendedPublicKey())
+	var err error
 
 	publicKeyHash := btcutil.Hash160(publicKey.SerializeCompressed())
 	switch accountConfiguration.ScriptType() {
@@ -109,6 +130,34 @@ func NewAccountAddress(
 		if err != nil {
 			log.WithError(err).Panic("Failed to get p2tr addr")
 		}
+	case signing.ScriptTypeP2WSH, signing.ScriptTypeP2WSHP2SH:
+		multisig := accountConfiguration.BitcoinMultisig
+		if multisig == nil {
+			log.Panic("Multisig script type without a multisig configuration.")
+		}
+		cosignerPublicKeys = make([]*btcec.PublicKey, len(multisig.KeyInfos))
+		for i, keyInfo := range multisig.KeyInfos {
+			cosignerPublicKeys[i] = derivePublicKey(keyInfo.ExtendedPublicKey)
+		}
+		witnessScript, err = sortedMultisigScript(multisig.Threshold, cosignerPublicKeys)
+		if err != nil {
+			log.WithError(err).Panic("Failed to build the multisig script.")
+		}
+		witnessScriptHash := sha256.Sum256(witnessScript)
+		address, err = btcutil.NewAddressWitnessScriptHash(witnessScriptHash[:], net)
+		if err != nil {
+			log.WithError(err).Panic("Failed to get p2wsh addr. from witness script hash.")
+		}
+		if accountConfiguration.ScriptType() == signing.ScriptTypeP2WSHP2SH {
+			redeemScript, err = txscript.PayToAddrScript(address)
+			if err != nil {
+				log.WithError(err).Panic("Failed to get redeem script for p2wsh address.")
+			}
+			address, err = btcutil.NewAddressScriptHash(redeemScript, net)
+			if err != nil {
+				log.WithError(err).Panic("Failed to get a P2SH address for p2wsh.")
+			}
+		}
 	default:
 		log.Panic(fmt.Sprintf("Unrecognized script type: %s", accountConfiguration.ScriptType()))
 	}
@@ -119,15 +168,107 @@ func NewAccountAddress(
 		publicKey:            publicKey,
 		Derivation:           derivation,
 		redeemScript:         redeemScript,
+		witnessScript:        witnessScript,
+		cosignerPublicKeys:   cosignerPublicKeys,
 		log:                  log,
 	}
 }
 
+// NewSilentPaymentAccountAddress creates the address of an output received through a silent
+// payment (BIP-352). `accountConfiguration` is the P2TR configuration of the extended spend key at
+// m/352'/coin'/account'/0'. The output key is the spend public key (child 0) tweaked with `tweak`,
+// without the BIP-86 taproot tweak.
+func NewSilentPaymentAccountAddress(
+	accountConfiguration *signing.Configuration,
+	tweak []byte,
+	net *chaincfg.Params,
+	log *logrus.Entry,
+) (*AccountAddress, error) {
+	if accountConfiguration.ScriptType() != signing.ScriptTypeP2TR {
+		return nil, errp.New("silent payment outputs must be taproot outputs")
+	}
+	spendXpub, err := accountConfiguration.ExtendedPublicKey().Derive(0)
+	if err != nil {
+		return nil, errp.WithStack(err)
+	}
+	spendPublicKey, err := spendXpub.ECPubKey()
+	if err != nil {
+		return nil, errp.WithStack(err)
+	}
+	outputKey, err := silentpayments.OutputKey(spendPublicKey, tweak)
+	if err != nil {
+		return nil, err
+	}
+	address, err := btcutil.NewAddressTaproot(schnorr.SerializePubKey(outputKey), net)
+	if err != nil {
+		return nil, errp.WithStack(err)
+	}
+	return &AccountAddress{
+		Address:              address,
+		AccountConfiguration: accountConfiguration,
+		publicKey:            outputKey,
+		silentPaymentTweak:   tweak,
+		log: log.WithFields(logrus.Fields{
+			"accountConfiguration": accountConfiguration.String(),
+			"silentPayment":        true,
+		}),
+	}, nil
+}
+
+// sortedMultisigScript returns the script `OP_k <pubkey1> ... <pubkeyn> OP_n OP_CHECKMULTISIG`
+// with the public keys sorted lexicographically (BIP-67).
+func sortedMultisigScript(threshold int, publicKeys []*btcec.PublicKey) ([]byte, error) {
+	serializedPublicKeys := make([][]byte, len(publicKeys))
+	for i, publicKey := range publicKeys {
+		serializedPublicKeys[i] = publicKey.SerializeCompressed()
+	}
+	sort.Slice(serializedPublicKeys, func(i, j int) bool {
+		return bytes.Compare(serializedPublicKeys[i], serializedPublicKeys[j]) < 0
+	})
+	builder := txscript.NewScriptBuilder().AddInt64(int64(threshold))
+	for _, serializedPublicKey := range serializedPublicKeys {
+		builder.AddData(serializedPublicKey)
+	}
+	return builder.
+		AddInt64(int64(len(serializedPublicKeys))).
+		AddOp(txscript.OP_CHECKMULTISIG).
+		Script()
+}
+
 // ID implements accounts.Address.
 func (address *AccountAddress) ID() string {
 	return string(address.PubkeyScriptHashHex())
 }
 
+// PublicKey returns the public key of a single-sig address, or our own public key of a multisig
+// address. For silent payment outputs, this is the tweaked output key.
+func (address *AccountAddress) PublicKey() *btcec.PublicKey {
+	return address.publicKey
+}
+
+// RedeemScript returns the redeem script of a P2SH-wrapped segwit address, or nil otherwise.
+func (address *AccountAddress) RedeemScript() []byte {
+	return address.redeemScript
+}
+
+// WitnessScript returns the multisig script of a multisig address, or nil for single-sig
+// addresses.
+func (address *AccountAddress) WitnessScript() []byte {
+	return address.witnessScript
+}
+
+// CosignerPublicKeys returns the public keys of all cosigners of a multisig address, in the order
+// of the key infos of the multisig configuration, or nil for single-sig addresses.
+func (address *AccountAddress) CosignerPublicKeys() []*btcec.PublicKey {
+	return address.cosignerPublicKeys
+}
+
+// SilentPaymentTweak returns the BIP-352 tweak which has to be added to the spend private key to
+// spend an output received through a silent payment, or nil if this is a regular address.
+func (address *AccountAddress) SilentPaymentTweak() []byte {
+	return address.silentPaymentTweak
+}
+
 // BIP352Pubkey returns the pubkey used for silent payments:
 // - 33 byte compressed public key for p2pkh, p2wpkh, p2wpkh-p2sh.
 // - 32 byte x-only public key for p2tr
@@ -138,6 +279,9 @@ func (address *AccountAddress) BIP352Pubkey() ([]byte, error) {
 	case signing.ScriptTypeP2PKH, signing.ScriptTypeP2WPKHP2SH, signing.ScriptTypeP2WPKH:
 		return publicKey.SerializeCompressed(), nil
 	case signing.ScriptTypeP2TR:
+		if address.silentPaymentTweak != nil {
+			return schnorr.SerializePubKey(publicKey), nil
+		}
 		outputKey := txscript.ComputeTaprootKeyNoScript(publicKey)
 		return schnorr.SerializePubKey(outputKey), nil
 	default:
@@ -150,8 +294,12 @@ func (address *AccountAddress) EncodeForHumans() string {
 	return address.EncodeAddress()
 }
 
-// AbsoluteKeypath implements accounts.Address.
+// AbsoluteKeypath implements accounts.Address. For silent payment outputs, this is the keypath of
+// the untweaked spend key.
 func (address *AccountAddress) AbsoluteKeypath() signing.AbsoluteKeypath {
+	if address.silentPaymentTweak != nil {
+		return address.AccountConfiguration.AbsoluteKeypath().Child(0, false)
+	}
 	return address.AccountConfiguration.AbsoluteKeypath().
 		Child(address.Derivation.SimpleChainIndex(), false).
 		Child(address.Derivation.AddressIndex, false)
@@ -183,6 +331,8 @@ func (address *AccountAddress) ScriptForHashToSign() (bool, []byte) {
 		return true, address.redeemScript
 	case signing.ScriptTypeP2WPKH:
 		return true, address.PubkeyScript()
+	case signing.ScriptTypeP2WSH, signing.ScriptTypeP2WSHP2SH:
+		return true, address.witnessScript
 	default:
 		address.log.Panic("Unrecognized address type.")
 	}
@@ -191,6 +341,8 @@ func (address *AccountAddress) ScriptForHashToSign() (bool, []byte) {
 
 // SignatureScript returns the signature script (and witness) needed to spend from this address.
 // The signatures have to be provided in the order of the configuration (and some can be nil).
+// For multisig addresses, only our own signature is included, which is only sufficient for 1-of-n
+// multisigs. Signatures of cosigners are combined using PSBTs.
 func (address *AccountAddress) SignatureScript(
 	signature types.Signature,
 ) ([]byte, wire.TxWitness) {
@@ -231,6 +383,23 @@ func (address *AccountAddress) SignatureScript(
 			signature.SerializeCompact(),
 		}
 		return []byte{}, txWitness
+	case signing.ScriptTypeP2WSH, signing.ScriptTypeP2WSHP2SH:
+		// The empty item is consumed by the off-by-one bug of OP_CHECKMULTISIG.
+		txWitness := wire.TxWitness{
+			nil,
+			append(signature.SerializeDER(), byte(txscript.SigHashAll)),
+			address.witnessScript,
+		}
+		if address.redeemScript == nil {
+			return []byte{}, txWitness
+		}
+		signatureScript, err := txscript.NewScriptBuilder().
+			AddData(address.redeemScript).
+			Script()
+		if err != nil {
+			address.log.WithError(err).Panic("Failed to build segwit signature script.")
+		}
+		return signatureScript, txWitness
 	default:
 		address.log.Panic("Unrecognized address type.")
 	}
@@ -15,19 +15,25 @@
 package addresses_test
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"encoding/hex"
 	"os"
+	"sort"
 	"testing"
 
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/addresses"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/addresses/test"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/blockchain"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/silentpayments"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/types"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/util/logging"
 	testlog "github.com/BitBoxSwiss/bitbox-wallet-app/util/test"
+	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/btcutil/hdkeychain"
 	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
@@ -121,3 +127,114 @@ func TestAddressP2TR(t *testing.T) {
 		require.Equal(t, test.expectedPkScript, hex.EncodeToString(addr.PubkeyScript()))
 	}
 }
+
+func TestAddressSilentPayment(t *testing.T) {
+	seed := make([]byte, 32)
+	master, err := hdkeychain.NewMaster(seed, &chaincfg.TestNet3Params)
+	require.NoError(t, err)
+	keypath, err := signing.NewAbsoluteKeypath("m/352'/1'/0'/0'")
+	require.NoError(t, err)
+	spendXprv, err := keypath.Derive(master)
+	require.NoError(t, err)
+	spendXpub, err := spendXprv.Neuter()
+	require.NoError(t, err)
+	configuration := signing.NewBitcoinConfiguration(
+		signing.ScriptTypeP2TR, []byte{1, 2, 3, 4}, keypath, spendXpub)
+	tweak, err := hex.DecodeString("f438b40179a3c4262de12986c0e6cce0634007cdc79c1dcd3e20b9ebc2e7eef6")
+	require.NoError(t, err)
+
+	addr, err := addresses.NewSilentPaymentAccountAddress(
+		configuration, tweak, net, logging.Get().WithGroup("addresses_test"))
+	require.NoError(t, err)
+	spendChild, err := spendXpub.Derive(0)
+	require.NoError(t, err)
+	spendPublicKey, err := spendChild.ECPubKey()
+	require.NoError(t, err)
+	outputKey, err := silentpayments.OutputKey(spendPublicKey, tweak)
+	require.NoError(t, err)
+	expectedPkScript, err := txscript.PayToTaprootScript(outputKey)
+	require.NoError(t, err)
+	require.Equal(t, expectedPkScript, addr.PubkeyScript())
+	require.Equal(t, "m/352'/1'/0'/0'/0", addr.AbsoluteKeypath().Encode())
+	require.Equal(t, tweak, addr.SilentPaymentTweak())
+	bip352Pubkey, err := addr.BIP352Pubkey()
+	require.NoError(t, err)
+	require.Equal(t, expectedPkScript[2:], bip352Pubkey)
+
+	require.Nil(t, test.GetAddress(signing.ScriptTypeP2TR).SilentPaymentTweak())
+
+	_, err = addresses.NewSilentPaymentAccountAddress(
+		configuration, tweak[1:], net, logging.Get().WithGroup("addresses_test"))
+	require.Error(t, err)
+	_, err = addresses.NewSilentPaymentAccountAddress(
+		signing.NewBitcoinConfiguration(signing.ScriptTypeP2WPKH, []byte{1, 2, 3, 4}, keypath, spendXpub),
+		tweak, net, logging.Get().WithGroup("addresses_test"))
+	require.Error(t, err)
+}
+
+func TestAddressMultisig(t *testing.T) {
+	keypath, err := signing.NewAbsoluteKeypath("m/48'/1'/0'/2'")
+	require.NoError(t, err)
+	keyInfos := make([]signing.KeyInfo, 3)
+	for i := range keyInfos {
+		seed := make([]byte, hdkeychain.RecommendedSeedLen)
+		seed[0] = byte(i)
+		xprv, err := hdkeychain.NewMaster(seed, net)
+		require.NoError(t, err)
+		xpub, err := xprv.Neuter()
+		require.NoError(t, err)
+		keyInfos[i] = signing.KeyInfo{
+			RootFingerprint:   []byte{byte(i), 2, 3, 4},
+			AbsoluteKeypath:   keypath,
+			ExtendedPublicKey: xpub,
+		}
+	}
+	derivation := types.Derivation{Change: true, AddressIndex: 7}
+
+	// Build the expected sorted 2-of-3 multisig script independently.
+	publicKeys := make([]*btcutil.AddressPubKey, len(keyInfos))
+	for i, keyInfo := range keyInfos {
+		derived, err := signing.NewEmptyRelativeKeypath().Child(1, false).Child(7, false).
+			Derive(keyInfo.ExtendedPublicKey)
+		require.NoError(t, err)
+		publicKey, err := derived.ECPubKey()
+		require.NoError(t, err)
+		publicKeys[i], err = btcutil.NewAddressPubKey(publicKey.SerializeCompressed(), net)
+		require.NoError(t, err)
+	}
+	sort.Slice(publicKeys, func(i, j int) bool {
+		return bytes.Compare(publicKeys[i].ScriptAddress(), publicKeys[j].ScriptAddress()) < 0
+	})
+	expectedWitnessScript, err := txscript.MultiSigScript(publicKeys, 2)
+	require.NoError(t, err)
+	witnessScriptHash := sha256.Sum256(expectedWitnessScript)
+
+	for _, scriptType := range []signing.ScriptType{signing.ScriptTypeP2WSH, signing.ScriptTypeP2WSHP2SH} {
+		configuration, err := signing.NewBitcoinMultisigConfiguration(scriptType, 2, keyInfos, 1)
+		require.NoError(t, err)
+		addr := addresses.NewAccountAddress(
+			configuration, derivation, net, logging.Get().WithGroup("addresses_test"))
+		require.Equal(t, expectedWitnessScript, addr.WitnessScript())
+		isSegwit, script := addr.ScriptForHashToSign()
+		require.True(t, isSegwit)
+		require.Equal(t, expectedWitnessScript, script)
+		require.Len(t, addr.CosignerPublicKeys(), 3)
+		require.Equal(t, addr.CosignerPublicKeys()[1], addr.PublicKey())
+		require.Equal(t, "m/48'/1'/0'/2'/1/7", addr.AbsoluteKeypath().Encode())
+
+		p2wshPkScript := append([]byte{txscript.OP_0, txscript.OP_DATA_32}, witnessScriptHash[:]...)
+		switch scriptType {
+		case signing.ScriptTypeP2WSH:
+			require.Nil(t, addr.RedeemScript())
+			require.Equal(t, p2wshPkScript, addr.PubkeyScript())
+			require.Contains(t, addr.EncodeForHumans(), "tb1q")
+		case signing.ScriptTypeP2WSHP2SH:
+			require.Equal(t, p2wshPkScript, addr.RedeemScript())
+			expectedPkScript := append(
+				append([]byte{txscript.OP_HASH160, txscript.OP_DATA_20},
+					btcutil.Hash160(p2wshPkScript)...),
+				txscript.OP_EQUAL)
+			require.Equal(t, expectedPkScript, addr.PubkeyScript())
+		}
+	}
+}
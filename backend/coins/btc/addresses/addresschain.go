@@ -113,6 +113,36 @@ func (addresses *AddressChain) LookupByScriptHashHex(hashHex blockchain.ScriptHa
 	return addresses.addressesLookup[hashHex]
 }
 
+// SetGapLimit changes the gap limit. Call EnsureAddresses() afterwards to extend the chain if the
+// gap limit was increased.
+func (addresses *AddressChain) SetGapLimit(gapLimit int) {
+	defer addresses.addressesLock.Lock()()
+	addresses.gapLimit = gapLimit
+}
+
+// RequiredGapLimit returns the smallest gap limit with which all used addresses of the chain are
+// discovered, i.e. the longest sequence of unused addresses followed by a used address plus one.
+func (addresses *AddressChain) RequiredGapLimit() (int, error) {
+	defer addresses.addressesLock.RLock()()
+	required := 1
+	unusedCount := 0
+	for _, address := range addresses.addresses {
+		used, err := addresses.isAddressUsed(address)
+		if err != nil {
+			return 0, err
+		}
+		if !used {
+			unusedCount++
+			continue
+		}
+		if unusedCount+1 > required {
+			required = unusedCount + 1
+		}
+		unusedCount = 0
+	}
+	return required, nil
+}
+
 // EnsureAddresses appends addresses to the address chain until there are `gapLimit` unused
 // ones, and returns the new addresses.
 func (addresses *AddressChain) EnsureAddresses() ([]*AccountAddress, error) {
@@ -89,6 +89,29 @@ func (s *addressChainTestSuite) TestGetUnused() {
 	s.Require().Equal(newAddresses[1], unusedAddresses[0])
 }
 
+func (s *addressChainTestSuite) TestRequiredGapLimit() {
+	s.isAddressUsed = func(*addresses.AccountAddress) bool { return false }
+	newAddresses, err := s.addresses.EnsureAddresses()
+	s.Require().NoError(err)
+	required, err := s.addresses.RequiredGapLimit()
+	s.Require().NoError(err)
+	s.Require().Equal(1, required)
+
+	// Raising the gap limit extends the chain, e.g. to find addresses used by another wallet.
+	s.addresses.SetGapLimit(20)
+	newAddresses2, err := s.addresses.EnsureAddresses()
+	s.Require().NoError(err)
+	s.Require().Len(newAddresses2, 20-s.gapLimit)
+	allAddresses := append(newAddresses, newAddresses2...)
+
+	used := map[*addresses.AccountAddress]bool{allAddresses[2]: true, allAddresses[15]: true}
+	s.isAddressUsed = func(addr *addresses.AccountAddress) bool { return used[addr] }
+	required, err = s.addresses.RequiredGapLimit()
+	s.Require().NoError(err)
+	// 12 unused addresses between index 2 and 15.
+	s.Require().Equal(13, required)
+}
+
 func (s *addressChainTestSuite) TestLookupByScriptHashHex() {
 	s.isAddressUsed = func(*addresses.AccountAddress) bool { return false }
 	newAddresses, err := s.addresses.EnsureAddresses()
@@ -67,3 +67,40 @@ func GetAddress(scriptType signing.ScriptType) *addresses.AccountAddress {
 		logging.Get().WithGroup("addresses_test"),
 	)
 }
+
+// GetMultisigAddress returns a dummy address of a k-of-n multisig configuration for a given
+// multisig address type. The cosigner keys are derived from different seeds.
+func GetMultisigAddress(scriptType signing.ScriptType, threshold int, numCosigners int) *addresses.AccountAddress {
+	keypath, err := signing.NewAbsoluteKeypath("m/48'/1'/0'/2'")
+	if err != nil {
+		panic(err)
+	}
+	keyInfos := make([]signing.KeyInfo, numCosigners)
+	for i := range keyInfos {
+		seed := make([]byte, hdkeychain.RecommendedSeedLen)
+		seed[0] = byte(i)
+		xprv, err := hdkeychain.NewMaster(seed, net)
+		if err != nil {
+			panic(err)
+		}
+		xpub, err := xprv.Neuter()
+		if err != nil {
+			panic(err)
+		}
+		keyInfos[i] = signing.KeyInfo{
+			RootFingerprint:   []byte{byte(i), 2, 3, 4},
+			AbsoluteKeypath:   keypath,
+			ExtendedPublicKey: xpub,
+		}
+	}
+	configuration, err := signing.NewBitcoinMultisigConfiguration(scriptType, threshold, keyInfos, 0)
+	if err != nil {
+		panic(err)
+	}
+	return addresses.NewAccountAddress(
+		configuration,
+		types.Derivation{Change: false, AddressIndex: 0},
+		net,
+		logging.Get().WithGroup("addresses_test"),
+	)
+}
@@ -0,0 +1,261 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package btc
+
+import (
+	"bytes"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/addresses"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/blockchain"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/maketx"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/types"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/keystore"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// BIP322Format is the encoding of a BIP-322 message signature.
+type BIP322Format string
+
+const (
+	// BIP322FormatSimple encodes only the witness stack of the signed to_sign transaction. It can't
+	// be used for legacy P2PKH addresses, which have no witness.
+	BIP322FormatSimple BIP322Format = "simple"
+	// BIP322FormatFull encodes the complete signed to_sign transaction.
+	BIP322FormatFull BIP322Format = "full"
+)
+
+// ErrBIP322InvalidSignature is returned by VerifyMessageBIP322 if the signature is well-formed,
+// but does not sign the message by the address.
+var ErrBIP322InvalidSignature = errp.New("The signature does not sign the message for this address")
+
+// bip322Tag is the tag of the tagged hash of the message, see
+// https://github.com/bitcoin/bips/blob/master/bip-0322.mediawiki#full.
+var bip322Tag = []byte("BIP0322-signed-message")
+
+// bip322MessageHash returns the BIP-340 tagged hash of the message, which is committed to in the
+// to_spend transaction.
+func bip322MessageHash(message []byte) *chainhash.Hash {
+	return chainhash.TaggedHash(bip322Tag, message)
+}
+
+// bip322ToSpend returns the virtual to_spend transaction, which has a single output with the
+// pubkey script of the address whose ownership is proven. The message is committed to in the
+// scriptSig of its input.
+func bip322ToSpend(pkScript []byte, message []byte) *wire.MsgTx {
+	scriptSig := append([]byte{txscript.OP_0, txscript.OP_DATA_32}, bip322MessageHash(message)[:]...)
+	toSpend := wire.NewMsgTx(0)
+	toSpend.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Index: 0xFFFFFFFF},
+		SignatureScript:  scriptSig,
+		Sequence:         0,
+	})
+	toSpend.AddTxOut(wire.NewTxOut(0, pkScript))
+	return toSpend
+}
+
+// bip322ToSign returns the unsigned virtual to_sign transaction, spending the output of to_spend.
+func bip322ToSign(toSpend *wire.MsgTx) *wire.MsgTx {
+	toSign := wire.NewMsgTx(0)
+	toSign.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Hash: toSpend.TxHash(), Index: 0},
+		Sequence:         0,
+	})
+	toSign.AddTxOut(wire.NewTxOut(0, []byte{txscript.OP_RETURN}))
+	return toSign
+}
+
+// SignMessageBIP322 signs a message with the key of the given address according to BIP-322. The
+// virtual to_sign transaction is signed by the keystore like a regular transaction. The signature
+// is returned in the requested format. Legacy P2PKH addresses are always signed using the full
+// format, as the simple format only contains the witness.
+//
+// Multisig addresses can only be signed if no cosigners are needed.
+func SignMessageBIP322(
+	keystore keystore.Keystore,
+	coin *Coin,
+	address *addresses.AccountAddress,
+	message []byte,
+	format BIP322Format,
+) ([]byte, BIP322Format, error) {
+	if multisig := address.AccountConfiguration.BitcoinMultisig; multisig != nil && multisig.Threshold > 1 {
+		return nil, "", errp.WithStack(ErrMultisigNeedsCosigners)
+	}
+	switch format {
+	case BIP322FormatSimple, BIP322FormatFull:
+	default:
+		return nil, "", errp.Newf("Unknown BIP-322 format: %s", format)
+	}
+	if address.AccountConfiguration.ScriptType() == signing.ScriptTypeP2PKH {
+		format = BIP322FormatFull
+	}
+
+	toSpend := bip322ToSpend(address.PubkeyScript(), message)
+	toSign := bip322ToSign(toSpend)
+	txProposal := &maketx.TxProposal{
+		Coin:        coin,
+		Transaction: toSign,
+		PreviousOutputs: maketx.PreviousOutputs{
+			toSign.TxIn[0].PreviousOutPoint: maketx.UTXO{
+				TxOut:   toSpend.TxOut[0],
+				Address: address,
+			},
+		},
+	}
+	proposedTransaction := &ProposedTransaction{
+		TXProposal:                   txProposal,
+		AccountSigningConfigurations: []*signing.Configuration{address.AccountConfiguration},
+		GetPrevTx: func(hash chainhash.Hash) (*wire.MsgTx, error) {
+			if hash != toSpend.TxHash() {
+				return nil, errp.Newf("Unknown previous transaction %s", hash)
+			}
+			return toSpend, nil
+		},
+		Signatures: make([]*types.Signature, len(toSign.TxIn)),
+		FormatUnit: coin.formatUnit,
+		GetKeystoreAddress: func(_ *Account, scriptHashHex blockchain.ScriptHashHex) (*addresses.AccountAddress, bool, error) {
+			if scriptHashHex != address.PubkeyScriptHashHex() {
+				return nil, false, nil
+			}
+			return address, true, nil
+		},
+	}
+	if err := keystore.SignTransaction(proposedTransaction); err != nil {
+		return nil, "", err
+	}
+	if err := proposedTransaction.Finalize(); err != nil {
+		return nil, "", err
+	}
+	if err := TxValidityCheck(toSign, txProposal.PreviousOutputs, txProposal.SigHashes()); err != nil {
+		return nil, "", err
+	}
+
+	var signature bytes.Buffer
+	switch format {
+	case BIP322FormatSimple:
+		if err := writeWitness(&signature, toSign.TxIn[0].Witness); err != nil {
+			return nil, "", err
+		}
+	case BIP322FormatFull:
+		if err := toSign.Serialize(&signature); err != nil {
+			return nil, "", errp.WithStack(err)
+		}
+	}
+	return signature.Bytes(), format, nil
+}
+
+// writeWitness serializes a witness stack as it is serialized in transactions.
+func writeWitness(buf *bytes.Buffer, witness wire.TxWitness) error {
+	if err := wire.WriteVarInt(buf, 0, uint64(len(witness))); err != nil {
+		return errp.WithStack(err)
+	}
+	for _, item := range witness {
+		if err := wire.WriteVarBytes(buf, 0, item); err != nil {
+			return errp.WithStack(err)
+		}
+	}
+	return nil
+}
+
+// readWitness deserializes a witness stack serialized with writeWitness.
+func readWitness(serialized []byte) (wire.TxWitness, error) {
+	reader := bytes.NewReader(serialized)
+	count, err := wire.ReadVarInt(reader, 0)
+	if err != nil {
+		return nil, errp.WithStack(err)
+	}
+	// Each item takes at least one byte.
+	if count > uint64(reader.Len()) {
+		return nil, errp.New("Invalid witness item count")
+	}
+	witness := make(wire.TxWitness, count)
+	for i := range witness {
+		witness[i], err = wire.ReadVarBytes(reader, 0, txscript.MaxScriptSize, "witness item")
+		if err != nil {
+			return nil, errp.WithStack(err)
+		}
+	}
+	if reader.Len() != 0 {
+		return nil, errp.New("Unexpected data after the witness")
+	}
+	return witness, nil
+}
+
+// VerifyMessageBIP322 verifies a BIP-322 signature of a message by the given address. The format
+// of the signature is detected automatically and returned. ErrBIP322InvalidSignature is returned
+// together with the format if the signature is not valid, and other errors if it is malformed.
+//
+// Proofs of funds, i.e. full signatures with additional inputs, are not supported.
+func VerifyMessageBIP322(
+	coin *Coin, address string, message []byte, signature []byte) (BIP322Format, error) {
+	pkScript, err := coin.AddressToPkScript(address)
+	if err != nil {
+		return "", err
+	}
+	if pkScript == nil {
+		return "", errp.New("Silent payment addresses can't sign messages")
+	}
+	toSpend := bip322ToSpend(pkScript, message)
+	expectedToSign := bip322ToSign(toSpend)
+
+	var format BIP322Format
+	var toSign *wire.MsgTx
+	if witness, err := readWitness(signature); err == nil && len(witness) > 0 {
+		format = BIP322FormatSimple
+		toSign = expectedToSign
+		toSign.TxIn[0].Witness = witness
+		// The simple format does not contain the scriptSig. For wrapped segwit, the redeem script
+		// is derived from the public key, as done by other wallets.
+		if txscript.IsPayToScriptHash(pkScript) && len(witness) == 2 {
+			redeemScript, err := txscript.NewScriptBuilder().
+				AddOp(txscript.OP_0).
+				AddData(btcutil.Hash160(witness[1])).
+				Script()
+			if err != nil {
+				return "", errp.WithStack(err)
+			}
+			toSign.TxIn[0].SignatureScript, err = txscript.NewScriptBuilder().AddData(redeemScript).Script()
+			if err != nil {
+				return "", errp.WithStack(err)
+			}
+		}
+	} else {
+		format = BIP322FormatFull
+		toSign = wire.NewMsgTx(0)
+		if err := toSign.Deserialize(bytes.NewReader(signature)); err != nil {
+			return "", errp.New("The signature is neither a simple nor a full BIP-322 signature")
+		}
+		if len(toSign.TxIn) != 1 ||
+			toSign.TxIn[0].PreviousOutPoint != expectedToSign.TxIn[0].PreviousOutPoint ||
+			len(toSign.TxOut) != 1 ||
+			toSign.TxOut[0].Value != 0 ||
+			!bytes.Equal(toSign.TxOut[0].PkScript, expectedToSign.TxOut[0].PkScript) {
+			return format, errp.WithStack(ErrBIP322InvalidSignature)
+		}
+	}
+
+	previousOutputs := maketx.PreviousOutputs{
+		toSign.TxIn[0].PreviousOutPoint: maketx.UTXO{TxOut: toSpend.TxOut[0]},
+	}
+	if err := TxValidityCheck(toSign, previousOutputs,
+		txscript.NewTxSigHashes(toSign, previousOutputs)); err != nil {
+		return format, errp.WithMessage(ErrBIP322InvalidSignature, err.Error())
+	}
+	return format, nil
+}
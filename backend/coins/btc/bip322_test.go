@@ -0,0 +1,105 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package btc
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"os"
+	"testing"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/socksproxy"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/test"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/stretchr/testify/require"
+)
+
+// Test vectors from https://github.com/bitcoin/bips/blob/master/bip-0322.mediawiki#test-vectors.
+const (
+	bip322TestAddress        = "bc1q9vza2e8x573nczrlzms0wvx3gsqjx7vavgkx0l"
+	bip322TestTaprootAddress = "bc1ppv609nr0vr25u07u95waq5lucwfm6tde4nydujnu8npg4q75mr5sxq8lt3"
+)
+
+func bip322TestCoin(t *testing.T) *Coin {
+	t.Helper()
+	dbFolder := test.TstTempDir("btc-dbfolder")
+	t.Cleanup(func() { _ = os.RemoveAll(dbFolder) })
+	return NewCoin(coin.CodeBTC, "Bitcoin", "BTC", coin.BtcUnitDefault, &chaincfg.MainNetParams,
+		dbFolder, nil, explorer, socksproxy.NewSocksProxy(false, ""))
+}
+
+func TestBIP322Transactions(t *testing.T) {
+	require.Equal(t,
+		"c90c269c4f8fcbe6880f72a721ddfbf1914268a794cbb21cfafee13770ae19f1",
+		hex.EncodeToString(bip322MessageHash([]byte(""))[:]))
+	require.Equal(t,
+		"f0eb03b1a75ac6d9847f55c624a99169b5dccba2a31f5b23bea77ba270de0a7a",
+		hex.EncodeToString(bip322MessageHash([]byte("Hello World"))[:]))
+
+	pkScript, err := bip322TestCoin(t).AddressToPkScript(bip322TestAddress)
+	require.NoError(t, err)
+
+	toSpend := bip322ToSpend(pkScript, []byte(""))
+	require.Equal(t, "c5680aa69bb8d860bf82d4e9cd3504b55dde018de765a91bb566283c545a99a7", toSpend.TxHash().String())
+	require.Equal(t, "1e9654e951a5ba44c8604c4de6c67fd78a27e81dcadcfe1edf638ba3aaebaed6", bip322ToSign(toSpend).TxHash().String())
+
+	toSpend = bip322ToSpend(pkScript, []byte("Hello World"))
+	require.Equal(t, "b79d196740ad5217771c1098fc4a4b51e0535c32236c71f1ea4d61a2d603352b", toSpend.TxHash().String())
+	require.Equal(t, "88737ae86f2077145f93cc4b153ae9a1cb8d56afa511988c149c5c8c9d93bddf", bip322ToSign(toSpend).TxHash().String())
+}
+
+func TestVerifyMessageBIP322(t *testing.T) {
+	coin := bip322TestCoin(t)
+	verify := func(address string, message string, signature string) (BIP322Format, error) {
+		t.Helper()
+		decoded, err := base64.StdEncoding.DecodeString(signature)
+		require.NoError(t, err)
+		return VerifyMessageBIP322(coin, address, []byte(message), decoded)
+	}
+
+	const emptySignature = "AkcwRAIgM2gBAQqvZX15ZiysmKmQpDrG83avLIT492QBzLnQIxYCIBaTpOaD20qRlEylyxFSeEA2ba9YOixpX8z46TSDtS40ASECx/EgAxlkQpQ9hYjgGu6EBCPMVPwVIVJqO4XCsMvViHI="
+	const helloWorldSignature = "AkcwRAIgZRfIY3p7/DoVTty6YZbWS71bc5Vct9p9Fia83eRmw2QCICK/ENGfwLtptFluMGs2KsqoNSk89pO7F29zJLUx9a/sASECx/EgAxlkQpQ9hYjgGu6EBCPMVPwVIVJqO4XCsMvViHI="
+	const taprootSignature = "AUHd69PrJQEv+oKTfZ8l+WROBHuy9HKrbFCJu7U1iK2iiEy1vMU5EfMtjc+VSHM7aU0SDbak5IUZRVno2P5mjSafAQ=="
+
+	format, err := verify(bip322TestAddress, "", emptySignature)
+	require.NoError(t, err)
+	require.Equal(t, BIP322FormatSimple, format)
+	_, err = verify(bip322TestAddress, "Hello World", helloWorldSignature)
+	require.NoError(t, err)
+	_, err = verify(bip322TestTaprootAddress, "Hello World", taprootSignature)
+	require.NoError(t, err)
+
+	// Wrong message, address or signature.
+	format, err = verify(bip322TestAddress, "Hello World", emptySignature)
+	require.Equal(t, ErrBIP322InvalidSignature, errp.Cause(err))
+	require.Equal(t, BIP322FormatSimple, format)
+	_, err = verify(bip322TestAddress, "", helloWorldSignature)
+	require.Equal(t, ErrBIP322InvalidSignature, errp.Cause(err))
+	_, err = verify(bip322TestTaprootAddress, "Hello World", helloWorldSignature)
+	require.Equal(t, ErrBIP322InvalidSignature, errp.Cause(err))
+
+	// Invalid address or malformed signature.
+	_, err = verify("tb1q9vza2e8x573nczrlzms0wvx3gsqjx7vaxwd45v", "", emptySignature)
+	require.Error(t, err)
+	require.NotEqual(t, ErrBIP322InvalidSignature, errp.Cause(err))
+	_, err = verify(bip322TestAddress, "", "")
+	require.Error(t, err)
+	require.NotEqual(t, ErrBIP322InvalidSignature, errp.Cause(err))
+	_, err = verify(bip322TestAddress, "", "AA==")
+	require.Error(t, err)
+	require.NotEqual(t, ErrBIP322InvalidSignature, errp.Cause(err))
+}
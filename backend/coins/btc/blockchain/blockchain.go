@@ -19,6 +19,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
 	"github.com/BitBoxSwiss/block-client-go/electrum/types"
@@ -101,6 +102,60 @@ type GetMerkleResult struct {
 	Pos    int
 }
 
+// FeeHistogramEntry is an entry of the mempool fee histogram.
+type FeeHistogramEntry struct {
+	// FeeRate in sat/vB.
+	FeeRate float64
+	// VSize is the total virtual size of the mempool transactions paying a fee rate between
+	// FeeRate and the fee rate of the previous entry.
+	VSize int64
+}
+
+// FeeHistogram is returned by MempoolFeeHistogram(). It is sorted by descending fee rate.
+type FeeHistogram []FeeHistogramEntry
+
+// ServerIssue is a reason for a lower server score, see ServerStatus.
+type ServerIssue string
+
+const (
+	// ServerIssueUnreachable means the last attempt to connect to the server failed.
+	ServerIssueUnreachable ServerIssue = "unreachable"
+	// ServerIssueTipLag means the server is behind the best known chain tip.
+	ServerIssueTipLag ServerIssue = "tipLag"
+	// ServerIssueErrors means many requests to the server failed recently.
+	ServerIssueErrors ServerIssue = "errors"
+	// ServerIssueLatency means the server is slow to respond.
+	ServerIssueLatency ServerIssue = "latency"
+)
+
+// ServerStatus is the health status of a configured backend server, returned by ServerStatus().
+type ServerStatus struct {
+	Server string
+	// Connected is true if this is the server currently in use.
+	Connected bool
+	// ServerVersion and ProtocolVersion are the versions reported by the server. They are empty
+	// if no connection was established yet.
+	ServerVersion   string
+	ProtocolVersion string
+	// Latency is the moving average of the response time.
+	Latency time.Duration
+	// TipHeight is the height of the chain tip reported by the server, 0 if unknown.
+	TipHeight int
+	// TipLag is the number of blocks by which the server was behind the best known chain tip.
+	TipLag   int
+	Requests int
+	Errors   int
+	// LastError is the last error of a request to the server, empty if there was none.
+	LastError string
+	// Score is the health score of the server, between 0 and 100. Higher is better.
+	Score int
+	// Issues are the reasons the score is below 100.
+	Issues []ServerIssue
+	// Avoided is true if the score is too low. The server is only used if all other servers are
+	// avoided as well.
+	Avoided bool
+}
+
 // Interface is the interface to a blockchain index backend. Currently geared to Electrum, though
 // other backends can implement the same interface.
 //
@@ -113,10 +168,16 @@ type Interface interface {
 	TransactionBroadcast(*wire.MsgTx) error
 	RelayFee() (btcutil.Amount, error)
 	EstimateFee(int) (btcutil.Amount, error)
+	MempoolFeeHistogram() (FeeHistogram, error)
 	Headers(int, int) (*HeadersResult, error)
 	GetMerkle(chainhash.Hash, int) (*GetMerkleResult, error)
 	Close()
 	ConnectionError() error
 	RegisterOnConnectionErrorChangedEvent(func(error))
 	ManualReconnect()
+	// ServerStatus returns the health status of all configured servers.
+	ServerStatus() []*ServerStatus
+	// RecordVerifiedTip records the height of the chain tip verified by the headers. The chain tips
+	// reported by the servers are compared to it to detect lagging servers.
+	RecordVerifiedTip(int)
 }
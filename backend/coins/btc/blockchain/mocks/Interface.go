@@ -141,6 +141,41 @@ func (_m *Interface) ManualReconnect() {
 	_m.Called()
 }
 
+// MempoolFeeHistogram provides a mock function with given fields:
+func (_m *Interface) MempoolFeeHistogram() (blockchain.FeeHistogram, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for MempoolFeeHistogram")
+	}
+
+	var r0 blockchain.FeeHistogram
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (blockchain.FeeHistogram, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() blockchain.FeeHistogram); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(blockchain.FeeHistogram)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RecordVerifiedTip provides a mock function with given fields: _a0
+func (_m *Interface) RecordVerifiedTip(_a0 int) {
+	_m.Called(_a0)
+}
+
 // RegisterOnConnectionErrorChangedEvent provides a mock function with given fields: _a0
 func (_m *Interface) RegisterOnConnectionErrorChangedEvent(_a0 func(error)) {
 	_m.Called(_a0)
@@ -209,6 +244,26 @@ func (_m *Interface) ScriptHashSubscribe(_a0 func() func(), _a1 blockchain.Scrip
 	_m.Called(_a0, _a1, _a2)
 }
 
+// ServerStatus provides a mock function with given fields:
+func (_m *Interface) ServerStatus() []*blockchain.ServerStatus {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ServerStatus")
+	}
+
+	var r0 []*blockchain.ServerStatus
+	if rf, ok := ret.Get(0).(func() []*blockchain.ServerStatus); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*blockchain.ServerStatus)
+		}
+	}
+
+	return r0
+}
+
 // TransactionBroadcast provides a mock function with given fields: _a0
 func (_m *Interface) TransactionBroadcast(_a0 *wire.MsgTx) error {
 	ret := _m.Called(_a0)
@@ -33,6 +33,7 @@ type BlockchainMock struct {
 	MockTransactionBroadcast func(*wire.MsgTx) error
 	MockRelayFee             func() (btcutil.Amount, error)
 	MockEstimateFee          func(int) (btcutil.Amount, error)
+	MockMempoolFeeHistogram  func() (blockchain.FeeHistogram, error)
 	MockHeaders              func(int, int) (*blockchain.HeadersResult, error)
 	MockGetMerkle            func(chainhash.Hash, int) (*blockchain.GetMerkleResult, error)
 	MockClose                func()
@@ -40,6 +41,8 @@ type BlockchainMock struct {
 
 	MockRegisterOnConnectionErrorChangedEvent func(func(error))
 	MockManualReconnect                       func()
+	MockServerStatus                          func() []*blockchain.ServerStatus
+	MockRecordVerifiedTip                     func(int)
 }
 
 // ScriptHashGetHistory implements Interface.
@@ -96,6 +99,14 @@ func (b *BlockchainMock) EstimateFee(i int) (btcutil.Amount, error) {
 	panic("not implemented")
 }
 
+// MempoolFeeHistogram implements Interface.
+func (b *BlockchainMock) MempoolFeeHistogram() (blockchain.FeeHistogram, error) {
+	if b.MockMempoolFeeHistogram != nil {
+		return b.MockMempoolFeeHistogram()
+	}
+	panic("not implemented")
+}
+
 // Headers implements Interface.
 func (b *BlockchainMock) Headers(i1 int, i2 int) (*blockchain.HeadersResult, error) {
 	if b.MockHeaders != nil {
@@ -143,3 +154,18 @@ func (b *BlockchainMock) ManualReconnect() {
 		b.MockManualReconnect()
 	}
 }
+
+// ServerStatus implements Interface.
+func (b *BlockchainMock) ServerStatus() []*blockchain.ServerStatus {
+	if b.MockServerStatus != nil {
+		return b.MockServerStatus()
+	}
+	return nil
+}
+
+// RecordVerifiedTip implements Interface.
+func (b *BlockchainMock) RecordVerifiedTip(height int) {
+	if b.MockRecordVerifiedTip != nil {
+		b.MockRecordVerifiedTip(height)
+	}
+}
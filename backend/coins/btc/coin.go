@@ -27,6 +27,7 @@ import (
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/db/headersdb"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/electrum"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/headers"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/silentpayments"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/util"
 	coinpkg "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/config"
@@ -60,6 +61,10 @@ type Coin struct {
 	blockchain blockchain.Interface
 	headers    *headers.Headers
 
+	// tweakIndex is used to scan for silent payments received by the accounts of this coin. It is
+	// nil if no tweak index server is configured.
+	tweakIndex silentpayments.TweakIndex
+
 	log *logrus.Entry
 }
 
@@ -102,6 +107,17 @@ func (coin *Coin) TstSetMakeBlockchain(f func() blockchain.Interface) {
 	coin.makeBlockchain = f
 }
 
+// SetTweakIndex sets the server used to scan for silent payments. It must be called before the
+// accounts of this coin are initialized.
+func (coin *Coin) SetTweakIndex(tweakIndex silentpayments.TweakIndex) {
+	coin.tweakIndex = tweakIndex
+}
+
+// TweakIndex returns the server used to scan for silent payments, or nil if none is configured.
+func (coin *Coin) TweakIndex() silentpayments.TweakIndex {
+	return coin.tweakIndex
+}
+
 // Initialize implements coinpkg.Coin.
 func (coin *Coin) Initialize() {
 	coin.initOnce.Do(func() {
@@ -127,12 +143,18 @@ func (coin *Coin) Initialize() {
 			db,
 			coin.blockchain,
 			coin.log)
+		// The tip verified in a previous session is used until the headers are synced.
+		if status, err := coin.headers.Status(); err == nil {
+			coin.blockchain.RecordVerifiedTip(status.Tip)
+		}
 		coin.headers.Initialize()
 		coin.headers.SubscribeEvent(func(event headers.Event) {
 			if event == headers.EventSyncing || event == headers.EventSynced {
 				status, err := coin.headers.Status()
 				if err != nil {
 					coin.log.WithError(err).Error("Could not get headers status")
+				} else {
+					coin.blockchain.RecordVerifiedTip(status.Tip)
 				}
 				coin.Notify(observable.Event{
 					Subject: fmt.Sprintf("coins/%s/headers/status", coin.code),
@@ -0,0 +1,154 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package btc
+
+import (
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/maketx"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/transactions"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// ConsolidationArgs are the arguments of ConsolidationProposal(). The filters restrict which coins
+// are merged. Frozen coins and coins spent by a scheduled transaction are never merged.
+type ConsolidationArgs struct {
+	// MaxFeeRatePerKb is the highest fee rate the consolidation may pay.
+	MaxFeeRatePerKb btcutil.Amount
+	// FutureFeeRatePerKb is the fee rate at which the savings are estimated. If zero, the fee rate
+	// of the highest fee target is used.
+	FutureFeeRatePerKb btcutil.Amount
+	// ScriptType, if not empty, restricts the consolidation to coins of this script type.
+	ScriptType signing.ScriptType
+	// MaxValue, if not zero, restricts the consolidation to coins worth at most this value.
+	MaxValue btcutil.Amount
+	// Address, if not empty, restricts the consolidation to coins received on this address.
+	Address string
+}
+
+// Consolidation is the result of ConsolidationProposal().
+type Consolidation struct {
+	Amount coin.Amount
+	Fee    coin.Amount
+	Total  coin.Amount
+	// CoinCount is the number of merged coins.
+	CoinCount int
+	// Savings estimates the savings of spending the merged coin instead of the individual coins.
+	Savings *maketx.ConsolidationSavings
+}
+
+// consolidationUTXOs returns the coins matching the filters of the consolidation args.
+func (account *Account) consolidationUTXOs(args *ConsolidationArgs) (map[wire.OutPoint]maketx.UTXO, error) {
+	utxos, err := account.transactions.SpendableOutputs()
+	if err != nil {
+		return nil, err
+	}
+	scheduledInputs, err := transactions.DBView(account.db, scheduledTxInputs)
+	if err != nil {
+		return nil, err
+	}
+	result := map[wire.OutPoint]maketx.UTXO{}
+	for outPoint, utxo := range utxos {
+		if _, scheduled := scheduledInputs[outPoint]; scheduled || account.OutputFrozen(outPoint) {
+			continue
+		}
+		address := account.GetAddress(utxo.ScriptHashHex())
+		if address == nil {
+			continue
+		}
+		if args.ScriptType != "" && address.AccountConfiguration.ScriptType() != args.ScriptType {
+			continue
+		}
+		if args.MaxValue != 0 && btcutil.Amount(utxo.TxOut.Value) > args.MaxValue {
+			continue
+		}
+		if args.Address != "" && address.EncodeForHumans() != args.Address {
+			continue
+		}
+		result[outPoint] = maketx.UTXO{TxOut: utxo.TxOut, Address: address}
+	}
+	return result, nil
+}
+
+// newConsolidationTx creates a transaction merging the coins matching the consolidation args into a
+// fresh change address of this account, paying the fee rate specified in txProposalArgs.
+func (account *Account) newConsolidationTx(args *ConsolidationArgs, txProposalArgs *accounts.TxProposalArgs) (
+	*maketx.TxProposal, error) {
+	if !account.Synced() {
+		return nil, accounts.ErrSyncInProgress
+	}
+	if args.MaxFeeRatePerKb <= 0 {
+		return nil, errp.New("A maximum fee rate is required")
+	}
+	feeRatePerKb, err := account.getFeePerKb(txProposalArgs)
+	if err != nil {
+		return nil, err
+	}
+	if feeRatePerKb > args.MaxFeeRatePerKb {
+		return nil, errp.Newf("The fee rate of %d sat/kvB exceeds the maximum of %d sat/kvB",
+			feeRatePerKb, args.MaxFeeRatePerKb)
+	}
+	futureFeeRatePerKb := args.FutureFeeRatePerKb
+	if futureFeeRatePerKb == 0 {
+		futureFeeRatePerKb = feeRatePerKb
+		if highest := account.feeTargets().highest(); highest != nil && *highest.feeRatePerKb > feeRatePerKb {
+			futureFeeRatePerKb = *highest.feeRatePerKb
+		}
+	}
+	utxos, err := account.consolidationUTXOs(args)
+	if err != nil {
+		return nil, err
+	}
+	outputAddress, err := account.pickChangeAddress(utxos, nil)
+	if err != nil {
+		return nil, err
+	}
+	return maketx.NewConsolidationTx(
+		account.coin,
+		utxos,
+		feeRatePerKb,
+		futureFeeRatePerKb,
+		outputAddress,
+		account.log,
+	)
+}
+
+// ConsolidationProposal creates a proposal merging many small coins into one (UTXO consolidation),
+// so that spending them later when fees are high is cheaper. The consolidation pays the fee target
+// or custom fee of txProposalArgs, which must not exceed the maximum fee rate of args. Like
+// TxProposal(), the proposal becomes the active tx proposal.
+func (account *Account) ConsolidationProposal(args *ConsolidationArgs, txProposalArgs *accounts.TxProposalArgs) (
+	*Consolidation, error) {
+	defer account.activeTxProposalLock.Lock()()
+
+	account.log.Debug("Proposing consolidation")
+	txProposal, err := account.newConsolidationTx(args, txProposalArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	account.activeTxProposal = txProposal
+
+	return &Consolidation{
+		Amount:    coin.NewAmountFromInt64(int64(txProposal.Amount)),
+		Fee:       coin.NewAmountFromInt64(int64(txProposal.Fee)),
+		Total:     coin.NewAmountFromInt64(int64(txProposal.Total())),
+		CoinCount: len(txProposal.Transaction.TxIn),
+		Savings:   txProposal.ConsolidationSavings,
+	}, nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package btc
+
+import (
+	"testing"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsolidationProposal(t *testing.T) {
+	account := testAccount(t, nil)
+	txProposalArgs := &accounts.TxProposalArgs{
+		FeeTargetCode: accounts.FeeTargetCodeCustom,
+		CustomFee:     "10",
+	}
+
+	consolidation, err := account.ConsolidationProposal(&ConsolidationArgs{
+		MaxFeeRatePerKb:    20000,
+		FutureFeeRatePerKb: 50000,
+	}, txProposalArgs)
+	require.NoError(t, err)
+	require.Equal(t, 2, consolidation.CoinCount)
+	// Two P2WPKH inputs and one P2WPKH output.
+	require.Equal(t, coin.NewAmountFromInt64(1780), consolidation.Fee)
+	require.Equal(t, coin.NewAmountFromInt64(1001000000-1780), consolidation.Amount)
+	require.Equal(t, coin.NewAmountFromInt64(1001000000), consolidation.Total)
+	require.Equal(t, 68, consolidation.Savings.VSize)
+	require.Equal(t, btcutil.Amount(3400), consolidation.Savings.FutureFee)
+	require.Equal(t, btcutil.Amount(3400-1780), consolidation.Savings.Net)
+
+	txProposal := account.activeTxProposal
+	require.Len(t, txProposal.Transaction.TxOut, 1)
+	require.NotNil(t, txProposal.ChangeAddress)
+	require.Equal(t, txProposal.ChangeAddress.PubkeyScript(), txProposal.Transaction.TxOut[0].PkScript)
+}
+
+func TestConsolidationProposalErrors(t *testing.T) {
+	account := testAccount(t, nil)
+	txProposalArgs := &accounts.TxProposalArgs{
+		FeeTargetCode: accounts.FeeTargetCodeCustom,
+		CustomFee:     "10",
+	}
+
+	// The fee rate exceeds the maximum.
+	_, err := account.ConsolidationProposal(&ConsolidationArgs{MaxFeeRatePerKb: 5000}, txProposalArgs)
+	require.Error(t, err)
+
+	_, err = account.ConsolidationProposal(&ConsolidationArgs{}, txProposalArgs)
+	require.Error(t, err)
+
+	// No coins of this script type.
+	_, err = account.ConsolidationProposal(&ConsolidationArgs{
+		MaxFeeRatePerKb: 20000,
+		ScriptType:      signing.ScriptTypeP2TR,
+	}, txProposalArgs)
+	require.Error(t, err)
+
+	// Only one coin is small enough.
+	_, err = account.ConsolidationProposal(&ConsolidationArgs{
+		MaxFeeRatePerKb: 20000,
+		MaxValue:        1000000,
+	}, txProposalArgs)
+	require.Error(t, err)
+
+	// Only one coin is not frozen.
+	require.NoError(t, account.SetOutputFrozen(*wire.NewOutPoint(&chainhash.Hash{}, 0), true))
+	_, err = account.ConsolidationProposal(&ConsolidationArgs{MaxFeeRatePerKb: 20000}, txProposalArgs)
+	require.Error(t, err)
+}
@@ -0,0 +1,181 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package btc
+
+import (
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/blockchain"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/maketx"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/transactions"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// cpfpParent contains the data of an unconfirmed transaction needed to accelerate it.
+type cpfpParent struct {
+	tx *wire.MsgTx
+	// parentOutputs are the unspent outputs of the transaction belonging to this account which are
+	// not frozen or spent by a scheduled transaction.
+	parentOutputs map[wire.OutPoint]maketx.UTXO
+	// knownInputValues contains the values of the spent outputs which are found in the database.
+	knownInputValues map[wire.OutPoint]btcutil.Amount
+}
+
+func (account *Account) loadCPFPParent(txHash chainhash.Hash) (*cpfpParent, error) {
+	return transactions.DBView(account.db, func(dbTx transactions.DBTxInterface) (*cpfpParent, error) {
+		txInfo, err := dbTx.TxInfo(txHash)
+		if err != nil {
+			return nil, err
+		}
+		if txInfo == nil || txInfo.Tx == nil {
+			return nil, errp.New("Transaction not found")
+		}
+		if txInfo.Height > 0 {
+			return nil, errp.New("Transaction is already confirmed")
+		}
+		if txInfo.Height < 0 {
+			// The fee rate of the package would depend on the unconfirmed ancestors too.
+			return nil, errp.New("Transactions with unconfirmed parents cannot be accelerated")
+		}
+		scheduledInputs, err := scheduledTxInputs(dbTx)
+		if err != nil {
+			return nil, err
+		}
+		parent := &cpfpParent{
+			tx:               txInfo.Tx,
+			parentOutputs:    map[wire.OutPoint]maketx.UTXO{},
+			knownInputValues: map[wire.OutPoint]btcutil.Amount{},
+		}
+		for _, txIn := range txInfo.Tx.TxIn {
+			txOut, err := dbTx.Output(txIn.PreviousOutPoint)
+			if err != nil {
+				return nil, err
+			}
+			if txOut != nil {
+				parent.knownInputValues[txIn.PreviousOutPoint] = btcutil.Amount(txOut.Value)
+			}
+		}
+		for index := range txInfo.Tx.TxOut {
+			outPoint := wire.OutPoint{Hash: txHash, Index: uint32(index)}
+			txOut, err := dbTx.Output(outPoint)
+			if err != nil {
+				return nil, err
+			}
+			if txOut == nil {
+				continue
+			}
+			spentBy, err := dbTx.Input(outPoint)
+			if err != nil {
+				return nil, err
+			}
+			_, scheduled := scheduledInputs[outPoint]
+			if spentBy != nil || scheduled || account.OutputFrozen(outPoint) {
+				continue
+			}
+			address := account.GetAddress(blockchain.NewScriptHashHex(txOut.PkScript))
+			if address == nil {
+				continue
+			}
+			parent.parentOutputs[outPoint] = maketx.UTXO{TxOut: txOut, Address: address}
+		}
+		return parent, nil
+	})
+}
+
+// newCPFPTx creates a transaction accelerating the unconfirmed transaction with the given ID by
+// spending its outputs belonging to this account back to the account (child-pays-for-parent). The
+// fee rate specified in args is the target fee rate of the package (parent and child).
+func (account *Account) newCPFPTx(txID string, args *accounts.TxProposalArgs) (
+	*maketx.TxProposal, error) {
+	if !account.Synced() {
+		return nil, accounts.ErrSyncInProgress
+	}
+	txHash, err := chainhash.NewHashFromStr(txID)
+	if err != nil {
+		return nil, errp.WithStack(err)
+	}
+	parent, err := account.loadCPFPParent(*txHash)
+	if err != nil {
+		return nil, err
+	}
+	if len(parent.parentOutputs) == 0 {
+		return nil, errp.New("The transaction has no unspent outputs belonging to this account")
+	}
+
+	// The spent outputs of incoming transactions are not ours, so they are fetched to compute the
+	// fee of the parent.
+	var inputsSum btcutil.Amount
+	for _, txIn := range parent.tx.TxIn {
+		if value, ok := parent.knownInputValues[txIn.PreviousOutPoint]; ok {
+			inputsSum += value
+			continue
+		}
+		prevTx, err := account.coin.Blockchain().TransactionGet(txIn.PreviousOutPoint.Hash)
+		if err != nil {
+			return nil, err
+		}
+		if int(txIn.PreviousOutPoint.Index) >= len(prevTx.TxOut) {
+			return nil, errp.New("Invalid previous output of the transaction")
+		}
+		inputsSum += btcutil.Amount(prevTx.TxOut[txIn.PreviousOutPoint.Index].Value)
+	}
+	var outputsSum btcutil.Amount
+	for _, txOut := range parent.tx.TxOut {
+		outputsSum += btcutil.Amount(txOut.Value)
+	}
+
+	feeRatePerKb, err := account.getFeePerKb(args)
+	if err != nil {
+		return nil, err
+	}
+	changeAddress, err := account.pickChangeAddress(parent.parentOutputs, nil)
+	if err != nil {
+		return nil, err
+	}
+	return maketx.NewCPFPTx(
+		account.coin,
+		&maketx.ParentTx{Transaction: parent.tx, Fee: inputsSum - outputsSum},
+		parent.parentOutputs,
+		feeRatePerKb,
+		changeAddress,
+		account.log,
+	)
+}
+
+// CPFPProposal creates a proposal accelerating the unconfirmed transaction with the given ID using
+// child-pays-for-parent, so that the parent and the child together pay the fee rate given by the
+// fee target or custom fee of args. Like TxProposal(), the proposal becomes the active tx proposal.
+// In addition to the amount, fee and total of the child, the effective fee rate of the package is
+// returned.
+func (account *Account) CPFPProposal(txID string, args *accounts.TxProposalArgs) (
+	coin.Amount, coin.Amount, coin.Amount, btcutil.Amount, error) {
+	defer account.activeTxProposalLock.Lock()()
+
+	account.log.WithField("txID", txID).Debug("Proposing CPFP transaction")
+	txProposal, err := account.newCPFPTx(txID, args)
+	if err != nil {
+		return coin.Amount{}, coin.Amount{}, coin.Amount{}, 0, err
+	}
+
+	account.activeTxProposal = txProposal
+
+	return coin.NewAmountFromInt64(int64(txProposal.Amount)),
+		coin.NewAmountFromInt64(int64(txProposal.Fee)),
+		coin.NewAmountFromInt64(int64(txProposal.Total())),
+		txProposal.PackageFeeRatePerKb, nil
+}
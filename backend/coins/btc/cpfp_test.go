@@ -0,0 +1,88 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package btc
+
+import (
+	"testing"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/blockchain"
+	blockchainMocks "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/blockchain/mocks"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/transactions"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/mempool"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCPFP(t *testing.T) {
+	account := testAccount(t, nil)
+	receiveAddresses, err := account.subaccounts[0].receiveAddresses.GetUnused()
+	require.NoError(t, err)
+	recipientPkScript, err := account.coin.AddressToPkScript("myY3Bbvj5mjwqqvubtu5Hfy2nuCeBfvNXL")
+	require.NoError(t, err)
+
+	// An incoming transaction paying 1 sat/vbyte.
+	foreignTx := wire.NewMsgTx(wire.TxVersion)
+	foreignTx.AddTxOut(wire.NewTxOut(1000000, recipientPkScript))
+	parentTx := wire.NewMsgTx(wire.TxVersion)
+	foreignTxHash := foreignTx.TxHash()
+	parentTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&foreignTxHash, 0), nil, wire.TxWitness{
+		make([]byte, 72), make([]byte, 33),
+	}))
+	parentTx.AddTxOut(wire.NewTxOut(500000, receiveAddresses[0].PubkeyScript()))
+	parentTx.AddTxOut(wire.NewTxOut(0, recipientPkScript))
+	parentVSize := mempool.GetTxVirtualSize(btcutil.NewTx(parentTx))
+	parentTx.TxOut[1].Value = 1000000 - 500000 - parentVSize
+
+	parentTxHash := parentTx.TxHash()
+	require.NoError(t, transactions.DBUpdate(account.db, func(dbTx transactions.DBTxInterface) error {
+		if err := dbTx.PutTx(parentTxHash, parentTx, 0); err != nil {
+			return err
+		}
+		return dbTx.PutOutput(wire.OutPoint{Hash: parentTxHash, Index: 0}, parentTx.TxOut[0])
+	}))
+	account.coin.blockchain.(*blockchainMocks.BlockchainMock).MockTransactionGet = func(txHash chainhash.Hash) (*wire.MsgTx, error) {
+		require.Equal(t, foreignTx.TxHash(), txHash)
+		return foreignTx, nil
+	}
+
+	amount, fee, _, packageFeeRatePerKb, err := account.CPFPProposal(parentTxHash.String(), &accounts.TxProposalArgs{
+		FeeTargetCode: accounts.FeeTargetCodeCustom,
+		CustomFee:     "20",
+	})
+	require.NoError(t, err)
+	require.Equal(t, btcutil.Amount(20000), packageFeeRatePerKb)
+
+	txProposal := account.activeTxProposal
+	require.Len(t, txProposal.Transaction.TxIn, 1)
+	require.Equal(t, wire.OutPoint{Hash: parentTxHash, Index: 0}, txProposal.Transaction.TxIn[0].PreviousOutPoint)
+	require.Len(t, txProposal.Transaction.TxOut, 1)
+	require.True(t, account.IsChange(blockchain.NewScriptHashHex(txProposal.Transaction.TxOut[0].PkScript)))
+	// The child pays for the missing 19 sat/vbyte of the parent on top of its own fee.
+	require.Greater(t, fee.BigInt().Int64(), 19*parentVSize)
+	require.Equal(t, int64(500000)-fee.BigInt().Int64(), amount.BigInt().Int64())
+
+	// Confirmed transactions don't need to be accelerated.
+	require.NoError(t, transactions.DBUpdate(account.db, func(dbTx transactions.DBTxInterface) error {
+		return dbTx.PutTx(parentTxHash, parentTx, 10)
+	}))
+	_, _, _, _, err = account.CPFPProposal(parentTxHash.String(), &accounts.TxProposalArgs{
+		FeeTargetCode: accounts.FeeTargetCodeCustom,
+		CustomFee:     "20",
+	})
+	require.EqualError(t, err, "Transaction is already confirmed")
+}
@@ -37,6 +37,11 @@ const (
 	bucketOutputsKey                = "outputs"
 	bucketAddressHistoriesKey       = "addressHistories"
 	bucketConfigKey                 = "config"
+	bucketReplacementsKey           = "replacements"
+	bucketCancellationsKey          = "cancellations"
+	bucketScheduledTxsKey           = "scheduledTransactions"
+	bucketSilentPaymentOutputsKey   = "silentPaymentOutputs"
+	bucketDustOutputsKey            = "dustOutputs"
 )
 
 // DB is a bbolt key/value database.
@@ -382,3 +387,187 @@ func (tx *Tx) GapLimits() (types.GapLimits, error) {
 	}
 	return types.GapLimits{}, nil
 }
+
+// PutReplacement implements transactions.DBTxInterface.
+func (tx *Tx) PutReplacement(replacement chainhash.Hash, replaced chainhash.Hash) error {
+	bucketReplacements, err := tx.tx.CreateBucketIfNotExists([]byte(bucketReplacementsKey))
+	if err != nil {
+		return errp.WithStack(err)
+	}
+	return bucketReplacements.Put(replacement[:], replaced[:])
+}
+
+// Replacements implements transactions.DBTxInterface.
+func (tx *Tx) Replacements() (map[chainhash.Hash]chainhash.Hash, error) {
+	replacements := map[chainhash.Hash]chainhash.Hash{}
+	bucketReplacements := tx.tx.Bucket([]byte(bucketReplacementsKey))
+	if bucketReplacements == nil {
+		return replacements, nil
+	}
+	cursor := bucketReplacements.Cursor()
+	for replacementBytes, replacedBytes := cursor.First(); replacementBytes != nil; replacementBytes, replacedBytes = cursor.Next() {
+		var replacement, replaced chainhash.Hash
+		if err := replacement.SetBytes(replacementBytes); err != nil {
+			return nil, errp.WithStack(err)
+		}
+		if err := replaced.SetBytes(replacedBytes); err != nil {
+			return nil, errp.WithStack(err)
+		}
+		replacements[replacement] = replaced
+	}
+	return replacements, nil
+}
+
+// PutCancellation implements transactions.DBTxInterface.
+func (tx *Tx) PutCancellation(replacement chainhash.Hash, cancelled *transactions.DBTxInfo) error {
+	bucketCancellations, err := tx.tx.CreateBucketIfNotExists([]byte(bucketCancellationsKey))
+	if err != nil {
+		return errp.WithStack(err)
+	}
+	return writeJSON(bucketCancellations, replacement[:], cancelled)
+}
+
+// Cancellations implements transactions.DBTxInterface.
+func (tx *Tx) Cancellations() (map[chainhash.Hash]*transactions.DBTxInfo, error) {
+	cancellations := map[chainhash.Hash]*transactions.DBTxInfo{}
+	bucketCancellations := tx.tx.Bucket([]byte(bucketCancellationsKey))
+	if bucketCancellations == nil {
+		return cancellations, nil
+	}
+	cursor := bucketCancellations.Cursor()
+	for replacementBytes, cancelledBytes := cursor.First(); replacementBytes != nil; replacementBytes, cancelledBytes = cursor.Next() {
+		var replacement chainhash.Hash
+		if err := replacement.SetBytes(replacementBytes); err != nil {
+			return nil, errp.WithStack(err)
+		}
+		cancelled := newWalletTransaction()
+		if err := json.Unmarshal(cancelledBytes, cancelled); err != nil {
+			return nil, errp.WithStack(err)
+		}
+		if cancelled.Tx == nil {
+			return nil, errp.New("Cancelled transaction is missing")
+		}
+		cancelled.TxHash = cancelled.Tx.TxHash()
+		cancellations[replacement] = cancelled
+	}
+	return cancellations, nil
+}
+
+// PutScheduledTx implements transactions.DBTxInterface.
+func (tx *Tx) PutScheduledTx(txHash chainhash.Hash, scheduledTx *transactions.ScheduledTx) error {
+	bucketScheduledTxs, err := tx.tx.CreateBucketIfNotExists([]byte(bucketScheduledTxsKey))
+	if err != nil {
+		return errp.WithStack(err)
+	}
+	return writeJSON(bucketScheduledTxs, txHash[:], scheduledTx)
+}
+
+// ScheduledTxs implements transactions.DBTxInterface.
+func (tx *Tx) ScheduledTxs() (map[chainhash.Hash]*transactions.ScheduledTx, error) {
+	scheduledTxs := map[chainhash.Hash]*transactions.ScheduledTx{}
+	bucketScheduledTxs := tx.tx.Bucket([]byte(bucketScheduledTxsKey))
+	if bucketScheduledTxs == nil {
+		return scheduledTxs, nil
+	}
+	cursor := bucketScheduledTxs.Cursor()
+	for txHashBytes, scheduledTxBytes := cursor.First(); txHashBytes != nil; txHashBytes, scheduledTxBytes = cursor.Next() {
+		var txHash chainhash.Hash
+		if err := txHash.SetBytes(txHashBytes); err != nil {
+			return nil, errp.WithStack(err)
+		}
+		scheduledTx := &transactions.ScheduledTx{}
+		if err := json.Unmarshal(scheduledTxBytes, scheduledTx); err != nil {
+			return nil, errp.WithStack(err)
+		}
+		if scheduledTx.Tx == nil {
+			return nil, errp.New("Scheduled transaction is missing")
+		}
+		scheduledTxs[txHash] = scheduledTx
+	}
+	return scheduledTxs, nil
+}
+
+// DeleteScheduledTx implements transactions.DBTxInterface.
+func (tx *Tx) DeleteScheduledTx(txHash chainhash.Hash) error {
+	bucketScheduledTxs := tx.tx.Bucket([]byte(bucketScheduledTxsKey))
+	if bucketScheduledTxs == nil {
+		return nil
+	}
+	return errp.WithStack(bucketScheduledTxs.Delete(txHash[:]))
+}
+
+// PutSilentPaymentOutput implements transactions.DBTxInterface.
+func (tx *Tx) PutSilentPaymentOutput(outPoint wire.OutPoint, output *transactions.SilentPaymentOutput) error {
+	bucketSilentPaymentOutputs, err := tx.tx.CreateBucketIfNotExists([]byte(bucketSilentPaymentOutputsKey))
+	if err != nil {
+		return errp.WithStack(err)
+	}
+	return writeJSON(bucketSilentPaymentOutputs, []byte(outPoint.String()), output)
+}
+
+// SilentPaymentOutputs implements transactions.DBTxInterface.
+func (tx *Tx) SilentPaymentOutputs() (map[wire.OutPoint]*transactions.SilentPaymentOutput, error) {
+	outputs := map[wire.OutPoint]*transactions.SilentPaymentOutput{}
+	bucketSilentPaymentOutputs := tx.tx.Bucket([]byte(bucketSilentPaymentOutputsKey))
+	if bucketSilentPaymentOutputs == nil {
+		return outputs, nil
+	}
+	cursor := bucketSilentPaymentOutputs.Cursor()
+	for outPointBytes, outputBytes := cursor.First(); outPointBytes != nil; outPointBytes, outputBytes = cursor.Next() {
+		output := &transactions.SilentPaymentOutput{}
+		if err := json.Unmarshal(outputBytes, output); err != nil {
+			return nil, errp.WithStack(err)
+		}
+		outPoint, err := util.ParseOutPoint(outPointBytes)
+		if err != nil {
+			return nil, err
+		}
+		outputs[*outPoint] = output
+	}
+	return outputs, nil
+}
+
+// PutSilentPaymentsScanHeight implements transactions.DBTxInterface.
+func (tx *Tx) PutSilentPaymentsScanHeight(height int) error {
+	bucketConfig, err := tx.tx.CreateBucketIfNotExists([]byte(bucketConfigKey))
+	if err != nil {
+		return errp.WithStack(err)
+	}
+	return writeJSON(bucketConfig, []byte("silentPaymentsScanHeight"), height)
+}
+
+// SilentPaymentsScanHeight implements transactions.DBTxInterface.
+func (tx *Tx) SilentPaymentsScanHeight() (int, error) {
+	var height int
+	if _, err := readJSON(tx.tx.Bucket([]byte(bucketConfigKey)), []byte("silentPaymentsScanHeight"), &height); err != nil {
+		return 0, err
+	}
+	return height, nil
+}
+
+// PutDustOutput implements transactions.DBTxInterface.
+func (tx *Tx) PutDustOutput(outPoint wire.OutPoint) error {
+	bucketDustOutputs, err := tx.tx.CreateBucketIfNotExists([]byte(bucketDustOutputsKey))
+	if err != nil {
+		return errp.WithStack(err)
+	}
+	return errp.WithStack(bucketDustOutputs.Put([]byte(outPoint.String()), nil))
+}
+
+// DustOutputs implements transactions.DBTxInterface.
+func (tx *Tx) DustOutputs() (map[wire.OutPoint]struct{}, error) {
+	outPoints := map[wire.OutPoint]struct{}{}
+	bucketDustOutputs := tx.tx.Bucket([]byte(bucketDustOutputsKey))
+	if bucketDustOutputs == nil {
+		return outPoints, nil
+	}
+	cursor := bucketDustOutputs.Cursor()
+	for outPointBytes, _ := cursor.First(); outPointBytes != nil; outPointBytes, _ = cursor.Next() {
+		outPoint, err := util.ParseOutPoint(outPointBytes)
+		if err != nil {
+			return nil, err
+		}
+		outPoints[*outPoint] = struct{}{}
+	}
+	return outPoints, nil
+}
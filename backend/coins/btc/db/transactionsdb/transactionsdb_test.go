@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/blockchain"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/transactions"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/types"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/util/test"
 	"github.com/btcsuite/btcd/btcutil"
@@ -548,3 +549,118 @@ func TestGapLimits(t *testing.T) {
 		require.Equal(t, uint16(123), limits.Change)
 	})
 }
+
+func TestReplacements(t *testing.T) {
+	testTx(func(tx *Tx) {
+		replacements, err := tx.Replacements()
+		require.NoError(t, err)
+		require.Empty(t, replacements)
+
+		replacement := chainhash.HashH([]byte("replacement"))
+		replaced := chainhash.HashH([]byte("replaced"))
+		require.NoError(t, tx.PutReplacement(replacement, replaced))
+
+		replacements, err = tx.Replacements()
+		require.NoError(t, err)
+		require.Equal(t, map[chainhash.Hash]chainhash.Hash{replacement: replaced}, replacements)
+	})
+}
+
+func TestCancellations(t *testing.T) {
+	testTx(func(tx *Tx) {
+		cancellations, err := tx.Cancellations()
+		require.NoError(t, err)
+		require.Empty(t, cancellations)
+
+		cancelledTx := wire.NewMsgTx(wire.TxVersion)
+		cancelledTx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: chainhash.HashH([]byte("funding"))}, nil, nil))
+		cancelledTx.AddTxOut(wire.NewTxOut(1000, []byte{0x51}))
+		created := time.Unix(1700000000, 0).UTC()
+		replacement := chainhash.HashH([]byte("replacement"))
+		require.NoError(t, tx.PutCancellation(replacement, &transactions.DBTxInfo{
+			Tx:               cancelledTx,
+			Addresses:        map[string]bool{},
+			CreatedTimestamp: &created,
+		}))
+
+		cancellations, err = tx.Cancellations()
+		require.NoError(t, err)
+		require.Len(t, cancellations, 1)
+		cancelled := cancellations[replacement]
+		require.NotNil(t, cancelled)
+		require.Equal(t, cancelledTx.TxHash(), cancelled.TxHash)
+		require.Equal(t, cancelledTx, cancelled.Tx)
+		require.Equal(t, created, *cancelled.CreatedTimestamp)
+	})
+}
+
+func TestScheduledTxs(t *testing.T) {
+	testTx(func(tx *Tx) {
+		scheduledTxs, err := tx.ScheduledTxs()
+		require.NoError(t, err)
+		require.Empty(t, scheduledTxs)
+		require.NoError(t, tx.DeleteScheduledTx(chainhash.HashH([]byte("unknown"))))
+
+		scheduledTx := wire.NewMsgTx(wire.TxVersion)
+		scheduledTx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: chainhash.HashH([]byte("funding"))}, nil, nil))
+		scheduledTx.AddTxOut(wire.NewTxOut(1000, []byte{0x51}))
+		scheduledTx.LockTime = 900000
+		created := time.Unix(1700000000, 0).UTC()
+		require.NoError(t, tx.PutScheduledTx(scheduledTx.TxHash(), &transactions.ScheduledTx{
+			Tx:               scheduledTx,
+			Note:             "vesting",
+			CreatedTimestamp: created,
+		}))
+
+		scheduledTxs, err = tx.ScheduledTxs()
+		require.NoError(t, err)
+		require.Equal(t, map[chainhash.Hash]*transactions.ScheduledTx{
+			scheduledTx.TxHash(): {Tx: scheduledTx, Note: "vesting", CreatedTimestamp: created},
+		}, scheduledTxs)
+
+		require.NoError(t, tx.DeleteScheduledTx(scheduledTx.TxHash()))
+		scheduledTxs, err = tx.ScheduledTxs()
+		require.NoError(t, err)
+		require.Empty(t, scheduledTxs)
+	})
+}
+
+func TestSilentPaymentOutputs(t *testing.T) {
+	testTx(func(tx *Tx) {
+		outputs, err := tx.SilentPaymentOutputs()
+		require.NoError(t, err)
+		require.Empty(t, outputs)
+		height, err := tx.SilentPaymentsScanHeight()
+		require.NoError(t, err)
+		require.Equal(t, 0, height)
+
+		outPoint := wire.OutPoint{Hash: chainhash.HashH([]byte("silent payment")), Index: 3}
+		output := &transactions.SilentPaymentOutput{Tweak: []byte{1, 2, 3}, Height: 850000}
+		require.NoError(t, tx.PutSilentPaymentOutput(outPoint, output))
+		outputs, err = tx.SilentPaymentOutputs()
+		require.NoError(t, err)
+		require.Equal(t, map[wire.OutPoint]*transactions.SilentPaymentOutput{outPoint: output}, outputs)
+
+		require.NoError(t, tx.PutSilentPaymentsScanHeight(850001))
+		height, err = tx.SilentPaymentsScanHeight()
+		require.NoError(t, err)
+		require.Equal(t, 850001, height)
+	})
+}
+
+func TestDustOutputs(t *testing.T) {
+	testTx(func(tx *Tx) {
+		outPoints, err := tx.DustOutputs()
+		require.NoError(t, err)
+		require.Empty(t, outPoints)
+
+		outPoint1 := wire.OutPoint{Hash: chainhash.HashH([]byte("dust")), Index: 1}
+		outPoint2 := wire.OutPoint{Hash: chainhash.HashH([]byte("dust")), Index: 2}
+		require.NoError(t, tx.PutDustOutput(outPoint1))
+		require.NoError(t, tx.PutDustOutput(outPoint2))
+		require.NoError(t, tx.PutDustOutput(outPoint1))
+		outPoints, err = tx.DustOutputs()
+		require.NoError(t, err)
+		require.Equal(t, map[wire.OutPoint]struct{}{outPoint1: {}, outPoint2: {}}, outPoints)
+	})
+}
@@ -0,0 +1,85 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package btc
+
+import (
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+)
+
+// networkKeyInfo returns a copy of the key info whose extended public key is encoded with the
+// version bytes of the coin's network (tpub for testnets), instead of the internal representation.
+func (account *Account) networkKeyInfo(keyInfo signing.KeyInfo) (signing.KeyInfo, error) {
+	extendedPublicKey, err := keyInfo.ExtendedPublicKey.CloneWithVersion(
+		account.coin.Net().HDPublicKeyID[:])
+	if err != nil {
+		return signing.KeyInfo{}, errp.WithStack(err)
+	}
+	keyInfo.ExtendedPublicKey = extendedPublicKey
+	return keyInfo, nil
+}
+
+// descriptorConfiguration returns a copy of the signing configuration with the extended public keys
+// encoded for the coin's network, as expected in descriptors.
+func (account *Account) descriptorConfiguration(
+	signingConfiguration *signing.Configuration) (*signing.Configuration, error) {
+	switch {
+	case signingConfiguration.BitcoinSimple != nil:
+		keyInfo, err := account.networkKeyInfo(signingConfiguration.BitcoinSimple.KeyInfo)
+		if err != nil {
+			return nil, err
+		}
+		return signing.NewBitcoinConfiguration(
+			signingConfiguration.ScriptType(),
+			keyInfo.RootFingerprint,
+			keyInfo.AbsoluteKeypath,
+			keyInfo.ExtendedPublicKey), nil
+	case signingConfiguration.BitcoinMultisig != nil:
+		multisig := signingConfiguration.BitcoinMultisig
+		keyInfos := make([]signing.KeyInfo, len(multisig.KeyInfos))
+		for i, keyInfo := range multisig.KeyInfos {
+			networkKeyInfo, err := account.networkKeyInfo(keyInfo)
+			if err != nil {
+				return nil, err
+			}
+			keyInfos[i] = networkKeyInfo
+		}
+		return signing.NewBitcoinMultisigConfiguration(
+			multisig.ScriptType, multisig.Threshold, keyInfos, multisig.OurKeyIndex)
+	default:
+		return signingConfiguration, nil
+	}
+}
+
+// Descriptors returns the BIP-380 output descriptors of all subaccounts of the account, the one of
+// the receive addresses followed by the one of the change addresses for each subaccount. They can
+// be imported into other descriptor-based wallets.
+func (account *Account) Descriptors() ([]string, error) {
+	descriptors := []string{}
+	for _, signingConfiguration := range account.Config().Config.SigningConfigurations {
+		signingConfiguration, err := account.descriptorConfiguration(signingConfiguration)
+		if err != nil {
+			return nil, err
+		}
+		for _, change := range []bool{false, true} {
+			descriptor, err := signingConfiguration.Descriptor(change)
+			if err != nil {
+				return nil, err
+			}
+			descriptors = append(descriptors, descriptor)
+		}
+	}
+	return descriptors, nil
+}
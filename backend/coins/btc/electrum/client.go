@@ -35,6 +35,10 @@ import (
 // also implements blockchain.Interface.
 type client struct {
 	client *electrum.Client
+	// conn is the connection of the client, used for RPC methods not supported by the client.
+	conn *rawRPCConn
+	// serverIndex is the index of the server in the servers of the failover client.
+	serverIndex int
 }
 
 func (c *client) EstimateFee(number int) (btcutil.Amount, error) {
@@ -45,6 +49,23 @@ func (c *client) EstimateFee(number int) (btcutil.Amount, error) {
 	return btcutil.NewAmount(fee)
 }
 
+func (c *client) MempoolFeeHistogram() (blockchain.FeeHistogram, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), methodTimeout)
+	defer cancel()
+	// A list of [fee rate, vsize] pairs sorted by descending fee rate. The fee rate is in sat/vB,
+	// and vsize is the cumulative virtual size of the mempool transactions whose fee rate is
+	// between the fee rate of the pair and the fee rate of the previous pair.
+	var result [][2]float64
+	if err := c.conn.method(ctx, &result, "mempool.get_fee_histogram"); err != nil {
+		return nil, err
+	}
+	histogram := make(blockchain.FeeHistogram, len(result))
+	for i, entry := range result {
+		histogram[i] = blockchain.FeeHistogramEntry{FeeRate: entry[0], VSize: int64(entry[1])}
+	}
+	return histogram, nil
+}
+
 func (c *client) GetMerkle(txHash chainhash.Hash, height int) (*blockchain.GetMerkleResult, error) {
 	result, err := c.client.GetMerkle(context.Background(), txHash.String(), height)
 	if err != nil {
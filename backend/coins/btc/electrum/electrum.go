@@ -16,12 +16,14 @@ package electrum
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/pem"
 	"errors"
 	"fmt"
 	"net"
+	"slices"
 	"time"
 
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/blockchain"
@@ -29,11 +31,24 @@ import (
 	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
 	"github.com/BitBoxSwiss/bitbox02-api-go/util/semver"
 	"github.com/BitBoxSwiss/block-client-go/electrum"
+	"github.com/BitBoxSwiss/block-client-go/electrum/types"
 	"github.com/BitBoxSwiss/block-client-go/failover"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/net/proxy"
 )
 
+const (
+	// methodTimeout is the duration after which method calls to the server in use time out. It is
+	// slightly less than the ping interval according to the `electrum.Options` docs - a ping is a
+	// method call by itself.
+	methodTimeout = 50 * time.Second
+	// initialProbeDelay is the time after which the servers not in use are checked for the first
+	// time, to not slow down the initial sync.
+	initialProbeDelay = time.Minute
+	// probeInterval is the interval in which the servers not in use are checked.
+	probeInterval = 5 * time.Minute
+)
+
 // softwareVersion reports to an electrum protocol compatible server
 // its name and a version so that server owners can identify what kind of
 // clients are connected.
@@ -151,30 +166,42 @@ func NewElectrumConnection(serverInfos []*config.ServerInfo, log *logrus.Entry,
 	servers := []*failover.Server[*client]{}
 	retryTimeout := 30 * time.Second
 
-	for _, serverInfo := range serverInfos {
+	health := newServerHealthTracker(serverInfos)
+
+	for index, serverInfo := range serverInfos {
 		servers = append(servers, &failover.Server[*client]{
 			Name: serverInfo.Server,
 			Connect: func() (*client, error) {
 				log := log.WithField("server", serverInfo.String())
+				if health.shouldSkip(index) {
+					log.Info("Skipping backend due to poor health")
+					return nil, errServerAvoided
+				}
 				log.Info("Trying to connect to backend")
+				var conn *rawRPCConn
 				c, err := electrum.Connect(&electrum.Options{
 					SoftwareVersion: softwareVersion,
-					// Slightly less than PingInterval according to the `electrum.Options` docs - a
-					// ping is a method call by itself.
-					MethodTimeout: 50 * time.Second,
-					PingInterval:  time.Minute,
+					MethodTimeout:   methodTimeout,
+					PingInterval:    time.Minute,
 					Dial: func() (net.Conn, error) {
-						return establishConnection(serverInfo, dialer)
+						netConn, err := establishConnection(serverInfo, dialer)
+						if err != nil {
+							return nil, err
+						}
+						conn = newRawRPCConn(netConn)
+						return conn, nil
 					},
 				})
 				if err != nil {
 					log.WithError(err).Error("Failover: backend is down")
+					health.recordConnectError(index, err)
 					return nil, err
 				}
 				log.
 					WithField("server-version", c.ServerVersion().String()).
 					Infof("Successfully connected to backend %s", serverInfo.Server)
-				return &client{client: c}, nil
+				health.recordConnect(index, c.ServerVersion().String())
+				return &client{client: c, conn: conn, serverIndex: index}, nil
 			},
 		})
 	}
@@ -190,8 +217,15 @@ func NewElectrumConnection(serverInfos []*config.ServerInfo, log *logrus.Entry,
 				WithError(err).
 				WithField("server", server.String()).
 				Errorf("backend disconnected")
+			// Failover errors are only used to switch away from an avoided server. Other errors
+			// are failures of the connection, e.g. failing to read from the socket.
+			failoverError := new(failover.FailoverError)
+			if !errors.Is(err, failover.ErrClosed) && !errors.As(err, &failoverError) {
+				health.recordResult(slices.Index(servers, server), 0, err)
+			}
 		},
 		OnRetry: func(err error) {
+			health.recordDisconnect()
 			log.WithError(err).Errorf("All backends failed, retrying after %v", retryTimeout)
 			if err != nil {
 				fclient.setConnectionError(err)
@@ -200,10 +234,60 @@ func NewElectrumConnection(serverInfos []*config.ServerInfo, log *logrus.Entry,
 				fclient.setConnectionError(errors.New("Servers unreachable"))
 			}
 		},
-	})
+	}, health)
+	if len(serverInfos) > 1 {
+		// With a single server, there is no choice of server and no other chain tip to compare to.
+		go fclient.monitorServers(initialProbeDelay, probeInterval, func(index int) (*serverProbe, error) {
+			return probeServer(serverInfos[index], dialer)
+		})
+	}
 	return fclient
 }
 
+// probeServer connects to the server to check its health. It returns the version of the server,
+// the response time of the request for the chain tip, and the height of the chain tip.
+func probeServer(serverInfo *config.ServerInfo, dialer proxy.Dialer) (*serverProbe, error) {
+	c, err := electrum.Connect(&electrum.Options{
+		SoftwareVersion: softwareVersion,
+		MethodTimeout:   30 * time.Second,
+		PingInterval:    -1,
+		Dial: func() (net.Conn, error) {
+			return establishConnection(serverInfo, dialer)
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+	type tipResult struct {
+		height  int
+		latency time.Duration
+		err     error
+	}
+	// Buffered, as the server could send a notification for a new block right after the response.
+	results := make(chan tipResult, 1)
+	start := time.Now()
+	c.HeadersSubscribe(context.Background(), func(header *types.Header, err error) {
+		result := tipResult{latency: time.Since(start), err: err}
+		if err == nil {
+			result.height = header.Height
+		}
+		select {
+		case results <- result:
+		default:
+		}
+	})
+	result := <-results
+	if result.err != nil {
+		return nil, result.err
+	}
+	return &serverProbe{
+		serverVersion: c.ServerVersion().String(),
+		latency:       result.latency,
+		tipHeight:     result.height,
+	}, nil
+}
+
 // DownloadCert downloads the first element of the remote certificate chain.
 func DownloadCert(server string, dialer proxy.Dialer) (string, error) {
 	// hostname is used as server name in SNI client hello during the handshake.
@@ -16,6 +16,7 @@ package electrum
 
 import (
 	"sync"
+	"time"
 
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/blockchain"
 	"github.com/BitBoxSwiss/block-client-go/electrum/types"
@@ -30,6 +31,11 @@ import (
 // servers are tried again. Subscriptions are automatically re-subscribed on new servers.
 type failoverClient struct {
 	failover *failover.Failover[*client]
+	// health collects the health metrics of the servers. Unhealthy servers are avoided.
+	health *serverHealthTracker
+
+	quit      chan struct{}
+	closeOnce sync.Once
 
 	connectionError                   error
 	onConnectionErrorChangedCallbacks []func(error)
@@ -38,13 +44,89 @@ type failoverClient struct {
 }
 
 // newFailoverClient creates a new failover client.
-func newFailoverClient(opts *failover.Options[*client]) *failoverClient {
+func newFailoverClient(opts *failover.Options[*client], health *serverHealthTracker) *failoverClient {
 	return &failoverClient{
 		failover:                          failover.New[*client](opts),
+		health:                            health,
+		quit:                              make(chan struct{}),
 		onConnectionErrorChangedCallbacks: []func(error){},
 	}
 }
 
+// call is like failover.Call, and records the response time and transport errors in the health
+// metrics of the server. An error response of the server counts as a response, see
+// isTransportError().
+func call[R any](f *failoverClient, do func(c *client) (R, error)) (R, error) {
+	return failover.Call(f.failover, func(c *client) (R, error) {
+		start := time.Now()
+		result, err := do(c)
+		var transportErr error
+		if isTransportError(err) {
+			transportErr = err
+		}
+		f.health.recordResult(c.serverIndex, time.Since(start), transportErr)
+		if transportErr != nil {
+			f.avoidUnhealthyServer()
+		}
+		return result, err
+	})
+}
+
+// avoidUnhealthyServer fails over to another server if the server currently in use is avoided and
+// there is a healthier one.
+func (f *failoverClient) avoidUnhealthyServer() {
+	if !f.health.shouldSwitch() {
+		return
+	}
+	go func() {
+		_, _ = failover.Call(f.failover, func(c *client) (struct{}, error) {
+			if f.health.shouldSkip(c.serverIndex) {
+				return struct{}{}, failover.NewFailoverError(errServerAvoided)
+			}
+			return struct{}{}, nil
+		})
+	}()
+}
+
+// monitorServers checks the servers which are not in use from time to time, so that failing or
+// lagging servers are not switched to, and so that avoided servers can recover. It returns when
+// the client is closed.
+func (f *failoverClient) monitorServers(
+	initialDelay time.Duration,
+	interval time.Duration,
+	probe func(index int) (*serverProbe, error),
+) {
+	delay := initialDelay
+	for {
+		select {
+		case <-f.quit:
+			return
+		case <-time.After(delay):
+		}
+		delay = interval
+		for index := range f.health.servers {
+			if f.isClosed() {
+				return
+			}
+			if f.health.isConnected(index) {
+				continue
+			}
+			result, err := probe(index)
+			f.health.recordProbe(index, result, err)
+		}
+		f.avoidUnhealthyServer()
+	}
+}
+
+func (f *failoverClient) isClosed() bool {
+	select {
+	case <-f.quit:
+		return true
+	default:
+		return false
+	}
+}
+
 func (f *failoverClient) setConnectionError(err error) {
 	f.mu.Lock()
 	defer f.mu.Unlock()
@@ -69,19 +151,25 @@ func (f *failoverClient) RegisterOnConnectionErrorChangedEvent(callback func(err
 }
 
 func (f *failoverClient) EstimateFee(number int) (btcutil.Amount, error) {
-	return failover.Call(f.failover, func(c *client) (btcutil.Amount, error) {
+	return call(f, func(c *client) (btcutil.Amount, error) {
 		return c.EstimateFee(number)
 	})
 }
 
+func (f *failoverClient) MempoolFeeHistogram() (blockchain.FeeHistogram, error) {
+	return call(f, func(c *client) (blockchain.FeeHistogram, error) {
+		return c.MempoolFeeHistogram()
+	})
+}
+
 func (f *failoverClient) GetMerkle(txHash chainhash.Hash, height int) (*blockchain.GetMerkleResult, error) {
-	return failover.Call(f.failover, func(c *client) (*blockchain.GetMerkleResult, error) {
+	return call(f, func(c *client) (*blockchain.GetMerkleResult, error) {
 		return c.GetMerkle(txHash, height)
 	})
 }
 
 func (f *failoverClient) Headers(startHeight int, count int) (*blockchain.HeadersResult, error) {
-	return failover.Call(f.failover, func(c *client) (*blockchain.HeadersResult, error) {
+	return call(f, func(c *client) (*blockchain.HeadersResult, error) {
 		return c.Headers(startHeight, count)
 	})
 }
@@ -91,6 +179,10 @@ func (f *failoverClient) HeadersSubscribe(result func(header *types.Header)) {
 		f.failover,
 		func(c *client, result func(*types.Header, error)) {
 			c.HeadersSubscribe(func(header *types.Header, err error) {
+				if err == nil {
+					f.health.recordTip(c.serverIndex, header.Height)
+					f.avoidUnhealthyServer()
+				}
 				result(header, err)
 			})
 		},
@@ -104,13 +196,13 @@ func (f *failoverClient) HeadersSubscribe(result func(header *types.Header)) {
 }
 
 func (f *failoverClient) RelayFee() (btcutil.Amount, error) {
-	return failover.Call(f.failover, func(c *client) (btcutil.Amount, error) {
+	return call(f, func(c *client) (btcutil.Amount, error) {
 		return c.RelayFee()
 	})
 }
 
 func (f *failoverClient) ScriptHashGetHistory(scriptHashHex blockchain.ScriptHashHex) (blockchain.TxHistory, error) {
-	return failover.Call(f.failover, func(c *client) (blockchain.TxHistory, error) {
+	return call(f, func(c *client) (blockchain.TxHistory, error) {
 		return c.ScriptHashGetHistory(scriptHashHex)
 	})
 }
@@ -144,6 +236,8 @@ func (f *failoverClient) ScriptHashSubscribe(
 }
 
 func (f *failoverClient) TransactionBroadcast(transaction *wire.MsgTx) error {
+	// A rejected transaction says nothing about the health of the server, so the result is not
+	// recorded.
 	_, err := failover.Call(f.failover, func(c *client) (struct{}, error) {
 		return struct{}{}, c.TransactionBroadcast(transaction)
 	})
@@ -151,7 +245,7 @@ func (f *failoverClient) TransactionBroadcast(transaction *wire.MsgTx) error {
 }
 
 func (f *failoverClient) TransactionGet(txHash chainhash.Hash) (*wire.MsgTx, error) {
-	return failover.Call(f.failover, func(c *client) (*wire.MsgTx, error) {
+	return call(f, func(c *client) (*wire.MsgTx, error) {
 		return c.TransactionGet(txHash)
 	})
 }
@@ -160,6 +254,18 @@ func (f *failoverClient) ManualReconnect() {
 	f.failover.ManualReconnect()
 }
 
+// ServerStatus implements blockchain.Interface.
+func (f *failoverClient) ServerStatus() []*blockchain.ServerStatus {
+	return f.health.status()
+}
+
+// RecordVerifiedTip implements blockchain.Interface.
+func (f *failoverClient) RecordVerifiedTip(height int) {
+	f.health.recordVerifiedTip(height)
+	f.avoidUnhealthyServer()
+}
+
 func (f *failoverClient) Close() {
+	f.closeOnce.Do(func() { close(f.quit) })
 	f.failover.Close()
 }
@@ -0,0 +1,144 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package electrum
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"sync"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/BitBoxSwiss/block-client-go/jsonrpc/types"
+)
+
+type rawRPCResult struct {
+	response *types.Response
+	err      error
+}
+
+// rawRPCConn wraps the connection of an Electrum client, so that RPC methods which the Electrum
+// client library does not support can be called on the same connection. The requests use negative
+// IDs, which never clash with the IDs of the library. The library ignores responses to requests it
+// did not make, and the wrapper passes the received data through unchanged.
+type rawRPCConn struct {
+	net.Conn
+
+	// writeMu makes sure the messages of the library and ours are not interleaved.
+	writeMu sync.Mutex
+
+	// mu covers the fields below.
+	mu      sync.Mutex
+	lastID  int
+	pending map[int]chan rawRPCResult
+	// received holds the received data after the last newline while there are pending requests.
+	// When the first request starts, it can begin in the middle of a message, which is skipped as
+	// it can't be parsed.
+	received []byte
+}
+
+func newRawRPCConn(conn net.Conn) *rawRPCConn {
+	return &rawRPCConn{
+		Conn:    conn,
+		pending: map[int]chan rawRPCResult{},
+	}
+}
+
+// Write implements net.Conn.
+func (conn *rawRPCConn) Write(data []byte) (int, error) {
+	conn.writeMu.Lock()
+	defer conn.writeMu.Unlock()
+	return conn.Conn.Write(data)
+}
+
+// Read implements net.Conn.
+func (conn *rawRPCConn) Read(data []byte) (int, error) {
+	n, err := conn.Conn.Read(data)
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+	if len(conn.pending) == 0 {
+		return n, err
+	}
+	if err != nil {
+		for _, result := range conn.pending {
+			result <- rawRPCResult{err: errp.WithMessage(err, "failed to read from socket")}
+		}
+		clear(conn.pending)
+		conn.received = nil
+		return n, err
+	}
+	conn.received = append(conn.received, data[:n]...)
+	for {
+		index := bytes.IndexByte(conn.received, '\n')
+		if index < 0 {
+			break
+		}
+		line := conn.received[:index]
+		conn.received = conn.received[index+1:]
+		var response types.Response
+		if json.Unmarshal(line, &response) != nil || response.ID == nil {
+			continue
+		}
+		if result, ok := conn.pending[*response.ID]; ok {
+			delete(conn.pending, *response.ID)
+			result <- rawRPCResult{response: &response}
+		}
+	}
+	return n, err
+}
+
+// method calls the RPC method and unmarshals the result into `result`.
+func (conn *rawRPCConn) method(
+	ctx context.Context, result interface{}, method string, params ...interface{}) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+	// Buffered, as the response can arrive after the request was abandoned.
+	resultCh := make(chan rawRPCResult, 1)
+	conn.mu.Lock()
+	conn.lastID--
+	id := conn.lastID
+	conn.pending[id] = resultCh
+	conn.mu.Unlock()
+	defer func() {
+		conn.mu.Lock()
+		defer conn.mu.Unlock()
+		delete(conn.pending, id)
+		if len(conn.pending) == 0 {
+			conn.received = nil
+		}
+	}()
+
+	request, err := json.Marshal(&types.Request{ID: id, Method: method, Params: params})
+	if err != nil {
+		return errp.WithStack(err)
+	}
+	if _, err := conn.Write(append(request, '\n')); err != nil {
+		return errp.WithMessage(err, "failed to write to socket")
+	}
+	select {
+	case <-ctx.Done():
+		return errp.WithStack(ctx.Err())
+	case rawResult := <-resultCh:
+		if rawResult.err != nil {
+			return rawResult.err
+		}
+		if err := rawResult.response.ParseError(); err != nil {
+			return errp.WithStack(err)
+		}
+		return errp.WithStack(json.Unmarshal(rawResult.response.Result, result))
+	}
+}
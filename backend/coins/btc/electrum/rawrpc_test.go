@@ -0,0 +1,102 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package electrum
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/BitBoxSwiss/block-client-go/jsonrpc/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRawRPCConn(t *testing.T) {
+	clientEnd, serverEnd := net.Pipe()
+	defer serverEnd.Close()
+	conn := newRawRPCConn(clientEnd)
+	defer conn.Close()
+
+	// Reads all data like the Electrum client library does.
+	libraryLines := make(chan string, 10)
+	go func() {
+		reader := bufio.NewReader(conn)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				close(libraryLines)
+				return
+			}
+			libraryLines <- line
+		}
+	}()
+
+	go func() {
+		reader := bufio.NewReader(serverEnd)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if err != nil {
+				return
+			}
+			var request types.Request
+			if json.Unmarshal(line, &request) != nil || request.Method == "server.ping" {
+				// No response.
+				return
+			}
+			// A response to the library arrives in between.
+			_, _ = serverEnd.Write([]byte(`{"jsonrpc": "2.0", "id": 1, "result": null}` + "\n"))
+			var response []byte
+			switch request.Method {
+			case "mempool.get_fee_histogram":
+				response, _ = json.Marshal(map[string]interface{}{
+					"jsonrpc": "2.0", "id": request.ID, "result": [][2]float64{{12.5, 1000}, {2, 5000}},
+				})
+			default:
+				response, _ = json.Marshal(map[string]interface{}{
+					"jsonrpc": "2.0", "id": request.ID, "error": map[string]interface{}{
+						"code": -32601, "message": "unknown method",
+					},
+				})
+			}
+			// The response is received in several parts.
+			_, _ = serverEnd.Write(response[:10])
+			_, _ = serverEnd.Write(append(response[10:], '\n'))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	var histogram [][2]float64
+	require.NoError(t, conn.method(ctx, &histogram, "mempool.get_fee_histogram"))
+	require.Equal(t, [][2]float64{{12.5, 1000}, {2, 5000}}, histogram)
+
+	require.EqualError(t, conn.method(ctx, &histogram, "unknown.method"), "unknown method")
+
+	// The library receives all data.
+	require.Equal(t, `{"jsonrpc": "2.0", "id": 1, "result": null}`+"\n", <-libraryLines)
+	require.Contains(t, <-libraryLines, `"id":-1`)
+	require.Equal(t, `{"jsonrpc": "2.0", "id": 1, "result": null}`+"\n", <-libraryLines)
+	require.Contains(t, <-libraryLines, `"id":-2`)
+
+	// Pending requests fail if the connection is closed.
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		_ = serverEnd.Close()
+	}()
+	require.Error(t, conn.method(context.Background(), &histogram, "server.ping"))
+}
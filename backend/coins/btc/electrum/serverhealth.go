@@ -0,0 +1,303 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package electrum
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/blockchain"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/config"
+)
+
+const (
+	// maxTipLag is the number of blocks a server can be behind the verified chain tip without being
+	// penalized. Some lag is expected, as new blocks take a moment to propagate.
+	maxTipLag = 2
+	// tipLagPenalty is subtracted from the score of a server lagging more than maxTipLag blocks
+	// behind. It is large enough to avoid the server on its own.
+	tipLagPenalty = 60
+	// highErrorRate is the error rate from which errors are reported as an issue of the server.
+	highErrorRate = 0.25
+	// slowLatency is the response time from which latency is reported as an issue of the server.
+	slowLatency = time.Second
+	// maxLatencyPenalty is the maximum subtracted from the score for a slow response time. Latency
+	// alone does not make a server avoided.
+	maxLatencyPenalty = 20
+	// minScore is the score below which a server is avoided.
+	minScore = 50
+	// healthSmoothing is the weight of the latest request in the moving averages of the response
+	// time and error rate.
+	healthSmoothing = 0.2
+)
+
+// errServerAvoided is returned when connecting to a server which is avoided because of its poor
+// health, and triggers a failover from an avoided server which is currently in use.
+var errServerAvoided = errors.New("server avoided due to poor health")
+
+// isTransportError returns true if the error is a failure of the connection to the server or a
+// timeout. Other errors, like an error response of the server for an unknown transaction, say
+// nothing about the health of the server.
+func isTransportError(err error) bool {
+	var netErr net.Error
+	// Pending requests are canceled when the connection is closed.
+	return errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, context.Canceled) ||
+		errors.Is(err, io.EOF) ||
+		errors.As(err, &netErr)
+}
+
+// serverProbe is the result of checking a server not currently in use, see probeServer().
+type serverProbe struct {
+	serverVersion string
+	latency       time.Duration
+	tipHeight     int
+}
+
+// serverHealth are the health metrics of one server.
+type serverHealth struct {
+	server          string
+	serverVersion   string
+	protocolVersion string
+	unreachable     bool
+	latency         time.Duration
+	tipHeight       int
+	tipLag          int
+	requests        int
+	errors          int
+	errorRate       float64
+	lastError       error
+}
+
+func (health *serverHealth) setServerVersion(serverVersion string) {
+	// The Electrum client formats the version as `software;protocol`.
+	health.serverVersion, health.protocolVersion, _ = strings.Cut(serverVersion, ";")
+}
+
+func (health *serverHealth) recordResult(latency time.Duration, err error) {
+	health.requests++
+	if err != nil {
+		health.errors++
+		health.lastError = err
+		health.errorRate += healthSmoothing * (1 - health.errorRate)
+		return
+	}
+	health.errorRate -= healthSmoothing * health.errorRate
+	if health.latency == 0 {
+		health.latency = latency
+	} else {
+		health.latency += time.Duration(healthSmoothing * float64(latency-health.latency))
+	}
+}
+
+// score returns the health score of the server between 0 and 100, and the issues which reduce it.
+func (health *serverHealth) score() (int, []blockchain.ServerIssue) {
+	issues := []blockchain.ServerIssue{}
+	if health.unreachable {
+		return 0, append(issues, blockchain.ServerIssueUnreachable)
+	}
+	score := 100
+	if health.tipLag > maxTipLag {
+		score -= tipLagPenalty
+		issues = append(issues, blockchain.ServerIssueTipLag)
+	}
+	score -= int(math.Round(health.errorRate * 100))
+	if health.errorRate >= highErrorRate {
+		issues = append(issues, blockchain.ServerIssueErrors)
+	}
+	score -= min(int(health.latency/(100*time.Millisecond)), maxLatencyPenalty)
+	if health.latency >= slowLatency {
+		issues = append(issues, blockchain.ServerIssueLatency)
+	}
+	return max(score, 0), issues
+}
+
+func (health *serverHealth) avoided() bool {
+	score, _ := health.score()
+	return score < minScore
+}
+
+// serverHealthTracker collects the health metrics of the servers of a failover client, and decides
+// which servers to avoid. A server is avoided if it is unreachable, lagging behind the chain tip
+// verified by the headers, or responding with many errors. Avoided servers are skipped when
+// connecting as long as there is a server which is not avoided.
+type serverHealthTracker struct {
+	// servers has the same order as the servers of the failover client.
+	servers []*serverHealth
+	// connected is the index of the server currently in use, -1 if there is none.
+	connected int
+	// verifiedTipHeight is the height of the chain tip verified by the headers (proof of work). The
+	// tips reported by the servers are not trusted, as a server could claim a high tip to make all
+	// other servers look lagging.
+	verifiedTipHeight int
+	// mu covers all fields of the tracker and of its servers.
+	mu sync.RWMutex
+}
+
+func newServerHealthTracker(serverInfos []*config.ServerInfo) *serverHealthTracker {
+	servers := make([]*serverHealth, len(serverInfos))
+	for index, serverInfo := range serverInfos {
+		servers[index] = &serverHealth{server: serverInfo.Server}
+	}
+	return &serverHealthTracker{
+		servers:   servers,
+		connected: -1,
+	}
+}
+
+// recordConnect records a successful connection to the server, which is now the server in use.
+func (tracker *serverHealthTracker) recordConnect(index int, serverVersion string) {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	health := tracker.servers[index]
+	health.unreachable = false
+	health.setServerVersion(serverVersion)
+	tracker.connected = index
+}
+
+// recordConnectError records a failed attempt to connect to the server.
+func (tracker *serverHealthTracker) recordConnectError(index int, err error) {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	health := tracker.servers[index]
+	health.unreachable = true
+	health.recordResult(0, err)
+}
+
+// recordDisconnect records that no server is in use.
+func (tracker *serverHealthTracker) recordDisconnect() {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	tracker.connected = -1
+}
+
+// recordResult records the response time and error of a request to the server.
+func (tracker *serverHealthTracker) recordResult(index int, latency time.Duration, err error) {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	tracker.servers[index].recordResult(latency, err)
+}
+
+// recordTip records the chain tip reported by the server.
+func (tracker *serverHealthTracker) recordTip(index int, height int) {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	health := tracker.servers[index]
+	health.tipHeight = height
+	health.tipLag = max(tracker.verifiedTipHeight-height, 0)
+}
+
+// recordVerifiedTip records the chain tip verified by the headers.
+func (tracker *serverHealthTracker) recordVerifiedTip(height int) {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	tracker.verifiedTipHeight = height
+	// The server in use notifies us of each new block, so its lag is always up to date. The other
+	// servers are only checked from time to time, so their lag is the one of their last check.
+	if tracker.connected >= 0 {
+		connected := tracker.servers[tracker.connected]
+		if connected.tipHeight > 0 {
+			connected.tipLag = max(height-connected.tipHeight, 0)
+		}
+	}
+}
+
+// recordProbe records the result of checking a server which is not currently in use.
+func (tracker *serverHealthTracker) recordProbe(index int, probe *serverProbe, err error) {
+	if err != nil {
+		tracker.recordConnectError(index, err)
+		return
+	}
+	func() {
+		tracker.mu.Lock()
+		defer tracker.mu.Unlock()
+		health := tracker.servers[index]
+		health.unreachable = false
+		health.setServerVersion(probe.serverVersion)
+		health.recordResult(probe.latency, nil)
+	}()
+	tracker.recordTip(index, probe.tipHeight)
+}
+
+// isConnected returns true if the server is the one currently in use.
+func (tracker *serverHealthTracker) isConnected(index int) bool {
+	tracker.mu.RLock()
+	defer tracker.mu.RUnlock()
+	return tracker.connected == index
+}
+
+// skip returns true if the server is avoided and there is another server which is not. `mu` must
+// be held.
+func (tracker *serverHealthTracker) skip(index int) bool {
+	if !tracker.servers[index].avoided() {
+		return false
+	}
+	for otherIndex, other := range tracker.servers {
+		if otherIndex != index && !other.avoided() {
+			return true
+		}
+	}
+	return false
+}
+
+// shouldSkip returns true if the server should not be connected to, as there is a healthier one.
+func (tracker *serverHealthTracker) shouldSkip(index int) bool {
+	tracker.mu.RLock()
+	defer tracker.mu.RUnlock()
+	return tracker.skip(index)
+}
+
+// shouldSwitch returns true if the server currently in use should be replaced by a healthier one.
+func (tracker *serverHealthTracker) shouldSwitch() bool {
+	tracker.mu.RLock()
+	defer tracker.mu.RUnlock()
+	return tracker.connected >= 0 && tracker.skip(tracker.connected)
+}
+
+// status returns the health status of all servers.
+func (tracker *serverHealthTracker) status() []*blockchain.ServerStatus {
+	tracker.mu.RLock()
+	defer tracker.mu.RUnlock()
+	result := make([]*blockchain.ServerStatus, len(tracker.servers))
+	for index, health := range tracker.servers {
+		score, issues := health.score()
+		var lastError string
+		if health.lastError != nil {
+			lastError = health.lastError.Error()
+		}
+		result[index] = &blockchain.ServerStatus{
+			Server:          health.server,
+			Connected:       tracker.connected == index,
+			ServerVersion:   health.serverVersion,
+			ProtocolVersion: health.protocolVersion,
+			Latency:         health.latency,
+			TipHeight:       health.tipHeight,
+			TipLag:          health.tipLag,
+			Requests:        health.requests,
+			Errors:          health.errors,
+			LastError:       lastError,
+			Score:           score,
+			Issues:          issues,
+			Avoided:         score < minScore,
+		}
+	}
+	return result
+}
@@ -0,0 +1,272 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package electrum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/blockchain"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/config"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/BitBoxSwiss/block-client-go/failover"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerHealthScore(t *testing.T) {
+	tt := []struct {
+		name           string
+		health         serverHealth
+		expectedScore  int
+		expectedIssues []blockchain.ServerIssue
+	}{
+		{
+			name:           "healthy",
+			health:         serverHealth{latency: 50 * time.Millisecond, tipLag: maxTipLag},
+			expectedScore:  100,
+			expectedIssues: []blockchain.ServerIssue{},
+		},
+		{
+			name:           "unreachable",
+			health:         serverHealth{unreachable: true},
+			expectedScore:  0,
+			expectedIssues: []blockchain.ServerIssue{blockchain.ServerIssueUnreachable},
+		},
+		{
+			name:           "lagging",
+			health:         serverHealth{tipLag: maxTipLag + 1},
+			expectedScore:  40,
+			expectedIssues: []blockchain.ServerIssue{blockchain.ServerIssueTipLag},
+		},
+		{
+			name:           "errors",
+			health:         serverHealth{errorRate: 0.3},
+			expectedScore:  70,
+			expectedIssues: []blockchain.ServerIssue{blockchain.ServerIssueErrors},
+		},
+		{
+			name:           "slow",
+			health:         serverHealth{latency: 3 * time.Second},
+			expectedScore:  80,
+			expectedIssues: []blockchain.ServerIssue{blockchain.ServerIssueLatency},
+		},
+		{
+			name:          "slow with errors",
+			health:        serverHealth{latency: 1500 * time.Millisecond, errorRate: 0.4},
+			expectedScore: 45,
+			expectedIssues: []blockchain.ServerIssue{
+				blockchain.ServerIssueErrors, blockchain.ServerIssueLatency,
+			},
+		},
+	}
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			score, issues := test.health.score()
+			require.Equal(t, test.expectedScore, score)
+			require.Equal(t, test.expectedIssues, issues)
+			require.Equal(t, test.expectedScore < minScore, test.health.avoided())
+		})
+	}
+}
+
+func TestServerHealthRecordResult(t *testing.T) {
+	health := &serverHealth{}
+	health.recordResult(10*time.Millisecond, nil)
+	require.Equal(t, 10*time.Millisecond, health.latency)
+	health.recordResult(60*time.Millisecond, nil)
+	require.Equal(t, 20*time.Millisecond, health.latency)
+
+	err := errors.New("timeout")
+	health.recordResult(0, err)
+	require.InDelta(t, 0.2, health.errorRate, 1e-9)
+	require.Equal(t, 20*time.Millisecond, health.latency)
+	health.recordResult(0, err)
+	health.recordResult(0, err)
+	require.InDelta(t, 0.488, health.errorRate, 1e-9)
+	require.False(t, health.avoided())
+	health.recordResult(0, err)
+	require.True(t, health.avoided())
+	require.Equal(t, 6, health.requests)
+	require.Equal(t, 4, health.errors)
+	require.Equal(t, err, health.lastError)
+
+	// The error rate recovers with successful requests.
+	health.recordResult(20*time.Millisecond, nil)
+	require.InDelta(t, 0.47232, health.errorRate, 1e-9)
+	require.False(t, health.avoided())
+}
+
+func TestIsTransportError(t *testing.T) {
+	require.False(t, isTransportError(nil))
+	require.True(t, isTransportError(context.DeadlineExceeded))
+	require.True(t, isTransportError(errp.WithStack(context.Canceled)))
+	require.True(t, isTransportError(errp.WithMessage(io.EOF, "failed to read from socket")))
+	require.True(t, isTransportError(fmt.Errorf("Failed to write to socket: %w",
+		&net.OpError{Op: "write", Net: "tcp", Err: net.ErrClosed})))
+	// Error responses of the server.
+	require.False(t, isTransportError(errors.New("missing transaction")))
+	require.False(t, isTransportError(errp.New("unknown method")))
+}
+
+func TestServerHealthTracker(t *testing.T) {
+	tracker := newServerHealthTracker([]*config.ServerInfo{
+		{Server: "one.example.com:50002"},
+		{Server: "two.example.com:50002"},
+		{Server: "three.example.com:50002"},
+	})
+	for index := range tracker.servers {
+		require.False(t, tracker.shouldSkip(index))
+	}
+	require.False(t, tracker.shouldSwitch())
+
+	tracker.recordConnect(0, "ElectrumX 1.16.0;1.4")
+	tracker.recordTip(0, 100)
+	require.True(t, tracker.isConnected(0))
+	require.False(t, tracker.shouldSwitch())
+
+	// The tip reported by another server is not verified, so the server in use is not lagging.
+	tracker.recordProbe(1, &serverProbe{
+		serverVersion: "Fulcrum 1.9.0;1.4.5",
+		latency:       300 * time.Millisecond,
+		tipHeight:     1000,
+	}, nil)
+	require.False(t, tracker.shouldSwitch())
+	require.Equal(t, 0, tracker.status()[0].TipLag)
+	require.Equal(t, 0, tracker.status()[1].TipLag)
+
+	// The headers verified a tip a few blocks ahead, e.g. in a previous session, so the server in
+	// use is lagging.
+	tracker.recordVerifiedTip(100 + maxTipLag + 1)
+	require.True(t, tracker.shouldSwitch())
+	require.True(t, tracker.shouldSkip(0))
+	require.False(t, tracker.shouldSkip(1))
+
+	tracker.recordProbe(2, nil, errors.New("connection refused"))
+	require.True(t, tracker.shouldSkip(2))
+
+	status := tracker.status()
+	require.Equal(t, []*blockchain.ServerStatus{
+		{
+			Server:          "one.example.com:50002",
+			Connected:       true,
+			ServerVersion:   "ElectrumX 1.16.0",
+			ProtocolVersion: "1.4",
+			TipHeight:       100,
+			TipLag:          maxTipLag + 1,
+			Score:           40,
+			Issues:          []blockchain.ServerIssue{blockchain.ServerIssueTipLag},
+			Avoided:         true,
+		},
+		{
+			Server:          "two.example.com:50002",
+			ServerVersion:   "Fulcrum 1.9.0",
+			ProtocolVersion: "1.4.5",
+			Latency:         300 * time.Millisecond,
+			TipHeight:       1000,
+			Requests:        1,
+			Score:           97,
+			Issues:          []blockchain.ServerIssue{},
+		},
+		{
+			Server:    "three.example.com:50002",
+			Requests:  1,
+			Errors:    1,
+			LastError: "connection refused",
+			Score:     0,
+			Issues:    []blockchain.ServerIssue{blockchain.ServerIssueUnreachable},
+			Avoided:   true,
+		},
+	}, status)
+
+	// The server in use catches up.
+	tracker.recordTip(0, 100+maxTipLag+1)
+	require.False(t, tracker.shouldSwitch())
+	require.Equal(t, 0, tracker.status()[0].TipLag)
+
+	// The other reachable server falls behind when it is checked again.
+	tracker.recordVerifiedTip(110)
+	tracker.recordTip(0, 110)
+	tracker.recordProbe(1, &serverProbe{tipHeight: 105}, nil)
+	require.Equal(t, 5, tracker.status()[1].TipLag)
+	require.True(t, tracker.shouldSkip(1))
+
+	// If all servers are avoided, none are skipped.
+	for range 4 {
+		tracker.recordResult(0, 0, errors.New("timeout"))
+	}
+	require.True(t, tracker.status()[0].Avoided)
+	for index := range tracker.servers {
+		require.False(t, tracker.shouldSkip(index))
+	}
+
+	tracker.recordDisconnect()
+	require.False(t, tracker.isConnected(0))
+	require.False(t, tracker.shouldSwitch())
+}
+
+func TestMonitorServers(t *testing.T) {
+	health := newServerHealthTracker([]*config.ServerInfo{
+		{Server: "one.example.com:50002"},
+		{Server: "two.example.com:50002"},
+		{Server: "three.example.com:50002"},
+	})
+	health.recordConnect(1, "ElectrumX 1.16.0;1.4")
+	health.recordVerifiedTip(101)
+	health.recordTip(1, 101)
+	fclient := newFailoverClient(&failover.Options[*client]{}, health)
+
+	var lock sync.Mutex
+	probed := []int{}
+	getProbed := func() []int {
+		lock.Lock()
+		defer lock.Unlock()
+		return append([]int{}, probed...)
+	}
+	done := make(chan struct{})
+	go func() {
+		fclient.monitorServers(0, time.Hour, func(index int) (*serverProbe, error) {
+			lock.Lock()
+			defer lock.Unlock()
+			probed = append(probed, index)
+			return &serverProbe{tipHeight: 100 + index}, nil
+		})
+		close(done)
+	}()
+
+	// The server in use is not probed.
+	require.Eventually(t,
+		func() bool { return len(getProbed()) == 2 },
+		time.Second, 10*time.Millisecond)
+	require.Equal(t, []int{0, 2}, getProbed())
+	status := health.status()
+	require.Equal(t, 100, status[0].TipHeight)
+	require.Equal(t, 1, status[0].TipLag)
+	require.Equal(t, 0, status[1].TipLag)
+	require.Equal(t, 102, status[2].TipHeight)
+	require.Equal(t, 0, status[2].TipLag)
+
+	fclient.Close()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("monitorServers did not return after closing the client")
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package btc
+
+import (
+	"math"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/blockchain"
+	"github.com/btcsuite/btcd/btcutil"
+)
+
+// blockVSize is the maximum virtual size of a block (4M weight units).
+const blockVSize = 1000000
+
+// FeeEstimateConfidence indicates how reliable a fee estimate is.
+type FeeEstimateConfidence string
+
+const (
+	// FeeEstimateConfidenceHigh is used for estimates based on the current mempool if the mempool
+	// contains enough transactions to fill the targeted blocks, i.e. the fee rate is determined by
+	// the transactions competing for block space.
+	FeeEstimateConfidenceHigh FeeEstimateConfidence = "high"
+	// FeeEstimateConfidenceMedium is used if the mempool does not fill the targeted blocks, so the
+	// estimate depends on the transactions arriving in the meantime, or if the estimate is based on
+	// past blocks (`blockchain.estimatefee`).
+	FeeEstimateConfidenceMedium FeeEstimateConfidence = "medium"
+	// FeeEstimateConfidenceLow is used if no estimate was available and the minimum relay fee rate
+	// is used instead.
+	FeeEstimateConfidenceLow FeeEstimateConfidence = "low"
+)
+
+// feeRateFromHistogram estimates the fee rate needed for a transaction to be confirmed within
+// `blocks` blocks, assuming that miners fill blocks with the highest paying mempool transactions
+// and that no other transactions arrive. The fee rate of the histogram entry at which the
+// transactions of the mempool fill the blocks is returned.
+//
+// If the mempool does not fill the blocks, false is returned, as any transaction paying at least
+// the minimum relay fee rate would currently be confirmed in time.
+func feeRateFromHistogram(histogram blockchain.FeeHistogram, blocks int) (btcutil.Amount, bool) {
+	if blocks < 1 {
+		blocks = 1
+	}
+	capacity := int64(blocks) * blockVSize
+	var vsize int64
+	for _, entry := range histogram {
+		vsize += entry.VSize
+		if vsize >= capacity {
+			return btcutil.Amount(math.Ceil(entry.FeeRate * 1000)), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,145 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package btc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/blockchain"
+	blockchainMocks "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/blockchain/mocks"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/stretchr/testify/require"
+)
+
+var testFeeHistogram = blockchain.FeeHistogram{
+	{FeeRate: 50.5, VSize: 400000},
+	{FeeRate: 20, VSize: 800000},
+	{FeeRate: 10, VSize: 1000000},
+	{FeeRate: 2, VSize: 1500000},
+}
+
+func TestFeeRateFromHistogram(t *testing.T) {
+	feeRate, ok := feeRateFromHistogram(testFeeHistogram, 1)
+	require.True(t, ok)
+	require.Equal(t, btcutil.Amount(20000), feeRate)
+
+	feeRate, ok = feeRateFromHistogram(testFeeHistogram, 2)
+	require.True(t, ok)
+	require.Equal(t, btcutil.Amount(10000), feeRate)
+
+	feeRate, ok = feeRateFromHistogram(testFeeHistogram, 3)
+	require.True(t, ok)
+	require.Equal(t, btcutil.Amount(2000), feeRate)
+
+	// The mempool does not fill four blocks.
+	_, ok = feeRateFromHistogram(testFeeHistogram, 4)
+	require.False(t, ok)
+
+	_, ok = feeRateFromHistogram(blockchain.FeeHistogram{}, 1)
+	require.False(t, ok)
+
+	// Fractional fee rates are rounded up.
+	feeRate, ok = feeRateFromHistogram(blockchain.FeeHistogram{{FeeRate: 1.0001, VSize: 2000000}}, 1)
+	require.True(t, ok)
+	require.Equal(t, btcutil.Amount(1001), feeRate)
+}
+
+func feeEstimationTestAccount(
+	t *testing.T,
+	histogram func() (blockchain.FeeHistogram, error),
+	estimateFee func(int) (btcutil.Amount, error),
+) *Account {
+	t.Helper()
+	account := mockAccount(t, nil)
+	account.coin.TstSetMakeBlockchain(func() blockchain.Interface {
+		return &blockchainMocks.BlockchainMock{
+			MockRegisterOnConnectionErrorChangedEvent: func(func(error)) {},
+			MockRelayFee: func() (btcutil.Amount, error) {
+				return 1000, nil
+			},
+			MockMempoolFeeHistogram: histogram,
+			MockEstimateFee:         estimateFee,
+		}
+	})
+	account.coin.blockchain = account.coin.makeBlockchain()
+	require.NoError(t, account.Initialize())
+	return account
+}
+
+func TestFeeTargetsFromHistogram(t *testing.T) {
+	account := feeEstimationTestAccount(t,
+		func() (blockchain.FeeHistogram, error) { return testFeeHistogram, nil },
+		func(int) (btcutil.Amount, error) { panic("unexpected call") },
+	)
+	defer account.Close()
+
+	feeTargets := account.feeTargets()
+	require.Len(t, feeTargets, 4)
+	expected := []struct {
+		code         accounts.FeeTargetCode
+		feeRatePerKb btcutil.Amount
+		confidence   FeeEstimateConfidence
+	}{
+		// The mempool does not fill 24, 12 or 6 blocks, so the min relay fee is enough.
+		{accounts.FeeTargetCodeEconomy, 1000, FeeEstimateConfidenceMedium},
+		{accounts.FeeTargetCodeLow, 1000, FeeEstimateConfidenceMedium},
+		{accounts.FeeTargetCodeNormal, 1000, FeeEstimateConfidenceMedium},
+		{accounts.FeeTargetCodeHigh, 10000, FeeEstimateConfidenceHigh},
+	}
+	for i, feeTarget := range feeTargets {
+		require.Equal(t, expected[i].code, feeTarget.code)
+		require.Equal(t, expected[i].feeRatePerKb, *feeTarget.feeRatePerKb)
+		require.Equal(t, expected[i].confidence, feeTarget.Confidence())
+	}
+	require.Equal(t, 24*10*time.Minute, feeTargets[0].ETA())
+	require.Equal(t, 20*time.Minute, feeTargets[3].ETA())
+
+	feeTarget, err := account.FeeEstimate(1)
+	require.NoError(t, err)
+	require.Equal(t, accounts.FeeTargetCodeCustom, feeTarget.Code())
+	require.Equal(t, "20 sat/vB", feeTarget.FormattedFeeRate())
+	require.Equal(t, FeeEstimateConfidenceHigh, feeTarget.Confidence())
+	require.Equal(t, 10*time.Minute, feeTarget.ETA())
+
+	_, err = account.FeeEstimate(0)
+	require.Error(t, err)
+}
+
+func TestFeeTargetsFallback(t *testing.T) {
+	// Without the fee histogram, the estimation of the server is used.
+	account := feeEstimationTestAccount(t,
+		func() (blockchain.FeeHistogram, error) { return nil, errp.New("unsupported") },
+		func(blocks int) (btcutil.Amount, error) {
+			if blocks == 2 {
+				return 0, errp.New("could not estimate fee")
+			}
+			return btcutil.Amount(5000 - blocks*100), nil
+		},
+	)
+	defer account.Close()
+
+	feeTargets := account.feeTargets()
+	require.Len(t, feeTargets, 4)
+	require.Equal(t, btcutil.Amount(2600), *feeTargets[0].feeRatePerKb)
+	require.Equal(t, FeeEstimateConfidenceMedium, feeTargets[0].Confidence())
+	require.Equal(t, btcutil.Amount(4400), *feeTargets[2].feeRatePerKb)
+	require.Equal(t, FeeEstimateConfidenceMedium, feeTargets[2].Confidence())
+	// If the server can't estimate the fee, the min relay fee is used.
+	require.Equal(t, btcutil.Amount(1000), *feeTargets[3].feeRatePerKb)
+	require.Equal(t, FeeEstimateConfidenceLow, feeTargets[3].Confidence())
+}
@@ -18,6 +18,7 @@ package btc
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
 	"github.com/btcsuite/btcd/btcutil"
@@ -33,6 +34,12 @@ type FeeTarget struct {
 
 	// FeeRatePerKb is the fee rate needed for this target. Can be nil until populated.
 	feeRatePerKb *btcutil.Amount
+
+	// confidence indicates how reliable the fee rate is. Populated with the fee rate.
+	confidence FeeEstimateConfidence
+
+	// eta is the expected time until confirmation, derived from the number of blocks.
+	eta time.Duration
 }
 
 // Code returns the btc fee target.
@@ -40,6 +47,21 @@ func (feeTarget *FeeTarget) Code() accounts.FeeTargetCode {
 	return feeTarget.code
 }
 
+// Blocks returns the target number of blocks in which the transaction should be confirmed.
+func (feeTarget *FeeTarget) Blocks() int {
+	return feeTarget.blocks
+}
+
+// Confidence returns how reliable the fee rate is.
+func (feeTarget *FeeTarget) Confidence() FeeEstimateConfidence {
+	return feeTarget.confidence
+}
+
+// ETA returns the expected time until a transaction paying the fee rate is confirmed.
+func (feeTarget *FeeTarget) ETA() time.Duration {
+	return feeTarget.eta
+}
+
 // FormattedFeeRate returns a string showing the fee rate.
 func (feeTarget *FeeTarget) FormattedFeeRate() string {
 	if feeTarget.feeRatePerKb == nil {
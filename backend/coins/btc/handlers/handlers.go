@@ -16,14 +16,18 @@
 package handlers
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"math/big"
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -32,6 +36,7 @@ import (
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/errors"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/types"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc"
+	btcTypes "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/types"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/util"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth"
@@ -62,13 +67,31 @@ func NewHandlers(
 	handleFunc("/status", handlers.getAccountStatus).Methods("GET")
 	handleFunc("/transactions", handlers.ensureAccountInitialized(handlers.getAccountTransactions)).Methods("GET")
 	handleFunc("/transaction", handlers.ensureAccountInitialized(handlers.getAccountTransaction)).Methods("GET")
+	handleFunc("/transaction-details", handlers.ensureAccountInitialized(handlers.getTransactionDetails)).Methods("GET")
 	handleFunc("/export", handlers.ensureAccountInitialized(handlers.postExportTransactions)).Methods("POST")
 	handleFunc("/info", handlers.ensureAccountInitialized(handlers.getAccountInfo)).Methods("GET")
+	handleFunc("/descriptors", handlers.ensureAccountInitialized(handlers.getDescriptors)).Methods("GET")
 	handleFunc("/utxos", handlers.ensureAccountInitialized(handlers.getUTXOs)).Methods("GET")
+	handleFunc("/utxos/frozen", handlers.ensureAccountInitialized(handlers.postSetUTXOFrozen)).Methods("POST")
+	handleFunc("/deep-rescan", handlers.ensureAccountInitialized(handlers.postDeepRescan)).Methods("POST")
 	handleFunc("/balance", handlers.ensureAccountInitialized(handlers.getAccountBalance)).Methods("GET")
 	handleFunc("/sendtx", handlers.ensureAccountInitialized(handlers.postAccountSendTx)).Methods("POST")
+	handleFunc("/scheduled-transactions", handlers.ensureAccountInitialized(handlers.getScheduledTransactions)).Methods("GET")
+	handleFunc("/scheduled-transactions/remove", handlers.ensureAccountInitialized(handlers.postRemoveScheduledTransaction)).Methods("POST")
+	handleFunc("/privacy-report", handlers.ensureAccountInitialized(handlers.getPrivacyReport)).Methods("GET")
+	handleFunc("/silent-payments", handlers.ensureAccountInitialized(handlers.getSilentPayments)).Methods("GET")
 	handleFunc("/fee-targets", handlers.ensureAccountInitialized(handlers.getAccountFeeTargets)).Methods("GET")
+	handleFunc("/fee-estimate", handlers.ensureAccountInitialized(handlers.getFeeEstimate)).Methods("GET")
 	handleFunc("/tx-proposal", handlers.ensureAccountInitialized(handlers.postAccountTxProposal)).Methods("POST")
+	handleFunc("/tx-proposal/psbt", handlers.ensureAccountInitialized(handlers.getTxProposalPSBT)).Methods("GET")
+	handleFunc("/sign-psbt", handlers.ensureAccountInitialized(handlers.postSignPSBT)).Methods("POST")
+	handleFunc("/send-psbt", handlers.ensureAccountInitialized(handlers.postSendPSBT)).Methods("POST")
+	handleFunc("/fee-bump-proposal", handlers.ensureAccountInitialized(handlers.postFeeBumpProposal)).Methods("POST")
+	handleFunc("/cancel-proposal", handlers.ensureAccountInitialized(handlers.postCancelProposal)).Methods("POST")
+	handleFunc("/cpfp-proposal", handlers.ensureAccountInitialized(handlers.postCPFPProposal)).Methods("POST")
+	handleFunc("/consolidation-proposal", handlers.ensureAccountInitialized(handlers.postConsolidationProposal)).Methods("POST")
+	handleFunc("/sweep-proposal", handlers.ensureAccountInitialized(handlers.postSweepProposal)).Methods("POST")
+	handleFunc("/sweep", handlers.ensureAccountInitialized(handlers.postSweep)).Methods("POST")
 	handleFunc("/receive-addresses", handlers.ensureAccountInitialized(handlers.getReceiveAddresses)).Methods("GET")
 	handleFunc("/verify-address", handlers.ensureAccountInitialized(handlers.postVerifyAddress)).Methods("POST")
 	handleFunc("/verify-extended-public-key", handlers.ensureAccountInitialized(handlers.postVerifyExtendedPublicKey)).Methods("POST")
@@ -111,10 +134,12 @@ type Transaction struct {
 	Note                     string                              `json:"note"`
 
 	// BTC specific fields.
-	VSize        int64                               `json:"vsize"`
-	Size         int64                               `json:"size"`
-	Weight       int64                               `json:"weight"`
-	FeeRatePerKb coin.FormattedAmountWithConversions `json:"feeRatePerKb"`
+	VSize          int64                               `json:"vsize"`
+	Size           int64                               `json:"size"`
+	Weight         int64                               `json:"weight"`
+	FeeRatePerKb   coin.FormattedAmountWithConversions `json:"feeRatePerKb"`
+	ReplacesTxID   string                              `json:"replacesTxID"`
+	ReplacedByTxID string                              `json:"replacedByTxID"`
 
 	// ETH specific fields
 	Gas   uint64  `json:"gas"`
@@ -175,6 +200,8 @@ func (handlers *Handlers) getTxInfoJSON(txInfo *accounts.TransactionData, detail
 		Addresses:            addresses,
 		Note:                 handlers.account.TxNote(txInfo.InternalID),
 		Fee:                  feeString,
+		ReplacesTxID:         txInfo.ReplacesTxID,
+		ReplacedByTxID:       txInfo.ReplacedByTxID,
 	}
 
 	if detail {
@@ -232,6 +259,88 @@ func (handlers *Handlers) getAccountTransaction(r *http.Request) (interface{}, e
 	return nil, nil
 }
 
+func (handlers *Handlers) getTransactionDetails(r *http.Request) (interface{}, error) {
+	type input struct {
+		PreviousOutPoint string `json:"previousOutPoint"`
+		Sequence         uint32 `json:"sequence"`
+		// Value and address are only known if the previous transaction is stored.
+		Value   *coin.FormattedAmountWithConversions `json:"value"`
+		Address string                               `json:"address"`
+		Ours    bool                                 `json:"ours"`
+		Keypath string                               `json:"keypath,omitempty"`
+	}
+	type output struct {
+		Index      uint32                              `json:"index"`
+		ScriptType string                              `json:"scriptType"`
+		Address    string                              `json:"address"`
+		Value      coin.FormattedAmountWithConversions `json:"value"`
+		Ours       bool                                `json:"ours"`
+		IsChange   bool                                `json:"isChange"`
+	}
+	type result struct {
+		TxID     string   `json:"txID"`
+		RawTx    string   `json:"rawTx"`
+		Version  int32    `json:"version"`
+		LockTime uint32   `json:"lockTime"`
+		RBF      bool     `json:"rbf"`
+		Height   int      `json:"height"`
+		Inputs   []input  `json:"inputs"`
+		Outputs  []output `json:"outputs"`
+	}
+	btcAccount, ok := handlers.account.(*btc.Account)
+	if !ok {
+		return nil, errp.New("Interface must be of type btc.Account")
+	}
+	details, err := btcAccount.TxDetails(r.URL.Query().Get("id"))
+	if err != nil {
+		return nil, err
+	}
+	rateUpdater := handlers.account.Config().RateUpdater
+	var rawTx bytes.Buffer
+	if err := details.Tx.Serialize(&rawTx); err != nil {
+		return nil, errp.WithStack(err)
+	}
+	res := result{
+		TxID:     details.Tx.TxHash().String(),
+		RawTx:    hex.EncodeToString(rawTx.Bytes()),
+		Version:  details.Tx.Version,
+		LockTime: details.Tx.LockTime,
+		RBF:      details.RBF,
+		Height:   details.Height,
+		Inputs:   []input{},
+		Outputs:  []output{},
+	}
+	for _, txIn := range details.Inputs {
+		in := input{
+			PreviousOutPoint: txIn.PreviousOutPoint.String(),
+			Sequence:         txIn.Sequence,
+			Address:          txIn.Address,
+			Ours:             txIn.AccountAddress != nil,
+		}
+		if txIn.PreviousOutput != nil {
+			value := coin.ConvertBTCAmount(
+				handlers.account.Coin(), btcutil.Amount(txIn.PreviousOutput.Value), false, rateUpdater)
+			in.Value = &value
+		}
+		if txIn.AccountAddress != nil {
+			in.Keypath = txIn.AccountAddress.AbsoluteKeypath().Encode()
+		}
+		res.Inputs = append(res.Inputs, in)
+	}
+	for _, txOut := range details.Outputs {
+		res.Outputs = append(res.Outputs, output{
+			Index:      txOut.Index,
+			ScriptType: txOut.ScriptType,
+			Address:    txOut.Address,
+			Value: coin.ConvertBTCAmount(
+				handlers.account.Coin(), btcutil.Amount(txOut.TxOut.Value), false, rateUpdater),
+			Ours:     txOut.Ours,
+			IsChange: txOut.IsChange,
+		})
+	}
+	return res, nil
+}
+
 func (handlers *Handlers) postExportTransactions(*http.Request) (interface{}, error) {
 	type result struct {
 		Success      bool   `json:"success"`
@@ -281,6 +390,24 @@ func (handlers *Handlers) getAccountInfo(*http.Request) (interface{}, error) {
 	return handlers.account.Info(), nil
 }
 
+func (handlers *Handlers) getDescriptors(*http.Request) (interface{}, error) {
+	type result struct {
+		Success      bool     `json:"success"`
+		Descriptors  []string `json:"descriptors,omitempty"`
+		ErrorMessage string   `json:"errorMessage,omitempty"`
+	}
+	btcAccount, ok := handlers.account.(*btc.Account)
+	if !ok {
+		return result{Success: false, ErrorMessage: "An account must be BTC based to export descriptors."}, nil
+	}
+	descriptors, err := btcAccount.Descriptors()
+	if err != nil {
+		handlers.log.WithError(err).Error("Failed to export descriptors")
+		return result{Success: false, ErrorMessage: err.Error()}, nil
+	}
+	return result{Success: true, Descriptors: descriptors}, nil
+}
+
 func (handlers *Handlers) getUTXOs(*http.Request) (interface{}, error) {
 	accountConfig := handlers.account.Config()
 	result := []map[string]interface{}{}
@@ -323,12 +450,180 @@ func (handlers *Handlers) getUTXOs(*http.Request) (interface{}, error) {
 				"note":          handlers.account.TxNote(output.OutPoint.Hash.String()),
 				"addressReused": addressReused,
 				"isChange":      output.IsChange,
+				"frozen":        output.Frozen,
 			})
 	}
 
 	return result, nil
 }
 
+func (handlers *Handlers) postSetUTXOFrozen(r *http.Request) (interface{}, error) {
+	var args struct {
+		OutPoint string `json:"outPoint"`
+		Frozen   bool   `json:"frozen"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&args); err != nil {
+		return nil, errp.WithStack(err)
+	}
+	btcAccount, ok := handlers.account.(*btc.Account)
+	if !ok {
+		return nil, errp.New("Interface must be of type btc.Account")
+	}
+	outPoint, err := util.ParseOutPoint([]byte(args.OutPoint))
+	if err != nil {
+		return nil, err
+	}
+	return nil, btcAccount.SetOutputFrozen(*outPoint, args.Frozen)
+}
+
+func (handlers *Handlers) postDeepRescan(r *http.Request) (interface{}, error) {
+	type gapLimits struct {
+		Receive uint16 `json:"receive"`
+		Change  uint16 `json:"change"`
+	}
+	var args gapLimits
+	if err := json.NewDecoder(r.Body).Decode(&args); err != nil {
+		return nil, errp.WithStack(err)
+	}
+	btcAccount, ok := handlers.account.(*btc.Account)
+	if !ok {
+		return nil, errp.New("Interface must be of type btc.Account")
+	}
+	result, err := btcAccount.DeepRescan(btcTypes.GapLimits{Receive: args.Receive, Change: args.Change})
+	if err != nil {
+		return nil, err
+	}
+	return gapLimits{Receive: result.Receive, Change: result.Change}, nil
+}
+
+func (handlers *Handlers) getScheduledTransactions(*http.Request) (interface{}, error) {
+	type scheduledTransaction struct {
+		TxID     string `json:"txID"`
+		RawTx    string `json:"rawTx"`
+		LockTime uint32 `json:"lockTime"`
+		Note     string `json:"note"`
+		Created  string `json:"created"`
+	}
+	btcAccount, ok := handlers.account.(*btc.Account)
+	if !ok {
+		return nil, errp.New("Interface must be of type btc.Account")
+	}
+	scheduledTxs, err := btcAccount.ScheduledTxs()
+	if err != nil {
+		return nil, err
+	}
+	result := []scheduledTransaction{}
+	for txHash, scheduledTx := range scheduledTxs {
+		var rawTx bytes.Buffer
+		if err := scheduledTx.Tx.Serialize(&rawTx); err != nil {
+			return nil, errp.WithStack(err)
+		}
+		result = append(result, scheduledTransaction{
+			TxID:     txHash.String(),
+			RawTx:    hex.EncodeToString(rawTx.Bytes()),
+			LockTime: scheduledTx.Tx.LockTime,
+			Note:     scheduledTx.Note,
+			Created:  scheduledTx.CreatedTimestamp.Format(time.RFC3339),
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].LockTime != result[j].LockTime {
+			return result[i].LockTime < result[j].LockTime
+		}
+		return result[i].TxID < result[j].TxID
+	})
+	return result, nil
+}
+
+func (handlers *Handlers) postRemoveScheduledTransaction(r *http.Request) (interface{}, error) {
+	var txID string
+	if err := json.NewDecoder(r.Body).Decode(&txID); err != nil {
+		return nil, errp.WithStack(err)
+	}
+	btcAccount, ok := handlers.account.(*btc.Account)
+	if !ok {
+		return nil, errp.New("Interface must be of type btc.Account")
+	}
+	return nil, btcAccount.RemoveScheduledTx(txID)
+}
+
+func (handlers *Handlers) getPrivacyReport(*http.Request) (interface{}, error) {
+	type reusedAddress struct {
+		Address    string             `json:"address"`
+		ScriptType signing.ScriptType `json:"scriptType"`
+		TxCount    int                `json:"txCount"`
+	}
+	type dustOutput struct {
+		OutPoint string                              `json:"outPoint"`
+		TxID     string                              `json:"txId"`
+		Address  string                              `json:"address"`
+		Amount   coin.FormattedAmountWithConversions `json:"amount"`
+		Spent    bool                                `json:"spent"`
+		Frozen   bool                                `json:"frozen"`
+	}
+	type privacyReport struct {
+		ReusedAddresses []reusedAddress `json:"reusedAddresses"`
+		DustOutputs     []dustOutput    `json:"dustOutputs"`
+	}
+	btcAccount, ok := handlers.account.(*btc.Account)
+	if !ok {
+		return nil, errp.New("Interface must be of type btc.Account")
+	}
+	report, err := btcAccount.PrivacyReport()
+	if err != nil {
+		return nil, err
+	}
+	result := privacyReport{
+		ReusedAddresses: []reusedAddress{},
+		DustOutputs:     []dustOutput{},
+	}
+	for _, reused := range report.ReusedAddresses {
+		result.ReusedAddresses = append(result.ReusedAddresses, reusedAddress{
+			Address:    reused.Address.EncodeForHumans(),
+			ScriptType: reused.Address.AccountConfiguration.ScriptType(),
+			TxCount:    reused.TxCount,
+		})
+	}
+	for _, dust := range report.DustOutputs {
+		result.DustOutputs = append(result.DustOutputs, dustOutput{
+			OutPoint: dust.OutPoint.String(),
+			TxID:     dust.OutPoint.Hash.String(),
+			Address:  dust.Address.EncodeForHumans(),
+			Amount: coin.ConvertBTCAmount(
+				handlers.account.Coin(), dust.Value, false, handlers.account.Config().RateUpdater),
+			Spent:  dust.Spent,
+			Frozen: dust.Frozen,
+		})
+	}
+	return result, nil
+}
+
+func (handlers *Handlers) getSilentPayments(*http.Request) (interface{}, error) {
+	type silentPayments struct {
+		Enabled    bool   `json:"enabled"`
+		Address    string `json:"address,omitempty"`
+		ScanHeight int    `json:"scanHeight,omitempty"`
+		Outputs    int    `json:"outputs,omitempty"`
+	}
+	btcAccount, ok := handlers.account.(*btc.Account)
+	if !ok {
+		return nil, errp.New("Interface must be of type btc.Account")
+	}
+	status, err := btcAccount.SilentPayments()
+	if err != nil {
+		return nil, err
+	}
+	if status == nil {
+		return silentPayments{Enabled: false}, nil
+	}
+	return silentPayments{
+		Enabled:    true,
+		Address:    status.Address,
+		ScanHeight: status.ScanHeight,
+		Outputs:    status.Outputs,
+	}, nil
+}
+
 func (handlers *Handlers) getAccountBalance(*http.Request) (interface{}, error) {
 	accountConfig := handlers.account.Config()
 	type balance struct {
@@ -406,6 +701,19 @@ type sendTxInput struct {
 	accounts.TxProposalArgs
 }
 
+type txRecipientInput struct {
+	Address string `json:"address"`
+	Amount  string `json:"amount"`
+	SendAll string `json:"sendAll"`
+}
+
+func newSendAmount(amount string, sendAll string) coin.SendAmount {
+	if sendAll == "yes" {
+		return coin.NewSendAmountAll()
+	}
+	return coin.NewSendAmount(amount)
+}
+
 func (input *sendTxInput) UnmarshalJSON(jsonBytes []byte) error {
 	jsonBody := struct {
 		Address   string `json:"address"`
@@ -419,6 +727,12 @@ func (input *sendTxInput) UnmarshalJSON(jsonBytes []byte) error {
 		Counter        int            `json:"counter"`
 		PaymentRequest *slip24Request `json:"paymentRequest"`
 		UseHighestFee  bool           `json:"useHighestFee"`
+		// AdditionalRecipients are paid in the same transaction (batch payment).
+		AdditionalRecipients []txRecipientInput `json:"additionalRecipients"`
+		// LockTime is a block height, or a Unix timestamp if it is 500000000 or higher.
+		LockTime uint32 `json:"lockTime"`
+		// Payjoin is the BIP-78 payjoin endpoint of the receiver.
+		Payjoin string `json:"payjoin"`
 	}{}
 	if err := json.Unmarshal(jsonBytes, &jsonBody); err != nil {
 		return errp.WithStack(err)
@@ -432,10 +746,13 @@ func (input *sendTxInput) UnmarshalJSON(jsonBytes []byte) error {
 	if input.FeeTargetCode == accounts.FeeTargetCodeCustom {
 		input.CustomFee = jsonBody.CustomFee
 	}
-	if jsonBody.SendAll == "yes" {
-		input.Amount = coin.NewSendAmountAll()
-	} else {
-		input.Amount = coin.NewSendAmount(jsonBody.Amount)
+	input.Amount = newSendAmount(jsonBody.Amount, jsonBody.SendAll)
+	input.AdditionalRecipients = nil
+	for _, recipient := range jsonBody.AdditionalRecipients {
+		input.AdditionalRecipients = append(input.AdditionalRecipients, accounts.TxRecipient{
+			Address: recipient.Address,
+			Amount:  newSendAmount(recipient.Amount, recipient.SendAll),
+		})
 	}
 	input.SelectedUTXOs = map[wire.OutPoint]struct{}{}
 	for _, outPointString := range jsonBody.SelectedUTXOS {
@@ -454,6 +771,8 @@ func (input *sendTxInput) UnmarshalJSON(jsonBytes []byte) error {
 		input.PaymentRequest = paymentRequest
 	}
 	input.UseHighestFee = jsonBody.UseHighestFee
+	input.LockTime = jsonBody.LockTime
+	input.Payjoin = jsonBody.Payjoin
 	return nil
 }
 
@@ -465,7 +784,13 @@ func (handlers *Handlers) postAccountSendTx(r *http.Request) (interface{}, error
 		// not return but only log an error here.
 		handlers.log.WithError(err).Error("Failed to unmarshal transaction note")
 	}
-	err := handlers.account.SendTx(txNote)
+	var payjoin bool
+	var err error
+	if btcAccount, ok := handlers.account.(*btc.Account); ok {
+		payjoin, err = btcAccount.SendTxPayjoin(txNote)
+	} else {
+		err = handlers.account.SendTx(txNote)
+	}
 	if errp.Cause(err) == keystore.ErrSigningAborted || errp.Cause(err) == errp.ErrUserAbort {
 		return map[string]interface{}{"success": false, "aborted": true}, nil
 	}
@@ -475,9 +800,15 @@ func (handlers *Handlers) postAccountSendTx(r *http.Request) (interface{}, error
 		if strings.Contains(err.Error(), etherscan.ERC20GasErr) {
 			result["errorCode"] = errors.ERC20InsufficientGasFunds.Error()
 		}
+		if errp.Cause(err) == btc.ErrMultisigNeedsCosigners {
+			result["errorCode"] = string(btc.ErrMultisigNeedsCosigners)
+		}
+		if errp.Cause(err) == keystore.ErrCannotSign {
+			result["errorCode"] = keystore.ErrCannotSign.Error()
+		}
 		return result, nil
 	}
-	return map[string]interface{}{"success": true}, nil
+	return map[string]interface{}{"success": true, "payjoin": payjoin}, nil
 }
 
 func txProposalError(err error) (interface{}, error) {
@@ -508,19 +839,350 @@ func (handlers *Handlers) postAccountTxProposal(r *http.Request) (interface{}, e
 	}, nil
 }
 
-func (handlers *Handlers) getAccountFeeTargets(*http.Request) (interface{}, error) {
-	type jsonFeeTarget struct {
-		Code        accounts.FeeTargetCode `json:"code"`
-		FeeRateInfo string                 `json:"feeRateInfo"`
+// accelerationInput is the input of the endpoints accelerating an unconfirmed transaction.
+type accelerationInput struct {
+	TxID string
+	accounts.TxProposalArgs
+}
+
+func (input *accelerationInput) UnmarshalJSON(jsonBytes []byte) error {
+	jsonBody := struct {
+		TxID      string `json:"txID"`
+		FeeTarget string `json:"feeTarget"`
+		// Provided in Sat/vByte.
+		CustomFee     string `json:"customFee"`
+		UseHighestFee bool   `json:"useHighestFee"`
+	}{}
+	if err := json.Unmarshal(jsonBytes, &jsonBody); err != nil {
+		return errp.WithStack(err)
 	}
+	input.TxID = jsonBody.TxID
+	input.UseHighestFee = jsonBody.UseHighestFee
+	if !jsonBody.UseHighestFee {
+		var err error
+		input.FeeTargetCode, err = accounts.NewFeeTargetCode(jsonBody.FeeTarget)
+		if err != nil {
+			return errp.WithMessage(err, "Failed to retrieve fee target code")
+		}
+		if input.FeeTargetCode == accounts.FeeTargetCodeCustom {
+			input.CustomFee = jsonBody.CustomFee
+		}
+	}
+	return nil
+}
 
+func (handlers *Handlers) postFeeBumpProposal(r *http.Request) (interface{}, error) {
+	accountConfig := handlers.account.Config()
+	var input accelerationInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		return txProposalError(errp.WithStack(err))
+	}
+	btcAccount, ok := handlers.account.(*btc.Account)
+	if !ok {
+		return nil, errp.New("An account must be BTC based to bump the fee of a transaction.")
+	}
+	outputAmount, fee, total, err := btcAccount.FeeBumpProposal(input.TxID, &input.TxProposalArgs)
+	if err != nil {
+		return txProposalError(err)
+	}
+	return map[string]interface{}{
+		"success": true,
+		"amount":  outputAmount.FormatWithConversions(handlers.account.Coin(), false, accountConfig.RateUpdater),
+		"fee":     fee.FormatWithConversions(handlers.account.Coin(), true, accountConfig.RateUpdater),
+		"total":   total.FormatWithConversions(handlers.account.Coin(), false, accountConfig.RateUpdater),
+	}, nil
+}
+
+func (handlers *Handlers) postCancelProposal(r *http.Request) (interface{}, error) {
+	accountConfig := handlers.account.Config()
+	var input accelerationInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		return txProposalError(errp.WithStack(err))
+	}
+	btcAccount, ok := handlers.account.(*btc.Account)
+	if !ok {
+		return nil, errp.New("An account must be BTC based to cancel a transaction.")
+	}
+	outputAmount, fee, total, err := btcAccount.CancelProposal(input.TxID, &input.TxProposalArgs)
+	if err != nil {
+		return txProposalError(err)
+	}
+	return map[string]interface{}{
+		"success": true,
+		"amount":  outputAmount.FormatWithConversions(handlers.account.Coin(), false, accountConfig.RateUpdater),
+		"fee":     fee.FormatWithConversions(handlers.account.Coin(), true, accountConfig.RateUpdater),
+		"total":   total.FormatWithConversions(handlers.account.Coin(), false, accountConfig.RateUpdater),
+	}, nil
+}
+
+func (handlers *Handlers) postCPFPProposal(r *http.Request) (interface{}, error) {
+	accountConfig := handlers.account.Config()
+	var input accelerationInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		return txProposalError(errp.WithStack(err))
+	}
+	btcAccount, ok := handlers.account.(*btc.Account)
+	if !ok {
+		return nil, errp.New("An account must be BTC based to accelerate a transaction.")
+	}
+	outputAmount, fee, total, packageFeeRatePerKb, err := btcAccount.CPFPProposal(input.TxID, &input.TxProposalArgs)
+	if err != nil {
+		return txProposalError(err)
+	}
+	return map[string]interface{}{
+		"success":             true,
+		"amount":              outputAmount.FormatWithConversions(handlers.account.Coin(), false, accountConfig.RateUpdater),
+		"fee":                 fee.FormatWithConversions(handlers.account.Coin(), true, accountConfig.RateUpdater),
+		"total":               total.FormatWithConversions(handlers.account.Coin(), false, accountConfig.RateUpdater),
+		"packageFeeRatePerKb": coin.ConvertBTCAmount(handlers.account.Coin(), packageFeeRatePerKb, true, accountConfig.RateUpdater),
+	}, nil
+}
+
+// consolidationInput is the input of the endpoint proposing a UTXO consolidation. The fee is
+// specified like in accelerationInput.
+type consolidationInput struct {
+	btc.ConsolidationArgs
+	accounts.TxProposalArgs
+}
+
+func (input *consolidationInput) UnmarshalJSON(jsonBytes []byte) error {
+	jsonBody := struct {
+		// Provided in Sat/vByte.
+		MaxFeeRate float64 `json:"maxFeeRate"`
+		// Provided in Sat/vByte. Optional.
+		FutureFeeRate float64 `json:"futureFeeRate"`
+		// Optional filters.
+		ScriptType signing.ScriptType `json:"scriptType"`
+		// Provided in satoshis.
+		MaxValue int64  `json:"maxValue"`
+		Address  string `json:"address"`
+	}{}
+	if err := json.Unmarshal(jsonBytes, &jsonBody); err != nil {
+		return errp.WithStack(err)
+	}
+	var feeInput accelerationInput
+	if err := json.Unmarshal(jsonBytes, &feeInput); err != nil {
+		return err
+	}
+	input.TxProposalArgs = feeInput.TxProposalArgs
+	input.ConsolidationArgs = btc.ConsolidationArgs{
+		MaxFeeRatePerKb:    btcutil.Amount(jsonBody.MaxFeeRate * 1000),
+		FutureFeeRatePerKb: btcutil.Amount(jsonBody.FutureFeeRate * 1000),
+		ScriptType:         jsonBody.ScriptType,
+		MaxValue:           btcutil.Amount(jsonBody.MaxValue),
+		Address:            jsonBody.Address,
+	}
+	return nil
+}
+
+func (handlers *Handlers) postConsolidationProposal(r *http.Request) (interface{}, error) {
+	accountConfig := handlers.account.Config()
+	var input consolidationInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		return txProposalError(errp.WithStack(err))
+	}
+	btcAccount, ok := handlers.account.(*btc.Account)
+	if !ok {
+		return nil, errp.New("An account must be BTC based to consolidate coins.")
+	}
+	consolidation, err := btcAccount.ConsolidationProposal(&input.ConsolidationArgs, &input.TxProposalArgs)
+	if err != nil {
+		return txProposalError(err)
+	}
+	return map[string]interface{}{
+		"success":          true,
+		"amount":           consolidation.Amount.FormatWithConversions(handlers.account.Coin(), false, accountConfig.RateUpdater),
+		"fee":              consolidation.Fee.FormatWithConversions(handlers.account.Coin(), true, accountConfig.RateUpdater),
+		"total":            consolidation.Total.FormatWithConversions(handlers.account.Coin(), false, accountConfig.RateUpdater),
+		"coinCount":        consolidation.CoinCount,
+		"savedVSize":       consolidation.Savings.VSize,
+		"futureFeeSavings": coin.ConvertBTCAmount(handlers.account.Coin(), consolidation.Savings.FutureFee, true, accountConfig.RateUpdater),
+		"netSavings":       coin.ConvertBTCAmount(handlers.account.Coin(), consolidation.Savings.Net, true, accountConfig.RateUpdater),
+	}, nil
+}
+
+// sweepInput is the input of the endpoint proposing to sweep private keys. The fee is specified
+// like in accelerationInput.
+type sweepInput struct {
+	Keys       []string
+	Passphrase string
+	ScriptType signing.ScriptType
+	accounts.TxProposalArgs
+}
+
+func (input *sweepInput) UnmarshalJSON(jsonBytes []byte) error {
+	jsonBody := struct {
+		// WIF or BIP-38 encrypted private keys.
+		Keys []string `json:"keys"`
+		// Only needed for BIP-38 encrypted keys.
+		Passphrase string `json:"passphrase"`
+		// Optional receive address type.
+		ScriptType signing.ScriptType `json:"scriptType"`
+	}{}
+	if err := json.Unmarshal(jsonBytes, &jsonBody); err != nil {
+		return errp.WithStack(err)
+	}
+	var feeInput accelerationInput
+	if err := json.Unmarshal(jsonBytes, &feeInput); err != nil {
+		return err
+	}
+	input.TxProposalArgs = feeInput.TxProposalArgs
+	input.Keys = jsonBody.Keys
+	input.Passphrase = jsonBody.Passphrase
+	input.ScriptType = jsonBody.ScriptType
+	return nil
+}
+
+func (handlers *Handlers) postSweepProposal(r *http.Request) (interface{}, error) {
+	accountConfig := handlers.account.Config()
+	var input sweepInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		return txProposalError(errp.WithStack(err))
+	}
+	btcAccount, ok := handlers.account.(*btc.Account)
+	if !ok {
+		return nil, errp.New("An account must be BTC based to sweep private keys.")
+	}
+	sweep, err := btcAccount.SweepProposal(input.Keys, input.Passphrase, input.ScriptType, &input.TxProposalArgs)
+	if err != nil {
+		return txProposalError(err)
+	}
+	return map[string]interface{}{
+		"success":   true,
+		"amount":    sweep.Amount.FormatWithConversions(handlers.account.Coin(), false, accountConfig.RateUpdater),
+		"fee":       sweep.Fee.FormatWithConversions(handlers.account.Coin(), true, accountConfig.RateUpdater),
+		"total":     sweep.Total.FormatWithConversions(handlers.account.Coin(), false, accountConfig.RateUpdater),
+		"coinCount": sweep.CoinCount,
+		"address":   sweep.Address,
+	}, nil
+}
+
+func (handlers *Handlers) postSweep(r *http.Request) (interface{}, error) {
+	type result struct {
+		Success      bool   `json:"success"`
+		TxID         string `json:"txID,omitempty"`
+		ErrorMessage string `json:"errorMessage,omitempty"`
+	}
+	var args struct {
+		Note string `json:"note"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&args); err != nil {
+		return result{Success: false, ErrorMessage: err.Error()}, nil
+	}
+	btcAccount, ok := handlers.account.(*btc.Account)
+	if !ok {
+		return result{Success: false, ErrorMessage: "An account must be BTC based to sweep private keys."}, nil
+	}
+	txID, err := btcAccount.SendSweep(args.Note)
+	if err != nil {
+		handlers.log.WithError(err).Error("Failed to send sweep transaction")
+		return result{Success: false, ErrorMessage: err.Error()}, nil
+	}
+	return result{Success: true, TxID: txID}, nil
+}
+
+func (handlers *Handlers) getTxProposalPSBT(*http.Request) (interface{}, error) {
+	type result struct {
+		Success      bool   `json:"success"`
+		PSBT         string `json:"psbt,omitempty"`
+		ErrorMessage string `json:"errorMessage,omitempty"`
+	}
+	btcAccount, ok := handlers.account.(*btc.Account)
+	if !ok {
+		return result{Success: false, ErrorMessage: "An account must be BTC based to export PSBTs."}, nil
+	}
+	encoded, err := btcAccount.TxProposalPSBT()
+	if err != nil {
+		handlers.log.WithError(err).Error("Failed to export tx proposal as PSBT")
+		return result{Success: false, ErrorMessage: err.Error()}, nil
+	}
+	return result{Success: true, PSBT: encoded}, nil
+}
+
+func (handlers *Handlers) postSignPSBT(r *http.Request) (interface{}, error) {
+	type result struct {
+		Success      bool   `json:"success"`
+		PSBT         string `json:"psbt,omitempty"`
+		Aborted      bool   `json:"aborted,omitempty"`
+		ErrorMessage string `json:"errorMessage,omitempty"`
+		ErrorCode    string `json:"errorCode,omitempty"`
+	}
+	var args struct {
+		PSBT string `json:"psbt"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&args); err != nil {
+		return result{Success: false, ErrorMessage: err.Error()}, nil
+	}
+	btcAccount, ok := handlers.account.(*btc.Account)
+	if !ok {
+		return result{Success: false, ErrorMessage: "An account must be BTC based to sign PSBTs."}, nil
+	}
+	encoded, err := btcAccount.SignPSBT(args.PSBT)
+	if errp.Cause(err) == keystore.ErrSigningAborted || errp.Cause(err) == errp.ErrUserAbort {
+		return result{Success: false, Aborted: true}, nil
+	}
+	if err != nil {
+		handlers.log.WithError(err).Error("Failed to sign PSBT")
+		if errp.Cause(err) == keystore.ErrCannotSign {
+			return result{Success: false, ErrorMessage: err.Error(), ErrorCode: keystore.ErrCannotSign.Error()}, nil
+		}
+		return result{Success: false, ErrorMessage: err.Error()}, nil
+	}
+	return result{Success: true, PSBT: encoded}, nil
+}
+
+func (handlers *Handlers) postSendPSBT(r *http.Request) (interface{}, error) {
+	type result struct {
+		Success      bool   `json:"success"`
+		TxID         string `json:"txID,omitempty"`
+		ErrorMessage string `json:"errorMessage,omitempty"`
+	}
+	var args struct {
+		PSBT string `json:"psbt"`
+		Note string `json:"note"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&args); err != nil {
+		return result{Success: false, ErrorMessage: err.Error()}, nil
+	}
+	btcAccount, ok := handlers.account.(*btc.Account)
+	if !ok {
+		return result{Success: false, ErrorMessage: "An account must be BTC based to send PSBTs."}, nil
+	}
+	txID, err := btcAccount.SendPSBT(args.PSBT, args.Note)
+	if err != nil {
+		handlers.log.WithError(err).Error("Failed to send PSBT")
+		return result{Success: false, ErrorMessage: err.Error()}, nil
+	}
+	return result{Success: true, TxID: txID}, nil
+}
+
+type jsonFeeTarget struct {
+	Code        accounts.FeeTargetCode `json:"code"`
+	FeeRateInfo string                 `json:"feeRateInfo"`
+	// Only available for BTC based accounts.
+	Blocks     int                       `json:"blocks,omitempty"`
+	ETAMinutes float64                   `json:"etaMinutes,omitempty"`
+	Confidence btc.FeeEstimateConfidence `json:"confidence,omitempty"`
+}
+
+func newJSONFeeTarget(feeTarget accounts.FeeTarget) jsonFeeTarget {
+	result := jsonFeeTarget{
+		Code:        feeTarget.Code(),
+		FeeRateInfo: feeTarget.FormattedFeeRate(),
+	}
+	if btcFeeTarget, ok := feeTarget.(*btc.FeeTarget); ok {
+		result.Blocks = btcFeeTarget.Blocks()
+		result.ETAMinutes = btcFeeTarget.ETA().Minutes()
+		result.Confidence = btcFeeTarget.Confidence()
+	}
+	return result
+}
+
+func (handlers *Handlers) getAccountFeeTargets(*http.Request) (interface{}, error) {
 	feeTargets, defaultFeeTarget := handlers.account.FeeTargets()
 	result := []jsonFeeTarget{}
 	for _, feeTarget := range feeTargets {
-		result = append(result, jsonFeeTarget{
-			Code:        feeTarget.Code(),
-			FeeRateInfo: feeTarget.FormattedFeeRate(),
-		})
+		result = append(result, newJSONFeeTarget(feeTarget))
 	}
 	return map[string]interface{}{
 		"feeTargets":       result,
@@ -528,6 +1190,28 @@ func (handlers *Handlers) getAccountFeeTargets(*http.Request) (interface{}, erro
 	}, nil
 }
 
+func (handlers *Handlers) getFeeEstimate(r *http.Request) (interface{}, error) {
+	type result struct {
+		Success      bool           `json:"success"`
+		FeeTarget    *jsonFeeTarget `json:"feeTarget,omitempty"`
+		ErrorMessage string         `json:"errorMessage,omitempty"`
+	}
+	btcAccount, ok := handlers.account.(*btc.Account)
+	if !ok {
+		return nil, errp.New("Interface must be of type btc.Account")
+	}
+	blocks, err := strconv.Atoi(r.URL.Query().Get("blocks"))
+	if err != nil {
+		return result{Success: false, ErrorMessage: "Invalid block target"}, nil
+	}
+	feeTarget, err := btcAccount.FeeEstimate(blocks)
+	if err != nil {
+		return result{Success: false, ErrorMessage: err.Error()}, nil
+	}
+	jsonFeeTarget := newJSONFeeTarget(feeTarget)
+	return result{Success: true, FeeTarget: &jsonFeeTarget}, nil
+}
+
 func (handlers *Handlers) postInit(*http.Request) (interface{}, error) {
 	if handlers.account == nil {
 		return nil, errp.New("/init called even though account was not added yet")
@@ -763,17 +1447,20 @@ func (handlers *Handlers) postEthSignWalletConnectTx(r *http.Request) (interface
 
 func (handlers *Handlers) postSignBTCAddress(r *http.Request) (interface{}, error) {
 	type response struct {
-		Success      bool   `json:"success"`
-		Address      string `json:"address"`
-		Signature    string `json:"signature"`
-		ErrorMessage string `json:"errorMessage,omitempty"`
-		ErrorCode    string `json:"errorCode,omitempty"`
+		Success      bool             `json:"success"`
+		Address      string           `json:"address"`
+		Signature    string           `json:"signature"`
+		BIP322       btc.BIP322Format `json:"bip322,omitempty"`
+		ErrorMessage string           `json:"errorMessage,omitempty"`
+		ErrorCode    string           `json:"errorCode,omitempty"`
 	}
 
 	var request struct {
 		AccountCode types.Code         `json:"accountCode"`
 		Msg         string             `json:"msg"`
 		Format      signing.ScriptType `json:"format"`
+		// BIP322 requests a BIP-322 signature in the given format instead of a legacy signature.
+		BIP322 btc.BIP322Format `json:"bip322"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
 		return response{Success: false, ErrorMessage: err.Error()}, nil
@@ -787,22 +1474,37 @@ func (handlers *Handlers) postSignBTCAddress(r *http.Request) (interface{}, erro
 		}, nil
 	}
 
-	address, signature, err := btc.SignBTCAddress(
-		account,
-		request.Msg,
-		request.Format)
+	// Legacy signatures are not defined for taproot.
+	if request.BIP322 == "" && request.Format == signing.ScriptTypeP2TR {
+		request.BIP322 = btc.BIP322FormatSimple
+	}
+	var address, signature string
+	var err error
+	if request.BIP322 != "" {
+		address, signature, request.BIP322, err = btc.SignBTCAddressBIP322(
+			account,
+			request.Msg,
+			request.Format,
+			request.BIP322)
+	} else {
+		address, signature, err = btc.SignBTCAddress(
+			account,
+			request.Msg,
+			request.Format)
+	}
 	if err != nil {
-		if firmware.IsErrorAbort(err) {
+		if firmware.IsErrorAbort(err) || errp.Cause(err) == keystore.ErrSigningAborted {
 			return response{Success: false, ErrorCode: errp.ErrUserAbort.Error()}, nil
 		}
-		if errp.Cause(err) == backend.ErrWrongKeystore {
-			return response{Success: false, ErrorCode: backend.ErrWrongKeystore.Error()}, nil
+		switch errp.Cause(err) {
+		case backend.ErrWrongKeystore, keystore.ErrUnsupportedFeature, keystore.ErrCannotSign:
+			return response{Success: false, ErrorCode: errp.Cause(err).Error()}, nil
 		}
 
 		handlers.log.WithField("code", account.Config().Config.Code).Error(err)
 		return response{Success: false, ErrorMessage: err.Error()}, nil
 	}
-	return response{Success: true, Address: address, Signature: signature}, nil
+	return response{Success: true, Address: address, Signature: signature, BIP322: request.BIP322}, nil
 }
 
 func (handlers *Handlers) getHasPaymentRequest(r *http.Request) (interface{}, error) {
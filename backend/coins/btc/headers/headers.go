@@ -19,6 +19,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"sort"
 	"time"
 
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/blockchain"
@@ -36,6 +37,9 @@ import (
 
 const reorgLimit = 100
 
+// medianTimeBlocks is the number of blocks whose timestamps determine the median time past.
+const medianTimeBlocks = 11
+
 // Event instances are sent to the onEvent callback.
 type Event string
 
@@ -168,6 +172,7 @@ func (headers *Headers) SubscribeEvent(f func(event Event)) func() {
 
 // TipHeight returns the height of the tip.
 func (headers *Headers) TipHeight() int {
+	defer headers.lock.RLock()()
 	return headers.targetHeight
 }
 
@@ -433,6 +438,34 @@ func (headers *Headers) VerifiedHeaderByHeight(height int) (*wire.BlockHeader, e
 	return headers.db.HeaderByHeight(height)
 }
 
+// MedianTimePast returns the median of the timestamps of the last 11 blocks up to the synced tip,
+// against which timestamp locktimes are checked (BIP-113). Returns the zero time if the headers
+// are not available.
+func (headers *Headers) MedianTimePast() (time.Time, error) {
+	defer headers.lock.RLock()()
+
+	tip, err := headers.db.Tip()
+	if err != nil {
+		return time.Time{}, err
+	}
+	timestamps := []time.Time{}
+	for height := tip; height >= 0 && height > tip-medianTimeBlocks; height-- {
+		header, err := headers.db.HeaderByHeight(height)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if header == nil {
+			return time.Time{}, nil
+		}
+		timestamps = append(timestamps, header.Timestamp)
+	}
+	if len(timestamps) == 0 {
+		return time.Time{}, nil
+	}
+	sort.Slice(timestamps, func(i, j int) bool { return timestamps[i].Before(timestamps[j]) })
+	return timestamps[len(timestamps)/2], nil
+}
+
 func (headers *Headers) kick() {
 	select {
 	case headers.kickChan <- struct{}{}:
@@ -444,7 +477,9 @@ func (headers *Headers) kick() {
 func (headers *Headers) update(blockHeight int) {
 	headers.log.Debugf("new target %d", blockHeight)
 	headers.kick()
+	unlock := headers.lock.Lock()
 	headers.targetHeight = blockHeight
+	unlock()
 	headers.notifyEvent(EventNewTip)
 }
 
@@ -20,6 +20,7 @@ import (
 
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/blockchain/mocks"
 	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/require"
 )
@@ -45,3 +46,42 @@ func TestClose(t *testing.T) {
 	}
 
 }
+
+func TestMedianTimePast(t *testing.T) {
+	tip := 100
+	db := &dbMock{
+		tip: func() (int, error) { return tip, nil },
+		headerByHeight: func(height int) (*wire.BlockHeader, error) {
+			// Timestamps are not monotonic, the median is taken.
+			timestamp := int64(1700000000 + height*600)
+			if height%2 == 0 {
+				timestamp += 3600
+			}
+			return &wire.BlockHeader{Timestamp: time.Unix(timestamp, 0)}, nil
+		},
+	}
+	headers := NewHeaders(
+		&chaincfg.TestNet3Params,
+		db,
+		&mocks.BlockchainMock{},
+		(&logrus.Logger{}).WithField("group", "headers_test"),
+	)
+
+	// Heights 90 to 100, in units of 600s: 91, 93, 95, 96 (90), 97, 98 (92), 99, 100 (94), ...
+	medianTimePast, err := headers.MedianTimePast()
+	require.NoError(t, err)
+	require.Equal(t, time.Unix(1700000000+98*600, 0), medianTimePast)
+
+	// Close to genesis, fewer blocks are used.
+	tip = 0
+	medianTimePast, err = headers.MedianTimePast()
+	require.NoError(t, err)
+	require.Equal(t, time.Unix(1700000000+3600, 0), medianTimePast)
+
+	// Missing headers.
+	tip = 100
+	db.headerByHeight = func(int) (*wire.BlockHeader, error) { return nil, nil }
+	medianTimePast, err = headers.MedianTimePast()
+	require.NoError(t, err)
+	require.True(t, medianTimePast.IsZero())
+}
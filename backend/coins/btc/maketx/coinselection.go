@@ -0,0 +1,463 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maketx
+
+import (
+	"math"
+	mrand "math/rand"
+	"sort"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/errors"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// DefaultLongTermFeePerKb is the fee rate at which we expect to be able to spend coins in the
+// future. It is used to weigh spending inputs now against spending them later (10 sat/vbyte, same
+// as Bitcoin Core's default consolidation fee rate).
+const DefaultLongTermFeePerKb = btcutil.Amount(10000)
+
+// CoinSelectionAlgorithm identifies the algorithm used to select the inputs of a transaction.
+type CoinSelectionAlgorithm string
+
+const (
+	// CoinSelectionBranchAndBound searches for an input set which does not need a change output.
+	CoinSelectionBranchAndBound CoinSelectionAlgorithm = "branchAndBound"
+	// CoinSelectionKnapsack searches for the input set with the smallest excess over the target.
+	CoinSelectionKnapsack CoinSelectionAlgorithm = "knapsack"
+	// CoinSelectionSingleRandomDraw selects random inputs until the target is reached.
+	CoinSelectionSingleRandomDraw CoinSelectionAlgorithm = "singleRandomDraw"
+)
+
+// bnbMaxTries limits the number of branches explored by the branch-and-bound search.
+const bnbMaxTries = 100000
+
+// knapsackIterations is the number of random subsets tried by approximateBestSubset().
+const knapsackIterations = 1000
+
+// selectionCandidate is a coin considered by the coin selection algorithms.
+type selectionCandidate struct {
+	outPoint wire.OutPoint
+	value    btcutil.Amount
+	// effectiveValue is the value minus the fee needed to spend it at the current fee rate.
+	effectiveValue btcutil.Amount
+	// fee and longTermFee are the fees needed to spend the coin at the current and the long-term
+	// fee rate.
+	fee         btcutil.Amount
+	longTermFee btcutil.Amount
+}
+
+// coinSelectionParams contains the selection targets, all in terms of effective values.
+type coinSelectionParams struct {
+	feePerKb         btcutil.Amount
+	longTermFeePerKb btcutil.Amount
+	isSegwitTx       bool
+	// changelessTarget is the amount plus the fee for all parts of the tx except for the inputs, if
+	// there is no change output.
+	changelessTarget btcutil.Amount
+	// changeTarget is like changelessTarget, but with a change output.
+	changeTarget btcutil.Amount
+	// costOfChange is the fee for adding the change output now plus the fee for spending it later.
+	costOfChange btcutil.Amount
+	// minChange is the smallest change amount which is not dust.
+	minChange btcutil.Amount
+}
+
+// coinSelectionResult is the outcome of a coin selection algorithm.
+type coinSelectionResult struct {
+	algorithm CoinSelectionAlgorithm
+	outPoints []wire.OutPoint
+	// change is true if the tx has a change output.
+	change bool
+	waste  btcutil.Amount
+}
+
+// ceilFee returns the fee for the given virtual size, rounded up.
+func ceilFee(feePerKb btcutil.Amount, vsize int) btcutil.Amount {
+	return (feePerKb*btcutil.Amount(vsize) + 999) / 1000
+}
+
+// inputVSize returns the virtual size of an input, rounded up. isSegwitTx is true if the tx has a
+// witness, in which case inputs without a witness need an empty witness.
+func inputVSize(configuration *signing.Configuration, isSegwitTx bool) int {
+	sigScriptSize, witnessSize := sigScriptWitnessSize(configuration)
+	weight := 4*calcInputSize(sigScriptSize) + witnessSize
+	if isSegwitTx && witnessSize == 0 {
+		weight += wire.VarIntSerializeSize(0)
+	}
+	return (weight + 3) / 4
+}
+
+// InputVSize returns the virtual size of an input spending an output of the given configuration in
+// a segwit transaction, rounded up.
+func InputVSize(configuration *signing.Configuration) int {
+	return inputVSize(configuration, true)
+}
+
+// hasWitness returns true if spending any of the outputs needs a witness. If so, we assume the tx
+// will be a segwit tx when computing the size of its parts.
+func hasWitness(spendableOutputs map[wire.OutPoint]UTXO) bool {
+	for _, utxo := range spendableOutputs {
+		if _, witnessSize := sigScriptWitnessSize(utxo.Address.AccountConfiguration); witnessSize > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// dustThreshold returns the smallest amount of an output which is not dust according to
+// isDustAmount().
+func dustThreshold(
+	pkScriptSize int, configuration *signing.Configuration, feePerKb btcutil.Amount) btcutil.Amount {
+	sigScriptSize, _ := sigScriptWitnessSize(configuration)
+	totalSize := outputSize(pkScriptSize) + calcInputSize(sigScriptSize)
+	return ceilFee(feePerKb, 3*totalSize)
+}
+
+// newCoinSelectionParams computes the targets for selecting coins to pay `amount` to outputs with
+// the given pkScript sizes.
+func newCoinSelectionParams(
+	amount btcutil.Amount,
+	outputPkScriptSizes []int,
+	changePkScriptSize int,
+	changeConfiguration *signing.Configuration,
+	isSegwitTx bool,
+	feePerKb btcutil.Amount,
+	longTermFeePerKb btcutil.Amount,
+) *coinSelectionParams {
+	nonInputVSize := estimateTxSize(nil, outputPkScriptSizes, 0)
+	if isSegwitTx {
+		// Segwit marker and flag, rounded up.
+		nonInputVSize++
+	}
+	changeOutputVSize := outputSize(changePkScriptSize)
+	return &coinSelectionParams{
+		feePerKb:         feePerKb,
+		longTermFeePerKb: longTermFeePerKb,
+		isSegwitTx:       isSegwitTx,
+		changelessTarget: amount + ceilFee(feePerKb, nonInputVSize),
+		changeTarget:     amount + ceilFee(feePerKb, nonInputVSize+changeOutputVSize),
+		costOfChange: ceilFee(feePerKb, changeOutputVSize) +
+			ceilFee(longTermFeePerKb, inputVSize(changeConfiguration, isSegwitTx)),
+		minChange: dustThreshold(changePkScriptSize, changeConfiguration, feePerKb),
+	}
+}
+
+// newSelectionCandidates returns the coins with a positive effective value, sorted by descending
+// effective value.
+func newSelectionCandidates(
+	spendableOutputs map[wire.OutPoint]UTXO,
+	params *coinSelectionParams,
+) []*selectionCandidate {
+	candidates := []*selectionCandidate{}
+	for outPoint, utxo := range spendableOutputs {
+		vsize := inputVSize(utxo.Address.AccountConfiguration, params.isSegwitTx)
+		candidate := &selectionCandidate{
+			outPoint:    outPoint,
+			value:       btcutil.Amount(utxo.TxOut.Value),
+			fee:         ceilFee(params.feePerKb, vsize),
+			longTermFee: ceilFee(params.longTermFeePerKb, vsize),
+		}
+		candidate.effectiveValue = candidate.value - candidate.fee
+		if candidate.effectiveValue <= 0 {
+			continue
+		}
+		candidates = append(candidates, candidate)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].effectiveValue == candidates[j].effectiveValue {
+			// Secondary sort to make coin selection deterministic.
+			return candidates[i].outPoint.String() < candidates[j].outPoint.String()
+		}
+		return candidates[i].effectiveValue > candidates[j].effectiveValue
+	})
+	return candidates
+}
+
+// waste computes the waste metric of an input set: the fees paid for the inputs now in excess of
+// what they would cost at the long-term fee rate, plus the cost of the change output if there is
+// one, or the excess paid to the miners if there is none.
+func waste(
+	selected []*selectionCandidate, params *coinSelectionParams, change bool) btcutil.Amount {
+	var result, sum btcutil.Amount
+	for _, candidate := range selected {
+		result += candidate.fee - candidate.longTermFee
+		sum += candidate.effectiveValue
+	}
+	if change {
+		return result + params.costOfChange
+	}
+	return result + sum - params.changelessTarget
+}
+
+func candidateOutPoints(selected []*selectionCandidate) []wire.OutPoint {
+	outPoints := make([]wire.OutPoint, len(selected))
+	for i, candidate := range selected {
+		outPoints[i] = candidate.outPoint
+	}
+	return outPoints
+}
+
+// selectionResult creates the result for a fallback algorithm, which adds a change output if the
+// excess is large enough.
+func selectionResult(
+	algorithm CoinSelectionAlgorithm,
+	selected []*selectionCandidate,
+	params *coinSelectionParams,
+) *coinSelectionResult {
+	var sum btcutil.Amount
+	for _, candidate := range selected {
+		sum += candidate.effectiveValue
+	}
+	change := sum-params.changeTarget >= params.minChange
+	return &coinSelectionResult{
+		algorithm: algorithm,
+		outPoints: candidateOutPoints(selected),
+		change:    change,
+		waste:     waste(selected, params, change),
+	}
+}
+
+// branchAndBound searches for the input set without change with the least waste, whose effective
+// value is between the changeless target and the changeless target plus the cost of change. This is
+// a depth-first search over the candidates sorted by descending effective value, following the
+// implementation in Bitcoin Core. Returns nil if no such set was found.
+func branchAndBound(
+	candidates []*selectionCandidate, params *coinSelectionParams) *coinSelectionResult {
+	target := params.changelessTarget
+	var currentAvailable btcutil.Amount
+	for _, candidate := range candidates {
+		currentAvailable += candidate.effectiveValue
+	}
+	if currentAvailable < target {
+		return nil
+	}
+	isFeeRateHigh := params.feePerKb > params.longTermFeePerKb
+
+	var currentValue, currentWaste btcutil.Amount
+	bestWaste := btcutil.Amount(math.MaxInt64)
+	var currentSelection, bestSelection []int
+	index := 0
+	for try := 0; try < bnbMaxTries; try, index = try+1, index+1 {
+		backtrack := false
+		switch {
+		case currentValue+currentAvailable < target ||
+			currentValue > target+params.costOfChange ||
+			(currentWaste > bestWaste && isFeeRateHigh):
+			// Cannot reach the target, exceeds the target too much, or wastes more than the best
+			// solution found so far (further inputs only add waste if the fee rate is high).
+			backtrack = true
+		case currentValue >= target:
+			excessWaste := currentWaste + currentValue - target
+			if excessWaste <= bestWaste {
+				bestSelection = append([]int{}, currentSelection...)
+				bestWaste = excessWaste
+			}
+			backtrack = true
+		}
+
+		if backtrack {
+			if len(currentSelection) == 0 {
+				// All branches have been explored.
+				break
+			}
+			last := currentSelection[len(currentSelection)-1]
+			// Add back the omitted candidates before exploring the branch omitting the last
+			// included candidate.
+			for index--; index > last; index-- {
+				currentAvailable += candidates[index].effectiveValue
+			}
+			currentValue -= candidates[index].effectiveValue
+			currentWaste -= candidates[index].fee - candidates[index].longTermFee
+			currentSelection = currentSelection[:len(currentSelection)-1]
+			continue
+		}
+
+		candidate := candidates[index]
+		currentAvailable -= candidate.effectiveValue
+		// Skip the inclusion branch if the previous candidate is equivalent and was omitted, as this
+		// branch was already explored.
+		if len(currentSelection) == 0 ||
+			index-1 == currentSelection[len(currentSelection)-1] ||
+			candidate.effectiveValue != candidates[index-1].effectiveValue ||
+			candidate.fee != candidates[index-1].fee {
+			currentSelection = append(currentSelection, index)
+			currentValue += candidate.effectiveValue
+			currentWaste += candidate.fee - candidate.longTermFee
+		}
+	}
+	if bestSelection == nil {
+		return nil
+	}
+	selected := make([]*selectionCandidate, len(bestSelection))
+	for i, index := range bestSelection {
+		selected[i] = candidates[index]
+	}
+	return &coinSelectionResult{
+		algorithm: CoinSelectionBranchAndBound,
+		outPoints: candidateOutPoints(selected),
+		change:    false,
+		waste:     bestWaste,
+	}
+}
+
+// approximateBestSubset randomly tries subsets of the candidates (sorted by descending effective
+// value) to find the one with the smallest effective value which reaches the target.
+func approximateBestSubset(
+	candidates []*selectionCandidate,
+	totalLower btcutil.Amount,
+	target btcutil.Amount,
+	rand *mrand.Rand,
+) ([]bool, btcutil.Amount) {
+	best := make([]bool, len(candidates))
+	for i := range best {
+		best[i] = true
+	}
+	bestValue := totalLower
+	for iteration := 0; iteration < knapsackIterations && bestValue != target; iteration++ {
+		included := make([]bool, len(candidates))
+		var total btcutil.Amount
+		reachedTarget := false
+		for pass := 0; pass < 2 && !reachedTarget; pass++ {
+			for i, candidate := range candidates {
+				// The first pass randomly includes candidates, the second pass includes all
+				// remaining ones until the target is reached.
+				include := !included[i]
+				if pass == 0 {
+					include = rand.Intn(2) == 0
+				}
+				if !include {
+					continue
+				}
+				total += candidate.effectiveValue
+				included[i] = true
+				if total >= target {
+					reachedTarget = true
+					if total < bestValue {
+						bestValue = total
+						copy(best, included)
+					}
+					total -= candidate.effectiveValue
+					included[i] = false
+				}
+			}
+		}
+	}
+	return best, bestValue
+}
+
+// knapsack selects the smallest single candidate larger than the target, or the subset of the
+// smaller candidates with the smallest excess over the target, whichever is smaller, following the
+// implementation in Bitcoin Core. Returns nil if the target cannot be reached.
+func knapsack(
+	candidates []*selectionCandidate,
+	params *coinSelectionParams,
+	rand *mrand.Rand,
+) *coinSelectionResult {
+	target := params.changeTarget
+	var applicable []*selectionCandidate
+	var lowestLarger *selectionCandidate
+	var totalLower btcutil.Amount
+	for _, candidate := range candidates {
+		switch {
+		case candidate.effectiveValue == target:
+			return selectionResult(
+				CoinSelectionKnapsack, []*selectionCandidate{candidate}, params)
+		case candidate.effectiveValue < target+params.minChange:
+			applicable = append(applicable, candidate)
+			totalLower += candidate.effectiveValue
+		case lowestLarger == nil || candidate.effectiveValue < lowestLarger.effectiveValue:
+			lowestLarger = candidate
+		}
+	}
+	if totalLower == target {
+		return selectionResult(CoinSelectionKnapsack, applicable, params)
+	}
+	if totalLower < target {
+		if lowestLarger == nil {
+			return nil
+		}
+		return selectionResult(
+			CoinSelectionKnapsack, []*selectionCandidate{lowestLarger}, params)
+	}
+
+	best, bestValue := approximateBestSubset(applicable, totalLower, target, rand)
+	if bestValue != target && totalLower >= target+params.minChange {
+		best, bestValue = approximateBestSubset(
+			applicable, totalLower, target+params.minChange, rand)
+	}
+	// Prefer the single larger candidate if the best subset would leave dust change, or if the
+	// single candidate is smaller.
+	if lowestLarger != nil &&
+		((bestValue != target && bestValue < target+params.minChange) ||
+			lowestLarger.effectiveValue <= bestValue) {
+		return selectionResult(
+			CoinSelectionKnapsack, []*selectionCandidate{lowestLarger}, params)
+	}
+	selected := []*selectionCandidate{}
+	for i, included := range best {
+		if included {
+			selected = append(selected, applicable[i])
+		}
+	}
+	return selectionResult(CoinSelectionKnapsack, selected, params)
+}
+
+// singleRandomDraw selects random candidates until the target plus the minimum change is reached.
+// Returns nil if the target cannot be reached.
+func singleRandomDraw(
+	candidates []*selectionCandidate,
+	params *coinSelectionParams,
+	rand *mrand.Rand,
+) *coinSelectionResult {
+	shuffled := append([]*selectionCandidate{}, candidates...)
+	rand.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+	var sum btcutil.Amount
+	for i, candidate := range shuffled {
+		sum += candidate.effectiveValue
+		if sum >= params.changeTarget+params.minChange {
+			return selectionResult(CoinSelectionSingleRandomDraw, shuffled[:i+1], params)
+		}
+	}
+	return nil
+}
+
+// selectCoins runs branch-and-bound, knapsack and single-random-draw coin selection and returns the
+// result with the least waste. On equal waste, the algorithms are preferred in this order.
+func selectCoins(
+	spendableOutputs map[wire.OutPoint]UTXO,
+	params *coinSelectionParams,
+	rand *mrand.Rand,
+) (*coinSelectionResult, error) {
+	candidates := newSelectionCandidates(spendableOutputs, params)
+	var best *coinSelectionResult
+	for _, result := range []*coinSelectionResult{
+		branchAndBound(candidates, params),
+		knapsack(candidates, params, rand),
+		singleRandomDraw(candidates, params, rand),
+	} {
+		if result != nil && (best == nil || result.waste < best.waste) {
+			best = result
+		}
+	}
+	if best == nil {
+		return nil, errp.WithStack(errors.ErrInsufficientFunds)
+	}
+	return best, nil
+}
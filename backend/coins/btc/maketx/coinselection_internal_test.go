@@ -0,0 +1,126 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maketx
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+// testCandidates creates candidates with the given effective values, sorted descending as by
+// newSelectionCandidates(). Spending each candidate costs `fee` now and `longTermFee` later.
+func testCandidates(fee, longTermFee btcutil.Amount, effectiveValues ...btcutil.Amount) []*selectionCandidate {
+	candidates := make([]*selectionCandidate, len(effectiveValues))
+	for i, effectiveValue := range effectiveValues {
+		candidates[i] = &selectionCandidate{
+			outPoint:       wire.OutPoint{Index: uint32(i)},
+			value:          effectiveValue + fee,
+			effectiveValue: effectiveValue,
+			fee:            fee,
+			longTermFee:    longTermFee,
+		}
+	}
+	return candidates
+}
+
+func testParams(target, costOfChange, minChange btcutil.Amount) *coinSelectionParams {
+	return &coinSelectionParams{
+		feePerKb:         2000,
+		longTermFeePerKb: 1000,
+		changelessTarget: target,
+		changeTarget:     target + 10,
+		costOfChange:     costOfChange,
+		minChange:        minChange,
+	}
+}
+
+func indices(outPoints []wire.OutPoint) []uint32 {
+	result := make([]uint32, len(outPoints))
+	for i, outPoint := range outPoints {
+		result[i] = outPoint.Index
+	}
+	return result
+}
+
+func TestBranchAndBound(t *testing.T) {
+	candidates := testCandidates(2, 1, 10000, 7000, 5000, 3000, 2000, 1000)
+
+	// Exact match.
+	result := branchAndBound(candidates, testParams(4000, 50, 100))
+	require.NotNil(t, result)
+	require.Equal(t, CoinSelectionBranchAndBound, result.algorithm)
+	require.False(t, result.change)
+	require.Equal(t, []uint32{3, 5}, indices(result.outPoints))
+	require.Equal(t, btcutil.Amount(2), result.waste)
+
+	// Within the cost of change: the excess counts as waste.
+	result = branchAndBound(candidates, testParams(3990, 50, 100))
+	require.NotNil(t, result)
+	require.Equal(t, []uint32{3, 5}, indices(result.outPoints))
+	require.Equal(t, btcutil.Amount(2+10), result.waste)
+
+	// As the fee rate is higher than the long-term fee rate, fewer inputs are preferred.
+	result = branchAndBound(candidates, testParams(10000, 50, 100))
+	require.NotNil(t, result)
+	require.Equal(t, []uint32{0}, indices(result.outPoints))
+	require.Equal(t, btcutil.Amount(1), result.waste)
+
+	// No combination within the cost of change.
+	require.Nil(t, branchAndBound(candidates, testParams(10100, 50, 100)))
+	// Not enough funds.
+	require.Nil(t, branchAndBound(candidates, testParams(28001, 50, 100)))
+}
+
+func TestKnapsack(t *testing.T) {
+	candidates := testCandidates(2, 1, 10000, 7000, 5000, 3000, 1500, 700)
+
+	// The smallest single coin larger than the target.
+	result := knapsack(candidates, testParams(6500, 50, 100), rand.New(rand.NewSource(1)))
+	require.NotNil(t, result)
+	require.Equal(t, CoinSelectionKnapsack, result.algorithm)
+	require.Equal(t, []uint32{1}, indices(result.outPoints))
+	require.True(t, result.change)
+	require.Equal(t, btcutil.Amount(1+50), result.waste)
+
+	// The subset of smaller coins with the smallest excess.
+	result = knapsack(candidates, testParams(7790, 50, 100), rand.New(rand.NewSource(1)))
+	require.NotNil(t, result)
+	require.ElementsMatch(t, []uint32{2, 3}, indices(result.outPoints))
+	require.True(t, result.change)
+
+	// Not enough funds.
+	require.Nil(t, knapsack(candidates, testParams(27300, 50, 100), rand.New(rand.NewSource(1))))
+}
+
+func TestSingleRandomDraw(t *testing.T) {
+	candidates := testCandidates(2, 1, 10000, 7000, 5000, 3000, 2000, 1000)
+
+	result := singleRandomDraw(candidates, testParams(12000, 50, 100), rand.New(rand.NewSource(1)))
+	require.NotNil(t, result)
+	require.Equal(t, CoinSelectionSingleRandomDraw, result.algorithm)
+	require.True(t, result.change)
+	var sum btcutil.Amount
+	for _, index := range indices(result.outPoints) {
+		sum += candidates[index].effectiveValue
+	}
+	require.GreaterOrEqual(t, sum, btcutil.Amount(12000+10+100))
+
+	// Not enough funds for the change.
+	require.Nil(t, singleRandomDraw(candidates, testParams(27900, 50, 100), rand.New(rand.NewSource(1))))
+}
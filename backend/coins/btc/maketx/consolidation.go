@@ -0,0 +1,149 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maketx
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/errors"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/addresses"
+	coinpkg "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/sirupsen/logrus"
+)
+
+// MaxConsolidationInputs is the maximum number of coins merged by one consolidation transaction,
+// which keeps the transaction well below the standard transaction weight limit.
+const MaxConsolidationInputs = 500
+
+// ConsolidationSavings estimates how much is saved by spending the consolidated output in a future
+// transaction instead of the individual coins.
+type ConsolidationSavings struct {
+	// VSize is the virtual size saved in the future transaction.
+	VSize int
+	// FutureFee is the fee saved in the future transaction at the future fee rate.
+	FutureFee btcutil.Amount
+	// Net is FutureFee minus the fee paid by the consolidation transaction. It is negative if the
+	// consolidation costs more than it saves.
+	Net btcutil.Amount
+}
+
+// NewConsolidationTx creates a transaction merging the given coins into a single output to
+// `outputAddress`, which must be an unused address of the wallet.
+//
+// Coins which cost more to spend at `feePerKb` than they are worth are left out. If there are more
+// than MaxConsolidationInputs coins, the ones with the smallest values are merged. The estimated
+// savings of spending the consolidated output at `futureFeePerKb` instead of the individual coins
+// are returned in the ConsolidationSavings field of the proposal.
+func NewConsolidationTx(
+	coin coinpkg.Coin,
+	spendableOutputs map[wire.OutPoint]UTXO,
+	feePerKb btcutil.Amount,
+	futureFeePerKb btcutil.Amount,
+	outputAddress *addresses.AccountAddress,
+	log *logrus.Entry,
+) (*TxProposal, error) {
+	isSegwitTx := hasWitness(spendableOutputs)
+	selectedOutPoints := []wire.OutPoint{}
+	for outPoint, utxo := range spendableOutputs {
+		inputFee := ceilFee(feePerKb, inputVSize(utxo.Address.AccountConfiguration, isSegwitTx))
+		if btcutil.Amount(utxo.TxOut.Value) <= inputFee {
+			continue
+		}
+		selectedOutPoints = append(selectedOutPoints, outPoint)
+	}
+	sort.Slice(selectedOutPoints, func(i, j int) bool {
+		valueI := spendableOutputs[selectedOutPoints[i]].TxOut.Value
+		valueJ := spendableOutputs[selectedOutPoints[j]].TxOut.Value
+		if valueI != valueJ {
+			return valueI < valueJ
+		}
+		// Sort deterministically.
+		outPointI, outPointJ := selectedOutPoints[i], selectedOutPoints[j]
+		if outPointI.Hash != outPointJ.Hash {
+			return bytes.Compare(outPointI.Hash[:], outPointJ.Hash[:]) < 0
+		}
+		return outPointI.Index < outPointJ.Index
+	})
+	if len(selectedOutPoints) > MaxConsolidationInputs {
+		selectedOutPoints = selectedOutPoints[:MaxConsolidationInputs]
+	}
+	if len(selectedOutPoints) < 2 {
+		return nil, errp.New("At least two coins are needed for a consolidation")
+	}
+
+	previousOutputs := make(map[wire.OutPoint]UTXO, len(selectedOutPoints))
+	inputs := make([]*wire.TxIn, len(selectedOutPoints))
+	var inputsSum btcutil.Amount
+	for i, outPoint := range selectedOutPoints {
+		previousOutputs[outPoint] = spendableOutputs[outPoint]
+		inputs[i] = wire.NewTxIn(&outPoint, nil, nil)
+		inputsSum += btcutil.Amount(spendableOutputs[outPoint].TxOut.Value)
+	}
+
+	pkScript := outputAddress.PubkeyScript()
+	txSize := estimateTxSize(
+		toInputConfigurations(spendableOutputs, selectedOutPoints),
+		[]int{len(pkScript)},
+		0)
+	fee := feeForSerializeSize(feePerKb, txSize, log)
+	outputAmount := inputsSum - fee
+	if outputAmount <= 0 || isDustAmount(
+		outputAmount, len(pkScript), outputAddress.AccountConfiguration, feePerKb) {
+		return nil, errp.WithStack(errors.ErrInsufficientFunds)
+	}
+
+	unsignedTransaction := &wire.MsgTx{
+		Version:  wire.TxVersion,
+		TxIn:     inputs,
+		TxOut:    []*wire.TxOut{wire.NewTxOut(int64(outputAmount), pkScript)},
+		LockTime: 0,
+	}
+	setRBF(coin, unsignedTransaction)
+
+	// A future transaction spending the coins individually has the same size as the consolidation
+	// transaction, assuming it has one output of the same type.
+	consolidatedTxSize := estimateTxSize(
+		[]*signing.Configuration{outputAddress.AccountConfiguration},
+		[]int{len(pkScript)},
+		0)
+	futureFee := feeForSerializeSize(futureFeePerKb, txSize, log) -
+		feeForSerializeSize(futureFeePerKb, consolidatedTxSize, log)
+	savings := &ConsolidationSavings{
+		VSize:     txSize - consolidatedTxSize,
+		FutureFee: futureFee,
+		Net:       futureFee - fee,
+	}
+	log.WithFields(logrus.Fields{
+		"inputs":    len(selectedOutPoints),
+		"fee":       fee,
+		"futureFee": futureFee,
+	}).Debug("Preparing consolidation transaction")
+
+	return &TxProposal{
+		Coin:                 coin,
+		Amount:               outputAmount,
+		Fee:                  fee,
+		Transaction:          unsignedTransaction,
+		ChangeAddress:        outputAddress,
+		PreviousOutputs:      previousOutputs,
+		OutIndex:             0,
+		ConsolidationSavings: savings,
+	}, nil
+}
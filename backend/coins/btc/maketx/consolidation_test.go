@@ -0,0 +1,88 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maketx_test
+
+import (
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/errors"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/maketx"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/wire"
+)
+
+func (s *newTxSuite) TestNewConsolidationTx() {
+	// The first coin costs more to spend at 10 sat/vbyte than it is worth.
+	utxo := s.buildUTXO(1000, 5000, 6000, 7000)
+	txProposal, err := maketx.NewConsolidationTx(s.coin, utxo, 10000, 50000, s.changeAddress, s.log)
+	s.Require().NoError(err)
+
+	pkScriptLen := len(s.changeAddress.PubkeyScript())
+	txSize := maketx.TstEstimateTxSize(
+		[]*signing.Configuration{s.inputConfiguration, s.inputConfiguration, s.inputConfiguration},
+		[]int{pkScriptLen}, 0)
+	s.Require().Equal(522-changeOutputSize, txSize)
+	expectedFee := btcutil.Amount(10 * txSize)
+	s.Require().Equal(expectedFee, txProposal.Fee)
+	s.Require().Equal(18000-expectedFee, txProposal.Amount)
+	s.Require().Equal(s.changeAddress, txProposal.ChangeAddress)
+
+	tx := txProposal.Transaction
+	s.Require().Len(tx.TxIn, 3)
+	s.Require().Equal(s.outpoint(1), tx.TxIn[0].PreviousOutPoint)
+	s.Require().Equal(s.outpoint(2), tx.TxIn[1].PreviousOutPoint)
+	s.Require().Equal(s.outpoint(3), tx.TxIn[2].PreviousOutPoint)
+	s.Require().Len(txProposal.PreviousOutputs, 3)
+	s.Require().Len(tx.TxOut, 1)
+	s.Require().Equal(s.changeAddress.PubkeyScript(), tx.TxOut[0].PkScript)
+
+	consolidatedTxSize := maketx.TstEstimateTxSize(
+		[]*signing.Configuration{s.inputConfiguration}, []int{pkScriptLen}, 0)
+	futureFee := btcutil.Amount(50 * (txSize - consolidatedTxSize))
+	s.Require().Equal(
+		&maketx.ConsolidationSavings{
+			VSize:     txSize - consolidatedTxSize,
+			FutureFee: futureFee,
+			Net:       futureFee - expectedFee,
+		},
+		txProposal.ConsolidationSavings)
+}
+
+func (s *newTxSuite) TestNewConsolidationTxMaxInputs() {
+	values := make([]int64, maketx.MaxConsolidationInputs+10)
+	for i := range values {
+		values[i] = int64(100000 - i)
+	}
+	txProposal, err := maketx.NewConsolidationTx(s.coin, s.buildUTXO(values...), 1000, 10000, s.changeAddress, s.log)
+	s.Require().NoError(err)
+	s.Require().Len(txProposal.Transaction.TxIn, maketx.MaxConsolidationInputs)
+	// The coins with the smallest values are merged.
+	for _, txIn := range txProposal.Transaction.TxIn {
+		s.Require().GreaterOrEqual(txIn.PreviousOutPoint.Index, uint32(10))
+	}
+}
+
+func (s *newTxSuite) TestNewConsolidationTxErrors() {
+	// Only one coin is worth spending.
+	_, err := maketx.NewConsolidationTx(s.coin, s.buildUTXO(1000, 100000), 10000, 50000, s.changeAddress, s.log)
+	s.Require().Error(err)
+
+	_, err = maketx.NewConsolidationTx(s.coin, map[wire.OutPoint]maketx.UTXO{}, 10000, 50000, s.changeAddress, s.log)
+	s.Require().Error(err)
+
+	// The merged output would be dust.
+	_, err = maketx.NewConsolidationTx(s.coin, s.buildUTXO(1600, 1600), 10000, 50000, s.changeAddress, s.log)
+	s.Require().Equal(errors.ErrInsufficientFunds, errp.Cause(err))
+}
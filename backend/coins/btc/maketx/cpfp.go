@@ -0,0 +1,124 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maketx
+
+import (
+	"sort"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/errors"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/addresses"
+	coinpkg "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/mempool"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/sirupsen/logrus"
+)
+
+// ParentTx describes an unconfirmed transaction to be accelerated with NewCPFPTx().
+type ParentTx struct {
+	Transaction *wire.MsgTx
+	// Fee is the fee paid by the transaction.
+	Fee btcutil.Amount
+}
+
+// VSize returns the virtual size of the parent transaction.
+func (parentTx *ParentTx) VSize() int64 {
+	return mempool.GetTxVirtualSize(btcutil.NewTx(parentTx.Transaction))
+}
+
+// NewCPFPTx creates a transaction accelerating an unconfirmed parent transaction using
+// child-pays-for-parent (CPFP). The child spends all `parentOutputs`, which must be unspent outputs
+// of the parent belonging to the wallet, to a single output to `changeAddress`.
+//
+// The child fee is chosen such that the package (parent and child) pays `packageFeePerKb`. The
+// resulting package fee rate is returned in the PackageFeeRatePerKb field of the proposal.
+func NewCPFPTx(
+	coin coinpkg.Coin,
+	parentTx *ParentTx,
+	parentOutputs map[wire.OutPoint]UTXO,
+	packageFeePerKb btcutil.Amount,
+	changeAddress *addresses.AccountAddress,
+	log *logrus.Entry,
+) (*TxProposal, error) {
+	if len(parentOutputs) == 0 {
+		return nil, errp.New("The parent transaction has no spendable outputs")
+	}
+	parentTxHash := parentTx.Transaction.TxHash()
+	parentVSize := parentTx.VSize()
+	if parentTx.Fee*1000 >= packageFeePerKb*btcutil.Amount(parentVSize) {
+		// The parent already pays the target fee rate.
+		return nil, errp.WithStack(errors.ErrFeeTooLow)
+	}
+
+	selectedOutPoints := []wire.OutPoint{}
+	var inputsSum btcutil.Amount
+	for outPoint, utxo := range parentOutputs {
+		if outPoint.Hash != parentTxHash {
+			return nil, errp.New("The child must only spend outputs of the parent transaction")
+		}
+		selectedOutPoints = append(selectedOutPoints, outPoint)
+		inputsSum += btcutil.Amount(utxo.TxOut.Value)
+	}
+	sort.Slice(selectedOutPoints, func(i, j int) bool {
+		return selectedOutPoints[i].Index < selectedOutPoints[j].Index
+	})
+
+	changePKScript := changeAddress.PubkeyScript()
+	childVSize := estimateTxSize(
+		toInputConfigurations(parentOutputs, selectedOutPoints),
+		[]int{len(changePKScript)},
+		0)
+	packageFee := feeForSerializeSize(packageFeePerKb, int(parentVSize)+childVSize, log)
+	// As the parent pays less than the target fee rate, the child pays more than the target fee
+	// rate for itself.
+	childFee := packageFee - parentTx.Fee
+	outputAmount := inputsSum - childFee
+	if outputAmount <= 0 || isDustAmount(
+		outputAmount, len(changePKScript), changeAddress.AccountConfiguration, packageFeePerKb) {
+		return nil, errp.WithStack(errors.ErrInsufficientFunds)
+	}
+
+	inputs := make([]*wire.TxIn, len(selectedOutPoints))
+	for i, outPoint := range selectedOutPoints {
+		inputs[i] = wire.NewTxIn(&outPoint, nil, nil)
+	}
+	unsignedTransaction := &wire.MsgTx{
+		Version:  wire.TxVersion,
+		TxIn:     inputs,
+		TxOut:    []*wire.TxOut{wire.NewTxOut(int64(outputAmount), changePKScript)},
+		LockTime: 0,
+	}
+	setRBF(coin, unsignedTransaction)
+
+	packageFeeRatePerKb := (parentTx.Fee + childFee) * 1000 /
+		btcutil.Amount(int(parentVSize)+childVSize)
+	log.WithFields(logrus.Fields{
+		"fee":                 childFee,
+		"parentFee":           parentTx.Fee,
+		"packageFeeRatePerKb": packageFeeRatePerKb,
+	}).Debug("Preparing CPFP transaction")
+
+	return &TxProposal{
+		Coin:                coin,
+		Amount:              outputAmount,
+		Fee:                 childFee,
+		Transaction:         unsignedTransaction,
+		ChangeAddress:       changeAddress,
+		PreviousOutputs:     parentOutputs,
+		OutIndex:            0,
+		PackageFeeRatePerKb: packageFeeRatePerKb,
+	}, nil
+}
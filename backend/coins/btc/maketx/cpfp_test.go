@@ -0,0 +1,83 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maketx_test
+
+import (
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/errors"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/maketx"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// parentTx creates an incoming transaction paying the given fee, with one output to us and one
+// output to someone else.
+func (s *newTxSuite) parentTx(fee btcutil.Amount, amount int64) (
+	*maketx.ParentTx, map[wire.OutPoint]maketx.UTXO) {
+	tx := wire.NewMsgTx(wire.TxVersion)
+	tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: chainhash.HashH([]byte(`foreign-tx`))}, make([]byte, 107), nil))
+	tx.AddTxOut(wire.NewTxOut(5000000, s.outputPkScript))
+	tx.AddTxOut(wire.NewTxOut(amount, s.someAddresses[0].PubkeyScript()))
+	parentOutputs := map[wire.OutPoint]maketx.UTXO{
+		{Hash: tx.TxHash(), Index: 1}: {
+			TxOut:   tx.TxOut[1],
+			Address: s.someAddresses[0],
+		},
+	}
+	return &maketx.ParentTx{Transaction: tx, Fee: fee}, parentOutputs
+}
+
+func (s *newTxSuite) TestNewCPFPTx() {
+	parentTx, parentOutputs := s.parentTx(100, 100000)
+	parentVSize := parentTx.VSize()
+	s.Require().Equal(int64(226), parentVSize)
+
+	const packageFeePerKb = 10000 // 10 sat/vbyte
+	txProposal, err := maketx.NewCPFPTx(
+		s.coin, parentTx, parentOutputs, packageFeePerKb, s.changeAddress, s.log)
+	s.Require().NoError(err)
+
+	childVSize := maketx.TstEstimateTxSize(
+		[]*signing.Configuration{s.inputConfiguration}, []int{len(s.changeAddress.PubkeyScript())}, 0)
+	expectedFee := btcutil.Amount(10*(int(parentVSize)+childVSize) - 100)
+	s.Require().Equal(expectedFee, txProposal.Fee)
+	s.Require().Equal(100000-expectedFee, txProposal.Amount)
+	s.Require().Equal(btcutil.Amount(packageFeePerKb), txProposal.PackageFeeRatePerKb)
+	s.Require().Equal(s.changeAddress, txProposal.ChangeAddress)
+
+	tx := txProposal.Transaction
+	s.Require().Len(tx.TxIn, 1)
+	s.Require().Equal(wire.OutPoint{Hash: parentTx.Transaction.TxHash(), Index: 1}, tx.TxIn[0].PreviousOutPoint)
+	s.Require().Len(tx.TxOut, 1)
+	s.Require().Equal(int64(100000-expectedFee), tx.TxOut[0].Value)
+	s.Require().Equal(s.changeAddress.PubkeyScript(), tx.TxOut[0].PkScript)
+}
+
+func (s *newTxSuite) TestNewCPFPTxErrors() {
+	parentTx, parentOutputs := s.parentTx(2260, 100000)
+	// The parent already pays 10 sat/vbyte.
+	_, err := maketx.NewCPFPTx(s.coin, parentTx, parentOutputs, 10000, s.changeAddress, s.log)
+	s.Require().Equal(errors.ErrFeeTooLow, errp.Cause(err))
+
+	// The output is too small to pay for the package.
+	parentTx, parentOutputs = s.parentTx(100, 3000)
+	_, err = maketx.NewCPFPTx(s.coin, parentTx, parentOutputs, 10000, s.changeAddress, s.log)
+	s.Require().Equal(errors.ErrInsufficientFunds, errp.Cause(err))
+
+	_, err = maketx.NewCPFPTx(s.coin, parentTx, nil, 10000, s.changeAddress, s.log)
+	s.Require().Error(err)
+}
@@ -15,6 +15,7 @@
 package maketx
 
 import (
+	"bytes"
 	"crypto/rand"
 	"encoding/binary"
 	mrand "math/rand"
@@ -29,6 +30,7 @@ import (
 	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
 	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/mempool"
 	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/sirupsen/logrus"
@@ -46,7 +48,8 @@ func (p PreviousOutputs) FetchPrevOutput(op wire.OutPoint) *wire.TxOut {
 type TxProposal struct {
 	// Coin is the coin this tx was made for.
 	Coin coinpkg.Coin
-	// Amount is the amount that is sent out. The fee is not included and is deducted on top.
+	// Amount is the amount that is sent out, summed over all recipients. The fee is not included
+	// and is deducted on top.
 	Amount btcutil.Amount
 	// Fee is the mining fee used.
 	Fee         btcutil.Amount
@@ -58,8 +61,25 @@ type TxProposal struct {
 	// If not empty, we are sending to a silent payment recipient. The keystore needs access to this
 	// to be able to generate the silent payment output. See BIP-352.
 	SilentPaymentAddress string
-	// OutIndex is the index of the output we send to.
+	// OutIndex is the index of the output we send to. If there are multiple recipients, it is the
+	// output of the first recipient, or the output receiving the remainder in send-all.
 	OutIndex int
+	// ReplacesTxHash is the hash of the unconfirmed transaction replaced by this one (RBF), or nil.
+	ReplacesTxHash *chainhash.Hash
+	// PackageFeeRatePerKb is the effective fee rate of the unconfirmed parent and this transaction
+	// together if this transaction accelerates its parent (CPFP), or zero.
+	PackageFeeRatePerKb btcutil.Amount
+	// CoinSelectionAlgorithm is the algorithm which selected the inputs, or empty if all coins are
+	// spent.
+	CoinSelectionAlgorithm CoinSelectionAlgorithm
+	// Waste is the waste metric of the selected inputs, see NewTx().
+	Waste btcutil.Amount
+	// ConsolidationSavings is the estimated future savings if this transaction consolidates coins,
+	// or nil.
+	ConsolidationSavings *ConsolidationSavings
+	// PayjoinEndpoint is the BIP-78 payjoin endpoint of the receiver, or empty if the transaction is
+	// not a payjoin.
+	PayjoinEndpoint string
 }
 
 // SigHashes computes the hashes cache to speed up per-input sighash computations.
@@ -93,31 +113,6 @@ func (p *byValue) Less(i, j int) bool {
 }
 func (p *byValue) Swap(i, j int) { p.outPoints[i], p.outPoints[j] = p.outPoints[j], p.outPoints[i] }
 
-func coinSelection(
-	minAmount btcutil.Amount,
-	outputs map[wire.OutPoint]UTXO,
-) (btcutil.Amount, []wire.OutPoint, error) {
-	outPoints := []wire.OutPoint{}
-	for outPoint := range outputs {
-		outPoints = append(outPoints, outPoint)
-	}
-	sort.Sort(sort.Reverse(&byValue{outPoints, outputs}))
-	selectedOutPoints := []wire.OutPoint{}
-	outputsSum := btcutil.Amount(0)
-
-	for _, outPoint := range outPoints {
-		if outputsSum >= minAmount {
-			break
-		}
-		selectedOutPoints = append(selectedOutPoints, outPoint)
-		outputsSum += btcutil.Amount(outputs[outPoint].TxOut.Value)
-	}
-	if outputsSum < minAmount {
-		return 0, nil, errp.WithStack(errors.ErrInsufficientFunds)
-	}
-	return outputsSum, selectedOutPoints, nil
-}
-
 // toInputConfigurations converts selected inputs to input configurations.
 // Currently, it just repeats one inputConfiguration, as all inputs are of the same type.
 // When mixing input types in a transaction, this function needs to be extended.
@@ -140,13 +135,53 @@ func setRBF(coin coinpkg.Coin, tx *wire.MsgTx) {
 			coin.Code() == coinpkg.CodeRBTC {
 			// Enable RBF
 			// https://github.com/bitcoin/bips/blob/master/bip-0125.mediawiki#summary
-			// Locktime is also enabled by this (https://en.bitcoin.it/wiki/NLockTime), but we keep
-			// the locktime at 0, which has no effect.
+			// Locktime is also enabled by this (https://en.bitcoin.it/wiki/NLockTime), see
+			// setLockTime().
 			txIn.Sequence = wire.MaxTxInSequenceNum - 2
 		}
 	}
 }
 
+// antiFeeSnipingLockTime returns the locktime discouraging fee sniping, i.e. miners reorging the
+// chain to collect the fees of past blocks, like Bitcoin Core does: the transaction can only be
+// mined in the block following the current tip. To not distinguish transactions which were delayed
+// before being broadcast (e.g. by privacy software or when signing takes long), the locktime is set
+// further back by up to 100 blocks with a 10% probability. See BIP-326.
+//
+// The locktime is 0 if the tip height is unknown.
+func antiFeeSnipingLockTime(tipHeight int, rand *mrand.Rand) uint32 {
+	if tipHeight <= 0 {
+		return 0
+	}
+	lockTime := tipHeight
+	if rand.Intn(10) == 0 {
+		lockTime -= rand.Intn(100)
+		if lockTime < 0 {
+			lockTime = 0
+		}
+	}
+	return uint32(lockTime)
+}
+
+// setLockTime sets the locktime of the transaction. If lockTime is 0, the locktime discouraging fee
+// sniping is used, see antiFeeSnipingLockTime(). The locktime is only enforced if at least one
+// input is not final, so final inputs (Litecoin, which does not have RBF) are changed to the
+// highest non-final sequence number. Must be called after setRBF().
+func setLockTime(tx *wire.MsgTx, lockTime uint32, tipHeight int, rand *mrand.Rand) {
+	if lockTime == 0 {
+		lockTime = antiFeeSnipingLockTime(tipHeight, rand)
+	}
+	tx.LockTime = lockTime
+	if tx.LockTime == 0 {
+		return
+	}
+	for _, txIn := range tx.TxIn {
+		if txIn.Sequence == wire.MaxTxInSequenceNum {
+			txIn.Sequence = wire.MaxTxInSequenceNum - 1
+		}
+	}
+}
+
 // OutputInfo carries info for the transaction output script sending funds to the recipient.
 type OutputInfo struct {
 	silentPaymentAddress string
@@ -171,153 +206,306 @@ func NewOutputInfo(pkScript []byte) *OutputInfo {
 	return &OutputInfo{pkScript: pkScript}
 }
 
-// NewTxSpendAll creates a transaction which spends all available unspent outputs.
+// ScriptType returns the script type of the output in terms of the script types of our accounts.
+// P2SH outputs are assumed to be P2WPKH-P2SH, as the redeem script is not known. Silent payment
+// outputs are P2TR. Returns false if the output does not match any of our script types.
+func (o *OutputInfo) ScriptType() (signing.ScriptType, bool) {
+	if o.silentPaymentAddress != "" {
+		return signing.ScriptTypeP2TR, true
+	}
+	switch txscript.GetScriptClass(o.pkScript) {
+	case txscript.PubKeyHashTy:
+		return signing.ScriptTypeP2PKH, true
+	case txscript.ScriptHashTy:
+		return signing.ScriptTypeP2WPKHP2SH, true
+	case txscript.WitnessV0PubKeyHashTy:
+		return signing.ScriptTypeP2WPKH, true
+	case txscript.WitnessV0ScriptHashTy:
+		return signing.ScriptTypeP2WSH, true
+	case txscript.WitnessV1TaprootTy:
+		return signing.ScriptTypeP2TR, true
+	default:
+		return "", false
+	}
+}
+
+// Recipient is an output of a new transaction paying a fixed amount.
+type Recipient struct {
+	OutputInfo *OutputInfo
+	Amount     btcutil.Amount
+}
+
+// newRecipientOutputs creates the outputs paying the recipients. Silent payment recipients are only
+// supported if there is only one recipient, in which case its address is returned.
+func newRecipientOutputs(recipients []*Recipient) ([]*wire.TxOut, string, error) {
+	outputs := make([]*wire.TxOut, len(recipients))
+	silentPaymentAddress := ""
+	for i, recipient := range recipients {
+		if recipient.Amount <= 0 {
+			panic("amount must be positive")
+		}
+		if recipient.OutputInfo.silentPaymentAddress != "" {
+			if len(recipients) > 1 {
+				return nil, "", errp.New("Silent payments are not supported with multiple recipients")
+			}
+			silentPaymentAddress = recipient.OutputInfo.silentPaymentAddress
+		}
+		outputs[i] = wire.NewTxOut(int64(recipient.Amount), recipient.OutputInfo.pkScript)
+	}
+	return outputs, silentPaymentAddress, nil
+}
+
+// pkScriptLens returns the sizes of the pkScripts of the given outputs.
+func pkScriptLens(outputInfos ...*OutputInfo) []int {
+	result := make([]int, len(outputInfos))
+	for i, outputInfo := range outputInfos {
+		result[i] = outputInfo.pkScriptLen()
+	}
+	return result
+}
+
+// NewTxSpendAll creates a transaction which spends all available unspent outputs. The recipients
+// receive fixed amounts and the remainder goes to `outputInfo`.
+//
+// lockTime is the absolute locktime of the transaction, a block height if below
+// `txscript.LockTimeThreshold`, a Unix timestamp otherwise. If it is 0, the locktime is derived from
+// the current block height `tipHeight`, see antiFeeSnipingLockTime(). seed seeds the randomness of
+// the input/output order and the locktime, use `SecureSeed()`.
 func NewTxSpendAll(
 	coin coinpkg.Coin,
 	spendableOutputs map[wire.OutPoint]UTXO,
 	outputInfo *OutputInfo,
+	recipients []*Recipient,
 	feePerKb btcutil.Amount,
+	lockTime uint32,
+	tipHeight int,
+	seed int64,
 	log *logrus.Entry,
 ) (*TxProposal, error) {
+	recipientOutputs, _, err := newRecipientOutputs(recipients)
+	if err != nil {
+		return nil, err
+	}
+	if outputInfo.silentPaymentAddress != "" && len(recipients) > 0 {
+		return nil, errp.New("Silent payments are not supported with multiple recipients")
+	}
+	outputInfos := []*OutputInfo{outputInfo}
+	recipientsSum := btcutil.Amount(0)
+	for _, recipient := range recipients {
+		outputInfos = append(outputInfos, recipient.OutputInfo)
+		recipientsSum += recipient.Amount
+	}
+
 	selectedOutPoints := []wire.OutPoint{}
-	inputs := []*wire.TxIn{}
-	outputsSum := btcutil.Amount(0)
-	for outPoint, output := range spendableOutputs {
+	for outPoint := range spendableOutputs {
 		selectedOutPoints = append(selectedOutPoints, outPoint)
-		outputsSum += btcutil.Amount(output.TxOut.Value)
-		inputs = append(inputs, wire.NewTxIn(&outPoint, nil, nil))
+	}
+	// Sort so that the input order only depends on the seed of the shuffle below.
+	sort.Slice(selectedOutPoints, func(i, j int) bool {
+		outPointI, outPointJ := selectedOutPoints[i], selectedOutPoints[j]
+		if outPointI.Hash != outPointJ.Hash {
+			return bytes.Compare(outPointI.Hash[:], outPointJ.Hash[:]) < 0
+		}
+		return outPointI.Index < outPointJ.Index
+	})
+	inputs := make([]*wire.TxIn, len(selectedOutPoints))
+	outputsSum := btcutil.Amount(0)
+	for i, outPoint := range selectedOutPoints {
+		outputsSum += btcutil.Amount(spendableOutputs[outPoint].TxOut.Value)
+		inputs[i] = wire.NewTxIn(&outPoint, nil, nil)
 	}
 	txSize := estimateTxSize(
 		toInputConfigurations(spendableOutputs, selectedOutPoints),
-		outputInfo.pkScriptLen(),
+		pkScriptLens(outputInfos...),
 		0)
 	maxRequiredFee := feeForSerializeSize(feePerKb, txSize, log)
-	if outputsSum < maxRequiredFee {
+	remainder := outputsSum - recipientsSum - maxRequiredFee
+	if remainder <= 0 {
+		return nil, errp.WithStack(errors.ErrInsufficientFunds)
+	}
+	output := wire.NewTxOut(int64(remainder), outputInfo.pkScript)
+	// With fixed amounts paid to the recipients, the remainder can be tiny. A transaction with a dust
+	// output is not relayed.
+	dustCheckOutput := output
+	if outputInfo.silentPaymentAddress != "" {
+		// The pkScript of a silent payment output is only derived when signing. It is a taproot
+		// output.
+		dustCheckOutput = wire.NewTxOut(
+			output.Value, append([]byte{txscript.OP_1, txscript.OP_DATA_32}, make([]byte, 32)...))
+	}
+	if mempool.IsDust(dustCheckOutput, feePerKb) {
 		return nil, errp.WithStack(errors.ErrInsufficientFunds)
 	}
-	output := wire.NewTxOut(int64(outputsSum-maxRequiredFee), outputInfo.pkScript)
 	unsignedTransaction := &wire.MsgTx{
 		Version:  wire.TxVersion,
 		TxIn:     inputs,
-		TxOut:    []*wire.TxOut{output},
+		TxOut:    append([]*wire.TxOut{output}, recipientOutputs...),
 		LockTime: 0,
 	}
 
-	secureRand := mrand.New(mrand.NewSource(secureSeed()))
+	secureRand := mrand.New(mrand.NewSource(seed))
 	shuffleTxInputsAndOutputs(unsignedTransaction, secureRand)
 
 	log.WithField("fee", maxRequiredFee).Debug("Preparing transaction to spend all outputs")
 
+	outIndex := outputIndex(unsignedTransaction, output)
+	if outIndex == -1 {
+		return nil, errp.New("could not identify output")
+	}
+
 	setRBF(coin, unsignedTransaction)
+	setLockTime(unsignedTransaction, lockTime, tipHeight, secureRand)
 	return &TxProposal{
 		Coin:                 coin,
-		Amount:               btcutil.Amount(output.Value),
+		Amount:               btcutil.Amount(output.Value) + recipientsSum,
 		Fee:                  maxRequiredFee,
 		Transaction:          unsignedTransaction,
 		PreviousOutputs:      spendableOutputs,
 		SilentPaymentAddress: outputInfo.silentPaymentAddress,
-		// Only one output in send-all
-		OutIndex: 0,
+		OutIndex:             outIndex,
 	}, nil
 }
 
-// NewTx creates a transaction from a set of unspent outputs, targeting an output value. A subset of
-// the unspent outputs is selected to cover the needed amount.
+// NewTx creates a transaction from a set of unspent outputs, paying the given recipients. A subset
+// of the unspent outputs is selected to cover the needed amount, preferring a selection which needs
+// no change output. The selection with the least waste is chosen, weighing the fee of the inputs at
+// `feePerKb` against the fee to spend them later at `longTermFeePerKb`.
 //
 // changeAddress: a change output to this address is added if needed.
+//
+// lockTime is the absolute locktime of the transaction, a block height if below
+// `txscript.LockTimeThreshold`, a Unix timestamp otherwise. If it is 0, the locktime is derived from
+// the current block height `tipHeight`, see antiFeeSnipingLockTime(). seed seeds the randomness of
+// the coin selection, the input/output order and the locktime, use `SecureSeed()`.
 func NewTx(
 	coin coinpkg.Coin,
 	spendableOutputs map[wire.OutPoint]UTXO,
-	outputInfo *OutputInfo,
-	outputAmount int64,
+	recipients []*Recipient,
 	feePerKb btcutil.Amount,
+	longTermFeePerKb btcutil.Amount,
 	changeAddress *addresses.AccountAddress,
+	lockTime uint32,
+	tipHeight int,
+	seed int64,
 	log *logrus.Entry,
 ) (*TxProposal, error) {
-	output := wire.NewTxOut(outputAmount, outputInfo.pkScript)
-
-	targetAmount := btcutil.Amount(output.Value)
-	if targetAmount <= 0 {
-		panic("amount must be positive")
+	if len(recipients) == 0 {
+		return nil, errp.New("A transaction needs at least one recipient")
 	}
-	outputs := []*wire.TxOut{output}
+	outputs, silentPaymentAddress, err := newRecipientOutputs(recipients)
+	if err != nil {
+		return nil, err
+	}
+	targetAmount := btcutil.Amount(0)
+	outputInfos := make([]*OutputInfo, len(recipients))
+	for i, recipient := range recipients {
+		targetAmount += recipient.Amount
+		outputInfos[i] = recipient.OutputInfo
+	}
+	outputPkScriptLens := pkScriptLens(outputInfos...)
 	changePKScript := changeAddress.PubkeyScript()
 
-	targetFee := btcutil.Amount(0)
-	for {
-		selectedOutputsSum, selectedOutPoints, err := coinSelection(
-			targetAmount+targetFee,
-			spendableOutputs,
-		)
-		if err != nil {
-			return nil, err
-		}
-
-		txSize := estimateTxSize(
-			toInputConfigurations(spendableOutputs, selectedOutPoints),
-			outputInfo.pkScriptLen(),
-			len(changePKScript))
-		maxRequiredFee := feeForSerializeSize(feePerKb, txSize, log)
-		if selectedOutputsSum-targetAmount < maxRequiredFee {
-			targetFee = maxRequiredFee
-			continue
-		}
+	secureRand := mrand.New(mrand.NewSource(seed))
+	selection, err := selectCoins(
+		spendableOutputs,
+		newCoinSelectionParams(
+			targetAmount,
+			outputPkScriptLens,
+			len(changePKScript),
+			changeAddress.AccountConfiguration,
+			hasWitness(spendableOutputs),
+			feePerKb,
+			longTermFeePerKb,
+		),
+		secureRand,
+	)
+	if err != nil {
+		return nil, err
+	}
+	selectedOutPoints := selection.outPoints
 
-		inputs := make([]*wire.TxIn, len(selectedOutPoints))
-		previousOutputs := make(PreviousOutputs, len(selectedOutPoints))
-		for i, outPoint := range selectedOutPoints {
-			inputs[i] = wire.NewTxIn(&outPoint, nil, nil)
-			previousOutputs[outPoint] = spendableOutputs[outPoint]
-		}
-		unsignedTransaction := &wire.MsgTx{
-			Version:  wire.TxVersion,
-			TxIn:     inputs,
-			TxOut:    outputs,
-			LockTime: 0,
-		}
+	inputs := make([]*wire.TxIn, len(selectedOutPoints))
+	previousOutputs := make(PreviousOutputs, len(selectedOutPoints))
+	selectedOutputsSum := btcutil.Amount(0)
+	for i, outPoint := range selectedOutPoints {
+		inputs[i] = wire.NewTxIn(&outPoint, nil, nil)
+		previousOutputs[outPoint] = spendableOutputs[outPoint]
+		selectedOutputsSum += btcutil.Amount(spendableOutputs[outPoint].TxOut.Value)
+	}
+	unsignedTransaction := &wire.MsgTx{
+		Version:  wire.TxVersion,
+		TxIn:     inputs,
+		TxOut:    append([]*wire.TxOut{}, outputs...),
+		LockTime: 0,
+	}
+	inputConfigurations := toInputConfigurations(spendableOutputs, selectedOutPoints)
+
+	// Without change, the excess is paid to the miners.
+	finalFee := selectedOutputsSum - targetAmount
+	hasChange := false
+	if selection.change {
+		maxRequiredFee := feeForSerializeSize(
+			feePerKb,
+			estimateTxSize(inputConfigurations, outputPkScriptLens, len(changePKScript)),
+			log)
 		changeAmount := selectedOutputsSum - targetAmount - maxRequiredFee
-		changeIsDust := isDustAmount(
-			changeAmount, len(changePKScript), changeAddress.AccountConfiguration, feePerKb)
-		finalFee := maxRequiredFee
-		if changeIsDust {
-			log.Info("change is dust")
-			finalFee = selectedOutputsSum - targetAmount
-		}
-		if changeAmount != 0 && !changeIsDust {
+		if changeAmount > 0 && !isDustAmount(
+			changeAmount, len(changePKScript), changeAddress.AccountConfiguration, feePerKb) {
+			finalFee = maxRequiredFee
+			hasChange = true
 			unsignedTransaction.TxOut = append(unsignedTransaction.TxOut,
 				wire.NewTxOut(int64(changeAmount), changePKScript))
 		} else {
-			changeAddress = nil
+			log.Info("change is dust")
 		}
+	}
+	if !hasChange {
+		changeAddress = nil
+		minFee := feeForSerializeSize(
+			feePerKb, estimateTxSize(inputConfigurations, outputPkScriptLens, 0), log)
+		if finalFee < minFee {
+			return nil, errp.WithStack(errors.ErrInsufficientFunds)
+		}
+	}
 
-		secureRand := mrand.New(mrand.NewSource(secureSeed()))
-		shuffleTxInputsAndOutputs(unsignedTransaction, secureRand)
+	shuffleTxInputsAndOutputs(unsignedTransaction, secureRand)
 
-		log.WithField("fee", finalFee).Debug("Preparing transaction")
+	log.WithFields(logrus.Fields{
+		"fee":                    finalFee,
+		"recipients":             len(recipients),
+		"coinSelectionAlgorithm": selection.algorithm,
+		"waste":                  selection.waste,
+	}).Debug("Preparing transaction")
 
-		outIndex := -1
-		for i, txOut := range unsignedTransaction.TxOut {
-			if txOut == output {
-				outIndex = i
-				break
-			}
-		}
-		if outIndex == -1 {
-			return nil, errp.New("could not identify output")
-		}
+	outIndex := outputIndex(unsignedTransaction, outputs[0])
+	if outIndex == -1 {
+		return nil, errp.New("could not identify output")
+	}
 
-		setRBF(coin, unsignedTransaction)
-		return &TxProposal{
-			Coin:                 coin,
-			Amount:               targetAmount,
-			Fee:                  finalFee,
-			Transaction:          unsignedTransaction,
-			ChangeAddress:        changeAddress,
-			PreviousOutputs:      previousOutputs,
-			SilentPaymentAddress: outputInfo.silentPaymentAddress,
-			OutIndex:             outIndex,
-		}, nil
+	setRBF(coin, unsignedTransaction)
+	setLockTime(unsignedTransaction, lockTime, tipHeight, secureRand)
+	return &TxProposal{
+		Coin:                   coin,
+		Amount:                 targetAmount,
+		Fee:                    finalFee,
+		Transaction:            unsignedTransaction,
+		ChangeAddress:          changeAddress,
+		PreviousOutputs:        previousOutputs,
+		SilentPaymentAddress:   silentPaymentAddress,
+		OutIndex:               outIndex,
+		CoinSelectionAlgorithm: selection.algorithm,
+		Waste:                  selection.waste,
+	}, nil
+}
+
+// outputIndex returns the index of the output in the tx, or -1 if it is not found.
+func outputIndex(tx *wire.MsgTx, output *wire.TxOut) int {
+	for i, txOut := range tx.TxOut {
+		if txOut == output {
+			return i
+		}
 	}
+	return -1
 }
 
 // shuffleTxInputsAndOutputs shuffles both the TxIn and TxOut slices of a wire.MsgTx.
@@ -333,8 +521,8 @@ func shuffleTxInputsAndOutputs(tx *wire.MsgTx, secureRand *mrand.Rand) {
 	})
 }
 
-// secureSeed generates a secure seed value.
-func secureSeed() int64 {
+// SecureSeed generates a secure seed value.
+func SecureSeed() int64 {
 	var b [8]byte
 	_, err := rand.Read(b[:])
 	if err != nil {
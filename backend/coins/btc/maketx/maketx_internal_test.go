@@ -58,3 +58,58 @@ func TestShuffleTxInputsAndOutputs(t *testing.T) {
 	require.Equal(t, expectedSortedIns, tx.TxIn, "The transaction inputs were not successfully shuffled.")
 	require.Equal(t, expectedSortedOuts, tx.TxOut, "The transaction outputs were not successfully shuffled.")
 }
+
+func TestAntiFeeSnipingLockTime(t *testing.T) {
+	const tipHeight = 800000
+	require.Equal(t, uint32(0), antiFeeSnipingLockTime(0, rand.New(rand.NewSource(1))))
+	require.Equal(t, uint32(0), antiFeeSnipingLockTime(-1, rand.New(rand.NewSource(1))))
+
+	testRand := rand.New(rand.NewSource(1))
+	atTip := 0
+	for range 1000 {
+		lockTime := antiFeeSnipingLockTime(tipHeight, testRand)
+		require.LessOrEqual(t, lockTime, uint32(tipHeight))
+		require.Greater(t, lockTime, uint32(tipHeight-100))
+		if lockTime == tipHeight {
+			atTip++
+		}
+	}
+	// About 90% of the locktimes are at the tip.
+	require.InDelta(t, 900, atTip, 50)
+
+	// The same seed results in the same locktime.
+	for seed := range int64(20) {
+		require.Equal(t,
+			antiFeeSnipingLockTime(tipHeight, rand.New(rand.NewSource(seed))),
+			antiFeeSnipingLockTime(tipHeight, rand.New(rand.NewSource(seed))))
+	}
+
+	// The locktime does not become negative close to the genesis block.
+	testRand = rand.New(rand.NewSource(1))
+	for range 100 {
+		require.LessOrEqual(t, antiFeeSnipingLockTime(5, testRand), uint32(5))
+	}
+}
+
+func TestSetLockTime(t *testing.T) {
+	tx := wire.NewMsgTx(wire.TxVersion)
+	tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: [32]byte{0x01}, Index: 0}, nil, nil))
+	tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: [32]byte{0x02}, Index: 0}, nil, nil))
+	tx.TxIn[1].Sequence = wire.MaxTxInSequenceNum - 2
+
+	// Unknown tip: the inputs stay final.
+	setLockTime(tx, 0, 0, rand.New(rand.NewSource(1)))
+	require.Equal(t, uint32(0), tx.LockTime)
+	require.Equal(t, uint32(wire.MaxTxInSequenceNum), tx.TxIn[0].Sequence)
+
+	// An explicit locktime is used as is.
+	setLockTime(tx, 1700000000, 800000, rand.New(rand.NewSource(1)))
+	require.Equal(t, uint32(1700000000), tx.LockTime)
+
+	setLockTime(tx, 0, 800000, rand.New(rand.NewSource(1)))
+	require.NotZero(t, tx.LockTime)
+	require.LessOrEqual(t, tx.LockTime, uint32(800000))
+	// Final inputs are made non-final so the locktime is enforced, RBF inputs are unchanged.
+	require.Equal(t, uint32(wire.MaxTxInSequenceNum-1), tx.TxIn[0].Sequence)
+	require.Equal(t, uint32(wire.MaxTxInSequenceNum-2), tx.TxIn[1].Sequence)
+}
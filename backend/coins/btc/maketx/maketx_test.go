@@ -17,6 +17,7 @@ package maketx_test
 
 import (
 	"bytes"
+	"encoding/hex"
 	"testing"
 
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/errors"
@@ -58,6 +59,9 @@ const (
 	txSizeOneInput   = 226
 	txSizeTwoInputs  = 374
 	txSizeFiveInputs = 818
+	// changeOutputSize is the size of the p2pkh change output, which is not part of txs without
+	// change.
+	changeOutputSize = 34
 )
 
 type newTxSuite struct {
@@ -104,10 +108,13 @@ func (s *newTxSuite) newTx(
 	return maketx.NewTx(
 		s.coin,
 		utxo,
-		maketx.NewOutputInfo(s.outputPkScript),
-		int64(amount),
+		[]*maketx.Recipient{{OutputInfo: maketx.NewOutputInfo(s.outputPkScript), Amount: amount}},
+		feePerKb,
 		feePerKb,
 		s.changeAddress,
+		0,
+		0,
+		maketx.SecureSeed(),
 		s.log,
 	)
 }
@@ -119,7 +126,11 @@ func (s *newTxSuite) newTxSpendAll(
 		s.coin,
 		utxo,
 		maketx.NewOutputInfo(s.outputPkScript),
+		nil,
 		feePerKb,
+		0,
+		0,
+		maketx.SecureSeed(),
 		s.log,
 	)
 }
@@ -217,8 +228,7 @@ func (s *newTxSuite) check(
 		inputSum += prevOut.TxOut.Value
 	}
 	txFee := btcutil.Amount(inputSum-output.Value) - expectedChange
-	// At the moment, the fee is based on the assumption of the tx having two outputs always, even
-	// if the change output is not there.
+	// Without change, the excess over the required fee is donated to the miners.
 
 	inputConfigurations := make([]*signing.Configuration, len(tx.TxIn))
 	for i := range inputConfigurations {
@@ -226,12 +236,12 @@ func (s *newTxSuite) check(
 	}
 
 	changeLen := 0
-	if !spendAll {
+	if expectedChange != 0 {
 		changeLen = len(s.changeAddress.PubkeyScript())
 	}
 	expectedFee := maketx.TstFeeForSerializeSize(
 		feePerKb,
-		maketx.TstEstimateTxSize(inputConfigurations, len(output.PkScript), changeLen),
+		maketx.TstEstimateTxSize(inputConfigurations, []int{len(output.PkScript)}, changeLen),
 		s.log) + expectedDustDonation
 	s.Require().Equal(expectedFee, txFee)
 	s.Require().Equal(expectedFee, txProposal.Fee)
@@ -270,25 +280,28 @@ func (s *newTxSuite) TestNewTxNoFee() {
 	feePerKb := btcutil.Amount(0)
 
 	s.check(false, btcutil.Amount(1), feePerKb, s.buildUTXO(1), s.change(0), noDust, s.selectCoins(0))
-	s.check(false, btcutil.Amount(1), feePerKb, s.buildUTXO(1, 2), s.change(1), noDust, s.selectCoins(1))
-	s.check(false, btcutil.Amount(1), feePerKb, s.buildUTXO(1, 2, 3), s.change(2), noDust, s.selectCoins(2))
+	// Coins matching the amount exactly are preferred, as no change is needed.
+	s.check(false, btcutil.Amount(1), feePerKb, s.buildUTXO(1, 2), s.change(0), noDust, s.selectCoins(0))
+	s.check(false, btcutil.Amount(1), feePerKb, s.buildUTXO(1, 2, 3), s.change(0), noDust, s.selectCoins(0))
 	s.check(false, btcutil.Amount(1), feePerKb, s.buildUTXO(2), s.change(1), noDust, s.selectCoins(0))
 
 	s.check(true, btcutil.Amount(1), feePerKb, s.buildUTXO(1), s.change(0), noDust, s.selectCoins(0))
 }
 
 func (s *newTxSuite) TestNewTxDust() {
-	// Have one coin be exactly the amount to spend + required fee, so there is no change.  We then
-	// add some dust, which does not produce change, but folds into the fee.  Also iterate through
-	// some amounts to spend, to check that the dust property is independent of the amount being
-	// spent.
+	// Have one coin be exactly the amount to spend + required fee including a change output. We
+	// then add some dust, which does not produce change, but folds into the fee, together with the
+	// fee saved by not having a change output.  Also iterate through some amounts to spend, to check
+	// that the dust property is independent of the amount being spent.
 	feePerKb := btcutil.Amount(1000) // 1 sat / vbyte
 	const maxDust = 545              // dust threshold for a p2pkh change output.
 	for baseAmount := int64(500); baseAmount <= 5000000000; baseAmount += 5000000000 / 10 {
 		for dust := int64(0); dust <= maxDust; dust++ {
-			s.check(false, btcutil.Amount(baseAmount), feePerKb, s.buildUTXO(400, baseAmount+txSizeOneInput+dust, 450), s.change(0), btcutil.Amount(dust), s.selectCoins(1))
+			s.check(false, btcutil.Amount(baseAmount), feePerKb, s.buildUTXO(baseAmount+txSizeOneInput+dust), s.change(0), btcutil.Amount(changeOutputSize+dust), s.selectCoins(0))
 		}
-		s.check(false, btcutil.Amount(baseAmount), feePerKb, s.buildUTXO(400, baseAmount+txSizeOneInput+maxDust+1, 450), s.change(maxDust+1), noDust, s.selectCoins(1))
+		s.check(false, btcutil.Amount(baseAmount), feePerKb, s.buildUTXO(baseAmount+txSizeOneInput+maxDust+1), s.change(maxDust+1), noDust, s.selectCoins(0))
+		// The other coins are not needed and would only add waste.
+		s.check(false, btcutil.Amount(baseAmount), feePerKb, s.buildUTXO(1000, baseAmount+txSizeOneInput, 1100), s.change(0), btcutil.Amount(changeOutputSize), s.selectCoins(1))
 	}
 }
 
@@ -312,11 +325,11 @@ func (s *newTxSuite) TestNewTxInsufficientFunds() {
 	// exact coin not enough, as fees need to be covered.
 	_, err = s.newTx(amount, feePerKb, s.buildUTXO(1000*mBTC))
 	s.Require().Equal(errors.ErrInsufficientFunds, errp.Cause(err))
-	// One satoshi short of covering the amount and fee.
-	_, err = s.newTx(amount, feePerKb, s.buildUTXO(1000*mBTC+txSizeOneInput-1))
+	// One satoshi short of covering the amount and fee of a tx without change.
+	_, err = s.newTx(amount, feePerKb, s.buildUTXO(1000*mBTC+txSizeOneInput-changeOutputSize-1))
 	s.Require().Equal(errors.ErrInsufficientFunds, errp.Cause(err))
 	// Just enough:
-	_, err = s.newTx(amount, feePerKb, s.buildUTXO(1000*mBTC+txSizeOneInput))
+	_, err = s.newTx(amount, feePerKb, s.buildUTXO(1000*mBTC+txSizeOneInput-changeOutputSize))
 	s.Require().NoError(err)
 
 	// Using two coins.
@@ -325,11 +338,11 @@ func (s *newTxSuite) TestNewTxInsufficientFunds() {
 	// exact coin not enough, as fees need to be covered.
 	_, err = s.newTx(amount, feePerKb, s.buildUTXO(mBTC, 999*mBTC))
 	s.Require().Equal(errors.ErrInsufficientFunds, errp.Cause(err))
-	// One satoshi short of covering the amount and fee.
-	_, err = s.newTx(amount, feePerKb, s.buildUTXO(mBTC, 999*mBTC+txSizeTwoInputs-1))
+	// One satoshi short of covering the amount and fee of a tx without change.
+	_, err = s.newTx(amount, feePerKb, s.buildUTXO(mBTC, 999*mBTC+txSizeTwoInputs-changeOutputSize-1))
 	s.Require().Equal(errors.ErrInsufficientFunds, errp.Cause(err))
 	// Just enough:
-	_, err = s.newTx(amount, feePerKb, s.buildUTXO(mBTC, 999*mBTC+txSizeTwoInputs))
+	_, err = s.newTx(amount, feePerKb, s.buildUTXO(mBTC, 999*mBTC+txSizeTwoInputs-changeOutputSize))
 	s.Require().NoError(err)
 }
 
@@ -339,11 +352,220 @@ func (s *newTxSuite) TestNewTxCoinSelection() {
 
 	feePerKb := btcutil.Amount(1000) // 1 sat / vbyte
 
-	s.check(false, amount, feePerKb, s.buildUTXO(mBTC, 2*mBTC, 1000*mBTC+txSizeOneInput), s.change(0), noDust, s.selectCoins(2))
+	s.check(false, amount, feePerKb, s.buildUTXO(mBTC, 2*mBTC, 1000*mBTC+txSizeOneInput), s.change(0), changeOutputSize, s.selectCoins(2))
 	s.check(false, amount, feePerKb, s.buildUTXO(mBTC, 1000*mBTC), s.change(mBTC-txSizeTwoInputs), noDust, s.selectCoins(0, 1))
-	// coins: .5, .3, .1, .1, .9, .8, .6. select .5+.3+.1+.1 to get 1BTC, take .9 to cover the fees.
-	s.check(false, amount, feePerKb, s.buildUTXO(500*mBTC, 300*mBTC, 100*mBTC, 100*mBTC, 90*mBTC, 80*mBTC, 70*mBTC), s.change(90*mBTC-txSizeFiveInputs), noDust, s.selectCoins(0, 1, 2, 3, 4))
+	// coins: .5, .3, .1, .1, .09, .08, .07. No combination matches 1BTC plus fees, so the smallest
+	// excess is chosen: .5+.3+.09+.08+.07.
+	s.check(false, amount, feePerKb, s.buildUTXO(500*mBTC, 300*mBTC, 100*mBTC, 100*mBTC, 90*mBTC, 80*mBTC, 70*mBTC), s.change(40*mBTC-txSizeFiveInputs), noDust, s.selectCoins(0, 1, 4, 5, 6))
 
 	s.check(true, btcutil.Amount(100299738), feePerKb, s.buildUTXO(mBTC, 2*mBTC, 1000*mBTC+txSizeOneInput), s.change(0), noDust, s.selectCoins(0, 1, 2))
 
 }
+
+func (s *newTxSuite) TestNewTxCoinSelectionAlgorithm() {
+	const mBTC = 100000
+	amount := btcutil.Amount(1000 * mBTC) // 1 BTC
+	const p2pkhInputSize = 148
+
+	newTx := func(feePerKb, longTermFeePerKb btcutil.Amount, utxo map[wire.OutPoint]maketx.UTXO) *maketx.TxProposal {
+		txProposal, err := maketx.NewTx(
+			s.coin,
+			utxo,
+			[]*maketx.Recipient{{OutputInfo: maketx.NewOutputInfo(s.outputPkScript), Amount: amount}},
+			feePerKb,
+			longTermFeePerKb,
+			s.changeAddress,
+			0,
+			0,
+			maketx.SecureSeed(),
+			s.log,
+		)
+		s.Require().NoError(err)
+		return txProposal
+	}
+
+	// Changeless: the waste is the excess paid to the miners.
+	txProposal := newTx(1000, 1000, s.buildUTXO(mBTC, 2*mBTC, 1000*mBTC+txSizeOneInput))
+	s.Require().Equal(maketx.CoinSelectionBranchAndBound, txProposal.CoinSelectionAlgorithm)
+	s.Require().Equal(btcutil.Amount(changeOutputSize), txProposal.Waste)
+
+	// With change: the waste is the cost of creating and later spending the change.
+	txProposal = newTx(1000, 1000, s.buildUTXO(mBTC, 1000*mBTC))
+	s.Require().Equal(maketx.CoinSelectionKnapsack, txProposal.CoinSelectionAlgorithm)
+	s.Require().Equal(btcutil.Amount(changeOutputSize+p2pkhInputSize), txProposal.Waste)
+
+	// Spending inputs at a higher fee rate than the long-term fee rate adds waste.
+	txProposal = newTx(10000, 1000, s.buildUTXO(mBTC, 1000*mBTC))
+	s.Require().Equal(
+		btcutil.Amount(2*(10-1)*p2pkhInputSize+10*changeOutputSize+p2pkhInputSize),
+		txProposal.Waste)
+
+	// Spending all coins does not select coins.
+	txProposal, err := s.newTxSpendAll(1000, s.buildUTXO(mBTC, 1000*mBTC))
+	s.Require().NoError(err)
+	s.Require().Equal(maketx.CoinSelectionAlgorithm(""), txProposal.CoinSelectionAlgorithm)
+}
+
+// outputsByPkScript maps the hex-encoded pkScripts of the tx outputs to their values.
+func outputsByPkScript(tx *wire.MsgTx) map[string]int64 {
+	result := map[string]int64{}
+	for _, txOut := range tx.TxOut {
+		result[hex.EncodeToString(txOut.PkScript)] = txOut.Value
+	}
+	return result
+}
+
+func (s *newTxSuite) TestNewTxMultipleRecipients() {
+	const mBTC = 100000
+	feePerKb := btcutil.Amount(1000) // 1 sat / vbyte
+	otherPkScript := s.someAddresses[1].PubkeyScript()
+	recipients := []*maketx.Recipient{
+		{OutputInfo: maketx.NewOutputInfo(s.outputPkScript), Amount: 1000 * mBTC},
+		{OutputInfo: maketx.NewOutputInfo(otherPkScript), Amount: 500 * mBTC},
+	}
+	utxo := s.buildUTXO(2000 * mBTC)
+
+	txProposal, err := maketx.NewTx(
+		s.coin, utxo, recipients, feePerKb, feePerKb, s.changeAddress, 0, 0, maketx.SecureSeed(), s.log)
+	s.Require().NoError(err)
+	tx := txProposal.Transaction
+	s.Require().Len(tx.TxOut, 3)
+	// One input, two recipients and change.
+	expectedFee := btcutil.Amount(txSizeOneInput + changeOutputSize)
+	s.Require().Equal(expectedFee, txProposal.Fee)
+	s.Require().Equal(btcutil.Amount(1500*mBTC), txProposal.Amount)
+	s.Require().Equal(map[string]int64{
+		hex.EncodeToString(s.outputPkScript):               1000 * mBTC,
+		hex.EncodeToString(otherPkScript):                  500 * mBTC,
+		hex.EncodeToString(s.changeAddress.PubkeyScript()): int64(500*mBTC - expectedFee),
+	}, outputsByPkScript(tx))
+	s.Require().Equal(s.outputPkScript, tx.TxOut[txProposal.OutIndex].PkScript)
+
+	_, err = maketx.NewTx(
+		s.coin, s.buildUTXO(1500*mBTC), recipients, feePerKb, feePerKb, s.changeAddress, 0, 0, maketx.SecureSeed(), s.log)
+	s.Require().Equal(errors.ErrInsufficientFunds, errp.Cause(err))
+
+	// Silent payments need a single recipient.
+	_, err = maketx.NewTx(
+		s.coin,
+		utxo,
+		[]*maketx.Recipient{
+			recipients[0],
+			{OutputInfo: maketx.NewOutputInfoSilentPayment("sp1"), Amount: mBTC},
+		},
+		feePerKb, feePerKb, s.changeAddress, 0, 0, maketx.SecureSeed(), s.log)
+	s.Require().Error(err)
+}
+
+func (s *newTxSuite) TestNewTxSpendAllMultipleRecipients() {
+	const mBTC = 100000
+	feePerKb := btcutil.Amount(1000) // 1 sat / vbyte
+	otherPkScript := s.someAddresses[1].PubkeyScript()
+	recipients := []*maketx.Recipient{
+		{OutputInfo: maketx.NewOutputInfo(otherPkScript), Amount: 200 * mBTC},
+	}
+	utxo := s.buildUTXO(1000*mBTC, mBTC)
+
+	txProposal, err := maketx.NewTxSpendAll(
+		s.coin, utxo, maketx.NewOutputInfo(s.outputPkScript), recipients, feePerKb, 0, 0, maketx.SecureSeed(), s.log)
+	s.Require().NoError(err)
+	tx := txProposal.Transaction
+	s.Require().Len(tx.TxIn, 2)
+	// Two inputs and two recipients, no change.
+	expectedFee := btcutil.Amount(txSizeTwoInputs)
+	s.Require().Equal(expectedFee, txProposal.Fee)
+	s.Require().Equal(1001*mBTC-expectedFee, txProposal.Amount)
+	s.Require().Nil(txProposal.ChangeAddress)
+	s.Require().Equal(map[string]int64{
+		hex.EncodeToString(s.outputPkScript): int64(801*mBTC - expectedFee),
+		hex.EncodeToString(otherPkScript):    200 * mBTC,
+	}, outputsByPkScript(tx))
+	// The output index refers to the output receiving the remainder.
+	s.Require().Equal(s.outputPkScript, tx.TxOut[txProposal.OutIndex].PkScript)
+
+	_, err = maketx.NewTxSpendAll(
+		s.coin, s.buildUTXO(200*mBTC), maketx.NewOutputInfo(s.outputPkScript), recipients, feePerKb, 0, 0, maketx.SecureSeed(), s.log)
+	s.Require().Equal(errors.ErrInsufficientFunds, errp.Cause(err))
+
+	// The remainder would be dust.
+	_, err = maketx.NewTxSpendAll(
+		s.coin, s.buildUTXO(200*mBTC+txSizeOneInput+100), maketx.NewOutputInfo(s.outputPkScript), recipients, feePerKb, 0, 0, maketx.SecureSeed(), s.log)
+	s.Require().Equal(errors.ErrInsufficientFunds, errp.Cause(err))
+}
+
+func (s *newTxSuite) TestNewTxAntiFeeSnipingLockTime() {
+	const mBTC = 100000
+	const tipHeight = 800000
+	feePerKb := btcutil.Amount(1000) // 1 sat / vbyte
+	recipients := []*maketx.Recipient{
+		{OutputInfo: maketx.NewOutputInfo(s.outputPkScript), Amount: 1000 * mBTC},
+	}
+	utxo := s.buildUTXO(500*mBTC, 600*mBTC, 700*mBTC)
+
+	newTx := func(seed int64) *wire.MsgTx {
+		txProposal, err := maketx.NewTx(
+			s.coin, utxo, recipients, feePerKb, feePerKb, s.changeAddress, 0, tipHeight, seed, s.log)
+		s.Require().NoError(err)
+		return txProposal.Transaction
+	}
+	newTxSpendAll := func(seed int64) *wire.MsgTx {
+		txProposal, err := maketx.NewTxSpendAll(
+			s.coin, utxo, maketx.NewOutputInfo(s.outputPkScript), nil, feePerKb, 0, tipHeight, seed, s.log)
+		s.Require().NoError(err)
+		return txProposal.Transaction
+	}
+
+	for _, makeTx := range []func(int64) *wire.MsgTx{newTx, newTxSpendAll} {
+		// The same seed results in the same transaction.
+		s.Require().Equal(makeTx(1), makeTx(1))
+
+		lockTimes := map[uint32]struct{}{}
+		for seed := range int64(100) {
+			tx := makeTx(seed)
+			s.Require().LessOrEqual(tx.LockTime, uint32(tipHeight))
+			s.Require().Greater(tx.LockTime, uint32(tipHeight-100))
+			lockTimes[tx.LockTime] = struct{}{}
+			for _, txIn := range tx.TxIn {
+				if s.coin == tbtc {
+					s.Require().Equal(wire.MaxTxInSequenceNum-2, txIn.Sequence)
+				} else {
+					// The locktime is only enforced with a non-final input.
+					s.Require().Equal(wire.MaxTxInSequenceNum-1, txIn.Sequence)
+				}
+			}
+		}
+		// Some locktimes are set back from the tip.
+		s.Require().Greater(len(lockTimes), 1)
+	}
+
+	// Without a known tip, the locktime is not set.
+	txProposal, err := maketx.NewTx(
+		s.coin, utxo, recipients, feePerKb, feePerKb, s.changeAddress, 0, 0, 1, s.log)
+	s.Require().NoError(err)
+	s.Require().Equal(uint32(0), txProposal.Transaction.LockTime)
+}
+
+func (s *newTxSuite) TestNewTxLockTime() {
+	const mBTC = 100000
+	feePerKb := btcutil.Amount(1000) // 1 sat / vbyte
+	recipients := []*maketx.Recipient{
+		{OutputInfo: maketx.NewOutputInfo(s.outputPkScript), Amount: 1000 * mBTC},
+	}
+	utxo := s.buildUTXO(2000 * mBTC)
+
+	// A block height and a timestamp in the future.
+	for _, lockTime := range []uint32{900000, 2000000000} {
+		txProposal, err := maketx.NewTx(
+			s.coin, utxo, recipients, feePerKb, feePerKb, s.changeAddress, lockTime, 800000, 1, s.log)
+		s.Require().NoError(err)
+		s.Require().Equal(lockTime, txProposal.Transaction.LockTime)
+		for _, txIn := range txProposal.Transaction.TxIn {
+			s.Require().NotEqual(wire.MaxTxInSequenceNum, txIn.Sequence)
+		}
+
+		txProposal, err = maketx.NewTxSpendAll(
+			s.coin, utxo, maketx.NewOutputInfo(s.outputPkScript), nil, feePerKb, lockTime, 800000, 1, s.log)
+		s.Require().NoError(err)
+		s.Require().Equal(lockTime, txProposal.Transaction.LockTime)
+	}
+}
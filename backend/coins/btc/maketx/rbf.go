@@ -0,0 +1,302 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maketx
+
+import (
+	"sort"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/errors"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/addresses"
+	coinpkg "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/sirupsen/logrus"
+)
+
+// SignalsRBF returns true if the transaction opted in to be replaceable according to BIP-125,
+// i.e. at least one input has a sequence number smaller than 0xfffffffe.
+func SignalsRBF(tx *wire.MsgTx) bool {
+	for _, txIn := range tx.TxIn {
+		if txIn.Sequence < wire.MaxTxInSequenceNum-1 {
+			return true
+		}
+	}
+	return false
+}
+
+// ReplacedTx describes the unconfirmed transaction to be replaced with NewReplacementTx().
+type ReplacedTx struct {
+	Transaction *wire.MsgTx
+	// PreviousOutputs must contain all outputs spent by the transaction.
+	PreviousOutputs PreviousOutputs
+	// ChangeIndex is the index of the change output of the transaction, or -1 if there is none. All
+	// other outputs are preserved in the replacement.
+	ChangeIndex int
+}
+
+// Fee returns the fee paid by the transaction.
+func (replacedTx *ReplacedTx) Fee() btcutil.Amount {
+	var fee btcutil.Amount
+	for _, txIn := range replacedTx.Transaction.TxIn {
+		fee += btcutil.Amount(replacedTx.PreviousOutputs[txIn.PreviousOutPoint].TxOut.Value)
+	}
+	for _, txOut := range replacedTx.Transaction.TxOut {
+		fee -= btcutil.Amount(txOut.Value)
+	}
+	return fee
+}
+
+// NewReplacementTx creates a transaction replacing an unconfirmed transaction by one paying a
+// higher fee, following the BIP-125 replacement rules:
+//
+// - All inputs of the replaced transaction are spent again, so the two transactions conflict.
+// - All outputs except for the change are preserved. The fee increase is deducted from the change.
+// - If the change is not sufficient, inputs from `additionalOutputs` are added, largest first. The
+// caller must only provide confirmed outputs, as BIP-125 forbids adding unconfirmed inputs.
+// - The new fee is at least the old fee plus the incremental relay fee for the size of the
+// replacement, and the fee rate must be higher than the fee rate of the replaced transaction.
+//
+// changeAddress: a change output to this address is added if needed.
+func NewReplacementTx(
+	coin coinpkg.Coin,
+	replacedTx *ReplacedTx,
+	additionalOutputs map[wire.OutPoint]UTXO,
+	feePerKb btcutil.Amount,
+	incrementalRelayFeePerKb btcutil.Amount,
+	changeAddress *addresses.AccountAddress,
+	log *logrus.Entry,
+) (*TxProposal, error) {
+	originalTx := replacedTx.Transaction
+	if !SignalsRBF(originalTx) {
+		return nil, errp.New("The transaction does not signal replaceability (BIP-125)")
+	}
+	originalFee := replacedTx.Fee()
+	originalVSize := estimateTxSizeWithOutputs(
+		toInputConfigurations(replacedTx.PreviousOutputs, outPointsOf(originalTx)),
+		originalTx.TxOut)
+	if feePerKb*btcutil.Amount(originalVSize) <= originalFee*1000 {
+		return nil, errp.WithStack(errors.ErrFeeTooLow)
+	}
+
+	keptOutputs := []*wire.TxOut{}
+	var keptOutputsSum btcutil.Amount
+	for index, txOut := range originalTx.TxOut {
+		if index == replacedTx.ChangeIndex {
+			continue
+		}
+		keptOutputs = append(keptOutputs, wire.NewTxOut(txOut.Value, txOut.PkScript))
+		keptOutputsSum += btcutil.Amount(txOut.Value)
+	}
+	if len(keptOutputs) == 0 {
+		return nil, errp.New("The transaction to replace has no outputs to preserve")
+	}
+
+	previousOutputs := make(PreviousOutputs, len(originalTx.TxIn))
+	selectedOutPoints := outPointsOf(originalTx)
+	var inputsSum btcutil.Amount
+	for _, outPoint := range selectedOutPoints {
+		utxo, ok := replacedTx.PreviousOutputs[outPoint]
+		if !ok {
+			return nil, errp.New("There needs to be exactly one output being spent per input.")
+		}
+		previousOutputs[outPoint] = utxo
+		inputsSum += btcutil.Amount(utxo.TxOut.Value)
+	}
+
+	// Candidates to add if the original inputs do not cover the new fee, largest first.
+	candidates := []wire.OutPoint{}
+	for outPoint := range additionalOutputs {
+		if _, ok := previousOutputs[outPoint]; ok {
+			continue
+		}
+		candidates = append(candidates, outPoint)
+	}
+	sort.Sort(sort.Reverse(&byValue{candidates, additionalOutputs}))
+
+	changePKScript := changeAddress.PubkeyScript()
+	requiredFee := func(outputs []*wire.TxOut) btcutil.Amount {
+		txSize := estimateTxSizeWithOutputs(
+			toInputConfigurations(previousOutputs, selectedOutPoints), outputs)
+		fee := feeForSerializeSize(feePerKb, txSize, log)
+		// BIP-125 rule 4: the replacement must pay for its own bandwidth.
+		minFee := originalFee + feeForSerializeSize(incrementalRelayFeePerKb, txSize, log)
+		if fee < minFee {
+			return minFee
+		}
+		return fee
+	}
+
+	for {
+		changeOutput := wire.NewTxOut(0, changePKScript)
+		withChange := append(append([]*wire.TxOut{}, keptOutputs...), changeOutput)
+		fee := requiredFee(withChange)
+		changeAmount := inputsSum - keptOutputsSum - fee
+		var outputs []*wire.TxOut
+		if changeAmount > 0 && !isDustAmount(
+			changeAmount, len(changePKScript), changeAddress.AccountConfiguration, feePerKb) {
+			changeOutput.Value = int64(changeAmount)
+			outputs = withChange
+		} else {
+			fee = requiredFee(keptOutputs)
+			if inputsSum-keptOutputsSum >= fee {
+				// The remainder is too small for a change output and is added to the fee.
+				fee = inputsSum - keptOutputsSum
+				outputs = keptOutputs
+				changeAddress = nil
+			}
+		}
+		if outputs == nil {
+			if len(candidates) == 0 {
+				return nil, errp.WithStack(errors.ErrInsufficientFunds)
+			}
+			outPoint := candidates[0]
+			candidates = candidates[1:]
+			selectedOutPoints = append(selectedOutPoints, outPoint)
+			previousOutputs[outPoint] = additionalOutputs[outPoint]
+			inputsSum += btcutil.Amount(additionalOutputs[outPoint].TxOut.Value)
+			continue
+		}
+
+		inputs := make([]*wire.TxIn, len(selectedOutPoints))
+		for i, outPoint := range selectedOutPoints {
+			inputs[i] = wire.NewTxIn(&outPoint, nil, nil)
+		}
+		unsignedTransaction := &wire.MsgTx{
+			Version:  originalTx.Version,
+			TxIn:     inputs,
+			TxOut:    outputs,
+			LockTime: originalTx.LockTime,
+		}
+		setRBF(coin, unsignedTransaction)
+		if !SignalsRBF(unsignedTransaction) {
+			return nil, errp.Newf("Replace-by-fee is not supported for %s", coin.Code())
+		}
+
+		log.WithFields(logrus.Fields{"fee": fee, "originalFee": originalFee}).
+			Debug("Preparing replacement transaction")
+
+		originalTxHash := originalTx.TxHash()
+		return &TxProposal{
+			Coin:            coin,
+			Amount:          keptOutputsSum,
+			Fee:             fee,
+			Transaction:     unsignedTransaction,
+			ChangeAddress:   changeAddress,
+			PreviousOutputs: previousOutputs,
+			OutIndex:        0,
+			ReplacesTxHash:  &originalTxHash,
+		}, nil
+	}
+}
+
+// NewCancellationTx creates a transaction cancelling an unconfirmed transaction by double-spending
+// all of its inputs to a single output to `changeAddress`, which must be an address of the wallet.
+// The fee follows the same BIP-125 rules as NewReplacementTx(). Once the cancellation confirms, the
+// outputs of the replaced transaction are void and the coins are back in the wallet, minus the fee.
+func NewCancellationTx(
+	coin coinpkg.Coin,
+	replacedTx *ReplacedTx,
+	feePerKb btcutil.Amount,
+	incrementalRelayFeePerKb btcutil.Amount,
+	changeAddress *addresses.AccountAddress,
+	log *logrus.Entry,
+) (*TxProposal, error) {
+	originalTx := replacedTx.Transaction
+	if !SignalsRBF(originalTx) {
+		return nil, errp.New("The transaction does not signal replaceability (BIP-125)")
+	}
+	originalFee := replacedTx.Fee()
+	selectedOutPoints := outPointsOf(originalTx)
+	originalVSize := estimateTxSizeWithOutputs(
+		toInputConfigurations(replacedTx.PreviousOutputs, selectedOutPoints),
+		originalTx.TxOut)
+	if feePerKb*btcutil.Amount(originalVSize) <= originalFee*1000 {
+		return nil, errp.WithStack(errors.ErrFeeTooLow)
+	}
+
+	previousOutputs := make(PreviousOutputs, len(originalTx.TxIn))
+	inputs := make([]*wire.TxIn, len(selectedOutPoints))
+	var inputsSum btcutil.Amount
+	for i, outPoint := range selectedOutPoints {
+		utxo, ok := replacedTx.PreviousOutputs[outPoint]
+		if !ok {
+			return nil, errp.New("There needs to be exactly one output being spent per input.")
+		}
+		previousOutputs[outPoint] = utxo
+		inputs[i] = wire.NewTxIn(&outPoint, nil, nil)
+		inputsSum += btcutil.Amount(utxo.TxOut.Value)
+	}
+
+	changePKScript := changeAddress.PubkeyScript()
+	txSize := estimateTxSize(
+		toInputConfigurations(previousOutputs, selectedOutPoints),
+		[]int{len(changePKScript)},
+		0)
+	fee := feeForSerializeSize(feePerKb, txSize, log)
+	// BIP-125 rule 4: the replacement must pay for its own bandwidth.
+	if minFee := originalFee + feeForSerializeSize(incrementalRelayFeePerKb, txSize, log); fee < minFee {
+		fee = minFee
+	}
+	outputAmount := inputsSum - fee
+	if outputAmount <= 0 || isDustAmount(
+		outputAmount, len(changePKScript), changeAddress.AccountConfiguration, feePerKb) {
+		return nil, errp.WithStack(errors.ErrInsufficientFunds)
+	}
+
+	unsignedTransaction := &wire.MsgTx{
+		Version:  originalTx.Version,
+		TxIn:     inputs,
+		TxOut:    []*wire.TxOut{wire.NewTxOut(int64(outputAmount), changePKScript)},
+		LockTime: originalTx.LockTime,
+	}
+	setRBF(coin, unsignedTransaction)
+	if !SignalsRBF(unsignedTransaction) {
+		return nil, errp.Newf("Replace-by-fee is not supported for %s", coin.Code())
+	}
+
+	log.WithFields(logrus.Fields{"fee": fee, "originalFee": originalFee}).
+		Debug("Preparing cancellation transaction")
+
+	originalTxHash := originalTx.TxHash()
+	return &TxProposal{
+		Coin:            coin,
+		Amount:          outputAmount,
+		Fee:             fee,
+		Transaction:     unsignedTransaction,
+		ChangeAddress:   changeAddress,
+		PreviousOutputs: previousOutputs,
+		OutIndex:        0,
+		ReplacesTxHash:  &originalTxHash,
+	}, nil
+}
+
+func outPointsOf(tx *wire.MsgTx) []wire.OutPoint {
+	outPoints := make([]wire.OutPoint, len(tx.TxIn))
+	for i, txIn := range tx.TxIn {
+		outPoints[i] = txIn.PreviousOutPoint
+	}
+	return outPoints
+}
+
+// estimateTxSizeWithOutputs is like estimateTxSize(), but for the given outputs.
+func estimateTxSizeWithOutputs(inputConfigurations []*signing.Configuration, outputs []*wire.TxOut) int {
+	pkScriptSizes := make([]int, len(outputs))
+	for i, txOut := range outputs {
+		pkScriptSizes[i] = len(txOut.PkScript)
+	}
+	return estimateTxSize(inputConfigurations, pkScriptSizes, 0)
+}
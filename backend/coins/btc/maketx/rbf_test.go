@@ -0,0 +1,185 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maketx_test
+
+import (
+	"bytes"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/errors"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/maketx"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// replacedTx creates a transaction to be replaced, spending from the given coins.
+func (s *newTxSuite) replacedTx(
+	amount btcutil.Amount,
+	feePerKb btcutil.Amount,
+	utxo map[wire.OutPoint]maketx.UTXO,
+) *maketx.ReplacedTx {
+	txProposal, err := s.newTx(amount, feePerKb, utxo)
+	s.Require().NoError(err)
+	changeIndex := -1
+	for index, txOut := range txProposal.Transaction.TxOut {
+		if bytes.Equal(txOut.PkScript, s.changeAddress.PubkeyScript()) {
+			changeIndex = index
+		}
+	}
+	return &maketx.ReplacedTx{
+		Transaction:     txProposal.Transaction,
+		PreviousOutputs: txProposal.PreviousOutputs,
+		ChangeIndex:     changeIndex,
+	}
+}
+
+func (s *newTxSuite) newReplacementTx(
+	replacedTx *maketx.ReplacedTx,
+	additionalOutputs map[wire.OutPoint]maketx.UTXO,
+	feePerKb btcutil.Amount,
+) (*maketx.TxProposal, error) {
+	return maketx.NewReplacementTx(
+		s.coin,
+		replacedTx,
+		additionalOutputs,
+		feePerKb,
+		1000,
+		s.changeAddress,
+		s.log,
+	)
+}
+
+func (s *newTxSuite) TestNewReplacementTx() {
+	replacedTx := s.replacedTx(100000, 1000, s.buildUTXO(200000))
+	if s.coin != tbtc {
+		// Only Bitcoin transactions signal replaceability.
+		s.Require().False(maketx.SignalsRBF(replacedTx.Transaction))
+		_, err := s.newReplacementTx(replacedTx, nil, 5000)
+		s.Require().Error(err)
+		return
+	}
+	s.Require().True(maketx.SignalsRBF(replacedTx.Transaction))
+	s.Require().NotEqual(-1, replacedTx.ChangeIndex)
+	originalFee := replacedTx.Fee()
+	s.Require().Equal(btcutil.Amount(txSizeOneInput), originalFee)
+
+	txProposal, err := s.newReplacementTx(replacedTx, nil, 5000)
+	s.Require().NoError(err)
+	tx := txProposal.Transaction
+	originalTxHash := replacedTx.Transaction.TxHash()
+	s.Require().Equal(&originalTxHash, txProposal.ReplacesTxHash)
+	s.Require().Equal(btcutil.Amount(5*txSizeOneInput), txProposal.Fee)
+	s.Require().Equal(btcutil.Amount(100000), txProposal.Amount)
+	s.Require().Equal(s.changeAddress, txProposal.ChangeAddress)
+
+	// Same inputs, same recipient output, the change pays for the fee increase.
+	s.Require().Len(tx.TxIn, 1)
+	s.Require().Equal(s.outpoint(0), tx.TxIn[0].PreviousOutPoint)
+	s.Require().Equal(wire.MaxTxInSequenceNum-2, tx.TxIn[0].Sequence)
+	s.Require().Len(tx.TxOut, 2)
+	s.Require().Equal(s.output(100000), tx.TxOut[0])
+	s.Require().Equal(int64(200000-100000-5*txSizeOneInput), tx.TxOut[1].Value)
+	s.Require().Equal(s.changeAddress.PubkeyScript(), tx.TxOut[1].PkScript)
+
+	// The new fee must pay for the bandwidth of the replacement on top of the original fee.
+	txProposal, err = s.newReplacementTx(replacedTx, nil, 1500)
+	s.Require().NoError(err)
+	s.Require().Equal(originalFee+txSizeOneInput, txProposal.Fee)
+}
+
+func (s *newTxSuite) TestNewReplacementTxFeeTooLow() {
+	if s.coin != tbtc {
+		return
+	}
+	replacedTx := s.replacedTx(100000, 2000, s.buildUTXO(200000))
+	for _, feePerKb := range []btcutil.Amount{1000, 2000} {
+		_, err := s.newReplacementTx(replacedTx, nil, feePerKb)
+		s.Require().Equal(errors.ErrFeeTooLow, errp.Cause(err))
+	}
+}
+
+func (s *newTxSuite) TestNewReplacementTxAdditionalInputs() {
+	if s.coin != tbtc {
+		return
+	}
+	// No change in the original tx, so the fee increase requires an additional input.
+	replacedTx := s.replacedTx(100000, 1000, s.buildUTXO(100000+txSizeOneInput))
+	s.Require().Equal(-1, replacedTx.ChangeIndex)
+	_, err := s.newReplacementTx(replacedTx, nil, 5000)
+	s.Require().Equal(errors.ErrInsufficientFunds, errp.Cause(err))
+
+	additionalOutputs := map[wire.OutPoint]maketx.UTXO{}
+	for index, value := range []int64{1000, 50000} {
+		additionalOutputs[wire.OutPoint{Hash: chainhash.HashH([]byte(`other-tx`)), Index: uint32(index)}] = maketx.UTXO{
+			TxOut:   wire.NewTxOut(value, s.someAddresses[0].PubkeyScript()),
+			Address: s.someAddresses[0],
+		}
+	}
+	txProposal, err := s.newReplacementTx(replacedTx, additionalOutputs, 5000)
+	s.Require().NoError(err)
+	tx := txProposal.Transaction
+	// The original input is kept and the largest additional coin is added.
+	s.Require().Len(tx.TxIn, 2)
+	s.Require().Equal(s.outpoint(0), tx.TxIn[0].PreviousOutPoint)
+	s.Require().Equal(int64(50000), txProposal.PreviousOutputs[tx.TxIn[1].PreviousOutPoint].TxOut.Value)
+	s.Require().Equal(btcutil.Amount(5*txSizeTwoInputs), txProposal.Fee)
+	s.Require().Len(tx.TxOut, 2)
+	s.Require().Equal(s.output(100000), tx.TxOut[0])
+	s.Require().Equal(int64(txSizeOneInput+50000-5*txSizeTwoInputs), tx.TxOut[1].Value)
+}
+
+func (s *newTxSuite) TestNewCancellationTx() {
+	replacedTx := s.replacedTx(100000, 1000, s.buildUTXO(200000))
+	if s.coin != tbtc {
+		_, err := maketx.NewCancellationTx(s.coin, replacedTx, 5000, 1000, s.changeAddress, s.log)
+		s.Require().Error(err)
+		return
+	}
+	originalFee := replacedTx.Fee()
+	_, err := maketx.NewCancellationTx(s.coin, replacedTx, 1000, 1000, s.changeAddress, s.log)
+	s.Require().Equal(errors.ErrFeeTooLow, errp.Cause(err))
+
+	txProposal, err := maketx.NewCancellationTx(s.coin, replacedTx, 5000, 1000, s.changeAddress, s.log)
+	s.Require().NoError(err)
+	txSize := maketx.TstEstimateTxSize(
+		[]*signing.Configuration{s.inputConfiguration}, []int{len(s.changeAddress.PubkeyScript())}, 0)
+	expectedFee := btcutil.Amount(5 * txSize)
+	originalTxHash := replacedTx.Transaction.TxHash()
+	s.Require().Equal(&originalTxHash, txProposal.ReplacesTxHash)
+	s.Require().Equal(expectedFee, txProposal.Fee)
+	s.Require().Equal(200000-expectedFee, txProposal.Amount)
+	s.Require().Equal(s.changeAddress, txProposal.ChangeAddress)
+
+	// Same inputs, a single output back to the wallet.
+	tx := txProposal.Transaction
+	s.Require().Len(tx.TxIn, 1)
+	s.Require().Equal(s.outpoint(0), tx.TxIn[0].PreviousOutPoint)
+	s.Require().True(maketx.SignalsRBF(tx))
+	s.Require().Len(tx.TxOut, 1)
+	s.Require().Equal(int64(200000-expectedFee), tx.TxOut[0].Value)
+	s.Require().Equal(s.changeAddress.PubkeyScript(), tx.TxOut[0].PkScript)
+
+	// The new fee must pay for the bandwidth of the cancellation on top of the original fee.
+	txProposal, err = maketx.NewCancellationTx(s.coin, replacedTx, 1500, 1000, s.changeAddress, s.log)
+	s.Require().NoError(err)
+	s.Require().Equal(originalFee+btcutil.Amount(txSize), txProposal.Fee)
+
+	// Nothing is left after paying the fee.
+	replacedTx = s.replacedTx(1000, 1000, s.buildUTXO(1000+txSizeOneInput))
+	_, err = maketx.NewCancellationTx(s.coin, replacedTx, 10000, 1000, s.changeAddress, s.log)
+	s.Require().Equal(errors.ErrInsufficientFunds, errp.Cause(err))
+}
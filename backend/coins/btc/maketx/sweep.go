@@ -0,0 +1,113 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maketx
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/errors"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/addresses"
+	coinpkg "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/sirupsen/logrus"
+)
+
+// uncompressedPubkeyExtraSize is the additional size of an uncompressed public key in a sigScript.
+const uncompressedPubkeyExtraSize = 65 - pubkeySize
+
+// SweepInput is a coin locked to an external private key, e.g. of a paper wallet, to be spent with
+// NewSweepTx().
+type SweepInput struct {
+	TxOut      *wire.TxOut
+	ScriptType signing.ScriptType
+	// Uncompressed is true if the coin is locked to an uncompressed public key, which is only
+	// possible for P2PKH.
+	Uncompressed bool
+}
+
+// SweepTx is the unsigned transaction created by NewSweepTx().
+type SweepTx struct {
+	Transaction *wire.MsgTx
+	// Amount is the value of the single output.
+	Amount btcutil.Amount
+	Fee    btcutil.Amount
+}
+
+// NewSweepTx creates a transaction spending all given coins to a single output to `outputAddress`,
+// which must be an unused address of the wallet. The inputs are sorted by outpoint and must be
+// signed by the caller.
+func NewSweepTx(
+	coin coinpkg.Coin,
+	inputs map[wire.OutPoint]*SweepInput,
+	feePerKb btcutil.Amount,
+	outputAddress *addresses.AccountAddress,
+	log *logrus.Entry,
+) (*SweepTx, error) {
+	if len(inputs) == 0 {
+		return nil, errp.New("No coins found to sweep")
+	}
+	outPoints := make([]wire.OutPoint, 0, len(inputs))
+	for outPoint := range inputs {
+		outPoints = append(outPoints, outPoint)
+	}
+	sort.Slice(outPoints, func(i, j int) bool {
+		if outPoints[i].Hash != outPoints[j].Hash {
+			return bytes.Compare(outPoints[i].Hash[:], outPoints[j].Hash[:]) < 0
+		}
+		return outPoints[i].Index < outPoints[j].Index
+	})
+
+	txIns := make([]*wire.TxIn, len(outPoints))
+	inputScriptTypes := make([]signing.ScriptType, len(outPoints))
+	var inputsSum btcutil.Amount
+	extraSize := 0
+	for i, outPoint := range outPoints {
+		input := inputs[outPoint]
+		txIns[i] = wire.NewTxIn(&outPoint, nil, nil)
+		inputScriptTypes[i] = input.ScriptType
+		inputsSum += btcutil.Amount(input.TxOut.Value)
+		if input.Uncompressed {
+			extraSize += uncompressedPubkeyExtraSize
+		}
+	}
+
+	pkScript := outputAddress.PubkeyScript()
+	txSize := estimateTxSizeForScriptTypes(inputScriptTypes, []int{len(pkScript)}, 0) + extraSize
+	fee := feeForSerializeSize(feePerKb, txSize, log)
+	outputAmount := inputsSum - fee
+	if outputAmount <= 0 || isDustAmount(
+		outputAmount, len(pkScript), outputAddress.AccountConfiguration, feePerKb) {
+		return nil, errp.WithStack(errors.ErrInsufficientFunds)
+	}
+
+	unsignedTransaction := &wire.MsgTx{
+		Version:  wire.TxVersion,
+		TxIn:     txIns,
+		TxOut:    []*wire.TxOut{wire.NewTxOut(int64(outputAmount), pkScript)},
+		LockTime: 0,
+	}
+	setRBF(coin, unsignedTransaction)
+
+	log.WithFields(logrus.Fields{"inputs": len(txIns), "fee": fee}).Debug("Preparing sweep transaction")
+	return &SweepTx{
+		Transaction: unsignedTransaction,
+		Amount:      outputAmount,
+		Fee:         fee,
+	}, nil
+}
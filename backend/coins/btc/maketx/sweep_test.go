@@ -0,0 +1,63 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maketx_test
+
+import (
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/errors"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/maketx"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+func (s *newTxSuite) TestNewSweepTx() {
+	_, err := maketx.NewSweepTx(s.coin, nil, 10000, s.changeAddress, s.log)
+	s.Require().EqualError(err, "No coins found to sweep")
+
+	hash := chainhash.HashH([]byte(`paper-wallet-tx`))
+	inputs := map[wire.OutPoint]*maketx.SweepInput{
+		{Hash: hash, Index: 1}: {
+			TxOut:        wire.NewTxOut(20000, []byte{}),
+			ScriptType:   signing.ScriptTypeP2PKH,
+			Uncompressed: true,
+		},
+		{Hash: hash, Index: 0}: {
+			TxOut:      wire.NewTxOut(30000, []byte{}),
+			ScriptType: signing.ScriptTypeP2WPKH,
+		},
+	}
+	sweepTx, err := maketx.NewSweepTx(s.coin, inputs, 10000, s.changeAddress, s.log)
+	s.Require().NoError(err)
+	// One P2WPKH input, one P2PKH input with an uncompressed pubkey and one P2PKH output: 261 vbytes
+	// plus 32 bytes for the uncompressed pubkey.
+	const expectedFee = 10 * (261 + 32)
+	s.Require().Equal(btcutil.Amount(expectedFee), sweepTx.Fee)
+	s.Require().Equal(btcutil.Amount(50000-expectedFee), sweepTx.Amount)
+
+	tx := sweepTx.Transaction
+	s.Require().Len(tx.TxIn, 2)
+	s.Require().Equal(wire.OutPoint{Hash: hash, Index: 0}, tx.TxIn[0].PreviousOutPoint)
+	s.Require().Equal(wire.OutPoint{Hash: hash, Index: 1}, tx.TxIn[1].PreviousOutPoint)
+	s.Require().Equal(s.coin == tbtc, maketx.SignalsRBF(tx))
+	s.Require().Len(tx.TxOut, 1)
+	s.Require().Equal(int64(50000-expectedFee), tx.TxOut[0].Value)
+	s.Require().Equal(s.changeAddress.PubkeyScript(), tx.TxOut[0].PkScript)
+
+	// The fee consumes the coins.
+	_, err = maketx.NewSweepTx(s.coin, inputs, 200000, s.changeAddress, s.log)
+	s.Require().Equal(errors.ErrInsufficientFunds, errp.Cause(err))
+}
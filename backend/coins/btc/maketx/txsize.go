@@ -47,7 +47,43 @@ var witnessV0Size = wire.VarIntSerializeSize(2) +
 // sigScriptWitnessSize returns the maximum possible sigscript/witness size for a given address type.
 // If there is no witness, 0 is returned.
 func sigScriptWitnessSize(configuration *signing.Configuration) (int, int) {
-	switch configuration.ScriptType() {
+	if configuration.BitcoinMultisig != nil {
+		return multisigSigScriptWitnessSize(configuration.BitcoinMultisig)
+	}
+	return scriptTypeSigScriptWitnessSize(configuration.ScriptType())
+}
+
+// multisigSigScriptWitnessSize is like sigScriptWitnessSize(), for a multisig configuration.
+func multisigSigScriptWitnessSize(multisig *signing.BitcoinMultisig) (int, int) {
+	// OP_k, n times OP_DATA_33 and the compressed pubkey, OP_n, OP_CHECKMULTISIG
+	witnessScriptSize := 1 + len(multisig.KeyInfos)*(1+pubkeySize) + 1 + 1
+	// <empty> <serialized sig>*k <witnessScript>
+	witnessSize := wire.VarIntSerializeSize(uint64(multisig.Threshold+2)) +
+		wire.VarIntSerializeSize(0) +
+		multisig.Threshold*(wire.VarIntSerializeSize(signatureSize)+signatureSize) +
+		wire.VarIntSerializeSize(uint64(witnessScriptSize)) + witnessScriptSize
+	switch multisig.ScriptType {
+	case signing.ScriptTypeP2WSH:
+		return 0, witnessSize
+	case signing.ScriptTypeP2WSHP2SH:
+		// OP_0 (1 byte) OP_32 (1 byte) witnessScriptHash (32 bytes)
+		const redeemScriptSize = 1 + 1 + 32
+		// OP_DATA_34 (1 Byte) redeemScript (34 bytes)
+		return 1 + redeemScriptSize, witnessSize
+	default:
+		panic("unknown multisig address type")
+	}
+}
+
+// inputScriptSizes are the sigscript and witness sizes of an input.
+type inputScriptSizes struct {
+	sigScriptSize int
+	witnessSize   int
+}
+
+// scriptTypeSigScriptWitnessSize is like sigScriptWitnessSize(), for a script type.
+func scriptTypeSigScriptWitnessSize(scriptType signing.ScriptType) (int, int) {
+	switch scriptType {
 	case signing.ScriptTypeP2PKH:
 		// OP_DATA_72
 		// 72 bytes of signature data (including SIGHASH op)
@@ -78,16 +114,42 @@ func sigScriptWitnessSize(configuration *signing.Configuration) (int, int) {
 // <serialized sig> <serialized compressed pubkey>
 //
 // inputConfigurations defines the number of inputs and the input configurations in the tx.
-// outputPkScriptSize is the size of the output pkScript. One output is assumed (apart from change).
+// outputPkScriptSizes are the sizes of the pkScripts of the outputs (apart from change).
 // changePkScriptSize  is the size of the change pkScript. A value of 0 means that there is no change output.
 // This function computes the virtual size of a transaction, taking segwit discount into account.
 func estimateTxSize(
 	inputConfigurations []*signing.Configuration,
-	outputPkScriptSize int,
+	outputPkScriptSizes []int,
 	changePkScriptSize int) int {
-	outputCount := 2 // 1 output + 1 change output
-	if changePkScriptSize == 0 {
-		outputCount = 1
+	inputSizes := make([]inputScriptSizes, len(inputConfigurations))
+	for i, inputConfiguration := range inputConfigurations {
+		inputSizes[i].sigScriptSize, inputSizes[i].witnessSize = sigScriptWitnessSize(inputConfiguration)
+	}
+	return estimateTxSizeForInputSizes(inputSizes, outputPkScriptSizes, changePkScriptSize)
+}
+
+// estimateTxSizeForScriptTypes is like estimateTxSize(), with the inputs given by their script
+// types.
+func estimateTxSizeForScriptTypes(
+	inputScriptTypes []signing.ScriptType,
+	outputPkScriptSizes []int,
+	changePkScriptSize int) int {
+	inputSizes := make([]inputScriptSizes, len(inputScriptTypes))
+	for i, inputScriptType := range inputScriptTypes {
+		inputSizes[i].sigScriptSize, inputSizes[i].witnessSize = scriptTypeSigScriptWitnessSize(inputScriptType)
+	}
+	return estimateTxSizeForInputSizes(inputSizes, outputPkScriptSizes, changePkScriptSize)
+}
+
+// estimateTxSizeForInputSizes is like estimateTxSize(), with the inputs given by their sigscript
+// and witness sizes.
+func estimateTxSizeForInputSizes(
+	inputSizes []inputScriptSizes,
+	outputPkScriptSizes []int,
+	changePkScriptSize int) int {
+	outputCount := len(outputPkScriptSizes)
+	if changePkScriptSize != 0 {
+		outputCount++
 	}
 
 	const (
@@ -97,24 +159,24 @@ func estimateTxSize(
 		nonWitness = 4
 	)
 
-	txWeight := nonWitness * (versionSize + lockTimeSize + wire.VarIntSerializeSize(uint64(len(inputConfigurations))) +
+	txWeight := nonWitness * (versionSize + lockTimeSize + wire.VarIntSerializeSize(uint64(len(inputSizes))) +
 		wire.VarIntSerializeSize(uint64(outputCount)) +
-		outputSize(outputPkScriptSize) +
 		outputSize(changePkScriptSize))
+	for _, outputPkScriptSize := range outputPkScriptSizes {
+		txWeight += nonWitness * outputSize(outputPkScriptSize)
+	}
 
 	isSegwitTx := false
-	for _, inputConfiguration := range inputConfigurations {
-		_, witnessSize := sigScriptWitnessSize(inputConfiguration)
-		if witnessSize > 0 {
+	for _, inputSize := range inputSizes {
+		if inputSize.witnessSize > 0 {
 			isSegwitTx = true
 			break
 		}
 	}
 
-	for _, inputConfiguration := range inputConfigurations {
-		sigScriptSize, witnessSize := sigScriptWitnessSize(inputConfiguration)
-		txWeight += nonWitness*calcInputSize(sigScriptSize) + witnessSize
-		if isSegwitTx && witnessSize == 0 {
+	for _, inputSize := range inputSizes {
+		txWeight += nonWitness*calcInputSize(inputSize.sigScriptSize) + inputSize.witnessSize
+		if isSegwitTx && inputSize.witnessSize == 0 {
 			// "Empty script witnesses are encoded as a zero byte"
 			// https://github.com/bitcoin/bips/blob/d8a56c9f2b521bf4af5d588f217e7618cc44952c/bip-0144.mediawiki
 			txWeight += wire.VarIntSerializeSize(0)
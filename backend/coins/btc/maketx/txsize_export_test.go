@@ -18,10 +18,10 @@ import "github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
 
 func TstEstimateTxSize(
 	inputConfigurations []*signing.Configuration,
-	outputPkScriptSize int,
+	outputPkScriptSizes []int,
 	changePkScriptSize int) int {
 	return estimateTxSize(
 		inputConfigurations,
-		outputPkScriptSize,
+		outputPkScriptSizes,
 		changePkScriptSize)
 }
@@ -20,6 +20,7 @@ import (
 	"math/big"
 	"testing"
 
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/addresses"
 	addressesTest "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/addresses/test"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/types"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
@@ -58,7 +59,7 @@ func makeSig() types.Signature {
 }
 
 func testEstimateTxSize(
-	t *testing.T, useSegwit bool, outputScriptType, changeScriptType signing.ScriptType) {
+	t *testing.T, useSegwit bool, numOutputs int, outputScriptType, changeScriptType signing.ScriptType) {
 	t.Helper()
 	sig := makeSig()
 
@@ -69,16 +70,17 @@ func testEstimateTxSize(
 
 	outputPkScript := addressesTest.GetAddress(outputScriptType).PubkeyScript()
 	tx := &wire.MsgTx{
-		Version: wire.TxVersion,
-		// One output and one change.
-		TxOut: []*wire.TxOut{
-			{
-				Value:    1,
-				PkScript: outputPkScript,
-			},
-		},
+		Version:  wire.TxVersion,
 		LockTime: 0,
 	}
+	var outputPkScriptSizes []int
+	for i := 0; i < numOutputs; i++ {
+		tx.TxOut = append(tx.TxOut, &wire.TxOut{
+			Value:    1,
+			PkScript: outputPkScript,
+		})
+		outputPkScriptSizes = append(outputPkScriptSizes, len(outputPkScript))
+	}
 
 	var inputConfigurations []*signing.Configuration
 	// Add each type of input, multiple times.  Only once might not catch errors that
@@ -108,7 +110,7 @@ func testEstimateTxSize(
 
 	estimatedSize := estimateTxSize(
 		inputConfigurations,
-		len(outputPkScript), changePkScriptSize)
+		outputPkScriptSizes, changePkScriptSize)
 	require.Equal(t, mempool.GetTxVirtualSize(btcutil.NewTx(tx)), int64(estimatedSize))
 
 }
@@ -140,15 +142,68 @@ func TestEstimateTxSize(t *testing.T) {
 	}
 
 	for _, useSegwit := range []bool{false, true} {
-		for _, outputScriptType := range scriptTypes {
-			t.Run(fmt.Sprintf("output=%s,noChange,segwit=%v", outputScriptType, useSegwit), func(t *testing.T) {
-				testEstimateTxSize(t, useSegwit, outputScriptType, "")
-			})
-			for _, changeScriptType := range scriptTypes {
-				t.Run(fmt.Sprintf("output=%s,change=%s,segwit=%v", outputScriptType, changeScriptType, useSegwit), func(t *testing.T) {
-					testEstimateTxSize(t, useSegwit, outputScriptType, changeScriptType)
+		for _, numOutputs := range []int{1, 3} {
+			for _, outputScriptType := range scriptTypes {
+				t.Run(fmt.Sprintf("outputs=%dx%s,noChange,segwit=%v", numOutputs, outputScriptType, useSegwit), func(t *testing.T) {
+					testEstimateTxSize(t, useSegwit, numOutputs, outputScriptType, "")
 				})
+				for _, changeScriptType := range scriptTypes {
+					t.Run(fmt.Sprintf("outputs=%dx%s,change=%s,segwit=%v", numOutputs, outputScriptType, changeScriptType, useSegwit), func(t *testing.T) {
+						testEstimateTxSize(t, useSegwit, numOutputs, outputScriptType, changeScriptType)
+					})
+				}
 			}
 		}
 	}
 }
+
+// multisigSigScriptWitness returns the sigscript and witness spending a multisig address with
+// `threshold` signatures.
+func multisigSigScriptWitness(address *addresses.AccountAddress, threshold int) ([]byte, wire.TxWitness) {
+	sig := makeSig()
+	sigScript, witness := address.SignatureScript(sig)
+	signatures := make(wire.TxWitness, threshold)
+	for i := range signatures {
+		signatures[i] = witness[1]
+	}
+	fullWitness := append(append(wire.TxWitness{nil}, signatures...), witness[2])
+	return sigScript, fullWitness
+}
+
+func TestSigScriptWitnessSizeMultisig(t *testing.T) {
+	for _, scriptType := range []signing.ScriptType{signing.ScriptTypeP2WSH, signing.ScriptTypeP2WSHP2SH} {
+		for _, kn := range [][2]int{{1, 2}, {2, 3}, {3, 5}, {15, 15}} {
+			address := addressesTest.GetMultisigAddress(scriptType, kn[0], kn[1])
+			t.Run(address.AccountConfiguration.String(), func(t *testing.T) {
+				sigScriptSize, witnessSize := sigScriptWitnessSize(address.AccountConfiguration)
+				sigScript, witness := multisigSigScriptWitness(address, kn[0])
+				require.Equal(t, len(sigScript), sigScriptSize)
+				require.Equal(t, witness.SerializeSize(), witnessSize)
+			})
+		}
+	}
+}
+
+func TestEstimateTxSizeMultisig(t *testing.T) {
+	outputPkScript := addressesTest.GetAddress(signing.ScriptTypeP2WPKH).PubkeyScript()
+	changeAddress := addressesTest.GetMultisigAddress(signing.ScriptTypeP2WSH, 2, 3)
+	tx := &wire.MsgTx{
+		Version: wire.TxVersion,
+		TxOut: []*wire.TxOut{
+			{Value: 1, PkScript: outputPkScript},
+			{Value: 1, PkScript: changeAddress.PubkeyScript()},
+		},
+	}
+	var inputConfigurations []*signing.Configuration
+	for counter := 0; counter < 10; counter++ {
+		for _, scriptType := range []signing.ScriptType{signing.ScriptTypeP2WSH, signing.ScriptTypeP2WSHP2SH} {
+			address := addressesTest.GetMultisigAddress(scriptType, 2, 3)
+			sigScript, witness := multisigSigScriptWitness(address, 2)
+			tx.TxIn = append(tx.TxIn, &wire.TxIn{SignatureScript: sigScript, Witness: witness})
+			inputConfigurations = append(inputConfigurations, address.AccountConfiguration)
+		}
+	}
+	estimatedSize := estimateTxSize(
+		inputConfigurations, []int{len(outputPkScript)}, len(changeAddress.PubkeyScript()))
+	require.Equal(t, mempool.GetTxVirtualSize(btcutil.NewTx(tx)), int64(estimatedSize))
+}
@@ -0,0 +1,150 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package btc
+
+import (
+	"bytes"
+
+	coinpkg "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// MessageSigner is the signer of a message, recovered from an Electrum-style compact signature.
+type MessageSigner struct {
+	PublicKey  *btcec.PublicKey
+	ScriptType signing.ScriptType
+	Address    btcutil.Address
+}
+
+// messageMagic returns the prefix of signed messages, which makes sure that a message signature
+// can't be a transaction signature.
+func messageMagic(coin *Coin) string {
+	switch coin.Code() {
+	case coinpkg.CodeLTC, coinpkg.CodeTLTC:
+		return "Litecoin Signed Message:\n"
+	default:
+		return "Bitcoin Signed Message:\n"
+	}
+}
+
+// messageHash returns the hash which is signed in Electrum-style message signatures.
+func messageHash(coin *Coin, message []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := wire.WriteVarString(&buf, 0, messageMagic(coin)); err != nil {
+		return nil, errp.WithStack(err)
+	}
+	if err := wire.WriteVarBytes(&buf, 0, message); err != nil {
+		return nil, errp.WithStack(err)
+	}
+	return chainhash.DoubleHashB(buf.Bytes()), nil
+}
+
+// messageSignerAddress returns the address of the public key for the given script type.
+func messageSignerAddress(
+	coin *Coin, publicKey *btcec.PublicKey, compressed bool, scriptType signing.ScriptType,
+) (btcutil.Address, error) {
+	serializedPublicKey := publicKey.SerializeCompressed()
+	if !compressed {
+		serializedPublicKey = publicKey.SerializeUncompressed()
+	}
+	publicKeyHash := btcutil.Hash160(serializedPublicKey)
+	switch scriptType {
+	case signing.ScriptTypeP2PKH:
+		return btcutil.NewAddressPubKeyHash(publicKeyHash, coin.Net())
+	case signing.ScriptTypeP2WPKH:
+		return btcutil.NewAddressWitnessPubKeyHash(publicKeyHash, coin.Net())
+	case signing.ScriptTypeP2WPKHP2SH:
+		redeemScript, err := txscript.NewScriptBuilder().
+			AddOp(txscript.OP_0).
+			AddData(publicKeyHash).
+			Script()
+		if err != nil {
+			return nil, errp.WithStack(err)
+		}
+		return btcutil.NewAddressScriptHash(redeemScript, coin.Net())
+	default:
+		return nil, errp.Newf("Unsupported script type: %s", scriptType)
+	}
+}
+
+// RecoverMessageSigner recovers the signer of an Electrum-style compact message signature, as
+// created by `SignBTCMessage()` of the keystores. The header byte of the signature indicates the
+// script type of the signer (27-34 for P2PKH, 35-38 for P2WPKH-P2SH and 39-42 for P2WPKH), but
+// many wallets use the P2PKH header for segwit addresses. All script types are therefore
+// considered, and the signer matching `address` is returned. If none matches, the signer of the
+// script type indicated by the header is returned, which has a different address.
+//
+// Taproot addresses can't create such signatures, see SignMessageBIP322.
+func RecoverMessageSigner(
+	coin *Coin, address string, message []byte, signature []byte) (*MessageSigner, error) {
+	expectedAddress, err := coin.decodeAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	if len(signature) != 65 || signature[0] < 27 || signature[0] > 42 {
+		return nil, errp.New("Invalid compact signature")
+	}
+	header := signature[0] - 27
+	compressed := header >= 4
+	headerScriptType := signing.ScriptTypeP2PKH
+	switch {
+	case header >= 12:
+		headerScriptType = signing.ScriptTypeP2WPKH
+	case header >= 8:
+		headerScriptType = signing.ScriptTypeP2WPKHP2SH
+	}
+
+	hash, err := messageHash(coin, message)
+	if err != nil {
+		return nil, err
+	}
+	// Normalize the header to the P2PKH range expected by btcec.
+	normalized := append([]byte{27 + header&3}, signature[1:]...)
+	if compressed {
+		normalized[0] += 4
+	}
+	publicKey, _, err := ecdsa.RecoverCompact(normalized, hash)
+	if err != nil {
+		return nil, errp.WithStack(err)
+	}
+
+	scriptTypes := []signing.ScriptType{headerScriptType}
+	if compressed {
+		scriptTypes = append(scriptTypes,
+			signing.ScriptTypeP2PKH, signing.ScriptTypeP2WPKHP2SH, signing.ScriptTypeP2WPKH)
+	}
+	var headerSigner *MessageSigner
+	for _, scriptType := range scriptTypes {
+		signerAddress, err := messageSignerAddress(coin, publicKey, compressed, scriptType)
+		if err != nil {
+			return nil, err
+		}
+		signer := &MessageSigner{PublicKey: publicKey, ScriptType: scriptType, Address: signerAddress}
+		if signerAddress.EncodeAddress() == expectedAddress.EncodeAddress() {
+			return signer, nil
+		}
+		if headerSigner == nil {
+			headerSigner = signer
+		}
+	}
+	return headerSigner, nil
+}
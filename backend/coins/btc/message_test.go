@@ -0,0 +1,92 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package btc
+
+import (
+	"testing"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecoverMessageSigner(t *testing.T) {
+	coin := bip322TestCoin(t)
+	privateKey, _ := btcec.PrivKeyFromBytes(append(make([]byte, 31), 1))
+	message := []byte("Hello World")
+	hash, err := messageHash(coin, message)
+	require.NoError(t, err)
+
+	sign := func(compressed bool, headerOffset byte) []byte {
+		t.Helper()
+		signature := ecdsa.SignCompact(privateKey, hash, compressed)
+		signature[0] += headerOffset
+		return signature
+	}
+	address := func(compressed bool, scriptType signing.ScriptType) string {
+		t.Helper()
+		address, err := messageSignerAddress(coin, privateKey.PubKey(), compressed, scriptType)
+		require.NoError(t, err)
+		return address.EncodeAddress()
+	}
+
+	for _, test := range []struct {
+		scriptType   signing.ScriptType
+		headerOffset byte
+	}{
+		{signing.ScriptTypeP2PKH, 0},
+		{signing.ScriptTypeP2WPKHP2SH, 4},
+		{signing.ScriptTypeP2WPKH, 8},
+		// Segwit signatures with the P2PKH header.
+		{signing.ScriptTypeP2WPKHP2SH, 0},
+		{signing.ScriptTypeP2WPKH, 0},
+	} {
+		expectedAddress := address(true, test.scriptType)
+		signer, err := RecoverMessageSigner(coin, expectedAddress, message, sign(true, test.headerOffset))
+		require.NoError(t, err)
+		require.Equal(t, test.scriptType, signer.ScriptType)
+		require.Equal(t, expectedAddress, signer.Address.EncodeAddress())
+		require.True(t, signer.PublicKey.IsEqual(privateKey.PubKey()))
+	}
+
+	// Uncompressed public keys are only used for P2PKH.
+	signer, err := RecoverMessageSigner(coin, address(false, signing.ScriptTypeP2PKH), message, sign(false, 0))
+	require.NoError(t, err)
+	require.Equal(t, address(false, signing.ScriptTypeP2PKH), signer.Address.EncodeAddress())
+	signer, err = RecoverMessageSigner(coin, address(true, signing.ScriptTypeP2PKH), message, sign(false, 0))
+	require.NoError(t, err)
+	require.NotEqual(t, address(true, signing.ScriptTypeP2PKH), signer.Address.EncodeAddress())
+
+	// A different message or address results in a different signer of the script type of the
+	// header.
+	signer, err = RecoverMessageSigner(coin, address(true, signing.ScriptTypeP2WPKH), []byte("Hello"), sign(true, 8))
+	require.NoError(t, err)
+	require.Equal(t, signing.ScriptTypeP2WPKH, signer.ScriptType)
+	require.NotEqual(t, address(true, signing.ScriptTypeP2WPKH), signer.Address.EncodeAddress())
+	signer, err = RecoverMessageSigner(coin, bip322TestAddress, message, sign(true, 8))
+	require.NoError(t, err)
+	require.Equal(t, address(true, signing.ScriptTypeP2WPKH), signer.Address.EncodeAddress())
+
+	// Invalid input.
+	_, err = RecoverMessageSigner(coin, "invalid", message, sign(true, 0))
+	require.Error(t, err)
+	_, err = RecoverMessageSigner(coin, bip322TestAddress, message, sign(true, 0)[:64])
+	require.Error(t, err)
+	invalidHeader := sign(true, 0)
+	invalidHeader[0] = 43
+	_, err = RecoverMessageSigner(coin, bip322TestAddress, message, invalidHeader)
+	require.Error(t, err)
+}
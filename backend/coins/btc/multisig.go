@@ -0,0 +1,36 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package btc
+
+import (
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+)
+
+// ErrMultisigNeedsCosigners is returned when trying to send from a multisig account which needs
+// the signatures of other cosigners. Such transactions are signed with SignPSBT() by each cosigner
+// and broadcasted with SendPSBT().
+const ErrMultisigNeedsCosigners errp.ErrorCode = "multisigNeedsCosigners"
+
+// multisig returns the multisig configuration of the account, or nil if it is not a multisig
+// account. A multisig account has exactly one signing configuration.
+func (account *Account) multisig() *signing.BitcoinMultisig {
+	for _, signingConfiguration := range account.Config().Config.SigningConfigurations {
+		if signingConfiguration.BitcoinMultisig != nil {
+			return signingConfiguration.BitcoinMultisig
+		}
+	}
+	return nil
+}
@@ -0,0 +1,479 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package btc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/blockchain"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/maketx"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/keystore"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/mempool"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+const (
+	// payjoinTimeout is how long we wait for the payjoin proposal of the receiver. BIP-78
+	// recommends waiting up to one minute.
+	payjoinTimeout = time.Minute
+	// payjoinMaxResponseSize limits the size of the response of the receiver.
+	payjoinMaxResponseSize = 1 << 20
+)
+
+// validatePayjoinEndpoint checks that a payjoin endpoint can be used. Like BIP-78 requires, the
+// connection to the receiver must be encrypted, i.e. use https or an onion service.
+func validatePayjoinEndpoint(endpoint string) error {
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil {
+		return errp.WithMessage(err, "Invalid payjoin endpoint")
+	}
+	if endpointURL.Scheme == "https" ||
+		(endpointURL.Scheme == "http" && strings.HasSuffix(endpointURL.Hostname(), ".onion")) {
+		return nil
+	}
+	return errp.New("The payjoin endpoint must use https or an onion service")
+}
+
+// payjoinParams are the parameters of a payjoin request, see BIP-78.
+type payjoinParams struct {
+	// feeRatePerKb is the fee rate of the original transaction.
+	feeRatePerKb btcutil.Amount
+	// inputVSize is the virtual size of our inputs, which the receiver inputs are expected to match.
+	inputVSize int
+	// feeOutputIndex is the index of our change output, from which the receiver can deduct the fee
+	// of the inputs they add, or -1 if there is no change.
+	feeOutputIndex int
+	// maxContribution is the maximum amount the receiver can deduct from the change output: the fee
+	// of one additional input at the fee rate of the original transaction.
+	maxContribution btcutil.Amount
+}
+
+// newPayjoinParams computes the payjoin parameters for a signed original transaction.
+func newPayjoinParams(txProposal *maketx.TxProposal) *payjoinParams {
+	transaction := txProposal.Transaction
+	vsize := mempool.GetTxVirtualSize(btcutil.NewTx(transaction))
+	params := &payjoinParams{
+		feeRatePerKb: txProposal.Fee * 1000 / btcutil.Amount(vsize),
+		inputVSize: maketx.InputVSize(
+			txProposal.PreviousOutputs[transaction.TxIn[0].PreviousOutPoint].Address.AccountConfiguration),
+		feeOutputIndex: -1,
+	}
+	if txProposal.ChangeAddress != nil {
+		changePkScript := txProposal.ChangeAddress.PubkeyScript()
+		for index, txOut := range transaction.TxOut {
+			if bytes.Equal(txOut.PkScript, changePkScript) {
+				params.feeOutputIndex = index
+				params.maxContribution = params.feeRatePerKb * btcutil.Amount(params.inputVSize) / 1000
+				break
+			}
+		}
+	}
+	return params
+}
+
+// payjoinOriginalPSBT returns the original PSBT sent to the payjoin receiver. It contains the fully
+// signed original transaction and the outputs spent by it, but no key origin info.
+func (account *Account) payjoinOriginalPSBT(txProposal *maketx.TxProposal) (*psbt.Packet, error) {
+	packet, scriptSigs, witnesses, err := psbt.NewFromSignedTx(txProposal.Transaction)
+	if err != nil {
+		return nil, errp.WithStack(err)
+	}
+	for index, txIn := range packet.UnsignedTx.TxIn {
+		prevOut := txProposal.PreviousOutputs[txIn.PreviousOutPoint]
+		pInput := &packet.Inputs[index]
+		if prevOut.Address.AccountConfiguration.ScriptType() == signing.ScriptTypeP2PKH {
+			prevTx, err := account.coin.Blockchain().TransactionGet(txIn.PreviousOutPoint.Hash)
+			if err != nil {
+				return nil, err
+			}
+			pInput.NonWitnessUtxo = prevTx
+		} else {
+			pInput.WitnessUtxo = prevOut.TxOut
+		}
+		if len(scriptSigs[index]) != 0 {
+			pInput.FinalScriptSig = scriptSigs[index]
+		}
+		if len(witnesses[index]) != 0 {
+			var witness bytes.Buffer
+			if err := psbt.WriteTxWitness(&witness, witnesses[index]); err != nil {
+				return nil, errp.WithStack(err)
+			}
+			pInput.FinalScriptWitness = witness.Bytes()
+		}
+	}
+	return packet, nil
+}
+
+// requestPayjoin posts the original PSBT to the payjoin endpoint of the receiver and returns their
+// payjoin proposal. Output substitution is disabled, so the receiver can't change the output we pay
+// to, except for increasing its amount.
+func (account *Account) requestPayjoin(
+	endpoint string, original *psbt.Packet, params *payjoinParams) (*psbt.Packet, error) {
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, errp.WithStack(err)
+	}
+	query := endpointURL.Query()
+	query.Set("v", "1")
+	query.Set("disableoutputsubstitution", "true")
+	if params.feeOutputIndex >= 0 {
+		query.Set("additionalfeeoutputindex", strconv.Itoa(params.feeOutputIndex))
+		query.Set("maxadditionalfeecontribution", strconv.FormatInt(int64(params.maxContribution), 10))
+	}
+	endpointURL.RawQuery = query.Encode()
+
+	encoded, err := original.B64Encode()
+	if err != nil {
+		return nil, errp.WithStack(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), payjoinTimeout)
+	defer cancel()
+	request, err := http.NewRequestWithContext(
+		ctx, http.MethodPost, endpointURL.String(), strings.NewReader(encoded))
+	if err != nil {
+		return nil, errp.WithStack(err)
+	}
+	request.Header.Set("Content-Type", "text/plain")
+	response, err := account.httpClient.Do(request)
+	if err != nil {
+		return nil, errp.WithStack(err)
+	}
+	defer func() { _ = response.Body.Close() }()
+	body, err := io.ReadAll(io.LimitReader(response.Body, payjoinMaxResponseSize))
+	if err != nil {
+		return nil, errp.WithStack(err)
+	}
+	if response.StatusCode != http.StatusOK {
+		var receiverError struct {
+			ErrorCode string `json:"errorCode"`
+			Message   string `json:"message"`
+		}
+		if err := json.Unmarshal(body, &receiverError); err != nil || receiverError.ErrorCode == "" {
+			return nil, errp.Newf("The payjoin receiver responded with status %d", response.StatusCode)
+		}
+		return nil, errp.Newf("The payjoin receiver responded with error %s: %s",
+			receiverError.ErrorCode, receiverError.Message)
+	}
+	proposal, err := psbt.NewFromRawBytes(bytes.NewReader(bytes.TrimSpace(body)), true)
+	if err != nil {
+		return nil, errp.WithMessage(err, "Invalid payjoin proposal")
+	}
+	return proposal, nil
+}
+
+// payjoinSpentOutput returns the output spent by an input added by the receiver to the payjoin
+// proposal.
+func payjoinSpentOutput(txIn *wire.TxIn, pInput *psbt.PInput) (*wire.TxOut, error) {
+	if pInput.NonWitnessUtxo != nil {
+		outPoint := txIn.PreviousOutPoint
+		if pInput.NonWitnessUtxo.TxHash() != outPoint.Hash ||
+			outPoint.Index >= uint32(len(pInput.NonWitnessUtxo.TxOut)) {
+			return nil, errp.New("The previous transaction does not match the input")
+		}
+		txOut := pInput.NonWitnessUtxo.TxOut[outPoint.Index]
+		if pInput.WitnessUtxo != nil && !psbt.TxOutsEqual(txOut, pInput.WitnessUtxo) {
+			return nil, errp.New("The spent output does not match the previous transaction")
+		}
+		return txOut, nil
+	}
+	if pInput.WitnessUtxo != nil {
+		return pInput.WitnessUtxo, nil
+	}
+	return nil, errp.New("The spent output is missing")
+}
+
+// validatePayjoinProposal performs the checks of the sender on the payjoin proposal of the receiver
+// (BIP-78). The receiver may only add their own signed inputs of the same script type as ours, add
+// outputs, increase the output we pay to, and deduct at most the max fee contribution from our
+// change output to pay for the fee of their inputs. Our inputs must be unchanged and unsigned.
+// Returns the outputs spent by the proposal.
+func (account *Account) validatePayjoinProposal(
+	txProposal *maketx.TxProposal,
+	proposal *psbt.Packet,
+	params *payjoinParams,
+) (maketx.PreviousOutputs, error) {
+	original := txProposal.Transaction
+	proposalTx := proposal.UnsignedTx
+	if proposalTx.Version != original.Version || proposalTx.LockTime != original.LockTime {
+		return nil, errp.New("The payjoin proposal changed the version or locktime")
+	}
+
+	originalInputs := make(map[wire.OutPoint]*wire.TxIn, len(original.TxIn))
+	ourScriptTypes := map[string]struct{}{}
+	for _, txIn := range original.TxIn {
+		originalInputs[txIn.PreviousOutPoint] = txIn
+		pkScript := txProposal.PreviousOutputs[txIn.PreviousOutPoint].TxOut.PkScript
+		ourScriptTypes[outputScriptType(pkScript)] = struct{}{}
+	}
+	utxos, err := account.transactions.SpendableOutputs()
+	if err != nil {
+		return nil, err
+	}
+	previousOutputs := make(maketx.PreviousOutputs, len(proposalTx.TxIn))
+	receiverInputs := 0
+	for index, txIn := range proposalTx.TxIn {
+		pInput := &proposal.Inputs[index]
+		if len(pInput.Bip32Derivation) != 0 || len(pInput.TaprootBip32Derivation) != 0 {
+			return nil, errp.Newf("Payjoin proposal input %d contains key paths", index)
+		}
+		if len(pInput.PartialSigs) != 0 || len(pInput.TaprootKeySpendSig) != 0 {
+			return nil, errp.Newf("Payjoin proposal input %d contains partial signatures", index)
+		}
+		if _, ok := previousOutputs[txIn.PreviousOutPoint]; ok {
+			return nil, errp.Newf("Payjoin proposal input %d is a duplicate", index)
+		}
+		finalized := len(pInput.FinalScriptSig) != 0 || len(pInput.FinalScriptWitness) != 0
+		if originalTxIn, ok := originalInputs[txIn.PreviousOutPoint]; ok {
+			if txIn.Sequence != originalTxIn.Sequence {
+				return nil, errp.Newf("Payjoin proposal input %d changed the sequence", index)
+			}
+			if finalized || pInput.WitnessUtxo != nil || pInput.NonWitnessUtxo != nil {
+				return nil, errp.Newf("Payjoin proposal input %d must not be signed", index)
+			}
+			previousOutputs[txIn.PreviousOutPoint] = txProposal.PreviousOutputs[txIn.PreviousOutPoint]
+			continue
+		}
+
+		// An input added by the receiver.
+		if !finalized {
+			return nil, errp.Newf("Payjoin proposal input %d of the receiver is not signed", index)
+		}
+		if txIn.Sequence != original.TxIn[0].Sequence {
+			return nil, errp.Newf("Payjoin proposal input %d has a different sequence", index)
+		}
+		spentOutput, err := payjoinSpentOutput(txIn, pInput)
+		if err != nil {
+			return nil, errp.WithMessage(err, "Payjoin proposal input "+strconv.Itoa(index))
+		}
+		if _, ok := utxos[txIn.PreviousOutPoint]; ok ||
+			account.GetAddress(blockchain.NewScriptHashHex(spentOutput.PkScript)) != nil {
+			return nil, errp.Newf("Payjoin proposal input %d spends a coin of this account", index)
+		}
+		if _, ok := ourScriptTypes[outputScriptType(spentOutput.PkScript)]; len(ourScriptTypes) == 1 && !ok {
+			return nil, errp.Newf("Payjoin proposal input %d has a different script type", index)
+		}
+		previousOutputs[txIn.PreviousOutPoint] = maketx.UTXO{TxOut: spentOutput}
+		receiverInputs++
+	}
+	for outPoint := range originalInputs {
+		if _, ok := previousOutputs[outPoint]; !ok {
+			return nil, errp.New("The payjoin proposal removed one of our inputs")
+		}
+	}
+
+	// The original outputs must be kept in the same order. Outputs added by the receiver can be
+	// anywhere in between.
+	contribution := btcutil.Amount(0)
+	originalIndex := 0
+	for index, txOut := range proposalTx.TxOut {
+		pOutput := &proposal.Outputs[index]
+		if len(pOutput.Bip32Derivation) != 0 || len(pOutput.TaprootBip32Derivation) != 0 {
+			return nil, errp.Newf("Payjoin proposal output %d contains key paths", index)
+		}
+		if originalIndex == len(original.TxOut) ||
+			!bytes.Equal(txOut.PkScript, original.TxOut[originalIndex].PkScript) {
+			continue
+		}
+		originalValue := original.TxOut[originalIndex].Value
+		switch originalIndex {
+		case params.feeOutputIndex:
+			contribution = btcutil.Amount(originalValue - txOut.Value)
+			if contribution < 0 || contribution > params.maxContribution {
+				return nil, errp.Newf("The fee contribution of %d sat deducted from our change is "+
+					"invalid", contribution)
+			}
+		case txProposal.OutIndex:
+			if txOut.Value < originalValue {
+				return nil, errp.New("The payjoin proposal decreased the amount paid to the receiver")
+			}
+		default:
+			if txOut.Value != originalValue {
+				return nil, errp.Newf("The payjoin proposal changed the amount of output %d", index)
+			}
+		}
+		originalIndex++
+	}
+	if originalIndex != len(original.TxOut) {
+		return nil, errp.New("The payjoin proposal removed one of the original outputs")
+	}
+
+	var fee btcutil.Amount
+	for _, previousOutput := range previousOutputs {
+		fee += btcutil.Amount(previousOutput.TxOut.Value)
+	}
+	for _, txOut := range proposalTx.TxOut {
+		fee -= btcutil.Amount(txOut.Value)
+	}
+	if fee < txProposal.Fee {
+		return nil, errp.New("The payjoin proposal decreased the fee")
+	}
+	// Our contribution must only pay for the fee of the inputs added by the receiver.
+	if contribution > fee-txProposal.Fee {
+		return nil, errp.New("The fee contribution of the payjoin proposal does not only pay the fee")
+	}
+	if contribution > params.feeRatePerKb*btcutil.Amount(params.inputVSize*receiverInputs)/1000 {
+		return nil, errp.New("The fee contribution of the payjoin proposal is above the fee of the " +
+			"added inputs")
+	}
+	return previousOutputs, nil
+}
+
+// signPayjoinProposal signs our inputs of a validated payjoin proposal and returns the final
+// transaction, including the signatures of the receiver. The final transaction is checked to be
+// fully valid.
+func (account *Account) signPayjoinProposal(
+	txProposal *maketx.TxProposal,
+	proposal *psbt.Packet,
+	previousOutputs maketx.PreviousOutputs,
+) (*wire.MsgTx, error) {
+	var fee btcutil.Amount
+	for _, previousOutput := range previousOutputs {
+		fee += btcutil.Amount(previousOutput.TxOut.Value)
+	}
+	for _, txOut := range proposal.UnsignedTx.TxOut {
+		fee -= btcutil.Amount(txOut.Value)
+	}
+	payjoinTxProposal := &maketx.TxProposal{
+		Coin:            account.coin,
+		Amount:          txProposal.Amount,
+		Fee:             fee,
+		Transaction:     proposal.UnsignedTx.Copy(),
+		ChangeAddress:   txProposal.ChangeAddress,
+		PreviousOutputs: previousOutputs,
+		PaymentRequest:  txProposal.PaymentRequest,
+	}
+	recipientPkScript := txProposal.Transaction.TxOut[txProposal.OutIndex].PkScript
+	for index, txOut := range payjoinTxProposal.Transaction.TxOut {
+		if bytes.Equal(txOut.PkScript, recipientPkScript) {
+			payjoinTxProposal.OutIndex = index
+			break
+		}
+	}
+
+	account.log.Info("Signing payjoin transaction")
+	proposedTransaction, err := account.signTxProposal(payjoinTxProposal, account.coin.Blockchain().TransactionGet)
+	if err != nil {
+		return nil, err
+	}
+	for index, txIn := range payjoinTxProposal.Transaction.TxIn {
+		address := previousOutputs[txIn.PreviousOutPoint].Address
+		if address == nil {
+			// Signed by the receiver.
+			continue
+		}
+		signature := proposedTransaction.Signatures[index]
+		if signature == nil {
+			return nil, errp.New("Signature missing")
+		}
+		signatureScript, witness := address.SignatureScript(*signature)
+		pInput := &proposal.Inputs[index]
+		if len(signatureScript) != 0 {
+			pInput.FinalScriptSig = signatureScript
+		}
+		if len(witness) != 0 {
+			var serializedWitness bytes.Buffer
+			if err := psbt.WriteTxWitness(&serializedWitness, witness); err != nil {
+				return nil, errp.WithStack(err)
+			}
+			pInput.FinalScriptWitness = serializedWitness.Bytes()
+		}
+	}
+	transaction, err := psbt.Extract(proposal)
+	if err != nil {
+		return nil, errp.WithStack(err)
+	}
+	if err := TxValidityCheck(transaction, previousOutputs,
+		txscript.NewTxSigHashes(transaction, previousOutputs)); err != nil {
+		return nil, err
+	}
+	return transaction, nil
+}
+
+// payjoin asks the receiver for a payjoin proposal for the signed original transaction, validates
+// it, and returns the signed payjoin transaction.
+func (account *Account) payjoin(txProposal *maketx.TxProposal) (*wire.MsgTx, error) {
+	original, err := account.payjoinOriginalPSBT(txProposal)
+	if err != nil {
+		return nil, err
+	}
+	params := newPayjoinParams(txProposal)
+	account.log.Info("Requesting payjoin proposal")
+	proposal, err := account.requestPayjoin(txProposal.PayjoinEndpoint, original, params)
+	if err != nil {
+		return nil, err
+	}
+	previousOutputs, err := account.validatePayjoinProposal(txProposal, proposal, params)
+	if err != nil {
+		return nil, err
+	}
+	return account.signPayjoinProposal(txProposal, proposal, previousOutputs)
+}
+
+// sendPayjoin makes a payjoin payment (BIP-78) to the receiver of the signed tx proposal. The
+// receiver adds their inputs to the original transaction, and the resulting transaction is signed
+// and broadcast. If the payjoin is not possible or fails for any reason, the original transaction
+// is broadcast instead, so the payment is made in any case. Returns true if the payment was made as
+// a payjoin.
+func (account *Account) sendPayjoin(txProposal *maketx.TxProposal, txNote string) (bool, error) {
+	original := txProposal.Transaction
+	signingKeystore, err := account.Config().ConnectKeystore()
+	if err != nil {
+		return false, err
+	}
+	if !signingKeystore.SupportsForeignInputs() {
+		// Checked before the original transaction is sent to the receiver, as we could not sign the
+		// payjoin proposal.
+		account.log.Info("The keystore can't sign inputs of the receiver, skipping payjoin")
+		return false, account.broadcastOrSchedule(original, txNote)
+	}
+	passed, err := account.txLockTimePassed(original)
+	if err != nil {
+		return false, err
+	}
+	if !passed {
+		// The receiver expects the payment to be broadcast right away.
+		account.log.Info("Locktime has not passed, skipping payjoin")
+		return false, account.broadcastOrSchedule(original, txNote)
+	}
+	payjoinTx, err := account.payjoin(txProposal)
+	if cause := errp.Cause(err); cause == keystore.ErrSigningAborted || cause == errp.ErrUserAbort {
+		return false, err
+	}
+	if err != nil {
+		account.log.WithError(err).Error("Payjoin failed, broadcasting the original transaction")
+		return false, account.broadcastOrSchedule(original, txNote)
+	}
+	account.log.Info("Broadcasting payjoin transaction")
+	if err := account.coin.Blockchain().TransactionBroadcast(payjoinTx); err != nil {
+		account.log.WithError(err).Error(
+			"Failed to broadcast payjoin transaction, broadcasting the original transaction")
+		return false, account.broadcastOrSchedule(original, txNote)
+	}
+	account.txBroadcasted(payjoinTx, txNote)
+	return true, nil
+}
@@ -0,0 +1,468 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package btc
+
+import (
+	"bytes"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
+	blockchainMocks "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/blockchain/mocks"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/types"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/keystore"
+	keystoremock "github.com/BitBoxSwiss/bitbox-wallet-app/backend/keystore/mocks"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+// testPayjoinReceiverKey holds the coin the payjoin receiver adds to the transaction.
+var testPayjoinReceiverKey, _ = btcec.PrivKeyFromBytes(bytes.Repeat([]byte{7}, 32))
+
+func testPayjoinReceiverAddress(t *testing.T) btcutil.Address {
+	t.Helper()
+	address, err := btcutil.NewAddressWitnessPubKeyHash(
+		btcutil.Hash160(testPayjoinReceiverKey.PubKey().SerializeCompressed()),
+		&chaincfg.TestNet3Params)
+	require.NoError(t, err)
+	return address
+}
+
+// makeTestPayjoinProposal makes the payjoin proposal of the receiver: a signed input of the
+// receiver is added, and its value is added to the output paid to the receiver. `contribution` is
+// deducted from the fee output. `modifyTx` can modify the proposal before the receiver signs.
+func makeTestPayjoinProposal(
+	t *testing.T,
+	original *psbt.Packet,
+	feeOutputIndex int,
+	contribution int64,
+	modifyTx func(*wire.MsgTx),
+) *psbt.Packet {
+	t.Helper()
+	receiverPkScript, err := txscript.PayToAddrScript(testPayjoinReceiverAddress(t))
+	require.NoError(t, err)
+	receiverTxOut := wire.NewTxOut(50000, receiverPkScript)
+
+	tx := original.UnsignedTx.Copy()
+	tx.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: *wire.NewOutPoint(&chainhash.Hash{7}, 0),
+		Sequence:         tx.TxIn[0].Sequence,
+	})
+	for _, txOut := range tx.TxOut {
+		if bytes.Equal(txOut.PkScript, receiverPkScript) {
+			txOut.Value += receiverTxOut.Value
+		}
+	}
+	if feeOutputIndex >= 0 {
+		tx.TxOut[feeOutputIndex].Value -= contribution
+	}
+	if modifyTx != nil {
+		modifyTx(tx)
+	}
+
+	proposal, err := psbt.NewFromUnsignedTx(tx)
+	require.NoError(t, err)
+	prevOutputFetcher := txscript.NewMultiPrevOutFetcher(nil)
+	receiverIndex := len(original.UnsignedTx.TxIn)
+	for index := range original.UnsignedTx.TxIn {
+		prevOutputFetcher.AddPrevOut(tx.TxIn[index].PreviousOutPoint, original.Inputs[index].WitnessUtxo)
+	}
+	prevOutputFetcher.AddPrevOut(tx.TxIn[receiverIndex].PreviousOutPoint, receiverTxOut)
+	witness, err := txscript.WitnessSignature(
+		tx, txscript.NewTxSigHashes(tx, prevOutputFetcher), receiverIndex, receiverTxOut.Value,
+		receiverPkScript, txscript.SigHashAll, testPayjoinReceiverKey, true)
+	require.NoError(t, err)
+	var serializedWitness bytes.Buffer
+	require.NoError(t, psbt.WriteTxWitness(&serializedWitness, witness))
+	proposal.Inputs[receiverIndex].WitnessUtxo = receiverTxOut
+	proposal.Inputs[receiverIndex].FinalScriptWitness = serializedWitness.Bytes()
+	return proposal
+}
+
+// payjoinTestKeystore signs the inputs of the test account, which are all spending coins of the
+// first change address. Inputs not belonging to the account are skipped.
+func payjoinTestKeystore(t *testing.T) keystore.Keystore {
+	t.Helper()
+	master, err := hdkeychain.NewMaster(make([]byte, 32), &chaincfg.TestNet3Params)
+	require.NoError(t, err)
+	xprv, err := master.Derive(1)
+	require.NoError(t, err)
+	xprv, err = xprv.Derive(0)
+	require.NoError(t, err)
+	privateKey, err := xprv.ECPrivKey()
+	require.NoError(t, err)
+	return &keystoremock.KeystoreMock{
+		SupportsForeignInputsFunc: func() bool { return true },
+		SignTransactionFunc: func(proposedTx interface{}) error {
+			btcProposedTx := proposedTx.(*ProposedTransaction)
+			txProposal := btcProposedTx.TXProposal
+			for index, txIn := range txProposal.Transaction.TxIn {
+				spentOutput := txProposal.PreviousOutputs[txIn.PreviousOutPoint]
+				if spentOutput.Address == nil {
+					continue
+				}
+				_, subScript := spentOutput.Address.ScriptForHashToSign()
+				signatureHash, err := txscript.CalcWitnessSigHash(subScript, txProposal.SigHashes(),
+					txscript.SigHashAll, txProposal.Transaction, index, spentOutput.TxOut.Value)
+				require.NoError(t, err)
+				signature := ecdsa.SignCompact(privateKey, signatureHash, true)
+				btcProposedTx.Signatures[index] = &types.Signature{
+					R: new(big.Int).SetBytes(signature[1:33]),
+					S: new(big.Int).SetBytes(signature[33:]),
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func payjoinTestAccount(t *testing.T) *Account {
+	t.Helper()
+	account := testAccount(t, nil)
+	testKeystore := payjoinTestKeystore(t)
+	account.Config().ConnectKeystore = func() (keystore.Keystore, error) {
+		return testKeystore, nil
+	}
+	return account
+}
+
+func TestValidatePayjoinEndpoint(t *testing.T) {
+	require.NoError(t, validatePayjoinEndpoint("https://example.com/pj"))
+	require.NoError(t, validatePayjoinEndpoint("http://2gzyxa5ihm7nsggfxnu52rck2vv4rvmdlkiu3zzui5du4xyclen53wid.onion/pj"))
+	require.Error(t, validatePayjoinEndpoint("http://example.com/pj"))
+	require.Error(t, validatePayjoinEndpoint("ftp://example.com/pj"))
+	require.Error(t, validatePayjoinEndpoint("://"))
+}
+
+func TestPayjoin(t *testing.T) {
+	account := payjoinTestAccount(t)
+	var broadcasted []*wire.MsgTx
+	account.coin.blockchain.(*blockchainMocks.BlockchainMock).MockTransactionBroadcast = func(tx *wire.MsgTx) error {
+		broadcasted = append(broadcasted, tx)
+		return nil
+	}
+
+	var lock sync.Mutex
+	var query url.Values
+	var original *psbt.Packet
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		query = r.URL.Query()
+		original, err = psbt.NewFromRawBytes(bytes.NewReader(body), true)
+		require.NoError(t, err)
+		feeOutputIndex, err := strconv.Atoi(query.Get("additionalfeeoutputindex"))
+		require.NoError(t, err)
+		contribution, err := strconv.ParseInt(query.Get("maxadditionalfeecontribution"), 10, 64)
+		require.NoError(t, err)
+		proposal := makeTestPayjoinProposal(t, original, feeOutputIndex, contribution, nil)
+		encoded, err := proposal.B64Encode()
+		require.NoError(t, err)
+		_, _ = w.Write([]byte(encoded))
+	}))
+	defer server.Close()
+	account.httpClient = server.Client()
+
+	_, _, _, err := account.TxProposal(&accounts.TxProposalArgs{
+		RecipientAddress: testPayjoinReceiverAddress(t).EncodeAddress(),
+		FeeTargetCode:    accounts.FeeTargetCodeCustom,
+		CustomFee:        "10",
+		Amount:           coin.NewSendAmount("0.0001"),
+		Payjoin:          server.URL + "/pj?foo=bar",
+	})
+	require.NoError(t, err)
+	txProposal := account.activeTxProposal
+	require.Equal(t, server.URL+"/pj?foo=bar", txProposal.PayjoinEndpoint)
+
+	payjoined, err := account.SendTxPayjoin("payjoin")
+	require.NoError(t, err)
+	require.True(t, payjoined)
+
+	lock.Lock()
+	defer lock.Unlock()
+	require.Equal(t, "1", query.Get("v"))
+	require.Equal(t, "true", query.Get("disableoutputsubstitution"))
+	require.Equal(t, "bar", query.Get("foo"))
+	// The fee of one more P2WPKH input at 10 sat/vB.
+	require.Equal(t, "680", query.Get("maxadditionalfeecontribution"))
+	// The original PSBT is the signed original transaction, without key origin info.
+	originalTx, err := psbt.Extract(original)
+	require.NoError(t, err)
+	require.Equal(t, txProposal.Transaction.TxHash(), originalTx.TxHash())
+	require.Equal(t, txProposal.Transaction.WitnessHash(), originalTx.WitnessHash())
+	require.Empty(t, original.Inputs[0].Bip32Derivation)
+
+	require.Len(t, broadcasted, 1)
+	payjoinTx := broadcasted[0]
+	require.Len(t, payjoinTx.TxIn, len(originalTx.TxIn)+1)
+	require.Equal(t, txProposal.Transaction.TxOut[txProposal.OutIndex].Value+50000,
+		payjoinTx.TxOut[txProposal.OutIndex].Value)
+	require.Equal(t, "payjoin", account.TxNote(payjoinTx.TxHash().String()))
+}
+
+func TestPayjoinFallback(t *testing.T) {
+	account := payjoinTestAccount(t)
+	var broadcasted []*wire.MsgTx
+	account.coin.blockchain.(*blockchainMocks.BlockchainMock).MockTransactionBroadcast = func(tx *wire.MsgTx) error {
+		broadcasted = append(broadcasted, tx)
+		return nil
+	}
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"errorCode": "unavailable", "message": "The payjoin endpoint is not available for now."}`))
+	}))
+	defer server.Close()
+	account.httpClient = server.Client()
+
+	args := &accounts.TxProposalArgs{
+		RecipientAddress: testPayjoinReceiverAddress(t).EncodeAddress(),
+		FeeTargetCode:    accounts.FeeTargetCodeCustom,
+		CustomFee:        "10",
+		Amount:           coin.NewSendAmount("0.0001"),
+		Payjoin:          server.URL,
+	}
+	_, _, _, err := account.TxProposal(args)
+	require.NoError(t, err)
+	payjoined, err := account.SendTxPayjoin("")
+	require.NoError(t, err)
+	require.False(t, payjoined)
+	// The original transaction is broadcast instead.
+	require.Len(t, broadcasted, 1)
+	require.Equal(t, account.activeTxProposal.Transaction, broadcasted[0])
+
+	// Payjoin endpoints must be encrypted.
+	args.Payjoin = strings.Replace(server.URL, "https", "http", 1)
+	_, _, _, err = account.TxProposal(args)
+	require.Error(t, err)
+	// Payjoin is not possible with multiple recipients.
+	args.Payjoin = server.URL
+	args.AdditionalRecipients = []accounts.TxRecipient{
+		{Address: "myY3Bbvj5mjwqqvubtu5Hfy2nuCeBfvNXL", Amount: coin.NewSendAmount("0.0001")},
+	}
+	_, _, _, err = account.TxProposal(args)
+	require.Error(t, err)
+}
+
+func TestPayjoinUnsupportedKeystore(t *testing.T) {
+	account := payjoinTestAccount(t)
+	testKeystore, err := account.Config().ConnectKeystore()
+	require.NoError(t, err)
+	testKeystore.(*keystoremock.KeystoreMock).SupportsForeignInputsFunc = func() bool { return false }
+	var broadcasted []*wire.MsgTx
+	account.coin.blockchain.(*blockchainMocks.BlockchainMock).MockTransactionBroadcast = func(tx *wire.MsgTx) error {
+		broadcasted = append(broadcasted, tx)
+		return nil
+	}
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The original transaction must not be sent to the receiver.
+		t.Error("unexpected payjoin request")
+	}))
+	defer server.Close()
+	account.httpClient = server.Client()
+
+	_, _, _, err = account.TxProposal(&accounts.TxProposalArgs{
+		RecipientAddress: testPayjoinReceiverAddress(t).EncodeAddress(),
+		FeeTargetCode:    accounts.FeeTargetCodeCustom,
+		CustomFee:        "10",
+		Amount:           coin.NewSendAmount("0.0001"),
+		Payjoin:          server.URL,
+	})
+	require.NoError(t, err)
+	payjoined, err := account.SendTxPayjoin("")
+	require.NoError(t, err)
+	require.False(t, payjoined)
+	require.Len(t, broadcasted, 1)
+	require.Equal(t, account.activeTxProposal.Transaction, broadcasted[0])
+}
+
+func TestValidatePayjoinProposal(t *testing.T) {
+	account := payjoinTestAccount(t)
+	_, _, _, err := account.TxProposal(&accounts.TxProposalArgs{
+		RecipientAddress: testPayjoinReceiverAddress(t).EncodeAddress(),
+		FeeTargetCode:    accounts.FeeTargetCodeCustom,
+		CustomFee:        "10",
+		Amount:           coin.NewSendAmount("0.0001"),
+		Payjoin:          "https://example.com/pj",
+	})
+	require.NoError(t, err)
+	txProposal := account.activeTxProposal
+	require.NoError(t, account.signTransaction(txProposal, nil))
+	original, err := account.payjoinOriginalPSBT(txProposal)
+	require.NoError(t, err)
+	params := newPayjoinParams(txProposal)
+	require.Equal(t, btcutil.Amount(680), params.maxContribution)
+	require.Equal(t, 1-txProposal.OutIndex, params.feeOutputIndex)
+	receiverIndex := len(original.UnsignedTx.TxIn)
+
+	testCases := []struct {
+		name         string
+		contribution int64
+		modifyTx     func(*wire.MsgTx)
+		modifyPSBT   func(*psbt.Packet)
+		valid        bool
+	}{
+		{name: "valid", contribution: 680, valid: true},
+		{name: "no contribution", contribution: 0, valid: true},
+		{
+			name:         "receiver output added",
+			contribution: 680,
+			modifyTx: func(tx *wire.MsgTx) {
+				tx.TxOut[txProposal.OutIndex].Value -= 10000
+				tx.AddTxOut(wire.NewTxOut(10000, []byte{0x51}))
+			},
+			valid: true,
+		},
+		{name: "contribution too high", contribution: 681},
+		{name: "negative contribution", contribution: -1},
+		{
+			name:         "contribution not paying fee",
+			contribution: 680,
+			modifyTx: func(tx *wire.MsgTx) {
+				tx.TxOut[txProposal.OutIndex].Value += 680
+			},
+		},
+		{
+			name:         "payment decreased",
+			contribution: 680,
+			modifyTx: func(tx *wire.MsgTx) {
+				tx.TxOut[txProposal.OutIndex].Value -= 50001
+			},
+		},
+		{
+			name:         "locktime changed",
+			contribution: 680,
+			modifyTx: func(tx *wire.MsgTx) {
+				tx.LockTime++
+			},
+		},
+		{
+			name:         "our sequence changed",
+			contribution: 680,
+			modifyTx: func(tx *wire.MsgTx) {
+				tx.TxIn[0].Sequence--
+			},
+		},
+		{
+			name:         "receiver sequence differs",
+			contribution: 680,
+			modifyTx: func(tx *wire.MsgTx) {
+				tx.TxIn[receiverIndex].Sequence--
+			},
+		},
+		{
+			name:         "our input removed",
+			contribution: 680,
+			modifyTx: func(tx *wire.MsgTx) {
+				tx.TxIn[0].PreviousOutPoint = *wire.NewOutPoint(&chainhash.Hash{8}, 0)
+			},
+		},
+		{
+			name:         "another coin of ours added",
+			contribution: 680,
+			modifyTx: func(tx *wire.MsgTx) {
+				for outPoint := range txProposal.PreviousOutputs {
+					tx.TxIn[receiverIndex].PreviousOutPoint = *wire.NewOutPoint(&outPoint.Hash, 1-outPoint.Index)
+				}
+			},
+		},
+		{
+			name:         "change output removed",
+			contribution: 680,
+			modifyTx: func(tx *wire.MsgTx) {
+				tx.TxOut = []*wire.TxOut{tx.TxOut[txProposal.OutIndex]}
+			},
+		},
+		{
+			name:         "our input signed",
+			contribution: 680,
+			modifyPSBT: func(packet *psbt.Packet) {
+				packet.Inputs[0].FinalScriptWitness = original.Inputs[0].FinalScriptWitness
+			},
+		},
+		{
+			name:         "our input with spent output",
+			contribution: 680,
+			modifyPSBT: func(packet *psbt.Packet) {
+				packet.Inputs[0].WitnessUtxo = original.Inputs[0].WitnessUtxo
+			},
+		},
+		{
+			name:         "receiver input not signed",
+			contribution: 680,
+			modifyPSBT: func(packet *psbt.Packet) {
+				packet.Inputs[receiverIndex].FinalScriptWitness = nil
+			},
+		},
+		{
+			name:         "receiver input without spent output",
+			contribution: 680,
+			modifyPSBT: func(packet *psbt.Packet) {
+				packet.Inputs[receiverIndex].WitnessUtxo = nil
+			},
+		},
+		{
+			name:         "receiver input of another script type",
+			contribution: 680,
+			modifyPSBT: func(packet *psbt.Packet) {
+				packet.Inputs[receiverIndex].WitnessUtxo = wire.NewTxOut(50000,
+					append([]byte{txscript.OP_1, txscript.OP_DATA_32}, make([]byte, 32)...))
+			},
+		},
+		{
+			name:         "key paths",
+			contribution: 680,
+			modifyPSBT: func(packet *psbt.Packet) {
+				packet.Outputs[0].Bip32Derivation = []*psbt.Bip32Derivation{{}}
+			},
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			proposal := makeTestPayjoinProposal(
+				t, original, params.feeOutputIndex, test.contribution, test.modifyTx)
+			if test.modifyPSBT != nil {
+				test.modifyPSBT(proposal)
+			}
+			previousOutputs, err := account.validatePayjoinProposal(txProposal, proposal, params)
+			if !test.valid {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, previousOutputs, len(proposal.UnsignedTx.TxIn))
+			transaction, err := account.signPayjoinProposal(txProposal, proposal, previousOutputs)
+			require.NoError(t, err)
+			require.Equal(t, proposal.UnsignedTx.TxHash(), transaction.TxHash())
+		})
+	}
+}
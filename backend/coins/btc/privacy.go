@@ -0,0 +1,189 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package btc
+
+import (
+	"sort"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/addresses"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/transactions"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// defaultDustThreshold is the value below which unsolicited outputs on already used addresses are
+// flagged as dust, unless configured otherwise in the account config.
+const defaultDustThreshold btcutil.Amount = 1000
+
+// ReusedAddress is a receive address which received funds in more than one transaction.
+type ReusedAddress struct {
+	Address *addresses.AccountAddress
+	// TxCount is the number of transactions paying to the address.
+	TxCount int
+}
+
+// DustOutput is a tiny output sent to an address which already received funds before, by someone
+// else. Spending it together with other coins links their addresses, which is the purpose of a
+// dust attack.
+type DustOutput struct {
+	OutPoint wire.OutPoint
+	Address  *addresses.AccountAddress
+	Value    btcutil.Amount
+	Spent    bool
+	Frozen   bool
+}
+
+// PrivacyReport lists the address reuse and dust outputs found in an account.
+type PrivacyReport struct {
+	ReusedAddresses []*ReusedAddress
+	DustOutputs     []*DustOutput
+}
+
+func (account *Account) dustThreshold() btcutil.Amount {
+	dustProtection := account.Config().Config.DustProtection
+	if dustProtection == nil || dustProtection.Threshold == 0 {
+		return defaultDustThreshold
+	}
+	return btcutil.Amount(dustProtection.Threshold)
+}
+
+// receivedBefore returns true if an output confirmed at `height` was received before an output
+// confirmed at `otherHeight`. Unconfirmed outputs (height <= 0) are received after all confirmed
+// outputs.
+func receivedBefore(height, otherHeight int) bool {
+	return height > 0 && (otherHeight <= 0 || height < otherHeight)
+}
+
+// PrivacyReport analyzes the outputs received by the account. It flags receive addresses which
+// received funds more than once, and unsolicited outputs below the dust threshold which were sent
+// to addresses that had already received funds.
+func (account *Account) PrivacyReport() (*PrivacyReport, error) {
+	if !account.isInitialized() {
+		return nil, errp.New("account not initialized")
+	}
+	if !account.Synced() {
+		return nil, accounts.ErrSyncInProgress
+	}
+	receivedOutputs, err := account.transactions.ReceivedOutputs()
+	if err != nil {
+		return nil, err
+	}
+	dustThreshold := account.dustThreshold()
+	report := &PrivacyReport{
+		ReusedAddresses: []*ReusedAddress{},
+		DustOutputs:     []*DustOutput{},
+	}
+	for scriptHashHex, outputs := range receivedOutputs {
+		address := account.GetAddress(scriptHashHex)
+		if address == nil {
+			continue
+		}
+		txHashes := map[chainhash.Hash]struct{}{}
+		for _, output := range outputs {
+			txHashes[output.OutPoint.Hash] = struct{}{}
+		}
+		if len(txHashes) > 1 && !account.IsChange(scriptHashHex) {
+			report.ReusedAddresses = append(report.ReusedAddresses, &ReusedAddress{
+				Address: address,
+				TxCount: len(txHashes),
+			})
+		}
+		for _, output := range outputs {
+			if !output.Unsolicited || btcutil.Amount(output.TxOut.Value) >= dustThreshold {
+				continue
+			}
+			usedBefore := false
+			for _, other := range outputs {
+				if other.OutPoint.Hash != output.OutPoint.Hash && receivedBefore(other.Height, output.Height) {
+					usedBefore = true
+					break
+				}
+			}
+			if !usedBefore {
+				continue
+			}
+			report.DustOutputs = append(report.DustOutputs, &DustOutput{
+				OutPoint: output.OutPoint,
+				Address:  address,
+				Value:    btcutil.Amount(output.TxOut.Value),
+				Spent:    output.Spent,
+				Frozen:   account.OutputFrozen(output.OutPoint),
+			})
+		}
+	}
+	sort.Slice(report.ReusedAddresses, func(i, j int) bool {
+		return report.ReusedAddresses[i].Address.EncodeForHumans() < report.ReusedAddresses[j].Address.EncodeForHumans()
+	})
+	sort.Slice(report.DustOutputs, func(i, j int) bool {
+		return report.DustOutputs[i].OutPoint.String() < report.DustOutputs[j].OutPoint.String()
+	})
+	return report, nil
+}
+
+// FlagDustOutputs records the unspent dust outputs found by PrivacyReport() which were not flagged
+// before, and returns them. If quarantine is enabled in the account config, they are frozen so
+// they are not spent together with other coins. Outputs are flagged only once, so the user can
+// unfreeze them again.
+func (account *Account) FlagDustOutputs() ([]*DustOutput, error) {
+	defer account.dustOutputsLock.Lock()()
+	report, err := account.PrivacyReport()
+	if err != nil {
+		return nil, err
+	}
+	flagged, err := transactions.DBView(account.db, func(dbTx transactions.DBTxInterface) (map[wire.OutPoint]struct{}, error) {
+		return dbTx.DustOutputs()
+	})
+	if err != nil {
+		return nil, err
+	}
+	var newDustOutputs []*DustOutput
+	for _, dustOutput := range report.DustOutputs {
+		if _, ok := flagged[dustOutput.OutPoint]; ok || dustOutput.Spent {
+			continue
+		}
+		newDustOutputs = append(newDustOutputs, dustOutput)
+	}
+	if len(newDustOutputs) == 0 {
+		return nil, nil
+	}
+	err = transactions.DBUpdate(account.db, func(dbTx transactions.DBTxInterface) error {
+		for _, dustOutput := range newDustOutputs {
+			if err := dbTx.PutDustOutput(dustOutput.OutPoint); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	dustProtection := account.Config().Config.DustProtection
+	if dustProtection != nil && dustProtection.Quarantine {
+		for _, dustOutput := range newDustOutputs {
+			if dustOutput.Frozen {
+				continue
+			}
+			if err := account.SetOutputFrozen(dustOutput.OutPoint, true); err != nil {
+				return nil, err
+			}
+			dustOutput.Frozen = true
+		}
+	}
+	account.log.Infof("Flagged %d dust outputs", len(newDustOutputs))
+	return newDustOutputs, nil
+}
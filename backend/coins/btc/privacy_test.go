@@ -0,0 +1,155 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package btc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/addresses"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/blockchain"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/transactions"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/transactions/mocks"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/config"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReceivedBefore(t *testing.T) {
+	require.True(t, receivedBefore(10, 20))
+	require.True(t, receivedBefore(10, 0))
+	require.True(t, receivedBefore(10, -1))
+	require.False(t, receivedBefore(10, 10))
+	require.False(t, receivedBefore(20, 10))
+	require.False(t, receivedBefore(0, 10))
+	require.False(t, receivedBefore(0, 0))
+}
+
+func TestPrivacyReport(t *testing.T) {
+	account := mockAccount(t, nil)
+	_, err := account.PrivacyReport()
+	require.Error(t, err)
+	require.NoError(t, account.Initialize())
+	defer account.Close()
+	require.Eventually(t, account.Synced, time.Second, time.Millisecond*200)
+
+	receiveAddresses, err := account.subaccounts[0].receiveAddresses.GetUnused()
+	require.NoError(t, err)
+	changeAddresses, err := account.subaccounts[0].changeAddresses.GetUnused()
+	require.NoError(t, err)
+	receive0, receive1, receive2 := receiveAddresses[0], receiveAddresses[1], receiveAddresses[2]
+	change0 := changeAddresses[0]
+
+	outPoint := func(n byte) wire.OutPoint {
+		return *wire.NewOutPoint(&chainhash.Hash{n}, 0)
+	}
+	output := func(
+		n byte, address *addresses.AccountAddress, value int64, height int, unsolicited, spent bool,
+	) *transactions.ReceivedOutput {
+		return &transactions.ReceivedOutput{
+			OutPoint:    outPoint(n),
+			TxOut:       wire.NewTxOut(value, address.PubkeyScript()),
+			Height:      height,
+			Unsolicited: unsolicited,
+			Spent:       spent,
+		}
+	}
+	receivedOutputs := map[blockchain.ScriptHashHex][]*transactions.ReceivedOutput{
+		receive0.PubkeyScriptHashHex(): {
+			output(1, receive0, 50000, 10, true, false),
+			// Dust received after the address was used.
+			output(2, receive0, 546, 20, true, false),
+			output(3, receive0, 700, 0, true, false),
+		},
+		receive1.PubkeyScriptHashHex(): {
+			// The address was not used before.
+			output(4, receive1, 600, 5, true, false),
+			output(5, receive1, 800, 30, true, true),
+		},
+		receive2.PubkeyScriptHashHex(): {
+			output(6, receive2, 100000, 10, true, false),
+			// Sent by ourselves.
+			output(7, receive2, 500, 30, false, false),
+		},
+		change0.PubkeyScriptHashHex(): {
+			output(8, change0, 20000, 10, false, false),
+			output(9, change0, 300, 15, true, false),
+		},
+	}
+	account.transactions = &mocks.InterfaceMock{
+		CloseFunc: func() {},
+		ReceivedOutputsFunc: func() (map[blockchain.ScriptHashHex][]*transactions.ReceivedOutput, error) {
+			return receivedOutputs, nil
+		},
+	}
+
+	report, err := account.PrivacyReport()
+	require.NoError(t, err)
+	require.ElementsMatch(t, []*ReusedAddress{
+		{Address: receive0, TxCount: 3},
+		{Address: receive1, TxCount: 2},
+		{Address: receive2, TxCount: 2},
+	}, report.ReusedAddresses)
+	require.Equal(t, []*DustOutput{
+		{OutPoint: outPoint(2), Address: receive0, Value: 546},
+		{OutPoint: outPoint(3), Address: receive0, Value: 700},
+		{OutPoint: outPoint(5), Address: receive1, Value: 800, Spent: true},
+		{OutPoint: outPoint(9), Address: change0, Value: 300},
+	}, report.DustOutputs)
+
+	// Spent dust outputs are not flagged. Without quarantine, nothing is frozen.
+	flagged, err := account.FlagDustOutputs()
+	require.NoError(t, err)
+	require.Equal(t, []*DustOutput{
+		{OutPoint: outPoint(2), Address: receive0, Value: 546},
+		{OutPoint: outPoint(3), Address: receive0, Value: 700},
+		{OutPoint: outPoint(9), Address: change0, Value: 300},
+	}, flagged)
+	require.False(t, account.OutputFrozen(outPoint(2)))
+	// Outputs are flagged only once.
+	flagged, err = account.FlagDustOutputs()
+	require.NoError(t, err)
+	require.Empty(t, flagged)
+
+	// New dust is quarantined.
+	account.Config().Config.DustProtection = &config.DustProtection{Quarantine: true}
+	receivedOutputs[receive2.PubkeyScriptHashHex()] = append(
+		receivedOutputs[receive2.PubkeyScriptHashHex()],
+		output(10, receive2, 999, 0, true, false))
+	flagged, err = account.FlagDustOutputs()
+	require.NoError(t, err)
+	require.Equal(t, []*DustOutput{
+		{OutPoint: outPoint(10), Address: receive2, Value: 999, Frozen: true},
+	}, flagged)
+	require.True(t, account.OutputFrozen(outPoint(10)))
+	require.False(t, account.OutputFrozen(outPoint(2)))
+
+	// Unfrozen by the user, it stays unfrozen.
+	require.NoError(t, account.SetOutputFrozen(outPoint(10), false))
+	flagged, err = account.FlagDustOutputs()
+	require.NoError(t, err)
+	require.Empty(t, flagged)
+	require.False(t, account.OutputFrozen(outPoint(10)))
+
+	// Configured threshold.
+	account.Config().Config.DustProtection = &config.DustProtection{Threshold: 600}
+	report, err = account.PrivacyReport()
+	require.NoError(t, err)
+	require.Equal(t, []*DustOutput{
+		{OutPoint: outPoint(2), Address: receive0, Value: 546},
+		{OutPoint: outPoint(9), Address: change0, Value: 300},
+	}, report.DustOutputs)
+}
@@ -0,0 +1,335 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package btc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/addresses"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/blockchain"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/maketx"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// psbtFingerprint converts a 4 byte root fingerprint to the integer representation used by the
+// psbt package, which serializes it in little endian.
+func psbtFingerprint(rootFingerprint []byte) uint32 {
+	if len(rootFingerprint) != 4 {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(rootFingerprint)
+}
+
+// addPSBTDerivation adds the key origin info of the address, so that external signers can find
+// the key to sign with, and verify that an output belongs to the same wallet. For multisig
+// addresses, the key origin info of all cosigners is added.
+func addPSBTDerivation(
+	address *addresses.AccountAddress,
+	addBip32Derivation func(*psbt.Bip32Derivation),
+	addTaprootDerivation func(internalKey []byte, derivation *psbt.TaprootBip32Derivation),
+) {
+	if multisig := address.AccountConfiguration.BitcoinMultisig; multisig != nil {
+		for i, publicKey := range address.CosignerPublicKeys() {
+			keyInfo := multisig.KeyInfos[i]
+			addBip32Derivation(&psbt.Bip32Derivation{
+				PubKey:               publicKey.SerializeCompressed(),
+				MasterKeyFingerprint: psbtFingerprint(keyInfo.RootFingerprint),
+				Bip32Path: keyInfo.AbsoluteKeypath.
+					Child(address.Derivation.SimpleChainIndex(), false).
+					Child(address.Derivation.AddressIndex, false).
+					ToUInt32(),
+			})
+		}
+		return
+	}
+	fingerprint := psbtFingerprint(address.AccountConfiguration.BitcoinSimple.KeyInfo.RootFingerprint)
+	keypath := address.AbsoluteKeypath().ToUInt32()
+	publicKey := address.PublicKey()
+	if address.AccountConfiguration.ScriptType() == signing.ScriptTypeP2TR {
+		xOnlyPubKey := schnorr.SerializePubKey(publicKey)
+		addTaprootDerivation(xOnlyPubKey, &psbt.TaprootBip32Derivation{
+			XOnlyPubKey:          xOnlyPubKey,
+			MasterKeyFingerprint: fingerprint,
+			Bip32Path:            keypath,
+		})
+		return
+	}
+	addBip32Derivation(&psbt.Bip32Derivation{
+		PubKey:               publicKey.SerializeCompressed(),
+		MasterKeyFingerprint: fingerprint,
+		Bip32Path:            keypath,
+	})
+}
+
+// newPSBT converts an unsigned tx proposal into a PSBT (BIP-174). Every input contains the spent
+// output (the full previous transaction for non-segwit inputs) and the BIP-32 derivation of the
+// key needed to sign it. Outputs belonging to the account (e.g. the change) also carry their
+// derivation.
+func (account *Account) newPSBT(
+	txProposal *maketx.TxProposal,
+	getPrevTx func(chainhash.Hash) (*wire.MsgTx, error),
+) (*psbt.Packet, error) {
+	if txProposal.SilentPaymentAddress != "" {
+		// The silent payment output script is only known after the keystore generated it.
+		return nil, errp.New("Silent payment transactions cannot be exported as a PSBT")
+	}
+	packet, err := psbt.NewFromUnsignedTx(txProposal.Transaction.Copy())
+	if err != nil {
+		return nil, errp.WithStack(err)
+	}
+	for index, txIn := range packet.UnsignedTx.TxIn {
+		prevOut, ok := txProposal.PreviousOutputs[txIn.PreviousOutPoint]
+		if !ok || prevOut.Address == nil {
+			return nil, errp.New("There needs to be exactly one output being spent per input.")
+		}
+		if prevOut.Address.SilentPaymentTweak() != nil {
+			// External signers can't derive the key from the BIP-32 derivation alone.
+			return nil, errp.New("Outputs received through silent payments cannot be exported as a PSBT")
+		}
+		pInput := &packet.Inputs[index]
+		switch prevOut.Address.AccountConfiguration.ScriptType() {
+		case signing.ScriptTypeP2PKH:
+			prevTx, err := getPrevTx(txIn.PreviousOutPoint.Hash)
+			if err != nil {
+				return nil, err
+			}
+			pInput.NonWitnessUtxo = prevTx
+		case signing.ScriptTypeP2WPKHP2SH, signing.ScriptTypeP2WSH, signing.ScriptTypeP2WSHP2SH:
+			pInput.RedeemScript = prevOut.Address.RedeemScript()
+			pInput.WitnessScript = prevOut.Address.WitnessScript()
+			pInput.WitnessUtxo = prevOut.TxOut
+		default:
+			pInput.WitnessUtxo = prevOut.TxOut
+		}
+		pInput.SighashType = txscript.SigHashAll
+		if prevOut.Address.AccountConfiguration.ScriptType() == signing.ScriptTypeP2TR {
+			pInput.SighashType = txscript.SigHashDefault
+		}
+		addPSBTDerivation(
+			prevOut.Address,
+			func(derivation *psbt.Bip32Derivation) {
+				pInput.Bip32Derivation = append(pInput.Bip32Derivation, derivation)
+			},
+			func(internalKey []byte, derivation *psbt.TaprootBip32Derivation) {
+				pInput.TaprootInternalKey = internalKey
+				pInput.TaprootBip32Derivation = append(pInput.TaprootBip32Derivation, derivation)
+			},
+		)
+	}
+	for index, txOut := range packet.UnsignedTx.TxOut {
+		address := account.GetAddress(blockchain.NewScriptHashHex(txOut.PkScript))
+		if address == nil {
+			continue
+		}
+		pOutput := &packet.Outputs[index]
+		pOutput.RedeemScript = address.RedeemScript()
+		pOutput.WitnessScript = address.WitnessScript()
+		addPSBTDerivation(
+			address,
+			func(derivation *psbt.Bip32Derivation) {
+				pOutput.Bip32Derivation = append(pOutput.Bip32Derivation, derivation)
+			},
+			func(internalKey []byte, derivation *psbt.TaprootBip32Derivation) {
+				pOutput.TaprootInternalKey = internalKey
+				pOutput.TaprootBip32Derivation = append(pOutput.TaprootBip32Derivation, derivation)
+			},
+		)
+	}
+	if err := packet.SanityCheck(); err != nil {
+		return nil, errp.WithStack(err)
+	}
+	return packet, nil
+}
+
+// TxProposalPSBT returns the active tx proposal, set by TxProposal(), as a base64 encoded PSBT, so
+// it can be inspected and signed by external tools.
+func (account *Account) TxProposalPSBT() (string, error) {
+	unlock := account.activeTxProposalLock.RLock()
+	txProposal := account.activeTxProposal
+	unlock()
+	if txProposal == nil {
+		return "", errp.New("No active tx proposal")
+	}
+	packet, err := account.newPSBT(txProposal, account.coin.Blockchain().TransactionGet)
+	if err != nil {
+		return "", err
+	}
+	encoded, err := packet.B64Encode()
+	if err != nil {
+		return "", errp.WithStack(err)
+	}
+	return encoded, nil
+}
+
+// psbtPreviousOutputs returns the outputs spent by a PSBT, which must all be coins of this account.
+// The spent outputs are taken from the account, not from the PSBT. The PSBT inputs are completed
+// with the spent outputs and scripts needed to sign and finalize them.
+func (account *Account) psbtPreviousOutputs(packet *psbt.Packet) (maketx.PreviousOutputs, error) {
+	if !account.Synced() {
+		return nil, accounts.ErrSyncInProgress
+	}
+	utxos, err := account.transactions.SpendableOutputs()
+	if err != nil {
+		return nil, err
+	}
+	previousOutputs := make(maketx.PreviousOutputs, len(packet.UnsignedTx.TxIn))
+	for index, txIn := range packet.UnsignedTx.TxIn {
+		utxo, ok := utxos[txIn.PreviousOutPoint]
+		if !ok {
+			return nil, errp.Newf("Input %d does not spend a coin of this account", index)
+		}
+		pInput := &packet.Inputs[index]
+		if pInput.WitnessUtxo != nil &&
+			(pInput.WitnessUtxo.Value != utxo.Value || !bytes.Equal(pInput.WitnessUtxo.PkScript, utxo.PkScript)) {
+			return nil, errp.Newf("Input %d does not match the spent coin", index)
+		}
+		address := account.GetAddress(blockchain.NewScriptHashHex(utxo.PkScript))
+		if address == nil {
+			return nil, errp.Newf("Input %d does not spend a coin of this account", index)
+		}
+		switch address.AccountConfiguration.ScriptType() {
+		case signing.ScriptTypeP2PKH:
+			if pInput.NonWitnessUtxo == nil {
+				prevTx, err := account.coin.Blockchain().TransactionGet(txIn.PreviousOutPoint.Hash)
+				if err != nil {
+					return nil, err
+				}
+				pInput.NonWitnessUtxo = prevTx
+			}
+		case signing.ScriptTypeP2WPKHP2SH, signing.ScriptTypeP2WSH, signing.ScriptTypeP2WSHP2SH:
+			pInput.RedeemScript = address.RedeemScript()
+			pInput.WitnessScript = address.WitnessScript()
+			pInput.WitnessUtxo = utxo.TxOut
+		default:
+			pInput.WitnessUtxo = utxo.TxOut
+		}
+		previousOutputs[txIn.PreviousOutPoint] = maketx.UTXO{
+			TxOut:   utxo.TxOut,
+			Address: address,
+		}
+	}
+	return previousOutputs, nil
+}
+
+// finalizePSBT finalizes a signed PSBT that spends outputs of this account and returns the final
+// transaction. The spent outputs are taken from the account, not from the PSBT, and the final
+// transaction is checked to be fully valid before it is returned.
+func (account *Account) finalizePSBT(packet *psbt.Packet) (*wire.MsgTx, error) {
+	previousOutputs, err := account.psbtPreviousOutputs(packet)
+	if err != nil {
+		return nil, err
+	}
+	if err := psbt.MaybeFinalizeAll(packet); err != nil {
+		return nil, errp.WithMessage(err, "Failed to finalize PSBT")
+	}
+	transaction, err := psbt.Extract(packet)
+	if err != nil {
+		return nil, errp.WithStack(err)
+	}
+	if err := TxValidityCheck(transaction, previousOutputs,
+		txscript.NewTxSigHashes(transaction, previousOutputs)); err != nil {
+		return nil, err
+	}
+	return transaction, nil
+}
+
+// SignPSBT signs all inputs of a base64 encoded PSBT with the keystore of this account and returns
+// the PSBT with the signatures added. All inputs must spend coins of this account. This is used to
+// collect the signatures of the cosigners of a multisig account, which are combined by passing the
+// PSBT from one cosigner to the next. Once there are enough signatures, the PSBT can be finalized
+// and broadcasted using SendPSBT().
+func (account *Account) SignPSBT(encodedPSBT string) (string, error) {
+	packet, err := psbt.NewFromRawBytes(strings.NewReader(strings.TrimSpace(encodedPSBT)), true)
+	if err != nil {
+		return "", errp.WithMessage(err, "Invalid PSBT")
+	}
+	previousOutputs, err := account.psbtPreviousOutputs(packet)
+	if err != nil {
+		return "", err
+	}
+	txProposal := &maketx.TxProposal{
+		Coin:            account.coin,
+		Transaction:     packet.UnsignedTx.Copy(),
+		PreviousOutputs: previousOutputs,
+	}
+	for _, txOut := range txProposal.Transaction.TxOut {
+		address := account.GetAddress(blockchain.NewScriptHashHex(txOut.PkScript))
+		if address != nil && address.Derivation.Change {
+			txProposal.ChangeAddress = address
+			break
+		}
+	}
+	account.log.Info("Signing PSBT")
+	proposedTransaction, err := account.signTxProposal(txProposal, account.coin.Blockchain().TransactionGet)
+	if err != nil {
+		return "", errp.WithMessage(err, "Failed to sign transaction")
+	}
+	for index, txIn := range packet.UnsignedTx.TxIn {
+		address := previousOutputs[txIn.PreviousOutPoint].Address
+		signature := proposedTransaction.Signatures[index]
+		if signature == nil {
+			return "", errp.New("Signature missing")
+		}
+		pInput := &packet.Inputs[index]
+		if address.AccountConfiguration.ScriptType() == signing.ScriptTypeP2TR {
+			pInput.TaprootKeySpendSig = signature.SerializeCompact()
+			continue
+		}
+		publicKey := address.PublicKey().SerializeCompressed()
+		partialSigs := []*psbt.PartialSig{}
+		for _, partialSig := range pInput.PartialSigs {
+			if !bytes.Equal(partialSig.PubKey, publicKey) {
+				partialSigs = append(partialSigs, partialSig)
+			}
+		}
+		pInput.PartialSigs = append(partialSigs, &psbt.PartialSig{
+			PubKey:    publicKey,
+			Signature: append(signature.SerializeDER(), byte(txscript.SigHashAll)),
+		})
+	}
+	encoded, err := packet.B64Encode()
+	if err != nil {
+		return "", errp.WithStack(err)
+	}
+	return encoded, nil
+}
+
+// SendPSBT finalizes and broadcasts a base64 encoded signed PSBT. All inputs must spend coins of
+// this account. Returns the ID of the broadcasted transaction.
+func (account *Account) SendPSBT(encodedPSBT string, txNote string) (string, error) {
+	packet, err := psbt.NewFromRawBytes(strings.NewReader(strings.TrimSpace(encodedPSBT)), true)
+	if err != nil {
+		return "", errp.WithMessage(err, "Invalid PSBT")
+	}
+	transaction, err := account.finalizePSBT(packet)
+	if err != nil {
+		return "", err
+	}
+	account.log.Info("Broadcasting transaction from signed PSBT")
+	if err := account.coin.Blockchain().TransactionBroadcast(transaction); err != nil {
+		return "", err
+	}
+	account.txBroadcasted(transaction, txNote)
+	return transaction.TxHash().String(), nil
+}
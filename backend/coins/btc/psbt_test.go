@@ -0,0 +1,260 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package btc
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
+	blockchainMocks "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/blockchain/mocks"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/types"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/config"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/keystore"
+	keystoremock "github.com/BitBoxSwiss/bitbox-wallet-app/backend/keystore/mocks"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+// signTestPSBT signs all inputs of the PSBT with the key of the first change address of the test
+// account, which holds all coins of the test account.
+func signTestPSBT(t *testing.T, packet *psbt.Packet) {
+	t.Helper()
+	master, err := hdkeychain.NewMaster(make([]byte, 32), &chaincfg.TestNet3Params)
+	require.NoError(t, err)
+	xprv, err := master.Derive(1)
+	require.NoError(t, err)
+	xprv, err = xprv.Derive(0)
+	require.NoError(t, err)
+	privateKey, err := xprv.ECPrivKey()
+	require.NoError(t, err)
+
+	prevOutputFetcher := txscript.NewMultiPrevOutFetcher(nil)
+	for index, txIn := range packet.UnsignedTx.TxIn {
+		prevOutputFetcher.AddPrevOut(txIn.PreviousOutPoint, packet.Inputs[index].WitnessUtxo)
+	}
+	sigHashes := txscript.NewTxSigHashes(packet.UnsignedTx, prevOutputFetcher)
+	for index := range packet.UnsignedTx.TxIn {
+		witnessUtxo := packet.Inputs[index].WitnessUtxo
+		signature, err := txscript.RawTxInWitnessSignature(
+			packet.UnsignedTx, sigHashes, index, witnessUtxo.Value, witnessUtxo.PkScript,
+			txscript.SigHashAll, privateKey)
+		require.NoError(t, err)
+		packet.Inputs[index].PartialSigs = append(packet.Inputs[index].PartialSigs, &psbt.PartialSig{
+			PubKey:    privateKey.PubKey().SerializeCompressed(),
+			Signature: signature,
+		})
+	}
+}
+
+func TestPSBTExportAndSend(t *testing.T) {
+	account := testAccount(t, nil)
+	_, _, _, err := account.TxProposal(&accounts.TxProposalArgs{
+		RecipientAddress: "myY3Bbvj5mjwqqvubtu5Hfy2nuCeBfvNXL",
+		FeeTargetCode:    accounts.FeeTargetCodeCustom,
+		CustomFee:        "10",
+		Amount:           coin.NewSendAmount("0.0001"),
+	})
+	require.NoError(t, err)
+
+	encoded, err := account.TxProposalPSBT()
+	require.NoError(t, err)
+	packet, err := psbt.NewFromRawBytes(strings.NewReader(encoded), true)
+	require.NoError(t, err)
+	require.Len(t, packet.Inputs, 1)
+	require.NotNil(t, packet.Inputs[0].WitnessUtxo)
+	require.Len(t, packet.Inputs[0].Bip32Derivation, 1)
+	derivation := packet.Inputs[0].Bip32Derivation[0]
+	require.Equal(t, psbtFingerprint([]byte{1, 2, 3, 4}), derivation.MasterKeyFingerprint)
+	require.Equal(t,
+		[]uint32{84 + hdkeychain.HardenedKeyStart, 1 + hdkeychain.HardenedKeyStart, hdkeychain.HardenedKeyStart, 1, 0},
+		derivation.Bip32Path)
+
+	// The change output carries its derivation, the recipient output does not.
+	numOutputsWithDerivation := 0
+	for _, output := range packet.Outputs {
+		numOutputsWithDerivation += len(output.Bip32Derivation)
+	}
+	require.Equal(t, 1, numOutputsWithDerivation)
+
+	var broadcasted *wire.MsgTx
+	account.coin.blockchain.(*blockchainMocks.BlockchainMock).MockTransactionBroadcast = func(tx *wire.MsgTx) error {
+		broadcasted = tx
+		return nil
+	}
+
+	// An unsigned PSBT can't be finalized.
+	_, err = account.SendPSBT(encoded, "")
+	require.Error(t, err)
+	require.Nil(t, broadcasted)
+
+	signTestPSBT(t, packet)
+	signed, err := packet.B64Encode()
+	require.NoError(t, err)
+	txID, err := account.SendPSBT(signed, "note")
+	require.NoError(t, err)
+	require.NotNil(t, broadcasted)
+	require.Equal(t, broadcasted.TxHash().String(), txID)
+	require.Equal(t, "note", account.TxNote(txID))
+}
+
+func TestSendPSBTForeignInput(t *testing.T) {
+	account := testAccount(t, nil)
+	tx := wire.NewMsgTx(wire.TxVersion)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{1}, 0), nil, nil))
+	tx.AddTxOut(wire.NewTxOut(1000, []byte{txscript.OP_TRUE}))
+	packet, err := psbt.NewFromUnsignedTx(tx)
+	require.NoError(t, err)
+	encoded, err := packet.B64Encode()
+	require.NoError(t, err)
+	_, err = account.SendPSBT(encoded, "")
+	require.ErrorContains(t, err, "does not spend a coin of this account")
+}
+
+func TestMultisigPSBTCosigning(t *testing.T) {
+	net := &chaincfg.TestNet3Params
+	masters := make([]*hdkeychain.ExtendedKey, 2)
+	keyInfos := make([]signing.KeyInfo, 2)
+	for i := range masters {
+		seed := make([]byte, 32)
+		seed[0] = byte(i + 1)
+		master, err := hdkeychain.NewMaster(seed, net)
+		require.NoError(t, err)
+		xpub, err := master.Neuter()
+		require.NoError(t, err)
+		masters[i] = master
+		keyInfos[i] = signing.KeyInfo{
+			RootFingerprint:   []byte{byte(i + 1), 0, 0, 0},
+			AbsoluteKeypath:   mustKeypath(t, "m/48'/1'/0'/2'"),
+			ExtendedPublicKey: xpub,
+		}
+	}
+	signingConfiguration, err := signing.NewBitcoinMultisigConfiguration(
+		signing.ScriptTypeP2WSH, 2, keyInfos, 0)
+	require.NoError(t, err)
+	account := testAccount(t, &config.Account{
+		Code:                  "multisig",
+		Name:                  "multisig",
+		SigningConfigurations: signing.Configurations{signingConfiguration},
+	})
+
+	// The keystore holds the key of the first cosigner.
+	account.Config().ConnectKeystore = func() (keystore.Keystore, error) {
+		return &keystoremock.KeystoreMock{
+			SignTransactionFunc: func(proposedTx interface{}) error {
+				btcProposedTx := proposedTx.(*ProposedTransaction)
+				txProposal := btcProposedTx.TXProposal
+				for index, txIn := range txProposal.Transaction.TxIn {
+					spentOutput := txProposal.PreviousOutputs[txIn.PreviousOutPoint]
+					derivation := spentOutput.Address.Derivation
+					xprv, err := masters[0].Derive(derivation.SimpleChainIndex())
+					require.NoError(t, err)
+					xprv, err = xprv.Derive(derivation.AddressIndex)
+					require.NoError(t, err)
+					privateKey, err := xprv.ECPrivKey()
+					require.NoError(t, err)
+					_, subScript := spentOutput.Address.ScriptForHashToSign()
+					signatureHash, err := txscript.CalcWitnessSigHash(subScript, txProposal.SigHashes(),
+						txscript.SigHashAll, txProposal.Transaction, index, spentOutput.TxOut.Value)
+					require.NoError(t, err)
+					signature := ecdsa.SignCompact(privateKey, signatureHash, true)
+					btcProposedTx.Signatures[index] = &types.Signature{
+						R: new(big.Int).SetBytes(signature[1:33]),
+						S: new(big.Int).SetBytes(signature[33:]),
+					}
+				}
+				return nil
+			},
+		}, nil
+	}
+
+	_, _, _, err = account.TxProposal(&accounts.TxProposalArgs{
+		RecipientAddress: "myY3Bbvj5mjwqqvubtu5Hfy2nuCeBfvNXL",
+		FeeTargetCode:    accounts.FeeTargetCodeCustom,
+		CustomFee:        "10",
+		Amount:           coin.NewSendAmount("0.0001"),
+	})
+	require.NoError(t, err)
+
+	// A 2-of-2 multisig transaction can't be sent directly.
+	err = account.SendTx("")
+	require.Equal(t, ErrMultisigNeedsCosigners, errp.Cause(err))
+
+	encoded, err := account.TxProposalPSBT()
+	require.NoError(t, err)
+	packet, err := psbt.NewFromRawBytes(strings.NewReader(encoded), true)
+	require.NoError(t, err)
+	require.Len(t, packet.Inputs, 1)
+	require.NotEmpty(t, packet.Inputs[0].WitnessScript)
+	require.Empty(t, packet.Inputs[0].RedeemScript)
+	require.Len(t, packet.Inputs[0].Bip32Derivation, 2)
+
+	var broadcasted *wire.MsgTx
+	account.coin.blockchain.(*blockchainMocks.BlockchainMock).MockTransactionBroadcast = func(tx *wire.MsgTx) error {
+		broadcasted = tx
+		return nil
+	}
+
+	signed, err := account.SignPSBT(encoded)
+	require.NoError(t, err)
+	// Signing twice replaces the signature instead of adding another one.
+	signed, err = account.SignPSBT(signed)
+	require.NoError(t, err)
+	packet, err = psbt.NewFromRawBytes(strings.NewReader(signed), true)
+	require.NoError(t, err)
+	require.Len(t, packet.Inputs[0].PartialSigs, 1)
+
+	// One signature is not enough.
+	_, err = account.SendPSBT(signed, "")
+	require.Error(t, err)
+	require.Nil(t, broadcasted)
+
+	// The second cosigner signs.
+	derivation := packet.Inputs[0].Bip32Derivation[1].Bip32Path
+	xprv, err := masters[1].Derive(derivation[len(derivation)-2])
+	require.NoError(t, err)
+	xprv, err = xprv.Derive(derivation[len(derivation)-1])
+	require.NoError(t, err)
+	privateKey, err := xprv.ECPrivKey()
+	require.NoError(t, err)
+	prevOutputFetcher := txscript.NewCannedPrevOutputFetcher(
+		packet.Inputs[0].WitnessUtxo.PkScript, packet.Inputs[0].WitnessUtxo.Value)
+	signature, err := txscript.RawTxInWitnessSignature(
+		packet.UnsignedTx, txscript.NewTxSigHashes(packet.UnsignedTx, prevOutputFetcher), 0,
+		packet.Inputs[0].WitnessUtxo.Value, packet.Inputs[0].WitnessScript,
+		txscript.SigHashAll, privateKey)
+	require.NoError(t, err)
+	packet.Inputs[0].PartialSigs = append(packet.Inputs[0].PartialSigs, &psbt.PartialSig{
+		PubKey:    privateKey.PubKey().SerializeCompressed(),
+		Signature: signature,
+	})
+	signed, err = packet.B64Encode()
+	require.NoError(t, err)
+
+	txID, err := account.SendPSBT(signed, "")
+	require.NoError(t, err)
+	require.NotNil(t, broadcasted)
+	require.Equal(t, broadcasted.TxHash().String(), txID)
+}
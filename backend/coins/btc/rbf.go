@@ -0,0 +1,338 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package btc
+
+import (
+	"slices"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/addresses"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/blockchain"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/maketx"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/transactions"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// replaceableTx loads an unconfirmed outgoing transaction of this account which can be replaced
+// using RBF. Also returns the change address used by the transaction, or nil if it has no change.
+func (account *Account) replaceableTx(
+	dbTx transactions.DBTxInterface, txHash chainhash.Hash) (
+	*maketx.ReplacedTx, *addresses.AccountAddress, error) {
+	txInfo, err := dbTx.TxInfo(txHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	if txInfo == nil || txInfo.Tx == nil {
+		return nil, nil, errp.New("Transaction not found")
+	}
+	if txInfo.Height > 0 {
+		return nil, nil, errp.New("Transaction is already confirmed")
+	}
+	if !maketx.SignalsRBF(txInfo.Tx) {
+		return nil, nil, errp.New("The transaction does not signal replaceability (BIP-125)")
+	}
+	replacedTx := &maketx.ReplacedTx{
+		Transaction:     txInfo.Tx,
+		PreviousOutputs: make(maketx.PreviousOutputs, len(txInfo.Tx.TxIn)),
+		ChangeIndex:     -1,
+	}
+	for _, txIn := range txInfo.Tx.TxIn {
+		txOut, err := dbTx.Output(txIn.PreviousOutPoint)
+		if err != nil {
+			return nil, nil, err
+		}
+		var address *addresses.AccountAddress
+		if txOut != nil {
+			address = account.GetAddress(blockchain.NewScriptHashHex(txOut.PkScript))
+		}
+		if address == nil {
+			return nil, nil, errp.New("Only transactions spending coins of this account can be replaced")
+		}
+		replacedTx.PreviousOutputs[txIn.PreviousOutPoint] = maketx.UTXO{
+			TxOut:   txOut,
+			Address: address,
+		}
+	}
+	var changeAddress *addresses.AccountAddress
+	for index, txOut := range txInfo.Tx.TxOut {
+		// Replacing the transaction would invalidate transactions spending its outputs.
+		spentBy, err := dbTx.Input(wire.OutPoint{Hash: txHash, Index: uint32(index)})
+		if err != nil {
+			return nil, nil, err
+		}
+		if spentBy != nil {
+			return nil, nil, errp.New("Transactions with descendants cannot be replaced")
+		}
+		scriptHashHex := blockchain.NewScriptHashHex(txOut.PkScript)
+		if changeAddress == nil && account.IsChange(scriptHashHex) {
+			changeAddress = account.GetAddress(scriptHashHex)
+			replacedTx.ChangeIndex = index
+		}
+	}
+	return replacedTx, changeAddress, nil
+}
+
+// newReplacementTx creates a transaction replacing the unconfirmed transaction with the given ID,
+// paying the fee rate specified in args. Only confirmed coins which are not frozen or spent by a
+// scheduled transaction are added if the change of the replaced transaction does not cover the
+// higher fee.
+func (account *Account) newReplacementTx(txID string, args *accounts.TxProposalArgs) (
+	*maketx.TxProposal, error) {
+	if !account.Synced() {
+		return nil, accounts.ErrSyncInProgress
+	}
+	txHash, err := chainhash.NewHashFromStr(txID)
+	if err != nil {
+		return nil, errp.WithStack(err)
+	}
+	utxos, err := account.transactions.SpendableOutputs()
+	if err != nil {
+		return nil, err
+	}
+	type replacementInputs struct {
+		replacedTx        *maketx.ReplacedTx
+		changeAddress     *addresses.AccountAddress
+		additionalOutputs map[wire.OutPoint]maketx.UTXO
+	}
+	inputs, err := transactions.DBView(account.db, func(dbTx transactions.DBTxInterface) (*replacementInputs, error) {
+		replacedTx, changeAddress, err := account.replaceableTx(dbTx, *txHash)
+		if err != nil {
+			return nil, err
+		}
+		scheduledInputs, err := scheduledTxInputs(dbTx)
+		if err != nil {
+			return nil, err
+		}
+		additionalOutputs := map[wire.OutPoint]maketx.UTXO{}
+		for outPoint, utxo := range utxos {
+			_, scheduled := scheduledInputs[outPoint]
+			if outPoint.Hash == *txHash || scheduled || account.OutputFrozen(outPoint) {
+				continue
+			}
+			txInfo, err := dbTx.TxInfo(outPoint.Hash)
+			if err != nil {
+				return nil, err
+			}
+			// BIP-125 forbids adding unconfirmed inputs.
+			if txInfo == nil || txInfo.Tx == nil || txInfo.Height <= 0 {
+				continue
+			}
+			additionalOutputs[outPoint] = maketx.UTXO{
+				TxOut:   utxo.TxOut,
+				Address: account.GetAddress(utxo.ScriptHashHex()),
+			}
+		}
+		return &replacementInputs{
+			replacedTx:        replacedTx,
+			changeAddress:     changeAddress,
+			additionalOutputs: additionalOutputs,
+		}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	feeRatePerKb, err := account.getFeePerKb(args)
+	if err != nil {
+		return nil, err
+	}
+	incrementalRelayFeeRate, err := account.getMinRelayFeeRate()
+	if err != nil {
+		return nil, err
+	}
+	changeAddress := inputs.changeAddress
+	if changeAddress == nil {
+		changeAddress, err = account.pickChangeAddress(inputs.replacedTx.PreviousOutputs, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return maketx.NewReplacementTx(
+		account.coin,
+		inputs.replacedTx,
+		inputs.additionalOutputs,
+		feeRatePerKb,
+		incrementalRelayFeeRate,
+		changeAddress,
+		account.log,
+	)
+}
+
+// FeeBumpProposal creates a proposal replacing the unconfirmed transaction with the given ID by one
+// paying a higher fee (RBF), using the fee target or custom fee of args. Like TxProposal(), the
+// proposal becomes the active tx proposal, to be sent with SendTx() or exported as a PSBT.
+func (account *Account) FeeBumpProposal(txID string, args *accounts.TxProposalArgs) (
+	coin.Amount, coin.Amount, coin.Amount, error) {
+	defer account.activeTxProposalLock.Lock()()
+
+	account.log.WithField("txID", txID).Debug("Proposing fee bump")
+	txProposal, err := account.newReplacementTx(txID, args)
+	if err != nil {
+		return coin.Amount{}, coin.Amount{}, coin.Amount{}, err
+	}
+
+	account.activeTxProposal = txProposal
+
+	return coin.NewAmountFromInt64(int64(txProposal.Amount)),
+		coin.NewAmountFromInt64(int64(txProposal.Fee)),
+		coin.NewAmountFromInt64(int64(txProposal.Total())), nil
+}
+
+// newCancellationTx creates a transaction cancelling the unconfirmed transaction with the given ID
+// by sending its inputs back to this account, paying the fee rate specified in args.
+func (account *Account) newCancellationTx(txID string, args *accounts.TxProposalArgs) (
+	*maketx.TxProposal, error) {
+	if !account.Synced() {
+		return nil, accounts.ErrSyncInProgress
+	}
+	txHash, err := chainhash.NewHashFromStr(txID)
+	if err != nil {
+		return nil, errp.WithStack(err)
+	}
+	type cancellationInputs struct {
+		replacedTx    *maketx.ReplacedTx
+		changeAddress *addresses.AccountAddress
+	}
+	inputs, err := transactions.DBView(account.db, func(dbTx transactions.DBTxInterface) (*cancellationInputs, error) {
+		replacedTx, changeAddress, err := account.replaceableTx(dbTx, *txHash)
+		if err != nil {
+			return nil, err
+		}
+		return &cancellationInputs{replacedTx: replacedTx, changeAddress: changeAddress}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	feeRatePerKb, err := account.getFeePerKb(args)
+	if err != nil {
+		return nil, err
+	}
+	incrementalRelayFeeRate, err := account.getMinRelayFeeRate()
+	if err != nil {
+		return nil, err
+	}
+	changeAddress := inputs.changeAddress
+	if changeAddress == nil {
+		changeAddress, err = account.pickChangeAddress(inputs.replacedTx.PreviousOutputs, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return maketx.NewCancellationTx(
+		account.coin,
+		inputs.replacedTx,
+		feeRatePerKb,
+		incrementalRelayFeeRate,
+		changeAddress,
+		account.log,
+	)
+}
+
+// CancelProposal creates a proposal cancelling the unconfirmed transaction with the given ID by
+// double-spending its inputs to a change address of this account, using the fee target or custom
+// fee of args. Like TxProposal(), the proposal becomes the active tx proposal. Returns the amount
+// returned to the account, the fee and the total.
+func (account *Account) CancelProposal(txID string, args *accounts.TxProposalArgs) (
+	coin.Amount, coin.Amount, coin.Amount, error) {
+	defer account.activeTxProposalLock.Lock()()
+
+	account.log.WithField("txID", txID).Debug("Proposing cancellation")
+	txProposal, err := account.newCancellationTx(txID, args)
+	if err != nil {
+		return coin.Amount{}, coin.Amount{}, coin.Amount{}, err
+	}
+
+	account.activeTxProposal = txProposal
+
+	return coin.NewAmountFromInt64(int64(txProposal.Amount)),
+		coin.NewAmountFromInt64(int64(txProposal.Fee)),
+		coin.NewAmountFromInt64(int64(txProposal.Total())), nil
+}
+
+// isCancellation returns true if all outputs of the transaction belong to this account, i.e. a
+// transaction it replaces is cancelled rather than replaced by a fee bump.
+func (account *Account) isCancellation(transaction *wire.MsgTx) bool {
+	for _, txOut := range transaction.TxOut {
+		if account.GetAddress(blockchain.NewScriptHashHex(txOut.PkScript)) == nil {
+			return false
+		}
+	}
+	return len(transaction.TxOut) > 0
+}
+
+// recordReplacedTxs links a broadcasted transaction to the unconfirmed transactions of this account
+// it double-spends, so that the replaced transactions are hidden in the transaction list. If the
+// transaction sends everything back to this account, the replaced transactions are also kept as
+// cancelled, so they can be listed as failed once the cancellation confirms. Returns the hashes of
+// the replaced transactions.
+func (account *Account) recordReplacedTxs(transaction *wire.MsgTx) ([]chainhash.Hash, error) {
+	txHash := transaction.TxHash()
+	isCancellation := account.isCancellation(transaction)
+	var replaced []chainhash.Hash
+	err := transactions.DBUpdate(account.db, func(dbTx transactions.DBTxInterface) error {
+		for _, txIn := range transaction.TxIn {
+			spentBy, err := dbTx.Input(txIn.PreviousOutPoint)
+			if err != nil {
+				return err
+			}
+			if spentBy == nil || *spentBy == txHash || slices.Contains(replaced, *spentBy) {
+				continue
+			}
+			txInfo, err := dbTx.TxInfo(*spentBy)
+			if err != nil {
+				return err
+			}
+			if txInfo == nil || txInfo.Tx == nil || txInfo.Height > 0 {
+				continue
+			}
+			if err := dbTx.PutReplacement(txHash, *spentBy); err != nil {
+				return err
+			}
+			if isCancellation {
+				if err := dbTx.PutCancellation(txHash, txInfo); err != nil {
+					return err
+				}
+			}
+			replaced = append(replaced, *spentBy)
+		}
+		return nil
+	})
+	return replaced, err
+}
+
+// txBroadcasted performs the bookkeeping after a transaction of this account was broadcasted:
+// replaced transactions are recorded and the note is stored. If the transaction replaces another
+// one and no note is given, the note of the replaced transaction is kept.
+func (account *Account) txBroadcasted(transaction *wire.MsgTx, txNote string) {
+	replaced, err := account.recordReplacedTxs(transaction)
+	if err != nil {
+		// Not critical.
+		account.log.WithError(err).Error("Failed to record replaced transactions")
+	}
+	if txNote == "" {
+		for _, txHash := range replaced {
+			if note := account.TxNote(txHash.String()); note != "" {
+				txNote = note
+				break
+			}
+		}
+	}
+	if err := account.SetTxNote(transaction.TxHash().String(), txNote); err != nil {
+		// Not critical.
+		account.log.WithError(err).Error("Failed to save transaction note when sending a tx")
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package btc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/errors"
+	blockchainMocks "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/blockchain/mocks"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/transactions"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+// putUnconfirmedTx stores an unconfirmed transaction of the test account spending its first coin,
+// sending 0.1 BTC to an external address and the change back to the account.
+func putUnconfirmedTx(t *testing.T, account *Account, sequence uint32) *wire.MsgTx {
+	t.Helper()
+	changeAddresses, err := account.subaccounts[0].changeAddresses.GetUnused()
+	require.NoError(t, err)
+	changePkScript := changeAddresses[0].PubkeyScript()
+	recipientPkScript, err := account.coin.AddressToPkScript("myY3Bbvj5mjwqqvubtu5Hfy2nuCeBfvNXL")
+	require.NoError(t, err)
+
+	spentOutPoint := *wire.NewOutPoint(&chainhash.Hash{}, 0)
+	spentTxOut := wire.NewTxOut(1000000000, changePkScript)
+	tx := wire.NewMsgTx(wire.TxVersion)
+	tx.AddTxIn(&wire.TxIn{PreviousOutPoint: spentOutPoint, Sequence: sequence})
+	tx.AddTxOut(wire.NewTxOut(10000000, recipientPkScript))
+	// Fee: 200 sat.
+	tx.AddTxOut(wire.NewTxOut(1000000000-10000000-200, changePkScript))
+
+	require.NoError(t, transactions.DBUpdate(account.db, func(dbTx transactions.DBTxInterface) error {
+		fundingTx := wire.NewMsgTx(wire.TxVersion)
+		fundingTx.AddTxOut(spentTxOut)
+		if err := dbTx.PutTx(chainhash.Hash{}, fundingTx, 10); err != nil {
+			return err
+		}
+		if err := dbTx.PutOutput(spentOutPoint, spentTxOut); err != nil {
+			return err
+		}
+		if err := dbTx.PutTx(tx.TxHash(), tx, 0); err != nil {
+			return err
+		}
+		return dbTx.PutInput(spentOutPoint, tx.TxHash())
+	}))
+	return tx
+}
+
+func TestFeeBump(t *testing.T) {
+	account := testAccount(t, nil)
+	replacedTx := putUnconfirmedTx(t, account, wire.MaxTxInSequenceNum-2)
+	require.NoError(t, account.SetTxNote(replacedTx.TxHash().String(), "rent"))
+
+	amount, fee, _, err := account.FeeBumpProposal(replacedTx.TxHash().String(), &accounts.TxProposalArgs{
+		FeeTargetCode: accounts.FeeTargetCodeCustom,
+		CustomFee:     "10",
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(10000000), amount.BigInt().Int64())
+	require.Greater(t, fee.BigInt().Int64(), int64(200))
+
+	txProposal := account.activeTxProposal
+	originalTxHash := replacedTx.TxHash()
+	require.Equal(t, &originalTxHash, txProposal.ReplacesTxHash)
+	require.Len(t, txProposal.Transaction.TxIn, 1)
+	require.Equal(t, replacedTx.TxIn[0].PreviousOutPoint, txProposal.Transaction.TxIn[0].PreviousOutPoint)
+	require.Len(t, txProposal.Transaction.TxOut, 2)
+	require.Equal(t, replacedTx.TxOut[0], txProposal.Transaction.TxOut[0])
+	require.Equal(t, replacedTx.TxOut[1].PkScript, txProposal.Transaction.TxOut[1].PkScript)
+
+	encoded, err := account.TxProposalPSBT()
+	require.NoError(t, err)
+	packet, err := psbt.NewFromRawBytes(strings.NewReader(encoded), true)
+	require.NoError(t, err)
+	signTestPSBT(t, packet)
+	signed, err := packet.B64Encode()
+	require.NoError(t, err)
+	account.coin.blockchain.(*blockchainMocks.BlockchainMock).MockTransactionBroadcast = func(tx *wire.MsgTx) error {
+		return nil
+	}
+	txID, err := account.SendPSBT(signed, "")
+	require.NoError(t, err)
+
+	// The replacement is linked to the original and inherits its note.
+	replacements, err := transactions.DBView(account.db, func(dbTx transactions.DBTxInterface) (map[chainhash.Hash]chainhash.Hash, error) {
+		return dbTx.Replacements()
+	})
+	require.NoError(t, err)
+	replacementHash, err := chainhash.NewHashFromStr(txID)
+	require.NoError(t, err)
+	require.Equal(t, map[chainhash.Hash]chainhash.Hash{*replacementHash: originalTxHash}, replacements)
+	require.Equal(t, "rent", account.TxNote(txID))
+
+	// A fee bump does not cancel the original.
+	cancellations, err := transactions.DBView(account.db, func(dbTx transactions.DBTxInterface) (map[chainhash.Hash]*transactions.DBTxInfo, error) {
+		return dbTx.Cancellations()
+	})
+	require.NoError(t, err)
+	require.Empty(t, cancellations)
+}
+
+func TestCancel(t *testing.T) {
+	account := testAccount(t, nil)
+	replacedTx := putUnconfirmedTx(t, account, wire.MaxTxInSequenceNum-2)
+
+	amount, fee, total, err := account.CancelProposal(replacedTx.TxHash().String(), &accounts.TxProposalArgs{
+		FeeTargetCode: accounts.FeeTargetCodeCustom,
+		CustomFee:     "10",
+	})
+	require.NoError(t, err)
+	// One P2WPKH input and one P2WPKH output at 10 sat/vB.
+	require.Equal(t, int64(1100), fee.BigInt().Int64())
+	require.Equal(t, int64(1000000000-1100), amount.BigInt().Int64())
+	require.Equal(t, int64(1000000000), total.BigInt().Int64())
+
+	txProposal := account.activeTxProposal
+	originalTxHash := replacedTx.TxHash()
+	require.Equal(t, &originalTxHash, txProposal.ReplacesTxHash)
+	require.Len(t, txProposal.Transaction.TxIn, 1)
+	require.Equal(t, replacedTx.TxIn[0].PreviousOutPoint, txProposal.Transaction.TxIn[0].PreviousOutPoint)
+	// Everything goes back to the change address of the original.
+	require.Len(t, txProposal.Transaction.TxOut, 1)
+	require.Equal(t, replacedTx.TxOut[1].PkScript, txProposal.Transaction.TxOut[0].PkScript)
+
+	encoded, err := account.TxProposalPSBT()
+	require.NoError(t, err)
+	packet, err := psbt.NewFromRawBytes(strings.NewReader(encoded), true)
+	require.NoError(t, err)
+	signTestPSBT(t, packet)
+	signed, err := packet.B64Encode()
+	require.NoError(t, err)
+	account.coin.blockchain.(*blockchainMocks.BlockchainMock).MockTransactionBroadcast = func(tx *wire.MsgTx) error {
+		return nil
+	}
+	txID, err := account.SendPSBT(signed, "")
+	require.NoError(t, err)
+
+	// The original is kept as cancelled by the replacement.
+	cancellations, err := transactions.DBView(account.db, func(dbTx transactions.DBTxInterface) (map[chainhash.Hash]*transactions.DBTxInfo, error) {
+		return dbTx.Cancellations()
+	})
+	require.NoError(t, err)
+	replacementHash, err := chainhash.NewHashFromStr(txID)
+	require.NoError(t, err)
+	require.Len(t, cancellations, 1)
+	require.Equal(t, originalTxHash, cancellations[*replacementHash].TxHash)
+}
+
+func TestFeeBumpErrors(t *testing.T) {
+	account := testAccount(t, nil)
+	args := &accounts.TxProposalArgs{
+		FeeTargetCode: accounts.FeeTargetCodeCustom,
+		CustomFee:     "10",
+	}
+
+	_, _, _, err := account.FeeBumpProposal(chainhash.Hash{1}.String(), args)
+	require.EqualError(t, err, "Transaction not found")
+
+	finalTx := putUnconfirmedTx(t, account, wire.MaxTxInSequenceNum)
+	_, _, _, err = account.FeeBumpProposal(finalTx.TxHash().String(), args)
+	require.ErrorContains(t, err, "does not signal replaceability")
+
+	replacedTx := putUnconfirmedTx(t, account, wire.MaxTxInSequenceNum-2)
+	_, _, _, err = account.FeeBumpProposal(replacedTx.TxHash().String(), &accounts.TxProposalArgs{
+		FeeTargetCode: accounts.FeeTargetCodeCustom,
+		CustomFee:     "1.1",
+	})
+	require.Equal(t, errors.ErrFeeTooLow, errp.Cause(err))
+	require.Nil(t, account.activeTxProposal)
+}
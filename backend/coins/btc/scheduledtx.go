@@ -0,0 +1,260 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package btc
+
+import (
+	"time"
+
+	accountsTypes "github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/types"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/headers"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/transactions"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/observable"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/observable/action"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// lockTimePassed returns true if a transaction with the given locktime can be included in the block
+// following the tip, i.e. if it can be broadcast. A locktime below `txscript.LockTimeThreshold` is
+// a block height, otherwise it is a Unix timestamp which is compared to the median time past of
+// the tip (BIP-113).
+func lockTimePassed(lockTime uint32, tipHeight int, medianTimePast time.Time) bool {
+	if lockTime < txscript.LockTimeThreshold {
+		return int64(lockTime) <= int64(tipHeight)
+	}
+	return int64(lockTime) < medianTimePast.Unix()
+}
+
+// txLockTimePassed returns true if the locktime of the transaction has passed, see
+// lockTimePassed().
+func (account *Account) txLockTimePassed(tx *wire.MsgTx) (bool, error) {
+	if tx.LockTime < txscript.LockTimeThreshold {
+		return lockTimePassed(tx.LockTime, account.tipHeight(), time.Time{}), nil
+	}
+	medianTimePast, err := account.coin.Headers().MedianTimePast()
+	if err != nil {
+		return false, err
+	}
+	return lockTimePassed(tx.LockTime, 0, medianTimePast), nil
+}
+
+// broadcastOrSchedule broadcasts a signed transaction. If the locktime of the transaction has not
+// passed yet, it is added to the queue of scheduled transactions instead, which are broadcast
+// automatically once their locktime has passed. See broadcastScheduledTxs().
+func (account *Account) broadcastOrSchedule(tx *wire.MsgTx, txNote string) error {
+	passed, err := account.txLockTimePassed(tx)
+	if err != nil {
+		return err
+	}
+	if passed {
+		account.log.Info("Signed transaction is broadcasted")
+		if err := account.coin.Blockchain().TransactionBroadcast(tx); err != nil {
+			return err
+		}
+		account.txBroadcasted(tx, txNote)
+		return nil
+	}
+	account.log.WithField("lockTime", tx.LockTime).Info("Signed transaction is scheduled")
+	err = transactions.DBUpdate(account.db, func(dbTx transactions.DBTxInterface) error {
+		return dbTx.PutScheduledTx(tx.TxHash(), &transactions.ScheduledTx{
+			Tx:               tx,
+			Note:             txNote,
+			CreatedTimestamp: time.Now(),
+		})
+	})
+	if err != nil {
+		return err
+	}
+	account.notifyScheduledTxsChanged()
+	return nil
+}
+
+// scheduledTxInputs returns the outputs spent by the scheduled transactions. Like frozen outputs,
+// they are excluded from automatic coin selection, as spending them elsewhere would invalidate the
+// scheduled transactions.
+func scheduledTxInputs(dbTx transactions.DBTxInterface) (map[wire.OutPoint]struct{}, error) {
+	scheduledTxs, err := dbTx.ScheduledTxs()
+	if err != nil {
+		return nil, err
+	}
+	result := map[wire.OutPoint]struct{}{}
+	for _, scheduledTx := range scheduledTxs {
+		for _, txIn := range scheduledTx.Tx.TxIn {
+			result[txIn.PreviousOutPoint] = struct{}{}
+		}
+	}
+	return result, nil
+}
+
+// scheduledTxInputsSpent returns true if one of the inputs of the scheduled transaction is not
+// available anymore, in which case the transaction can never be broadcast. spentBy is the
+// transaction spending the input, or nil if the output itself is gone, e.g. because the
+// transaction creating it was replaced. spentBy is the scheduled transaction itself if someone
+// else broadcast it already.
+func scheduledTxInputsSpent(dbTx transactions.DBTxInterface, tx *wire.MsgTx) (
+	spent bool, spentBy *chainhash.Hash, err error) {
+	for _, txIn := range tx.TxIn {
+		txOut, err := dbTx.Output(txIn.PreviousOutPoint)
+		if err != nil {
+			return false, nil, err
+		}
+		if txOut == nil {
+			return true, nil, nil
+		}
+		spentBy, err := dbTx.Input(txIn.PreviousOutPoint)
+		if err != nil {
+			return false, nil, err
+		}
+		if spentBy != nil {
+			return true, spentBy, nil
+		}
+	}
+	return false, nil, nil
+}
+
+// broadcastScheduledTxs broadcasts all scheduled transactions whose locktime has passed and
+// removes them from the queue. Transactions whose inputs were spent in the meantime are removed
+// from the queue, as they can't be broadcast anymore. Transactions failing to broadcast for
+// another reason stay in the queue until they are removed with RemoveScheduledTx().
+func (account *Account) broadcastScheduledTxs() {
+	defer account.scheduledTxsLock.Lock()()
+	if account.isClosed() {
+		return
+	}
+	scheduledTxs, err := account.ScheduledTxs()
+	if err != nil {
+		account.log.WithError(err).Error("Failed to get scheduled transactions")
+		return
+	}
+	// The spent inputs are only known once the account is synced.
+	synced := account.Synced()
+	changed := false
+	for txHash, scheduledTx := range scheduledTxs {
+		log := account.log.WithField("txID", txHash.String())
+		remove := func() {
+			err := transactions.DBUpdate(account.db, func(dbTx transactions.DBTxInterface) error {
+				return dbTx.DeleteScheduledTx(txHash)
+			})
+			if err != nil {
+				log.WithError(err).Error("Failed to remove transaction from the schedule")
+				return
+			}
+			changed = true
+		}
+		if synced {
+			type inputsSpent struct {
+				spent   bool
+				spentBy *chainhash.Hash
+			}
+			result, err := transactions.DBView(account.db, func(dbTx transactions.DBTxInterface) (*inputsSpent, error) {
+				spent, spentBy, err := scheduledTxInputsSpent(dbTx, scheduledTx.Tx)
+				return &inputsSpent{spent: spent, spentBy: spentBy}, err
+			})
+			if err != nil {
+				log.WithError(err).Error("Failed to check the inputs of a scheduled transaction")
+				continue
+			}
+			if result.spent {
+				if result.spentBy != nil && *result.spentBy == txHash {
+					log.Info("Scheduled transaction was already broadcasted")
+					account.txBroadcasted(scheduledTx.Tx, scheduledTx.Note)
+				} else {
+					log.Warning("Scheduled transaction is removed, as its inputs were spent by another transaction")
+				}
+				remove()
+				continue
+			}
+		}
+		passed, err := account.txLockTimePassed(scheduledTx.Tx)
+		if err != nil {
+			log.WithError(err).Error("Failed to check the locktime of a scheduled transaction")
+			continue
+		}
+		if !passed {
+			continue
+		}
+		if err := account.coin.Blockchain().TransactionBroadcast(scheduledTx.Tx); err != nil {
+			log.WithError(err).Error("Failed to broadcast scheduled transaction")
+			continue
+		}
+		log.Info("Scheduled transaction is broadcasted")
+		account.txBroadcasted(scheduledTx.Tx, scheduledTx.Note)
+		remove()
+	}
+	if changed {
+		account.notifyScheduledTxsChanged()
+	}
+}
+
+// onHeadersEvent broadcasts the scheduled transactions whose locktime passed with a new block, and
+// scans the new block for silent payments.
+func (account *Account) onHeadersEvent(event headers.Event) {
+	if event == headers.EventNewTip || event == headers.EventSynced {
+		account.broadcastScheduledTxs()
+		if account.silentPayments != nil {
+			go account.scanSilentPayments()
+		}
+	}
+}
+
+func (account *Account) notifyScheduledTxsChanged() {
+	account.Notify(observable.Event{
+		Subject: string(accountsTypes.EventStatusChanged),
+		Action:  action.Reload,
+		Object:  nil,
+	})
+}
+
+// ScheduledTxs returns the signed transactions waiting for their locktime to pass, by transaction
+// hash.
+func (account *Account) ScheduledTxs() (map[chainhash.Hash]*transactions.ScheduledTx, error) {
+	if !account.isInitialized() {
+		return nil, errp.New("account not initialized")
+	}
+	return transactions.DBView(account.db, func(dbTx transactions.DBTxInterface) (map[chainhash.Hash]*transactions.ScheduledTx, error) {
+		return dbTx.ScheduledTxs()
+	})
+}
+
+// RemoveScheduledTx removes a transaction from the queue of scheduled transactions, so it will not
+// be broadcast by this wallet. Note that the signed transaction remains valid: anyone who obtained
+// it can still broadcast it once its locktime has passed, until one of its inputs is spent.
+func (account *Account) RemoveScheduledTx(txID string) error {
+	if !account.isInitialized() {
+		return errp.New("account not initialized")
+	}
+	txHash, err := chainhash.NewHashFromStr(txID)
+	if err != nil {
+		return errp.WithStack(err)
+	}
+	defer account.scheduledTxsLock.Lock()()
+	err = transactions.DBUpdate(account.db, func(dbTx transactions.DBTxInterface) error {
+		scheduledTxs, err := dbTx.ScheduledTxs()
+		if err != nil {
+			return err
+		}
+		if _, ok := scheduledTxs[*txHash]; !ok {
+			return errp.New("Scheduled transaction not found")
+		}
+		return dbTx.DeleteScheduledTx(*txHash)
+	})
+	if err != nil {
+		return err
+	}
+	account.notifyScheduledTxsChanged()
+	return nil
+}
@@ -0,0 +1,173 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package btc
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/blockchain"
+	blockchainMocks "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/blockchain/mocks"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/transactions"
+	"github.com/BitBoxSwiss/block-client-go/electrum/types"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockTimePassed(t *testing.T) {
+	medianTimePast := time.Unix(1700000000, 0)
+	require.True(t, lockTimePassed(0, 0, time.Time{}))
+	require.True(t, lockTimePassed(99, 100, medianTimePast))
+	require.True(t, lockTimePassed(100, 100, medianTimePast))
+	require.False(t, lockTimePassed(101, 100, medianTimePast))
+	// Unknown tip.
+	require.False(t, lockTimePassed(1, 0, medianTimePast))
+
+	require.True(t, lockTimePassed(1699999999, 100, medianTimePast))
+	require.False(t, lockTimePassed(1700000000, 100, medianTimePast))
+	// Unknown median time past.
+	require.False(t, lockTimePassed(500000000, 100, time.Time{}))
+}
+
+func TestScheduledTxs(t *testing.T) {
+	var lock sync.Mutex
+	var onHeader func(*types.Header)
+	var broadcasted []chainhash.Hash
+	blockchainMock := &blockchainMocks.BlockchainMock{
+		MockRegisterOnConnectionErrorChangedEvent: func(func(error)) {},
+		MockHeadersSubscribe: func(f func(*types.Header)) {
+			lock.Lock()
+			onHeader = f
+			lock.Unlock()
+			f(&types.Header{Height: 100})
+		},
+		MockTransactionBroadcast: func(tx *wire.MsgTx) error {
+			lock.Lock()
+			defer lock.Unlock()
+			broadcasted = append(broadcasted, tx.TxHash())
+			return nil
+		},
+	}
+	getBroadcasted := func() []chainhash.Hash {
+		lock.Lock()
+		defer lock.Unlock()
+		return append([]chainhash.Hash{}, broadcasted...)
+	}
+	account := mockAccount(t, nil)
+	account.coin.TstSetMakeBlockchain(func() blockchain.Interface { return blockchainMock })
+	require.NoError(t, account.Initialize())
+	defer account.Close()
+	require.Eventually(t,
+		func() bool { return account.tipHeight() == 100 },
+		time.Second, 10*time.Millisecond)
+
+	newTx := func(lockTime uint32) *wire.MsgTx {
+		tx := wire.NewMsgTx(wire.TxVersion)
+		outPoint := *wire.NewOutPoint(&chainhash.Hash{byte(lockTime), byte(lockTime >> 8)}, 0)
+		tx.AddTxIn(&wire.TxIn{
+			PreviousOutPoint: outPoint,
+			Sequence:         wire.MaxTxInSequenceNum - 2,
+		})
+		tx.AddTxOut(wire.NewTxOut(1000, []byte{0x51}))
+		tx.LockTime = lockTime
+		// The spent output belongs to the account.
+		require.NoError(t, transactions.DBUpdate(account.db, func(dbTx transactions.DBTxInterface) error {
+			return dbTx.PutOutput(outPoint, wire.NewTxOut(2000, []byte{0x51}))
+		}))
+		return tx
+	}
+	spend := func(outPoint wire.OutPoint, txHash chainhash.Hash) {
+		require.NoError(t, transactions.DBUpdate(account.db, func(dbTx transactions.DBTxInterface) error {
+			return dbTx.PutInput(outPoint, txHash)
+		}))
+	}
+
+	// The locktime has passed: the transaction is broadcast right away.
+	pastTx := newTx(100)
+	require.NoError(t, account.broadcastOrSchedule(pastTx, "past"))
+	require.Equal(t, []chainhash.Hash{pastTx.TxHash()}, getBroadcasted())
+	require.Equal(t, "past", account.TxNote(pastTx.TxHash().String()))
+
+	heightTx := newTx(150)
+	require.NoError(t, account.broadcastOrSchedule(heightTx, "vesting"))
+	timestampTx := newTx(2000000000)
+	require.NoError(t, account.broadcastOrSchedule(timestampTx, ""))
+	require.Len(t, getBroadcasted(), 1)
+
+	scheduledTxs, err := account.ScheduledTxs()
+	require.NoError(t, err)
+	require.Len(t, scheduledTxs, 2)
+	require.Equal(t, heightTx, scheduledTxs[heightTx.TxHash()].Tx)
+	require.Equal(t, "vesting", scheduledTxs[heightTx.TxHash()].Note)
+	require.Equal(t, timestampTx, scheduledTxs[timestampTx.TxHash()].Tx)
+
+	// The inputs of scheduled transactions are excluded from coin selection.
+	scheduledInputs, err := transactions.DBView(account.db, scheduledTxInputs)
+	require.NoError(t, err)
+	require.Equal(t, map[wire.OutPoint]struct{}{
+		heightTx.TxIn[0].PreviousOutPoint:    {},
+		timestampTx.TxIn[0].PreviousOutPoint: {},
+	}, scheduledInputs)
+
+	// Nothing happens before the locktime is reached.
+	account.broadcastScheduledTxs()
+	require.Len(t, getBroadcasted(), 1)
+
+	// A new block at the locktime height: the transaction is broadcast automatically.
+	lock.Lock()
+	notifyHeader := onHeader
+	lock.Unlock()
+	notifyHeader(&types.Header{Height: 150})
+	require.Eventually(t,
+		func() bool { return len(getBroadcasted()) == 2 },
+		time.Second, 10*time.Millisecond)
+	require.Equal(t, heightTx.TxHash(), getBroadcasted()[1])
+	require.Eventually(t, func() bool {
+		scheduledTxs, err := account.ScheduledTxs()
+		require.NoError(t, err)
+		return len(scheduledTxs) == 1
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, "vesting", account.TxNote(heightTx.TxHash().String()))
+
+	require.NoError(t, account.RemoveScheduledTx(timestampTx.TxHash().String()))
+	scheduledTxs, err = account.ScheduledTxs()
+	require.NoError(t, err)
+	require.Empty(t, scheduledTxs)
+	require.Error(t, account.RemoveScheduledTx(timestampTx.TxHash().String()))
+	require.Error(t, account.RemoveScheduledTx("invalid"))
+
+	// Transactions whose inputs were spent by another transaction are removed, as they can never
+	// be broadcast.
+	require.Eventually(t, account.Synced, time.Second, 10*time.Millisecond)
+	conflictedTx := newTx(160)
+	require.NoError(t, account.broadcastOrSchedule(conflictedTx, ""))
+	spend(conflictedTx.TxIn[0].PreviousOutPoint, chainhash.Hash{0xff})
+	// A transaction broadcast by someone else is removed too, keeping its note.
+	broadcastedTx := newTx(170)
+	require.NoError(t, account.broadcastOrSchedule(broadcastedTx, "elsewhere"))
+	spend(broadcastedTx.TxIn[0].PreviousOutPoint, broadcastedTx.TxHash())
+	pendingTx := newTx(180)
+	require.NoError(t, account.broadcastOrSchedule(pendingTx, ""))
+
+	account.broadcastScheduledTxs()
+	scheduledTxs, err = account.ScheduledTxs()
+	require.NoError(t, err)
+	require.Len(t, scheduledTxs, 1)
+	require.Contains(t, scheduledTxs, pendingTx.TxHash())
+	require.Equal(t, "elsewhere", account.TxNote(broadcastedTx.TxHash().String()))
+	require.Len(t, getBroadcasted(), 2)
+}
@@ -33,7 +33,8 @@ type ProposedTransaction struct {
 	// List of signing configurations that might be used in the tx inputs.
 	AccountSigningConfigurations []*signing.Configuration
 	GetPrevTx                    func(chainhash.Hash) (*wire.MsgTx, error)
-	// Signatures collects the signatures, one per transaction input.
+	// Signatures collects the signatures, one per transaction input. The signature of an input not
+	// belonging to the account, e.g. an input of the receiver of a payjoin transaction, is nil.
 	Signatures []*types.Signature
 	FormatUnit coin.BtcUnit
 	// GetKeystoreAddress returns the address from the same keystore given the script hash,
@@ -58,18 +59,17 @@ func (p *ProposedTransaction) Finalize() error {
 	return nil
 }
 
-// signTransaction signs all inputs. It assumes all outputs spent belong to this
-// wallet. previousOutputs must contain all outputs which are spent by the transaction.
-func (account *Account) signTransaction(
+// signTxProposal lets the keystore sign all inputs, without adding the signatures to the
+// transaction. It assumes all outputs spent belong to this wallet. previousOutputs must contain all
+// outputs which are spent by the transaction.
+func (account *Account) signTxProposal(
 	txProposal *maketx.TxProposal,
 	getPrevTx func(chainhash.Hash) (*wire.MsgTx, error),
-) error {
+) (*ProposedTransaction, error) {
 	signingConfigs := make([]*signing.Configuration, len(account.subaccounts))
 	for i, subacc := range account.subaccounts {
 		signingConfigs[i] = subacc.signingConfiguration
 	}
-	previousOutputs := txProposal.PreviousOutputs
-
 	proposedTransaction := &ProposedTransaction{
 		TXProposal:                   txProposal,
 		AccountSigningConfigurations: signingConfigs,
@@ -82,9 +82,26 @@ func (account *Account) signTransaction(
 
 	keystore, err := account.Config().ConnectKeystore()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if err := keystore.SignTransaction(proposedTransaction); err != nil {
+		return nil, err
+	}
+	return proposedTransaction, nil
+}
+
+// signTransaction signs all inputs. It assumes all outputs spent belong to this
+// wallet. previousOutputs must contain all outputs which are spent by the transaction.
+func (account *Account) signTransaction(
+	txProposal *maketx.TxProposal,
+	getPrevTx func(chainhash.Hash) (*wire.MsgTx, error),
+) error {
+	if multisig := account.multisig(); multisig != nil && multisig.Threshold > 1 {
+		return errp.WithStack(ErrMultisigNeedsCosigners)
+	}
+	previousOutputs := txProposal.PreviousOutputs
+	proposedTransaction, err := account.signTxProposal(txProposal, getPrevTx)
+	if err != nil {
 		return err
 	}
 
@@ -0,0 +1,271 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package btc
+
+import (
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/addresses"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/blockchain"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/silentpayments"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/transactions"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/config"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// silentPaymentKeys are the keys of an account used to receive silent payments (BIP-352).
+type silentPaymentKeys struct {
+	// configuration is the taproot configuration of the extended spend key, see
+	// config.SilentPayments.
+	configuration  *signing.Configuration
+	scanKey        *btcec.PrivateKey
+	spendPublicKey *btcec.PublicKey
+	birthHeight    int
+}
+
+func newSilentPaymentKeys(silentPaymentsConfig *config.SilentPayments) (*silentPaymentKeys, error) {
+	configuration := silentPaymentsConfig.Configuration
+	if configuration == nil || configuration.ScriptType() != signing.ScriptTypeP2TR {
+		return nil, errp.New("invalid silent payments configuration")
+	}
+	if len(silentPaymentsConfig.ScanKey) != btcec.PrivKeyBytesLen {
+		return nil, errp.New("invalid silent payments scan key")
+	}
+	scanKey, _ := btcec.PrivKeyFromBytes(silentPaymentsConfig.ScanKey)
+	spendXpub, err := configuration.ExtendedPublicKey().Derive(0)
+	if err != nil {
+		return nil, errp.WithStack(err)
+	}
+	spendPublicKey, err := spendXpub.ECPubKey()
+	if err != nil {
+		return nil, errp.WithStack(err)
+	}
+	return &silentPaymentKeys{
+		configuration:  configuration,
+		scanKey:        scanKey,
+		spendPublicKey: spendPublicKey,
+		birthHeight:    silentPaymentsConfig.BirthHeight,
+	}, nil
+}
+
+// initializeSilentPayments loads the outputs received through silent payments which were found
+// in previous scans. It is called in Initialize() if silent payments are enabled for the account.
+func (account *Account) initializeSilentPayments(silentPaymentsConfig *config.SilentPayments) error {
+	keys, err := newSilentPaymentKeys(silentPaymentsConfig)
+	if err != nil {
+		return err
+	}
+	account.silentPayments = keys
+	account.silentPaymentAddresses = map[blockchain.ScriptHashHex]*addresses.AccountAddress{}
+	outputs, err := transactions.DBView(account.db, func(dbTx transactions.DBTxInterface) (map[wire.OutPoint]*transactions.SilentPaymentOutput, error) {
+		return dbTx.SilentPaymentOutputs()
+	})
+	if err != nil {
+		return err
+	}
+	for _, output := range outputs {
+		if _, err := account.addSilentPaymentAddress(output.Tweak); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addSilentPaymentAddress adds the address of an output received through a silent payment to the
+// account. Returns nil if the address was added before.
+func (account *Account) addSilentPaymentAddress(tweak []byte) (*addresses.AccountAddress, error) {
+	address, err := addresses.NewSilentPaymentAccountAddress(
+		account.silentPayments.configuration, tweak, account.coin.Net(), account.log)
+	if err != nil {
+		return nil, err
+	}
+	defer account.silentPaymentAddressesLock.Lock()()
+	if _, ok := account.silentPaymentAddresses[address.PubkeyScriptHashHex()]; ok {
+		return nil, nil
+	}
+	account.silentPaymentAddresses[address.PubkeyScriptHashHex()] = address
+	return address, nil
+}
+
+// silentPaymentAddress returns the address of an output received through a silent payment, or nil
+// if there is no such output with the given script hash.
+func (account *Account) silentPaymentAddress(scriptHashHex blockchain.ScriptHashHex) *addresses.AccountAddress {
+	defer account.silentPaymentAddressesLock.RLock()()
+	return account.silentPaymentAddresses[scriptHashHex]
+}
+
+// subscribeSilentPaymentAddresses subscribes to the addresses of all outputs received through
+// silent payments, so that their transactions are synced like the ones of any other address.
+func (account *Account) subscribeSilentPaymentAddresses() {
+	unlock := account.silentPaymentAddressesLock.RLock()
+	silentPaymentAddresses := make([]*addresses.AccountAddress, 0, len(account.silentPaymentAddresses))
+	for _, address := range account.silentPaymentAddresses {
+		silentPaymentAddresses = append(silentPaymentAddresses, address)
+	}
+	unlock()
+	for _, address := range silentPaymentAddresses {
+		account.subscribeAddress(address)
+	}
+}
+
+// scanSilentPaymentsBlock returns the outputs in the block at the given height which were received
+// through a silent payment to the account.
+func (account *Account) scanSilentPaymentsBlock(
+	tweakIndex silentpayments.TweakIndex, height int,
+) (map[wire.OutPoint]*transactions.SilentPaymentOutput, error) {
+	tweaks, err := tweakIndex.Tweaks(height)
+	if err != nil {
+		return nil, err
+	}
+	if len(tweaks) == 0 {
+		return nil, nil
+	}
+	outputs, err := tweakIndex.TaprootOutputs(height)
+	if err != nil {
+		return nil, err
+	}
+	outputKeys := make([][]byte, len(outputs))
+	for index, output := range outputs {
+		outputKeys[index] = output.OutputKey()
+	}
+	found := map[wire.OutPoint]*transactions.SilentPaymentOutput{}
+	// The tweak index does not tell which transaction a tweak belongs to, so the outputs of the
+	// whole block are searched with each tweak.
+	for _, tweak := range tweaks {
+		keys := account.silentPayments
+		for index, outputTweak := range silentpayments.Scan(keys.scanKey, keys.spendPublicKey, tweak, outputKeys) {
+			found[outputs[index].OutPoint] = &transactions.SilentPaymentOutput{
+				Tweak:  outputTweak,
+				Height: height,
+			}
+		}
+	}
+	return found, nil
+}
+
+// scanSilentPayments scans the blocks which were not scanned yet for outputs paying to the
+// account's silent payment address, using the tweak index server of the coin. Found outputs are
+// stored and their addresses are subscribed to, so that their transactions are synced and the
+// outputs can be spent like any other output of the account. Reorgs are handled by the regular
+// address sync: an output whose transaction is not in the chain anymore is not spendable.
+func (account *Account) scanSilentPayments() {
+	defer account.silentPaymentsScanLock.Lock()()
+	if account.isClosed() {
+		return
+	}
+	tweakIndex := account.coin.TweakIndex()
+	if tweakIndex == nil {
+		account.log.Warn("No tweak index server configured, not scanning for silent payments")
+		return
+	}
+	tipHeight, err := tweakIndex.BlockHeight()
+	if err != nil {
+		account.log.WithError(err).Error("Failed to get the block height of the tweak index")
+		return
+	}
+	scanHeight, err := account.silentPaymentsScanHeight()
+	if err != nil {
+		account.log.WithError(err).Error("Failed to get the silent payments scan height")
+		return
+	}
+	if scanHeight == 0 && account.silentPayments.birthHeight == 0 {
+		// The chain tip was not known when silent payments were enabled. Start scanning now.
+		scanHeight = tipHeight
+	}
+	for height := max(scanHeight, account.silentPayments.birthHeight) + 1; height <= tipHeight; height++ {
+		if account.isClosed() {
+			return
+		}
+		found, err := account.scanSilentPaymentsBlock(tweakIndex, height)
+		if err != nil {
+			account.log.WithError(err).WithField("height", height).Error("Failed to scan for silent payments")
+			return
+		}
+		err = transactions.DBUpdate(account.db, func(dbTx transactions.DBTxInterface) error {
+			for outPoint, output := range found {
+				if err := dbTx.PutSilentPaymentOutput(outPoint, output); err != nil {
+					return err
+				}
+			}
+			return dbTx.PutSilentPaymentsScanHeight(height)
+		})
+		if err != nil {
+			account.log.WithError(err).Error("Failed to store silent payment outputs")
+			return
+		}
+		for outPoint, output := range found {
+			account.log.WithField("outPoint", outPoint.String()).Info("Received a silent payment")
+			address, err := account.addSilentPaymentAddress(output.Tweak)
+			if err != nil {
+				account.log.WithError(err).Error("Failed to add silent payment address")
+				continue
+			}
+			if address != nil {
+				account.subscribeAddress(address)
+			}
+		}
+	}
+	if scanHeight == tipHeight {
+		err := transactions.DBUpdate(account.db, func(dbTx transactions.DBTxInterface) error {
+			return dbTx.PutSilentPaymentsScanHeight(scanHeight)
+		})
+		if err != nil {
+			account.log.WithError(err).Error("Failed to store the silent payments scan height")
+		}
+	}
+}
+
+func (account *Account) silentPaymentsScanHeight() (int, error) {
+	return transactions.DBView(account.db, func(dbTx transactions.DBTxInterface) (int, error) {
+		return dbTx.SilentPaymentsScanHeight()
+	})
+}
+
+// SilentPaymentsStatus is the status of receiving silent payments on an account.
+type SilentPaymentsStatus struct {
+	// Address is the silent payment address of the account, sp1... on mainnet.
+	Address string
+	// ScanHeight is the height of the last block scanned for silent payments.
+	ScanHeight int
+	// Outputs is the number of outputs received through silent payments.
+	Outputs int
+}
+
+// SilentPayments returns the status of receiving silent payments, or nil if silent payments are
+// not enabled for the account.
+func (account *Account) SilentPayments() (*SilentPaymentsStatus, error) {
+	if !account.isInitialized() {
+		return nil, errp.New("account not initialized")
+	}
+	keys := account.silentPayments
+	if keys == nil {
+		return nil, nil
+	}
+	address, err := silentpayments.EncodeAddress(keys.scanKey.PubKey(), keys.spendPublicKey, account.coin.Net())
+	if err != nil {
+		return nil, err
+	}
+	scanHeight, err := account.silentPaymentsScanHeight()
+	if err != nil {
+		return nil, err
+	}
+	defer account.silentPaymentAddressesLock.RLock()()
+	return &SilentPaymentsStatus{
+		Address:    address,
+		ScanHeight: max(scanHeight, keys.birthHeight),
+		Outputs:    len(account.silentPaymentAddresses),
+	}, nil
+}
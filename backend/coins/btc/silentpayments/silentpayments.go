@@ -0,0 +1,173 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package silentpayments implements receiving silent payments, see
+// https://github.com/bitcoin/bips/blob/master/bip-0352.mediawiki.
+package silentpayments
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil/bech32"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+const (
+	// purpose is the BIP-43 purpose of silent payment keys: m/352'/coin'/account'.
+	purpose = 352
+
+	// maxOutputsPerTx is K_max of BIP-352, the maximum number of outputs a sender may create for
+	// the same recipient in one transaction.
+	maxOutputsPerTx = 2323
+)
+
+var sharedSecretTag = []byte("BIP0352/SharedSecret")
+
+// AccountKeypath returns the keypath m/352'/coin'/account' of the silent payment keys of an
+// account. The spend key is at m/352'/coin'/account'/0'/0 and the scan key at
+// m/352'/coin'/account'/1'/0.
+func AccountKeypath(coinType uint32, accountNumber uint16) signing.AbsoluteKeypath {
+	return signing.NewAbsoluteKeypathFromUint32(
+		purpose+hdkeychain.HardenedKeyStart,
+		coinType,
+		uint32(accountNumber)+hdkeychain.HardenedKeyStart,
+	)
+}
+
+// hrp returns the human-readable part of silent payment addresses on the given network.
+func hrp(net *chaincfg.Params) (string, error) {
+	switch net.Net {
+	case chaincfg.MainNetParams.Net:
+		return "sp", nil
+	case chaincfg.TestNet3Params.Net:
+		return "tsp", nil
+	case chaincfg.RegressionNetParams.Net:
+		return "sprt", nil
+	default:
+		return "", errp.Newf("silent payments are not supported on %s", net.Name)
+	}
+}
+
+// EncodeAddress returns the version 0 silent payment address (sp1..., or tsp1... on testnet) of the
+// given scan and spend public keys.
+func EncodeAddress(scanPublicKey, spendPublicKey *btcec.PublicKey, net *chaincfg.Params) (string, error) {
+	addressHrp, err := hrp(net)
+	if err != nil {
+		return "", err
+	}
+	payload := append(scanPublicKey.SerializeCompressed(), spendPublicKey.SerializeCompressed()...)
+	data, err := bech32.ConvertBits(payload, 8, 5, true)
+	if err != nil {
+		return "", errp.WithStack(err)
+	}
+	address, err := bech32.EncodeM(addressHrp, append([]byte{0}, data...))
+	if err != nil {
+		return "", errp.WithStack(err)
+	}
+	return address, nil
+}
+
+// sharedSecretTweak returns t_k = hash_BIP0352/SharedSecret(serP(ecdh_shared_secret) || ser32(k)).
+func sharedSecretTweak(sharedSecret *btcec.PublicKey, k uint32) *btcec.ModNScalar {
+	var kBytes [4]byte
+	binary.BigEndian.PutUint32(kBytes[:], k)
+	hash := chainhash.TaggedHash(sharedSecretTag, sharedSecret.SerializeCompressed(), kBytes[:])
+	var tweak btcec.ModNScalar
+	// The probability of an overflow is negligible.
+	tweak.SetByteSlice(hash[:])
+	return &tweak
+}
+
+// OutputKey returns the taproot output key B_spend + t·G of an output received with the given
+// tweak t.
+func OutputKey(spendPublicKey *btcec.PublicKey, tweak []byte) (*btcec.PublicKey, error) {
+	if len(tweak) != 32 {
+		return nil, errp.New("the tweak must be 32 bytes")
+	}
+	var scalar btcec.ModNScalar
+	if overflow := scalar.SetByteSlice(tweak); overflow || scalar.IsZero() {
+		return nil, errp.New("invalid tweak")
+	}
+	return outputKey(spendPublicKey, &scalar), nil
+}
+
+func outputKey(spendPublicKey *btcec.PublicKey, tweak *btcec.ModNScalar) *btcec.PublicKey {
+	var spend, tweakPoint, result btcec.JacobianPoint
+	spendPublicKey.AsJacobian(&spend)
+	btcec.ScalarBaseMultNonConst(tweak, &tweakPoint)
+	btcec.AddNonConst(&spend, &tweakPoint, &result)
+	result.ToAffine()
+	return btcec.NewPublicKey(&result.X, &result.Y)
+}
+
+// SpendPrivateKey returns the private key b_spend + t spending an output received with the given
+// tweak t. It is used as is to sign the taproot key path spend, there is no BIP-86 tweak.
+func SpendPrivateKey(spendPrivateKey *btcec.PrivateKey, tweak []byte) (*btcec.PrivateKey, error) {
+	if len(tweak) != 32 {
+		return nil, errp.New("the tweak must be 32 bytes")
+	}
+	var scalar btcec.ModNScalar
+	if overflow := scalar.SetByteSlice(tweak); overflow || scalar.IsZero() {
+		return nil, errp.New("invalid tweak")
+	}
+	scalar.Add(&spendPrivateKey.Key)
+	return &btcec.PrivateKey{Key: scalar}, nil
+}
+
+// Scan returns the outputs of a transaction which pay to us, given the scan private key, the spend
+// public key and the transaction's tweak input_hash·A, as provided by a tweak index server.
+// `outputKeys` are the 32 byte x-only output keys of the taproot outputs of the transaction, or
+// of all transactions of a block if it is not known which transaction the tweak belongs to. The
+// result maps the indices of our outputs in `outputKeys` to their 32 byte tweak t_k, which is
+// needed to spend them. Labels are not supported.
+func Scan(
+	scanPrivateKey *btcec.PrivateKey,
+	spendPublicKey *btcec.PublicKey,
+	tweak *btcec.PublicKey,
+	outputKeys [][]byte,
+) map[int][]byte {
+	// ecdh_shared_secret = b_scan·input_hash·A
+	var tweakPoint, sharedSecretPoint btcec.JacobianPoint
+	tweak.AsJacobian(&tweakPoint)
+	btcec.ScalarMultNonConst(&scanPrivateKey.Key, &tweakPoint, &sharedSecretPoint)
+	sharedSecretPoint.ToAffine()
+	sharedSecret := btcec.NewPublicKey(&sharedSecretPoint.X, &sharedSecretPoint.Y)
+
+	result := map[int][]byte{}
+	for k := uint32(0); k < maxOutputsPerTx; k++ {
+		tweakK := sharedSecretTweak(sharedSecret, k)
+		expected := schnorr.SerializePubKey(outputKey(spendPublicKey, tweakK))
+		found := false
+		for index, key := range outputKeys {
+			if _, ok := result[index]; ok || !bytes.Equal(key, expected) {
+				continue
+			}
+			tweakBytes := tweakK.Bytes()
+			result[index] = tweakBytes[:]
+			found = true
+			break
+		}
+		if !found {
+			break
+		}
+	}
+	return result
+}
@@ -0,0 +1,125 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package silentpayments
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/stretchr/testify/require"
+)
+
+func unhex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return b
+}
+
+// Keys of the receiver of the "Simple send: two inputs" test vector of BIP-352.
+func vectorKeys(t *testing.T) (*btcec.PrivateKey, *btcec.PrivateKey) {
+	t.Helper()
+	scanKey, _ := btcec.PrivKeyFromBytes(
+		unhex(t, "0f694e068028a717f8af6b9411f9a133dd3565258714cc226594b34db90c1f2c"))
+	spendKey, _ := btcec.PrivKeyFromBytes(
+		unhex(t, "9d6ad855ce3417ef84e836892e5a56392bfba05fa5d97ccea30e266f540e08b3"))
+	return scanKey, spendKey
+}
+
+func TestAccountKeypath(t *testing.T) {
+	require.Equal(t, "m/352'/0'/0'", AccountKeypath(0x80000000, 0).Encode())
+	require.Equal(t, "m/352'/1'/3'", AccountKeypath(0x80000001, 3).Encode())
+}
+
+func TestEncodeAddress(t *testing.T) {
+	scanKey, spendKey := vectorKeys(t)
+	address, err := EncodeAddress(scanKey.PubKey(), spendKey.PubKey(), &chaincfg.MainNetParams)
+	require.NoError(t, err)
+	require.Equal(t,
+		"sp1qqgste7k9hx0qftg6qmwlkqtwuy6cycyavzmzj85c6qdfhjdpdjtdgqjuexzk6murw56suy3e0rd2cgqvycxttddwsvgxe2usfpxumr70xc9pkqwv",
+		address)
+
+	address, err = EncodeAddress(scanKey.PubKey(), spendKey.PubKey(), &chaincfg.TestNet3Params)
+	require.NoError(t, err)
+	require.Equal(t, "tsp1", address[:4])
+
+	_, err = EncodeAddress(scanKey.PubKey(), spendKey.PubKey(), &chaincfg.SimNetParams)
+	require.Error(t, err)
+}
+
+func TestScan(t *testing.T) {
+	scanKey, spendKey := vectorKeys(t)
+	tweak, err := btcec.ParsePubKey(
+		unhex(t, "024ac253c216532e961988e2a8ce266a447c894c781e52ef6cee902361db960004"))
+	require.NoError(t, err)
+	outputKey := unhex(t, "3e9fce73d4e77a4809908e3c3a2e54ee147b9312dc5044a193d1fc85de46e3c1")
+	privKeyTweak := unhex(t, "f438b40179a3c4262de12986c0e6cce0634007cdc79c1dcd3e20b9ebc2e7eef6")
+	otherKey := unhex(t, "0000000000000000000000000000000000000000000000000000000000000001")
+
+	require.Empty(t, Scan(scanKey, spendKey.PubKey(), tweak, nil))
+	require.Empty(t, Scan(scanKey, spendKey.PubKey(), tweak, [][]byte{otherKey}))
+	require.Equal(t,
+		map[int][]byte{1: privKeyTweak},
+		Scan(scanKey, spendKey.PubKey(), tweak, [][]byte{otherKey, outputKey}))
+
+	// Another tweak does not match.
+	require.Empty(t, Scan(scanKey, spendKey.PubKey(), spendKey.PubKey(), [][]byte{outputKey}))
+
+	computedOutputKey, err := OutputKey(spendKey.PubKey(), privKeyTweak)
+	require.NoError(t, err)
+	require.Equal(t, outputKey, schnorr.SerializePubKey(computedOutputKey))
+
+	spendPrivateKey, err := SpendPrivateKey(spendKey, privKeyTweak)
+	require.NoError(t, err)
+	require.Equal(t, outputKey, schnorr.SerializePubKey(spendPrivateKey.PubKey()))
+
+	_, err = SpendPrivateKey(spendKey, privKeyTweak[1:])
+	require.Error(t, err)
+	_, err = OutputKey(spendKey.PubKey(), make([]byte, 32))
+	require.Error(t, err)
+}
+
+func TestScanMultipleOutputs(t *testing.T) {
+	scanKey, spendKey := vectorKeys(t)
+	tweak, err := btcec.ParsePubKey(
+		unhex(t, "024ac253c216532e961988e2a8ce266a447c894c781e52ef6cee902361db960004"))
+	require.NoError(t, err)
+
+	var tweakPoint, sharedSecretPoint btcec.JacobianPoint
+	tweak.AsJacobian(&tweakPoint)
+	btcec.ScalarMultNonConst(&scanKey.Key, &tweakPoint, &sharedSecretPoint)
+	sharedSecretPoint.ToAffine()
+	sharedSecret := btcec.NewPublicKey(&sharedSecretPoint.X, &sharedSecretPoint.Y)
+	outputKeyK := func(k uint32) []byte {
+		return schnorr.SerializePubKey(outputKey(spendKey.PubKey(), sharedSecretTweak(sharedSecret, k)))
+	}
+	tweakK := func(k uint32) []byte {
+		tweakBytes := sharedSecretTweak(sharedSecret, k).Bytes()
+		return tweakBytes[:]
+	}
+
+	// The outputs can be in any order.
+	require.Equal(t,
+		map[int][]byte{0: tweakK(2), 1: tweakK(0), 2: tweakK(1)},
+		Scan(scanKey, spendKey.PubKey(), tweak, [][]byte{outputKeyK(2), outputKeyK(0), outputKeyK(1)}))
+
+	// Scanning stops at the first k without output.
+	require.Equal(t,
+		map[int][]byte{0: tweakK(0)},
+		Scan(scanKey, spendKey.PubKey(), tweak, [][]byte{outputKeyK(0), outputKeyK(2)}))
+}
@@ -0,0 +1,140 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package silentpayments
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/util"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// maxResponseSize limits the size of a response of the tweak index server. Full blocks have a few
+// thousand tweaks and taproot outputs.
+const maxResponseSize = 20 << 20
+
+// TaprootOutput is a taproot output created in a block.
+type TaprootOutput struct {
+	OutPoint wire.OutPoint
+	Value    int64
+	PkScript []byte
+}
+
+// OutputKey returns the 32 byte x-only output key of the output.
+func (output *TaprootOutput) OutputKey() []byte {
+	return output.PkScript[2:]
+}
+
+// TweakIndex is a server providing the data needed to scan the blockchain for silent payments
+// without downloading full blocks: the tweaks input_hash·A of all transactions eligible for silent
+// payments, and the taproot outputs created in each block.
+type TweakIndex interface {
+	// BlockHeight returns the height of the latest block indexed by the server.
+	BlockHeight() (int, error)
+	// Tweaks returns the tweaks of the transactions in the block at the given height.
+	Tweaks(height int) ([]*btcec.PublicKey, error)
+	// TaprootOutputs returns the taproot outputs created in the block at the given height.
+	TaprootOutputs(height int) ([]*TaprootOutput, error)
+}
+
+// BlindBitOracle is a TweakIndex backed by the REST API of a BlindBit Oracle server, see
+// https://github.com/setavenger/blindbit-oracle.
+type BlindBitOracle struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewBlindBitOracle creates a new BlindBitOracle client. `baseURL` is the URL of the API, e.g.
+// "https://silentpayments.example.com/api".
+func NewBlindBitOracle(baseURL string, httpClient *http.Client) *BlindBitOracle {
+	return &BlindBitOracle{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: httpClient,
+	}
+}
+
+// BlockHeight implements TweakIndex.
+func (oracle *BlindBitOracle) BlockHeight() (int, error) {
+	var response struct {
+		BlockHeight int `json:"block_height"`
+	}
+	_, err := util.APIGet(oracle.httpClient, oracle.baseURL+"/block-height", "", maxResponseSize, &response)
+	if err != nil {
+		return 0, err
+	}
+	return response.BlockHeight, nil
+}
+
+// Tweaks implements TweakIndex.
+func (oracle *BlindBitOracle) Tweaks(height int) ([]*btcec.PublicKey, error) {
+	var response []string
+	endpoint := fmt.Sprintf("%s/tweaks/%d", oracle.baseURL, height)
+	if _, err := util.APIGet(oracle.httpClient, endpoint, "", maxResponseSize, &response); err != nil {
+		return nil, err
+	}
+	tweaks := make([]*btcec.PublicKey, len(response))
+	for index, tweakHex := range response {
+		tweakBytes, err := hex.DecodeString(tweakHex)
+		if err != nil {
+			return nil, errp.WithStack(err)
+		}
+		tweaks[index], err = btcec.ParsePubKey(tweakBytes)
+		if err != nil {
+			return nil, errp.WithStack(err)
+		}
+	}
+	return tweaks, nil
+}
+
+// TaprootOutputs implements TweakIndex.
+func (oracle *BlindBitOracle) TaprootOutputs(height int) ([]*TaprootOutput, error) {
+	var response []struct {
+		TxID         string `json:"txid"`
+		Vout         uint32 `json:"vout"`
+		Value        int64  `json:"value"`
+		ScriptPubKey string `json:"scriptpubkey"`
+	}
+	endpoint := fmt.Sprintf("%s/utxos/%d", oracle.baseURL, height)
+	if _, err := util.APIGet(oracle.httpClient, endpoint, "", maxResponseSize, &response); err != nil {
+		return nil, err
+	}
+	outputs := make([]*TaprootOutput, len(response))
+	for index, utxo := range response {
+		txHash, err := chainhash.NewHashFromStr(utxo.TxID)
+		if err != nil {
+			return nil, errp.WithStack(err)
+		}
+		pkScript, err := hex.DecodeString(utxo.ScriptPubKey)
+		if err != nil {
+			return nil, errp.WithStack(err)
+		}
+		if !txscript.IsPayToTaproot(pkScript) {
+			return nil, errp.Newf("unexpected output script %s", utxo.ScriptPubKey)
+		}
+		outputs[index] = &TaprootOutput{
+			OutPoint: *wire.NewOutPoint(txHash, utxo.Vout),
+			Value:    utxo.Value,
+			PkScript: pkScript,
+		}
+	}
+	return outputs, nil
+}
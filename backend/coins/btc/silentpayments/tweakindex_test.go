@@ -0,0 +1,97 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package silentpayments
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlindBitOracle(t *testing.T) {
+	responses := map[string]string{
+		"/api/block-height":  `{"block_height": 850000}`,
+		"/api/tweaks/850000": `["024ac253c216532e961988e2a8ce266a447c894c781e52ef6cee902361db960004"]`,
+		"/api/utxos/850000": `[{
+			"txid": "f4184fc596403b9d638783cf57adfe4c75c605f6356fbc91338530e9831e9e16",
+			"vout": 1,
+			"value": 12345,
+			"scriptpubkey": "51203e9fce73d4e77a4809908e3c3a2e54ee147b9312dc5044a193d1fc85de46e3c1",
+			"block_height": 850000,
+			"spent": false
+		}]`,
+		"/api/tweaks/1":  `["invalid"]`,
+		"/api/utxos/1":   `[{"txid": "f4184fc596403b9d638783cf57adfe4c75c605f6356fbc91338530e9831e9e16", "vout": 0, "value": 1, "scriptpubkey": "0014a5f2d1c9e6ad7f6e04c00b3be7fc2e3a5b6c7d8e"}]`,
+		"/api/tweaks/2":  `[]`,
+		"/api/utxos/2":   `[]`,
+		"/api/tweaks/99": `not json`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(response))
+	}))
+	defer server.Close()
+
+	oracle := NewBlindBitOracle(server.URL+"/api/", server.Client())
+
+	height, err := oracle.BlockHeight()
+	require.NoError(t, err)
+	require.Equal(t, 850000, height)
+
+	tweaks, err := oracle.Tweaks(850000)
+	require.NoError(t, err)
+	require.Len(t, tweaks, 1)
+	require.Equal(t,
+		unhex(t, "024ac253c216532e961988e2a8ce266a447c894c781e52ef6cee902361db960004"),
+		tweaks[0].SerializeCompressed())
+
+	outputs, err := oracle.TaprootOutputs(850000)
+	require.NoError(t, err)
+	txHash, err := chainhash.NewHashFromStr("f4184fc596403b9d638783cf57adfe4c75c605f6356fbc91338530e9831e9e16")
+	require.NoError(t, err)
+	require.Equal(t, []*TaprootOutput{{
+		OutPoint: *wire.NewOutPoint(txHash, 1),
+		Value:    12345,
+		PkScript: unhex(t, "51203e9fce73d4e77a4809908e3c3a2e54ee147b9312dc5044a193d1fc85de46e3c1"),
+	}}, outputs)
+	require.Equal(t,
+		unhex(t, "3e9fce73d4e77a4809908e3c3a2e54ee147b9312dc5044a193d1fc85de46e3c1"),
+		outputs[0].OutputKey())
+
+	tweaks, err = oracle.Tweaks(2)
+	require.NoError(t, err)
+	require.Empty(t, tweaks)
+	outputs, err = oracle.TaprootOutputs(2)
+	require.NoError(t, err)
+	require.Empty(t, outputs)
+
+	_, err = oracle.Tweaks(1)
+	require.Error(t, err)
+	// Only taproot outputs are expected.
+	_, err = oracle.TaprootOutputs(1)
+	require.Error(t, err)
+	_, err = oracle.Tweaks(99)
+	require.Error(t, err)
+	_, err = oracle.Tweaks(3)
+	require.Error(t, err)
+}
@@ -0,0 +1,196 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package btc
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/blockchain"
+	blockchainMocks "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/blockchain/mocks"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/silentpayments"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/transactions"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/config"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+type tweakIndexMock struct {
+	lock    sync.Mutex
+	height  int
+	tweaks  map[int][]*btcec.PublicKey
+	outputs map[int][]*silentpayments.TaprootOutput
+	scanned []int
+}
+
+func (index *tweakIndexMock) BlockHeight() (int, error) {
+	index.lock.Lock()
+	defer index.lock.Unlock()
+	return index.height, nil
+}
+
+func (index *tweakIndexMock) Tweaks(height int) ([]*btcec.PublicKey, error) {
+	index.lock.Lock()
+	defer index.lock.Unlock()
+	index.scanned = append(index.scanned, height)
+	return index.tweaks[height], nil
+}
+
+func (index *tweakIndexMock) TaprootOutputs(height int) ([]*silentpayments.TaprootOutput, error) {
+	index.lock.Lock()
+	defer index.lock.Unlock()
+	return index.outputs[height], nil
+}
+
+func (index *tweakIndexMock) getScanned() []int {
+	index.lock.Lock()
+	defer index.lock.Unlock()
+	return append([]int{}, index.scanned...)
+}
+
+func TestSilentPayments(t *testing.T) {
+	net := &chaincfg.TestNet3Params
+	master, err := hdkeychain.NewMaster(make([]byte, 32), net)
+	require.NoError(t, err)
+	keypath := silentpayments.AccountKeypath(hdkeychain.HardenedKeyStart+1, 0)
+	spendXprv, err := keypath.Child(0, signing.Hardened).Derive(master)
+	require.NoError(t, err)
+	spendXpub, err := spendXprv.Neuter()
+	require.NoError(t, err)
+	spendChild, err := spendXpub.Derive(0)
+	require.NoError(t, err)
+	spendPublicKey, err := spendChild.ECPubKey()
+	require.NoError(t, err)
+	scanXprv, err := keypath.Child(1, signing.Hardened).Child(0, signing.NonHardened).Derive(master)
+	require.NoError(t, err)
+	scanKey, err := scanXprv.ECPrivKey()
+	require.NoError(t, err)
+
+	// A payment to the silent payment address, computed like a sender would.
+	_, inputKey := btcec.PrivKeyFromBytes([]byte{3})
+	var tweakPoint, sharedSecretPoint btcec.JacobianPoint
+	inputKey.AsJacobian(&tweakPoint)
+	btcec.ScalarMultNonConst(&scanKey.Key, &tweakPoint, &sharedSecretPoint)
+	sharedSecretPoint.ToAffine()
+	sharedSecret := btcec.NewPublicKey(&sharedSecretPoint.X, &sharedSecretPoint.Y)
+	outputTweak := chainhash.TaggedHash(
+		[]byte("BIP0352/SharedSecret"), sharedSecret.SerializeCompressed(), []byte{0, 0, 0, 0})
+	outputKey, err := silentpayments.OutputKey(spendPublicKey, outputTweak[:])
+	require.NoError(t, err)
+	pkScript, err := txscript.PayToTaprootScript(outputKey)
+	require.NoError(t, err)
+	otherPkScript, err := txscript.PayToTaprootScript(inputKey)
+	require.NoError(t, err)
+	outPoint := *wire.NewOutPoint(&chainhash.Hash{1}, 1)
+
+	tweakIndex := &tweakIndexMock{
+		height: 102,
+		tweaks: map[int][]*btcec.PublicKey{
+			// Before the birth height, not scanned.
+			100: {inputKey},
+			101: {inputKey},
+		},
+		outputs: map[int][]*silentpayments.TaprootOutput{
+			100: {{OutPoint: outPoint, Value: 1000, PkScript: pkScript}},
+			101: {
+				{OutPoint: *wire.NewOutPoint(&chainhash.Hash{1}, 0), Value: 2000, PkScript: otherPkScript},
+				{OutPoint: outPoint, Value: 1000, PkScript: pkScript},
+			},
+		},
+	}
+
+	var subscribedLock sync.Mutex
+	subscribed := map[blockchain.ScriptHashHex]bool{}
+	blockchainMock := &blockchainMocks.BlockchainMock{
+		MockRegisterOnConnectionErrorChangedEvent: func(func(error)) {},
+		MockScriptHashSubscribe: func(_ func() func(), scriptHashHex blockchain.ScriptHashHex, _ func(string)) {
+			subscribedLock.Lock()
+			defer subscribedLock.Unlock()
+			subscribed[scriptHashHex] = true
+		},
+	}
+	isSubscribed := func(scriptHashHex blockchain.ScriptHashHex) bool {
+		subscribedLock.Lock()
+		defer subscribedLock.Unlock()
+		return subscribed[scriptHashHex]
+	}
+
+	account := mockAccount(t, nil)
+	account.coin.TstSetMakeBlockchain(func() blockchain.Interface { return blockchainMock })
+	account.coin.SetTweakIndex(tweakIndex)
+	account.Config().Config.SilentPayments = &config.SilentPayments{
+		Configuration: signing.NewBitcoinConfiguration(
+			signing.ScriptTypeP2TR, []byte{1, 2, 3, 4}, keypath.Child(0, signing.Hardened), spendXpub),
+		ScanKey:     scanKey.Serialize(),
+		BirthHeight: 100,
+	}
+
+	_, err = account.SilentPayments()
+	require.Error(t, err)
+
+	require.NoError(t, account.Initialize())
+	defer account.Close()
+
+	require.Eventually(t,
+		func() bool {
+			status, err := account.SilentPayments()
+			require.NoError(t, err)
+			return status.ScanHeight == 102
+		},
+		time.Second, 10*time.Millisecond)
+	require.Equal(t, []int{101, 102}, tweakIndex.getScanned())
+	scriptHashHex := blockchain.NewScriptHashHex(pkScript)
+	require.True(t, isSubscribed(scriptHashHex))
+
+	status, err := account.SilentPayments()
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(status.Address, "tsp1"), status.Address)
+	require.Equal(t, 102, status.ScanHeight)
+	require.Equal(t, 1, status.Outputs)
+
+	address := account.GetAddress(scriptHashHex)
+	require.NotNil(t, address)
+	require.Equal(t, outputTweak[:], address.SilentPaymentTweak())
+	require.Equal(t, "m/352'/1'/0'/0'/0", address.AbsoluteKeypath().Encode())
+	require.Nil(t, account.GetAddress(blockchain.NewScriptHashHex(otherPkScript)))
+
+	outputs, err := transactions.DBView(account.db, func(dbTx transactions.DBTxInterface) (map[wire.OutPoint]*transactions.SilentPaymentOutput, error) {
+		return dbTx.SilentPaymentOutputs()
+	})
+	require.NoError(t, err)
+	require.Len(t, outputs, 1)
+	require.Equal(t, 101, outputs[outPoint].Height)
+
+	// Already scanned blocks are not scanned again.
+	account.scanSilentPayments()
+	require.Equal(t, []int{101, 102}, tweakIndex.getScanned())
+}
+
+func TestSilentPaymentsDisabled(t *testing.T) {
+	account := mockAccount(t, nil)
+	require.NoError(t, account.Initialize())
+	defer account.Close()
+	status, err := account.SilentPayments()
+	require.NoError(t, err)
+	require.Nil(t, status)
+}
@@ -0,0 +1,138 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package btc
+
+import (
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/errors"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/addresses"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/maketx"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/sweep"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+)
+
+// Sweep is the result of SweepProposal().
+type Sweep struct {
+	Amount coin.Amount
+	Fee    coin.Amount
+	Total  coin.Amount
+	// CoinCount is the number of swept coins.
+	CoinCount int
+	// Address is the receive address of this account the coins are sent to.
+	Address string
+}
+
+// sweepAddress returns a fresh receive address of the subaccount with the given script type, or of
+// the first subaccount if the script type is empty.
+func (account *Account) sweepAddress(scriptType signing.ScriptType) (*addresses.AccountAddress, error) {
+	if len(account.subaccounts) == 0 {
+		return nil, errp.New("Account has no subaccounts")
+	}
+	index := 0
+	if scriptType != "" {
+		index = account.subaccounts.signingConfigurations().FindScriptType(scriptType)
+		if index < 0 {
+			return nil, errp.Newf("The account has no %s addresses", scriptType)
+		}
+	}
+	unusedAddresses, err := account.subaccounts[index].receiveAddresses.GetUnused()
+	if err != nil {
+		return nil, err
+	}
+	return unusedAddresses[0], nil
+}
+
+// SweepProposal creates and signs a transaction moving all coins locked to the given private keys,
+// e.g. of a paper wallet, to a fresh receive address of this account. The keys are WIF encoded or
+// BIP-38 encrypted, in which case they are decrypted using the passphrase. Coins of all standard
+// script types are found. The fee target or custom fee of args is used, and scriptType selects the
+// receive address type (optional).
+//
+// The keys are only used for signing and are never stored. The signed transaction is kept in
+// memory until it is broadcast with SendSweep(), and replaces any previous sweep proposal.
+func (account *Account) SweepProposal(
+	encodedKeys []string, passphrase string, scriptType signing.ScriptType,
+	args *accounts.TxProposalArgs) (*Sweep, error) {
+	defer account.activeTxProposalLock.Lock()()
+	account.activeSweepTx = nil
+
+	if !account.Synced() {
+		return nil, accounts.ErrSyncInProgress
+	}
+	if len(encodedKeys) == 0 {
+		return nil, errp.WithStack(sweep.ErrInvalidKey)
+	}
+	net := account.coin.Net()
+	keys := make([]*sweep.Key, len(encodedKeys))
+	for i, encodedKey := range encodedKeys {
+		key, err := sweep.ParseKey(encodedKey, passphrase, net)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = key
+	}
+	account.log.WithField("keys", len(keys)).Debug("Proposing sweep")
+	coins, err := sweep.FindCoins(account.coin.Blockchain(), keys, net)
+	if err != nil {
+		return nil, err
+	}
+	if len(coins) == 0 {
+		return nil, errp.WithStack(errors.ErrInsufficientFunds)
+	}
+	feeRatePerKb, err := account.getFeePerKb(args)
+	if err != nil {
+		return nil, err
+	}
+	address, err := account.sweepAddress(scriptType)
+	if err != nil {
+		return nil, err
+	}
+	sweepTx, err := maketx.NewSweepTx(
+		account.coin, sweep.SweepInputs(coins), feeRatePerKb, address, account.log)
+	if err != nil {
+		return nil, err
+	}
+	if err := sweep.Sign(sweepTx.Transaction, coins, net); err != nil {
+		return nil, errp.WithMessage(err, "Failed to sign sweep transaction")
+	}
+
+	account.activeSweepTx = sweepTx.Transaction
+
+	return &Sweep{
+		Amount:    coin.NewAmountFromInt64(int64(sweepTx.Amount)),
+		Fee:       coin.NewAmountFromInt64(int64(sweepTx.Fee)),
+		Total:     coin.NewAmountFromInt64(int64(sweepTx.Amount + sweepTx.Fee)),
+		CoinCount: len(coins),
+		Address:   address.EncodeForHumans(),
+	}, nil
+}
+
+// SendSweep broadcasts the transaction created by SweepProposal() and returns its ID.
+func (account *Account) SendSweep(txNote string) (string, error) {
+	defer account.activeTxProposalLock.Lock()()
+	transaction := account.activeSweepTx
+	if transaction == nil {
+		return "", errp.New("No active sweep proposal")
+	}
+	account.log.Info("Broadcasting sweep transaction")
+	if err := account.coin.Blockchain().TransactionBroadcast(transaction); err != nil {
+		return "", err
+	}
+	account.activeSweepTx = nil
+	account.txBroadcasted(transaction, txNote)
+	return transaction.TxHash().String(), nil
+}
@@ -0,0 +1,152 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sweep
+
+import (
+	"bytes"
+	"crypto/aes"
+	"math/big"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/base58"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"golang.org/x/crypto/scrypt"
+)
+
+// See https://github.com/bitcoin/bips/blob/master/bip-0038.mediawiki.
+const (
+	bip38Length = 39
+
+	bip38PrefixNonECMultiplied = 0x42
+	bip38PrefixECMultiplied    = 0x43
+
+	bip38FlagCompressed    = 0x20
+	bip38FlagLotSequence   = 0x04
+	bip38FlagNonECMultiply = 0xc0
+)
+
+// IsBIP38 returns true if the encoded key looks like a BIP-38 encrypted private key.
+func IsBIP38(encoded string) bool {
+	decoded := base58.Decode(encoded)
+	return len(decoded) == bip38Length+4 && decoded[0] == 0x01 &&
+		(decoded[1] == bip38PrefixNonECMultiplied || decoded[1] == bip38PrefixECMultiplied)
+}
+
+// decryptBIP38 decrypts a BIP-38 encrypted private key. Both the non-EC-multiplied and the
+// EC-multiplied (passphrase-protected paper wallet) variants are supported. The address hash is
+// checked against the address of the decrypted key on the given network.
+func decryptBIP38(encoded string, passphrase string, net *chaincfg.Params) (*btcutil.WIF, error) {
+	decoded, version, err := base58.CheckDecode(encoded)
+	if err != nil {
+		return nil, errp.WithStack(ErrInvalidKey)
+	}
+	// CheckDecode treats the first byte as the version.
+	data := append([]byte{version}, decoded...)
+	if len(data) != bip38Length || data[0] != 0x01 {
+		return nil, errp.WithStack(ErrInvalidKey)
+	}
+	flag := data[2]
+	compressed := flag&bip38FlagCompressed != 0
+	addressHash := data[3:7]
+	password := []byte(passphrase)
+
+	var privateKey *btcec.PrivateKey
+	switch data[1] {
+	case bip38PrefixNonECMultiplied:
+		if flag&bip38FlagNonECMultiply != bip38FlagNonECMultiply {
+			return nil, errp.WithStack(ErrInvalidKey)
+		}
+		derived, err := scrypt.Key(password, addressHash, 16384, 8, 8, 64)
+		if err != nil {
+			return nil, errp.WithStack(err)
+		}
+		decrypted, err := aesDecryptXOR(derived[32:], data[7:23], derived[:16])
+		if err != nil {
+			return nil, err
+		}
+		decrypted2, err := aesDecryptXOR(derived[32:], data[23:39], derived[16:32])
+		if err != nil {
+			return nil, err
+		}
+		privateKey, _ = btcec.PrivKeyFromBytes(append(decrypted, decrypted2...))
+	case bip38PrefixECMultiplied:
+		ownerEntropy := data[7:15]
+		ownerSalt := ownerEntropy
+		if flag&bip38FlagLotSequence != 0 {
+			ownerSalt = ownerEntropy[:4]
+		}
+		passFactor, err := scrypt.Key(password, ownerSalt, 16384, 8, 8, 32)
+		if err != nil {
+			return nil, errp.WithStack(err)
+		}
+		if flag&bip38FlagLotSequence != 0 {
+			passFactor = chainhash.DoubleHashB(append(passFactor, ownerEntropy...))
+		}
+		passFactorKey, _ := btcec.PrivKeyFromBytes(passFactor)
+		passPoint := passFactorKey.PubKey().SerializeCompressed()
+		derived, err := scrypt.Key(passPoint, data[3:15], 1024, 1, 1, 64)
+		if err != nil {
+			return nil, errp.WithStack(err)
+		}
+		// encryptedpart2 decrypts to the second half of encryptedpart1 and the end of seedb.
+		decrypted2, err := aesDecryptXOR(derived[32:], data[23:39], derived[16:32])
+		if err != nil {
+			return nil, err
+		}
+		encryptedPart1 := append(append([]byte{}, data[15:23]...), decrypted2[:8]...)
+		decrypted1, err := aesDecryptXOR(derived[32:], encryptedPart1, derived[:16])
+		if err != nil {
+			return nil, err
+		}
+		seedB := append(decrypted1, decrypted2[8:]...)
+		factorB := new(big.Int).SetBytes(chainhash.DoubleHashB(seedB))
+		key := new(big.Int).SetBytes(passFactor)
+		key.Mul(key, factorB)
+		key.Mod(key, btcec.S256().N)
+		privateKey, _ = btcec.PrivKeyFromBytes(key.FillBytes(make([]byte, 32)))
+	default:
+		return nil, errp.WithStack(ErrInvalidKey)
+	}
+
+	wif, err := btcutil.NewWIF(privateKey, net, compressed)
+	if err != nil {
+		return nil, errp.WithStack(err)
+	}
+	address, err := btcutil.NewAddressPubKeyHash(btcutil.Hash160(wif.SerializePubKey()), net)
+	if err != nil {
+		return nil, errp.WithStack(err)
+	}
+	if !bytes.Equal(chainhash.DoubleHashB([]byte(address.EncodeAddress()))[:4], addressHash) {
+		return nil, errp.WithStack(ErrWrongPassphrase)
+	}
+	return wif, nil
+}
+
+// aesDecryptXOR decrypts a 16 byte block with AES-256 and XORs the result with `mask`.
+func aesDecryptXOR(key []byte, block []byte, mask []byte) ([]byte, error) {
+	cipher, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errp.WithStack(err)
+	}
+	decrypted := make([]byte, aes.BlockSize)
+	cipher.Decrypt(decrypted, block)
+	for i := range decrypted {
+		decrypted[i] ^= mask[i]
+	}
+	return decrypted, nil
+}
@@ -0,0 +1,166 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sweep moves coins locked to external private keys, e.g. of paper wallets, into an
+// account. The keys are only held in memory while signing.
+package sweep
+
+import (
+	"strings"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/errors"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+var (
+	// ErrInvalidKey is returned if a key is neither a valid WIF key nor a BIP-38 encrypted key for
+	// the network of the account.
+	ErrInvalidKey = errors.TxValidationError("invalidKey")
+	// ErrWrongPassphrase is returned if a BIP-38 encrypted key cannot be decrypted with the given
+	// passphrase.
+	ErrWrongPassphrase = errors.TxValidationError("wrongPassphrase")
+)
+
+// Key is a private key to be swept.
+type Key struct {
+	wif *btcutil.WIF
+}
+
+// ParseKey parses a private key in the wallet import format (WIF), or a BIP-38 encrypted private
+// key, which is decrypted using the passphrase.
+func ParseKey(encoded string, passphrase string, net *chaincfg.Params) (*Key, error) {
+	encoded = strings.TrimSpace(encoded)
+	if IsBIP38(encoded) {
+		wif, err := decryptBIP38(encoded, passphrase, net)
+		if err != nil {
+			return nil, err
+		}
+		return &Key{wif: wif}, nil
+	}
+	wif, err := btcutil.DecodeWIF(encoded)
+	if err != nil || !wif.IsForNet(net) {
+		return nil, errp.WithStack(ErrInvalidKey)
+	}
+	return &Key{wif: wif}, nil
+}
+
+// scriptTypes returns the script types coins locked to this key can have. Coins locked to an
+// uncompressed public key can only be P2PKH.
+func (key *Key) scriptTypes() []signing.ScriptType {
+	if !key.wif.CompressPubKey {
+		return []signing.ScriptType{signing.ScriptTypeP2PKH}
+	}
+	return []signing.ScriptType{
+		signing.ScriptTypeP2PKH,
+		signing.ScriptTypeP2WPKHP2SH,
+		signing.ScriptTypeP2WPKH,
+		signing.ScriptTypeP2TR,
+	}
+}
+
+// pkScript returns the pubkey script of the given type locking coins to this key. P2TR outputs
+// commit to the key without a script path, like BIP-86.
+func (key *Key) pkScript(scriptType signing.ScriptType, net *chaincfg.Params) ([]byte, error) {
+	publicKeyHash := btcutil.Hash160(key.wif.SerializePubKey())
+	var address btcutil.Address
+	var err error
+	switch scriptType {
+	case signing.ScriptTypeP2PKH:
+		address, err = btcutil.NewAddressPubKeyHash(publicKeyHash, net)
+	case signing.ScriptTypeP2WPKHP2SH:
+		var redeemScript []byte
+		redeemScript, err = key.pkScript(signing.ScriptTypeP2WPKH, net)
+		if err != nil {
+			return nil, err
+		}
+		address, err = btcutil.NewAddressScriptHash(redeemScript, net)
+	case signing.ScriptTypeP2WPKH:
+		address, err = btcutil.NewAddressWitnessPubKeyHash(publicKeyHash, net)
+	case signing.ScriptTypeP2TR:
+		outputKey := txscript.ComputeTaprootKeyNoScript(key.wif.PrivKey.PubKey())
+		address, err = btcutil.NewAddressTaproot(schnorr.SerializePubKey(outputKey), net)
+	default:
+		return nil, errp.Newf("Unsupported script type %s", scriptType)
+	}
+	if err != nil {
+		return nil, errp.WithStack(err)
+	}
+	pkScript, err := txscript.PayToAddrScript(address)
+	if err != nil {
+		return nil, errp.WithStack(err)
+	}
+	return pkScript, nil
+}
+
+// sign signs the input at the given index, which spends a coin of the given type locked to this
+// key.
+func (key *Key) sign(
+	tx *wire.MsgTx,
+	sigHashes *txscript.TxSigHashes,
+	index int,
+	txOut *wire.TxOut,
+	scriptType signing.ScriptType,
+	net *chaincfg.Params,
+) error {
+	txIn := tx.TxIn[index]
+	privateKey := key.wif.PrivKey
+	switch scriptType {
+	case signing.ScriptTypeP2PKH:
+		sigScript, err := txscript.SignatureScript(
+			tx, index, txOut.PkScript, txscript.SigHashAll, privateKey, key.wif.CompressPubKey)
+		if err != nil {
+			return errp.WithStack(err)
+		}
+		txIn.SignatureScript = sigScript
+	case signing.ScriptTypeP2WPKH:
+		witness, err := txscript.WitnessSignature(
+			tx, sigHashes, index, txOut.Value, txOut.PkScript, txscript.SigHashAll, privateKey, true)
+		if err != nil {
+			return errp.WithStack(err)
+		}
+		txIn.Witness = witness
+	case signing.ScriptTypeP2WPKHP2SH:
+		redeemScript, err := key.pkScript(signing.ScriptTypeP2WPKH, net)
+		if err != nil {
+			return err
+		}
+		witness, err := txscript.WitnessSignature(
+			tx, sigHashes, index, txOut.Value, redeemScript, txscript.SigHashAll, privateKey, true)
+		if err != nil {
+			return errp.WithStack(err)
+		}
+		sigScript, err := txscript.NewScriptBuilder().AddData(redeemScript).Script()
+		if err != nil {
+			return errp.WithStack(err)
+		}
+		txIn.Witness = witness
+		txIn.SignatureScript = sigScript
+	case signing.ScriptTypeP2TR:
+		witness, err := txscript.TaprootWitnessSignature(
+			tx, sigHashes, index, txOut.Value, txOut.PkScript, txscript.SigHashDefault, privateKey)
+		if err != nil {
+			return errp.WithStack(err)
+		}
+		txIn.Witness = witness
+	default:
+		return errp.Newf("Unsupported script type %s", scriptType)
+	}
+	return nil
+}
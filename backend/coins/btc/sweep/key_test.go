@@ -0,0 +1,81 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sweep
+
+import (
+	"testing"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseKeyWIF(t *testing.T) {
+	const wif = "L44B5gGEpqEDRS9vVPz7QT35jcBG2r3CZwSwQ4fCewXAhAhqGVpP"
+	key, err := ParseKey(" "+wif+"\n", "", &chaincfg.MainNetParams)
+	require.NoError(t, err)
+	require.Equal(t, wif, key.wif.String())
+	require.Len(t, key.scriptTypes(), 4)
+
+	_, err = ParseKey(wif, "", &chaincfg.TestNet3Params)
+	require.Equal(t, ErrInvalidKey, errp.Cause(err))
+	_, err = ParseKey("not a key", "", &chaincfg.MainNetParams)
+	require.Equal(t, ErrInvalidKey, errp.Cause(err))
+}
+
+// TestParseKeyBIP38 uses the test vectors of BIP-38.
+func TestParseKeyBIP38(t *testing.T) {
+	tests := []struct {
+		encrypted  string
+		passphrase string
+		wif        string
+	}{
+		// No compression, no EC multiply.
+		{
+			encrypted:  "6PRVWUbkzzsbcVac2qwfssoUJAN1Xhrg6bNk8J7Nzm5H7kxEbn2Nh2ZoGg",
+			passphrase: "TestingOneTwoThree",
+			wif:        "5KN7MzqK5wt2TP1fQCYyHBtDrXdJuXbUzm4A9rKAteGu3Qi5CVR",
+		},
+		// Compression, no EC multiply.
+		{
+			encrypted:  "6PYNKZ1EAgYgmQfmNVamxyXVWHzK5s6DGhwP4J5o44cvXdoY7sRzhtpUeo",
+			passphrase: "TestingOneTwoThree",
+			wif:        "L44B5gGEpqEDRS9vVPz7QT35jcBG2r3CZwSwQ4fCewXAhAhqGVpP",
+		},
+		// EC multiply, no compression, no lot/sequence numbers.
+		{
+			encrypted:  "6PfQu77ygVyJLZjfvMLyhLMQbYnu5uguoJJ4kMCLqWwPEdfpwANVS76gTX",
+			passphrase: "TestingOneTwoThree",
+			wif:        "5K4caxezwjGCGfnoPTZ8tMcJBLB7Jvyjv4xxeacadhq8nLisLR2",
+		},
+		// EC multiply, no compression, lot/sequence numbers.
+		{
+			encrypted:  "6PgNBNNzDkKdhkT6uJntUXwwzQV8Rr2tZcbkDcuC9DZRsS6AtHts4Ypo1j",
+			passphrase: "MOLON LABE",
+			wif:        "5JLdxTtcTHcfYcmJsNVy1v2PMDx432JPoYcBTVVRHpPaxUrdtf8",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.encrypted, func(t *testing.T) {
+			require.True(t, IsBIP38(test.encrypted))
+			key, err := ParseKey(test.encrypted, test.passphrase, &chaincfg.MainNetParams)
+			require.NoError(t, err)
+			require.Equal(t, test.wif, key.wif.String())
+		})
+	}
+
+	_, err := ParseKey(tests[1].encrypted, "wrong", &chaincfg.MainNetParams)
+	require.Equal(t, ErrWrongPassphrase, errp.Cause(err))
+}
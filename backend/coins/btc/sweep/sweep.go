@@ -0,0 +1,113 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sweep
+
+import (
+	"bytes"
+
+	blockchainpkg "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/blockchain"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/maketx"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// Coin is an unspent coin locked to a key to be swept.
+type Coin struct {
+	maketx.SweepInput
+	key *Key
+}
+
+// FindCoins looks up the unspent coins locked to the given keys, for all standard script types.
+// Unconfirmed coins are included.
+func FindCoins(
+	blockchain blockchainpkg.Interface, keys []*Key, net *chaincfg.Params) (map[wire.OutPoint]*Coin, error) {
+	coins := map[wire.OutPoint]*Coin{}
+	for _, key := range keys {
+		for _, scriptType := range key.scriptTypes() {
+			pkScript, err := key.pkScript(scriptType, net)
+			if err != nil {
+				return nil, err
+			}
+			history, err := blockchain.ScriptHashGetHistory(blockchainpkg.NewScriptHashHex(pkScript))
+			if err != nil {
+				return nil, err
+			}
+			received := map[wire.OutPoint]*wire.TxOut{}
+			spent := map[wire.OutPoint]struct{}{}
+			// The history contains the transactions paying to the script as well as the
+			// transactions spending from it.
+			for _, txInfo := range history {
+				tx, err := blockchain.TransactionGet(txInfo.TXHash.Hash())
+				if err != nil {
+					return nil, err
+				}
+				for _, txIn := range tx.TxIn {
+					spent[txIn.PreviousOutPoint] = struct{}{}
+				}
+				txHash := tx.TxHash()
+				for index, txOut := range tx.TxOut {
+					if bytes.Equal(txOut.PkScript, pkScript) {
+						received[wire.OutPoint{Hash: txHash, Index: uint32(index)}] = txOut
+					}
+				}
+			}
+			for outPoint, txOut := range received {
+				if _, ok := spent[outPoint]; ok {
+					continue
+				}
+				coins[outPoint] = &Coin{
+					SweepInput: maketx.SweepInput{
+						TxOut:        txOut,
+						ScriptType:   scriptType,
+						Uncompressed: !key.wif.CompressPubKey,
+					},
+					key: key,
+				}
+			}
+		}
+	}
+	return coins, nil
+}
+
+// SweepInputs returns the coins as inputs for maketx.NewSweepTx().
+func SweepInputs(coins map[wire.OutPoint]*Coin) map[wire.OutPoint]*maketx.SweepInput {
+	inputs := make(map[wire.OutPoint]*maketx.SweepInput, len(coins))
+	for outPoint, coin := range coins {
+		inputs[outPoint] = &coin.SweepInput
+	}
+	return inputs
+}
+
+// Sign signs all inputs of the transaction, each of which must spend one of the given coins.
+func Sign(tx *wire.MsgTx, coins map[wire.OutPoint]*Coin, net *chaincfg.Params) error {
+	prevOuts := txscript.NewMultiPrevOutFetcher(nil)
+	for _, txIn := range tx.TxIn {
+		coin, ok := coins[txIn.PreviousOutPoint]
+		if !ok {
+			return errp.New("There needs to be exactly one output being spent per input.")
+		}
+		prevOuts.AddPrevOut(txIn.PreviousOutPoint, coin.TxOut)
+	}
+	sigHashes := txscript.NewTxSigHashes(tx, prevOuts)
+	for index, txIn := range tx.TxIn {
+		coin := coins[txIn.PreviousOutPoint]
+		if err := coin.key.sign(tx, sigHashes, index, coin.TxOut, coin.ScriptType, net); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sweep
+
+import (
+	"testing"
+
+	blockchainpkg "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/blockchain"
+	blockchainMocks "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/blockchain/mocks"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+var net = &chaincfg.TestNet3Params
+
+func testKey(t *testing.T, seed byte, compressed bool) *Key {
+	t.Helper()
+	privateKey, _ := btcec.PrivKeyFromBytes(chainhash.HashB([]byte{seed}))
+	wif, err := btcutil.NewWIF(privateKey, net, compressed)
+	require.NoError(t, err)
+	key, err := ParseKey(wif.String(), "", net)
+	require.NoError(t, err)
+	return key
+}
+
+func TestFindCoinsAndSign(t *testing.T) {
+	compressedKey := testKey(t, 1, true)
+	uncompressedKey := testKey(t, 2, false)
+	require.Equal(t, []signing.ScriptType{signing.ScriptTypeP2PKH}, uncompressedKey.scriptTypes())
+
+	pkScripts := map[signing.ScriptType][]byte{}
+	for _, scriptType := range compressedKey.scriptTypes() {
+		pkScript, err := compressedKey.pkScript(scriptType, net)
+		require.NoError(t, err)
+		pkScripts[scriptType] = pkScript
+	}
+	uncompressedPkScript, err := uncompressedKey.pkScript(signing.ScriptTypeP2PKH, net)
+	require.NoError(t, err)
+
+	fundingTx := wire.NewMsgTx(wire.TxVersion)
+	fundingTx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: chainhash.HashH([]byte("funding"))}, nil, nil))
+	fundingTx.AddTxOut(wire.NewTxOut(10000, pkScripts[signing.ScriptTypeP2WPKH]))
+	fundingTx.AddTxOut(wire.NewTxOut(20000, pkScripts[signing.ScriptTypeP2TR]))
+	fundingTx.AddTxOut(wire.NewTxOut(30000, pkScripts[signing.ScriptTypeP2WPKHP2SH]))
+	fundingTx.AddTxOut(wire.NewTxOut(40000, uncompressedPkScript))
+	fundingTx.AddTxOut(wire.NewTxOut(50000, pkScripts[signing.ScriptTypeP2PKH]))
+	// The P2WPKH coin was already spent.
+	spendingTx := wire.NewMsgTx(wire.TxVersion)
+	spendingTx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: fundingTx.TxHash(), Index: 0}, nil, nil))
+	spendingTx.AddTxOut(wire.NewTxOut(9000, []byte{txscript.OP_TRUE}))
+
+	blockchainMock := &blockchainMocks.Interface{}
+	history := func(txs ...*wire.MsgTx) blockchainpkg.TxHistory {
+		result := blockchainpkg.TxHistory{}
+		for _, tx := range txs {
+			result = append(result, &blockchainpkg.TxInfo{Height: 10, TXHash: blockchainpkg.TXHash(tx.TxHash())})
+		}
+		return result
+	}
+	for scriptType, pkScript := range pkScripts {
+		txs := []*wire.MsgTx{fundingTx}
+		if scriptType == signing.ScriptTypeP2WPKH {
+			txs = append(txs, spendingTx)
+		}
+		blockchainMock.On("ScriptHashGetHistory", blockchainpkg.NewScriptHashHex(pkScript)).
+			Return(history(txs...), nil)
+	}
+	blockchainMock.On("ScriptHashGetHistory", blockchainpkg.NewScriptHashHex(uncompressedPkScript)).
+		Return(history(fundingTx), nil)
+	blockchainMock.On("TransactionGet", fundingTx.TxHash()).Return(fundingTx, nil)
+	blockchainMock.On("TransactionGet", spendingTx.TxHash()).Return(spendingTx, nil)
+
+	coins, err := FindCoins(blockchainMock, []*Key{compressedKey, uncompressedKey}, net)
+	require.NoError(t, err)
+	require.Len(t, coins, 4)
+	expectedScriptTypes := map[uint32]signing.ScriptType{
+		1: signing.ScriptTypeP2TR,
+		2: signing.ScriptTypeP2WPKHP2SH,
+		3: signing.ScriptTypeP2PKH,
+		4: signing.ScriptTypeP2PKH,
+	}
+	for index, scriptType := range expectedScriptTypes {
+		coin := coins[wire.OutPoint{Hash: fundingTx.TxHash(), Index: index}]
+		require.NotNil(t, coin)
+		require.Equal(t, scriptType, coin.ScriptType)
+		require.Equal(t, index == 3, coin.Uncompressed)
+	}
+	require.Len(t, SweepInputs(coins), 4)
+
+	tx := wire.NewMsgTx(wire.TxVersion)
+	prevOuts := txscript.NewMultiPrevOutFetcher(nil)
+	for outPoint, coin := range coins {
+		tx.AddTxIn(wire.NewTxIn(&outPoint, nil, nil))
+		prevOuts.AddPrevOut(outPoint, coin.TxOut)
+	}
+	tx.AddTxOut(wire.NewTxOut(140000-1000, []byte{txscript.OP_TRUE}))
+	require.NoError(t, Sign(tx, coins, net))
+
+	sigHashes := txscript.NewTxSigHashes(tx, prevOuts)
+	for index, txIn := range tx.TxIn {
+		txOut := coins[txIn.PreviousOutPoint].TxOut
+		engine, err := txscript.NewEngine(
+			txOut.PkScript, tx, index, txscript.StandardVerifyFlags, nil, sigHashes, txOut.Value, prevOuts)
+		require.NoError(t, err)
+		require.NoError(t, engine.Execute())
+	}
+
+	// Signing fails for inputs which do not spend one of the coins.
+	tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: chainhash.HashH([]byte("other"))}, nil, nil))
+	require.Error(t, Sign(tx, coins, net))
+}
@@ -0,0 +1,103 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package btc
+
+import (
+	"testing"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/errors"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/blockchain"
+	blockchainMocks "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/blockchain/mocks"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/sweep"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSweep(t *testing.T) {
+	account := testAccount(t, nil)
+	net := account.coin.Net()
+	privateKey, _ := btcec.PrivKeyFromBytes(chainhash.HashB([]byte("paper wallet")))
+	wif, err := btcutil.NewWIF(privateKey, net, true)
+	require.NoError(t, err)
+	address, err := btcutil.NewAddressWitnessPubKeyHash(btcutil.Hash160(wif.SerializePubKey()), net)
+	require.NoError(t, err)
+	pkScript, err := txscript.PayToAddrScript(address)
+	require.NoError(t, err)
+
+	fundingTx := wire.NewMsgTx(wire.TxVersion)
+	fundingTx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: chainhash.HashH([]byte("funding"))}, nil, nil))
+	fundingTx.AddTxOut(wire.NewTxOut(100000, pkScript))
+
+	var broadcasted *wire.MsgTx
+	blockchainMock := account.coin.blockchain.(*blockchainMocks.BlockchainMock)
+	blockchainMock.MockScriptHashGetHistory = func(scriptHashHex blockchain.ScriptHashHex) (blockchain.TxHistory, error) {
+		if scriptHashHex != blockchain.NewScriptHashHex(pkScript) {
+			return blockchain.TxHistory{}, nil
+		}
+		return blockchain.TxHistory{{Height: 10, TXHash: blockchain.TXHash(fundingTx.TxHash())}}, nil
+	}
+	blockchainMock.MockTransactionGet = func(chainhash.Hash) (*wire.MsgTx, error) {
+		return fundingTx, nil
+	}
+	blockchainMock.MockTransactionBroadcast = func(tx *wire.MsgTx) error {
+		broadcasted = tx
+		return nil
+	}
+	args := &accounts.TxProposalArgs{
+		FeeTargetCode: accounts.FeeTargetCodeCustom,
+		CustomFee:     "10",
+	}
+
+	_, err = account.SweepProposal([]string{"invalid"}, "", "", args)
+	require.Equal(t, sweep.ErrInvalidKey, errp.Cause(err))
+
+	result, err := account.SweepProposal([]string{wif.String()}, "", "", args)
+	require.NoError(t, err)
+	// One P2WPKH input and one P2WPKH output at 10 sat/vB.
+	require.Equal(t, int64(1100), result.Fee.BigInt().Int64())
+	require.Equal(t, int64(100000-1100), result.Amount.BigInt().Int64())
+	require.Equal(t, int64(100000), result.Total.BigInt().Int64())
+	require.Equal(t, 1, result.CoinCount)
+	receiveAddresses, err := account.subaccounts[0].receiveAddresses.GetUnused()
+	require.NoError(t, err)
+	require.Equal(t, receiveAddresses[0].EncodeForHumans(), result.Address)
+	// Sweeping does not touch the regular tx proposal.
+	require.Nil(t, account.activeTxProposal)
+
+	txID, err := account.SendSweep("paper wallet")
+	require.NoError(t, err)
+	require.NotNil(t, broadcasted)
+	require.Equal(t, broadcasted.TxHash().String(), txID)
+	require.Equal(t, "paper wallet", account.TxNote(txID))
+	require.Len(t, broadcasted.TxIn, 1)
+	require.Equal(t, wire.OutPoint{Hash: fundingTx.TxHash(), Index: 0}, broadcasted.TxIn[0].PreviousOutPoint)
+	require.Len(t, broadcasted.TxIn[0].Witness, 2)
+	require.Equal(t, receiveAddresses[0].PubkeyScript(), broadcasted.TxOut[0].PkScript)
+
+	// The signed transaction can only be sent once.
+	_, err = account.SendSweep("")
+	require.EqualError(t, err, "No active sweep proposal")
+
+	// Nothing to sweep.
+	fundingTx.TxOut[0].PkScript = []byte{txscript.OP_TRUE}
+	_, err = account.SweepProposal([]string{wif.String()}, "", "", args)
+	require.Equal(t, errors.ErrInsufficientFunds, errp.Cause(err))
+}
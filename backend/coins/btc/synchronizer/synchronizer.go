@@ -64,3 +64,14 @@ func (synchronizer *Synchronizer) decRequestsCounter() {
 		panic("request counter cannot be negative")
 	}
 }
+
+// WaitSynchronized blocks until the counter drops to zero, i.e. until all outstanding tasks have
+// finished. It returns immediately if there are none.
+func (synchronizer *Synchronizer) WaitSynchronized() {
+	unlock := synchronizer.waitLock.RLock()
+	wait := synchronizer.wait
+	unlock()
+	if wait != nil {
+		<-wait
+	}
+}
@@ -79,76 +79,151 @@ func (account *Account) getFeePerKb(args *accounts.TxProposalArgs) (btcutil.Amou
 // If the account is a unified account with multiple subaccounts (script/address types), we choose
 // the change address type like this:
 //
-// - If there is at least one P2TR UTXO, the change address will be a P2TR change address.
-// - Otherwise we pick P2WPKH if available.
-// - Otherwise we take the change of the first subaccount as a fallback.
+//   - If all recipients have the same script type and there is a subaccount with this script type,
+//     the change address has this script type too, so the change output can't be told apart from
+//     the payment by its script type. P2TR is only matched if there is a P2TR UTXO (see below).
+//   - If there is at least one P2TR UTXO, the change address will be a P2TR change address.
+//   - Otherwise we pick P2WPKH if available.
+//   - Otherwise we take the change of the first subaccount as a fallback.
 //
 // The above is a solution for the current lack of adoption of Taproot in third party (watch-only)
 // wallets.  Only users who already opted-in to Taproot (by receiving on a Taproot address) continue
 // with Taproot changes. This ensures that also users who received on Taproot and broke their
 // watch-only tools can fix it by moving the coins back to P2WPKH, and not have them go a Taproot
 // change again by accident.
-func (account *Account) pickChangeAddress(utxos map[wire.OutPoint]maketx.UTXO) (*addresses.AccountAddress, error) {
+func (account *Account) pickChangeAddress(
+	utxos map[wire.OutPoint]maketx.UTXO,
+	recipients []*maketx.OutputInfo,
+) (*addresses.AccountAddress, error) {
 	if len(account.subaccounts) == 0 {
 		return nil, errp.New("Account has no subaccounts")
 	}
 	if len(account.subaccounts) == 1 {
-		unusedAddresses, err := account.subaccounts[0].changeAddresses.GetUnused()
-		if err != nil {
-			return nil, err
+		return account.subaccounts[0].unusedChangeAddress()
+	}
+
+	hasP2TRUTXO := false
+	for _, utxo := range utxos {
+		if utxo.Address.AccountConfiguration.ScriptType() == signing.ScriptTypeP2TR {
+			hasP2TRUTXO = true
+			break
 		}
-		return unusedAddresses[0], nil
 	}
+	signingConfigurations := account.subaccounts.signingConfigurations()
 
-	p2trIndex := account.subaccounts.signingConfigurations().FindScriptType(signing.ScriptTypeP2TR)
-	if p2trIndex >= 0 {
-		// Check if there is at least one taproot UTXO.
-		for _, utxo := range utxos {
-			if utxo.Address.AccountConfiguration.ScriptType() == signing.ScriptTypeP2TR {
-				// Found a taproot UTXO.
-				unusedAddresses, err := account.subaccounts[p2trIndex].changeAddresses.GetUnused()
-				if err != nil {
-					return nil, err
-				}
-				return unusedAddresses[0], nil
-			}
+	if scriptType, ok := recipientsScriptType(recipients); ok &&
+		(scriptType != signing.ScriptTypeP2TR || hasP2TRUTXO) {
+		if index := signingConfigurations.FindScriptType(scriptType); index >= 0 {
+			return account.subaccounts[index].unusedChangeAddress()
 		}
 	}
 
-	p2wpkhIndex := account.subaccounts.signingConfigurations().FindScriptType(signing.ScriptTypeP2WPKH)
-	if p2wpkhIndex >= 0 {
-		unusedAddresses, err := account.subaccounts[p2wpkhIndex].changeAddresses.GetUnused()
-		if err != nil {
-			return nil, err
+	if p2trIndex := signingConfigurations.FindScriptType(signing.ScriptTypeP2TR); p2trIndex >= 0 && hasP2TRUTXO {
+		return account.subaccounts[p2trIndex].unusedChangeAddress()
+	}
+
+	if p2wpkhIndex := signingConfigurations.FindScriptType(signing.ScriptTypeP2WPKH); p2wpkhIndex >= 0 {
+		return account.subaccounts[p2wpkhIndex].unusedChangeAddress()
+	}
+
+	return account.subaccounts[0].unusedChangeAddress()
+}
+
+// recipientsScriptType returns the script type shared by all recipients. Returns false if there are
+// no recipients, or if they have different or unknown script types.
+func recipientsScriptType(recipients []*maketx.OutputInfo) (signing.ScriptType, bool) {
+	var result signing.ScriptType
+	for i, recipient := range recipients {
+		scriptType, ok := recipient.ScriptType()
+		if !ok || (i > 0 && scriptType != result) {
+			return "", false
 		}
-		return unusedAddresses[0], nil
+		result = scriptType
 	}
+	return result, len(recipients) > 0
+}
 
-	unusedAddresses, err := account.subaccounts[0].changeAddresses.GetUnused()
+// tipHeight returns the height of the current tip, used for the locktime of new transactions. It is
+// 0 if the tip is not known yet.
+func (account *Account) tipHeight() int {
+	return account.coin.Headers().TipHeight()
+}
+
+// outputInfo returns the output info for sending to the given address.
+func (account *Account) outputInfo(address string) (*maketx.OutputInfo, error) {
+	if err := account.coin.ValidateSilentPaymentAddress(address); err == nil {
+		return maketx.NewOutputInfoSilentPayment(address), nil
+	}
+	pkScript, err := account.coin.AddressToPkScript(address)
 	if err != nil {
 		return nil, err
 	}
-	return unusedAddresses[0], nil
+	return maketx.NewOutputInfo(pkScript), nil
+}
+
+// parseAmount parses an amount to send in the unit of the account.
+func (account *Account) parseAmount(amount coin.SendAmount) (btcutil.Amount, error) {
+	allowZero := false
+
+	unit := int64(unitSatoshi)
+	if account.coin.formatUnit == coin.BtcUnitSats {
+		unit = 1
+	}
+	parsedAmount, err := amount.Amount(big.NewInt(unit), allowZero)
+	if err != nil {
+		return 0, err
+	}
+	parsedAmountInt64, err := parsedAmount.Int64()
+	if err != nil {
+		return 0, errp.WithStack(errors.ErrInvalidAmount)
+	}
+	return btcutil.Amount(parsedAmountInt64), nil
 }
 
-// newTx creates a new tx to the given recipient address. It also returns a set of used account
-// outputs, which contains all outputs that spent in the tx. Those are needed to be able to sign the
-// transaction. selectedUTXOs restricts the available coins; if empty, no restriction is applied and
-// all unspent coins can be used.
+// newTx creates a new tx to the given recipients. It also returns a set of used account outputs,
+// which contains all outputs that spent in the tx. Those are needed to be able to sign the
+// transaction. selectedUTXOs restricts the available coins; if empty, all unspent coins which are
+// not frozen and not spent by a scheduled transaction can be used.
 func (account *Account) newTx(args *accounts.TxProposalArgs) (
 	map[wire.OutPoint]*transactions.SpendableOutput, *maketx.TxProposal, error) {
 
 	account.log.Debug("Prepare new transaction")
 
-	var outputInfo *maketx.OutputInfo
-	if err := account.coin.ValidateSilentPaymentAddress(args.RecipientAddress); err == nil {
-		outputInfo = maketx.NewOutputInfoSilentPayment(args.RecipientAddress)
-	} else {
-		pkScript, err := account.coin.AddressToPkScript(args.RecipientAddress)
+	recipients := args.Recipients()
+	if len(recipients) > 1 && args.PaymentRequest != nil {
+		return nil, nil, errp.New("Payment Requests do not allow multiple recipients")
+	}
+	if args.Payjoin != "" {
+		if len(recipients) > 1 || args.PaymentRequest != nil {
+			return nil, nil, errp.New("Payjoin only allows a single recipient")
+		}
+		if err := validatePayjoinEndpoint(args.Payjoin); err != nil {
+			return nil, nil, err
+		}
+	}
+	// The recipient receiving the remaining funds in a send-all transaction, if any.
+	var sendAllOutputInfo *maketx.OutputInfo
+	fixedRecipients := []*maketx.Recipient{}
+	for _, recipient := range recipients {
+		outputInfo, err := account.outputInfo(recipient.Address)
+		if err != nil {
+			return nil, nil, err
+		}
+		if recipient.Amount.SendAll() {
+			if sendAllOutputInfo != nil {
+				return nil, nil, errp.New("Only one recipient can receive the remaining funds")
+			}
+			sendAllOutputInfo = outputInfo
+			continue
+		}
+		amount, err := account.parseAmount(recipient.Amount)
 		if err != nil {
 			return nil, nil, err
 		}
-		outputInfo = maketx.NewOutputInfo(pkScript)
+		fixedRecipients = append(fixedRecipients, &maketx.Recipient{
+			OutputInfo: outputInfo,
+			Amount:     amount,
+		})
 	}
 
 	if !account.Synced() {
@@ -158,13 +233,20 @@ func (account *Account) newTx(args *accounts.TxProposalArgs) (
 	if err != nil {
 		return nil, nil, err
 	}
+	scheduledInputs, err := transactions.DBView(account.db, scheduledTxInputs)
+	if err != nil {
+		return nil, nil, err
+	}
 	wireUTXO := make(map[wire.OutPoint]maketx.UTXO, len(utxo))
 	for outPoint, txOut := range utxo {
-		// Apply coin control.
+		// Apply coin control. Frozen coins and coins spent by a scheduled transaction are only
+		// spent if they are selected explicitly.
 		if len(args.SelectedUTXOs) != 0 {
 			if _, ok := args.SelectedUTXOs[outPoint]; !ok {
 				continue
 			}
+		} else if _, scheduled := scheduledInputs[outPoint]; scheduled || account.OutputFrozen(outPoint) {
+			continue
 		}
 		wireUTXO[outPoint] = maketx.UTXO{
 			TxOut: txOut.TxOut,
@@ -178,36 +260,30 @@ func (account *Account) newTx(args *accounts.TxProposalArgs) (
 	}
 
 	var txProposal *maketx.TxProposal
-	if args.Amount.SendAll() {
+	if sendAllOutputInfo != nil {
 		if args.PaymentRequest != nil {
 			return nil, nil, errp.New("Payment Requests do not allow send-all transaction proposals")
 		}
 		txProposal, err = maketx.NewTxSpendAll(
 			account.coin,
 			wireUTXO,
-			outputInfo,
+			sendAllOutputInfo,
+			fixedRecipients,
 			feeRatePerKb,
+			args.LockTime,
+			account.tipHeight(),
+			maketx.SecureSeed(),
 			account.log,
 		)
 		if err != nil {
 			return nil, nil, err
 		}
 	} else {
-		allowZero := false
-
-		unit := int64(unitSatoshi)
-		if account.coin.formatUnit == coin.BtcUnitSats {
-			unit = 1
-		}
-		parsedAmount, err := args.Amount.Amount(big.NewInt(unit), allowZero)
-		if err != nil {
-			return nil, nil, err
-		}
-		parsedAmountInt64, err := parsedAmount.Int64()
-		if err != nil {
-			return nil, nil, errp.WithStack(errors.ErrInvalidAmount)
+		recipientOutputInfos := make([]*maketx.OutputInfo, len(fixedRecipients))
+		for i, recipient := range fixedRecipients {
+			recipientOutputInfos[i] = recipient.OutputInfo
 		}
-		changeAddress, err := account.pickChangeAddress(wireUTXO)
+		changeAddress, err := account.pickChangeAddress(wireUTXO, recipientOutputInfos)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -215,10 +291,13 @@ func (account *Account) newTx(args *accounts.TxProposalArgs) (
 		txProposal, err = maketx.NewTx(
 			account.coin,
 			wireUTXO,
-			outputInfo,
-			parsedAmountInt64,
+			fixedRecipients,
 			feeRatePerKb,
+			maketx.DefaultLongTermFeePerKb,
 			changeAddress,
+			args.LockTime,
+			account.tipHeight(),
+			maketx.SecureSeed(),
 			account.log,
 		)
 		if err != nil {
@@ -230,6 +309,7 @@ func (account *Account) newTx(args *accounts.TxProposalArgs) (
 			txProposal.PaymentRequest = args.PaymentRequest
 		}
 	}
+	txProposal.PayjoinEndpoint = args.Payjoin
 	account.log.Debugf("creating tx with %d inputs, %d outputs",
 		len(txProposal.Transaction.TxIn), len(txProposal.Transaction.TxOut))
 	return utxo, txProposal, nil
@@ -246,33 +326,39 @@ func (account *Account) GetAddress(scriptHashHex blockchain.ScriptHashHex) *addr
 			return address
 		}
 	}
+	if account.silentPayments != nil {
+		return account.silentPaymentAddress(scriptHashHex)
+	}
 	return nil
 }
 
-// SendTx implements accounts.Interface.
+// SendTx implements accounts.Interface. If the locktime of the transaction has not passed yet, the
+// signed transaction is scheduled to be broadcast later, see broadcastOrSchedule().
 func (account *Account) SendTx(txNote string) error {
+	_, err := account.SendTxPayjoin(txNote)
+	return err
+}
+
+// SendTxPayjoin is like SendTx(), and also returns whether the payment was made as a payjoin. This
+// is false if the tx proposal has no payjoin endpoint, or if the original transaction was sent
+// instead, see sendPayjoin().
+func (account *Account) SendTxPayjoin(txNote string) (bool, error) {
 	unlock := account.activeTxProposalLock.RLock()
 	txProposal := account.activeTxProposal
 	unlock()
 	if txProposal == nil {
-		return errp.New("No active tx proposal")
+		return false, errp.New("No active tx proposal")
 	}
 
 	account.log.Info("Signing and sending transaction")
 	if err := account.signTransaction(txProposal, account.coin.Blockchain().TransactionGet); err != nil {
-		return errp.WithMessage(err, "Failed to sign transaction")
+		return false, errp.WithMessage(err, "Failed to sign transaction")
 	}
 
-	account.log.Info("Signed transaction is broadcasted")
-	if err := account.coin.Blockchain().TransactionBroadcast(txProposal.Transaction); err != nil {
-		return err
+	if txProposal.PayjoinEndpoint != "" {
+		return account.sendPayjoin(txProposal, txNote)
 	}
-
-	if err := account.SetTxNote(txProposal.Transaction.TxHash().String(), txNote); err != nil {
-		// Not critical.
-		account.log.WithError(err).Error("Failed to save transaction note when sending a tx")
-	}
-	return nil
+	return false, account.broadcastOrSchedule(txProposal.Transaction, txNote)
 }
 
 // TxProposal creates a tx from the relevant input and returns information about it for display in
@@ -34,6 +34,7 @@ import (
 	"github.com/BitBoxSwiss/bitbox-wallet-app/util/test"
 	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/stretchr/testify/require"
 )
@@ -55,6 +56,9 @@ func testAccount(t *testing.T, config *config.Account) *Account {
 			MockRelayFee: func() (btcutil.Amount, error) {
 				return btcutil.Amount(1001), nil
 			},
+			MockMempoolFeeHistogram: func() (blockchain.FeeHistogram, error) {
+				return nil, errp.New("unsupported")
+			},
 			MockEstimateFee: func(number int) (btcutil.Amount, error) {
 				switch number {
 				case 2:
@@ -185,10 +189,26 @@ func TestPickChangeAddressSucceeds(t *testing.T) {
 			test.TstMustXKey("xpub6CUmEcJb7juvpvNs2hKMc9BP1n82ixzUb4jyHUdYzSLmnXru3nb4hhGsfS23WRx8hgJLxMxZ7WcBGzTiYfiANUQZe3TVFghLrxvA2Ls7u4a")),
 	}
 
+	p2wpkhConfiguration := signing.NewBitcoinConfiguration(
+		signing.ScriptTypeP2WPKH,
+		rootFingerprint,
+		mustKeypath(t, "m/84'/0'/1'"),
+		test.TstMustXKey("xpub6Cxa67Bfe1Aw7YVtdqKPYLhSkf7omb7WkGXQzof15VXbAZKVct1caHHK55UQN2Fnojbp2okiBCbGXyQSRzMQ6XKJJeeM2jAt6FR8K8ckA88"))
+	p2trConfiguration := signing.NewBitcoinConfiguration(
+		signing.ScriptTypeP2TR,
+		rootFingerprint,
+		mustKeypath(t, "m/86'/0'/1'"),
+		test.TstMustXKey("xpub6CC9Tsi4eJvmSBj5xoU4sKnFGF9nF8qwExB3axxu2F7oWKFH5RucWQUfrgVGfnTDr6p5acBGpAqAMKb2A7ek8SbAUvDEXtvj37pM1S9X2km"))
+	p2shRecipient := maketx.NewOutputInfo(
+		append(append([]byte{txscript.OP_HASH160, txscript.OP_DATA_20}, make([]byte, 20)...), txscript.OP_EQUAL))
+	p2trRecipient := maketx.NewOutputInfo(
+		append([]byte{txscript.OP_1, txscript.OP_DATA_32}, make([]byte, 32)...))
+
 	testCases := []struct {
 		name                  string
 		signingConfigurations signing.Configurations
 		utxos                 map[wire.OutPoint]maketx.UTXO
+		recipients            []*maketx.OutputInfo
 		wantAddress           string
 	}{
 		{
@@ -253,6 +273,44 @@ func TestPickChangeAddressSucceeds(t *testing.T) {
 			},
 			wantAddress: "tb1p00h4lrrxueq94y62e3668sp42hxs7w0kulp54uq72050urnplf9s54w5x5",
 		},
+		{
+			name:                  "matches the recipient script type",
+			signingConfigurations: append(baseSigningConfigurations, p2wpkhConfiguration),
+			recipients:            []*maketx.OutputInfo{p2shRecipient, p2shRecipient},
+			wantAddress:           "2MsjfL7qJTBDnuhUiDJEUR9iWhtcyEnzQYU",
+		},
+		{
+			name:                  "recipients with different script types",
+			signingConfigurations: append(baseSigningConfigurations, p2wpkhConfiguration),
+			recipients:            []*maketx.OutputInfo{p2shRecipient, p2trRecipient},
+			wantAddress:           "tb1q42x65gqluatm6x6vvwlpg93wqcvz8jn3arakke",
+		},
+		{
+			name:                  "p2tr recipient without p2tr UTXO",
+			signingConfigurations: append(baseSigningConfigurations, p2trConfiguration, p2wpkhConfiguration),
+			recipients:            []*maketx.OutputInfo{p2trRecipient},
+			wantAddress:           "tb1q42x65gqluatm6x6vvwlpg93wqcvz8jn3arakke",
+		},
+		{
+			name:                  "p2tr recipient with p2tr UTXO",
+			signingConfigurations: append(baseSigningConfigurations, p2trConfiguration, p2wpkhConfiguration),
+			utxos: map[wire.OutPoint]maketx.UTXO{
+				*wire.NewOutPoint(&chainhash.Hash{}, 1): utxo(signing.ScriptTypeP2TR),
+			},
+			recipients:  []*maketx.OutputInfo{p2trRecipient},
+			wantAddress: "tb1p00h4lrrxueq94y62e3668sp42hxs7w0kulp54uq72050urnplf9s54w5x5",
+		},
+		{
+			name:                  "p2wpkh recipient with p2tr UTXO",
+			signingConfigurations: append(baseSigningConfigurations, p2trConfiguration, p2wpkhConfiguration),
+			utxos: map[wire.OutPoint]maketx.UTXO{
+				*wire.NewOutPoint(&chainhash.Hash{}, 1): utxo(signing.ScriptTypeP2TR),
+			},
+			recipients: []*maketx.OutputInfo{
+				maketx.NewOutputInfo(append([]byte{txscript.OP_0, txscript.OP_DATA_20}, make([]byte, 20)...)),
+			},
+			wantAddress: "tb1q42x65gqluatm6x6vvwlpg93wqcvz8jn3arakke",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -265,7 +323,7 @@ func TestPickChangeAddressSucceeds(t *testing.T) {
 			require.NoError(t, account.Initialize())
 			require.Eventually(t, account.Synced, time.Second, time.Millisecond*200)
 
-			address, err := account.pickChangeAddress(tc.utxos)
+			address, err := account.pickChangeAddress(tc.utxos, tc.recipients)
 			require.NoError(t, err)
 			require.Equal(t, tc.wantAddress, address.EncodeForHumans())
 		})
@@ -291,7 +349,7 @@ func TestPickChangeAddressFails(t *testing.T) {
 	require.Eventually(t, account.Synced, time.Second, time.Millisecond*200)
 
 	account.subaccounts = subaccounts{}
-	_, err := account.pickChangeAddress(nil)
+	_, err := account.pickChangeAddress(nil, nil)
 	require.ErrorContains(t, err, "Account has no subaccounts")
 }
 
@@ -372,6 +430,49 @@ func TestTxProposal(t *testing.T) {
 			wantFee:    coin.NewAmountFromInt64(1440),
 			wantTotal:  coin.NewAmountFromInt64(11440),
 		},
+		{
+			name: "Multiple recipients - success",
+			args: &accounts.TxProposalArgs{
+				RecipientAddress: "myY3Bbvj5mjwqqvubtu5Hfy2nuCeBfvNXL",
+				Amount:           coin.NewSendAmount("1"),
+				AdditionalRecipients: []accounts.TxRecipient{
+					{Address: "mfWxJ45yp2SFn7UciZyNpvDKrzbhyfKrY8", Amount: coin.NewSendAmount("0.5")},
+				},
+				FeeTargetCode: accounts.FeeTargetCodeCustom,
+				CustomFee:     "100",
+			},
+			wantAmount: coin.NewAmountFromInt64(150000000),
+			wantFee:    coin.NewAmountFromInt64(17800),
+			wantTotal:  coin.NewAmountFromInt64(150017800),
+		},
+		{
+			name: "Multiple recipients with send-all - success",
+			args: &accounts.TxProposalArgs{
+				RecipientAddress: "myY3Bbvj5mjwqqvubtu5Hfy2nuCeBfvNXL",
+				Amount:           coin.NewSendAmount("0.5"),
+				AdditionalRecipients: []accounts.TxRecipient{
+					{Address: "mfWxJ45yp2SFn7UciZyNpvDKrzbhyfKrY8", Amount: coin.NewSendAmountAll()},
+				},
+				FeeTargetCode: accounts.FeeTargetCodeCustom,
+				CustomFee:     "100",
+			},
+			wantAmount: coin.NewAmountFromInt64(1000978500),
+			wantFee:    coin.NewAmountFromInt64(21500),
+			wantTotal:  coin.NewAmountFromInt64(1001000000),
+		},
+		{
+			name: "Failure - Multiple recipients with send-all",
+			args: &accounts.TxProposalArgs{
+				RecipientAddress: "myY3Bbvj5mjwqqvubtu5Hfy2nuCeBfvNXL",
+				Amount:           coin.NewSendAmountAll(),
+				AdditionalRecipients: []accounts.TxRecipient{
+					{Address: "mfWxJ45yp2SFn7UciZyNpvDKrzbhyfKrY8", Amount: coin.NewSendAmountAll()},
+				},
+				FeeTargetCode: accounts.FeeTargetCodeCustom,
+				CustomFee:     "100",
+			},
+			wantErr: errp.New("Only one recipient can receive the remaining funds"),
+		},
 		{
 			name: "Failure - Invalid address",
 			args: &accounts.TxProposalArgs{
@@ -400,3 +501,61 @@ func TestTxProposal(t *testing.T) {
 		})
 	}
 }
+
+func TestTxProposalFrozenOutputs(t *testing.T) {
+	account := testAccount(t, nil)
+	frozenOutPoint := *wire.NewOutPoint(&chainhash.Hash{}, 0)
+	require.NoError(t, account.SetOutputFrozen(frozenOutPoint, true))
+
+	spendableOutputs, err := account.SpendableOutputs()
+	require.NoError(t, err)
+	require.Len(t, spendableOutputs, 2)
+	for _, output := range spendableOutputs {
+		require.Equal(t, output.OutPoint == frozenOutPoint, output.Frozen)
+	}
+
+	// Frozen coins are not used by send-all.
+	amount, fee, total, err := account.TxProposal(&accounts.TxProposalArgs{
+		RecipientAddress: "myY3Bbvj5mjwqqvubtu5Hfy2nuCeBfvNXL",
+		Amount:           coin.NewSendAmountAll(),
+		FeeTargetCode:    accounts.FeeTargetCodeCustom,
+		CustomFee:        "10",
+	})
+	require.NoError(t, err)
+	require.Equal(t, coin.NewAmountFromInt64(998870), amount)
+	require.Equal(t, coin.NewAmountFromInt64(1130), fee)
+	require.Equal(t, coin.NewAmountFromInt64(1000000), total)
+
+	// Frozen coins are not used by automatic coin selection.
+	_, _, _, err = account.TxProposal(&accounts.TxProposalArgs{
+		RecipientAddress: "myY3Bbvj5mjwqqvubtu5Hfy2nuCeBfvNXL",
+		Amount:           coin.NewSendAmount("1"),
+		FeeTargetCode:    accounts.FeeTargetCodeCustom,
+		CustomFee:        "10",
+	})
+	require.ErrorIs(t, err, errors.ErrInsufficientFunds)
+
+	// Frozen coins can be spent if they are selected explicitly.
+	amount, _, _, err = account.TxProposal(&accounts.TxProposalArgs{
+		RecipientAddress: "myY3Bbvj5mjwqqvubtu5Hfy2nuCeBfvNXL",
+		Amount:           coin.NewSendAmount("1"),
+		FeeTargetCode:    accounts.FeeTargetCodeCustom,
+		CustomFee:        "10",
+		SelectedUTXOs: map[wire.OutPoint]struct{}{
+			frozenOutPoint: {},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, coin.NewAmountFromInt64(100000000), amount)
+
+	// Unfrozen coins are used again.
+	require.NoError(t, account.SetOutputFrozen(frozenOutPoint, false))
+	require.False(t, account.OutputFrozen(frozenOutPoint))
+	_, _, _, err = account.TxProposal(&accounts.TxProposalArgs{
+		RecipientAddress: "myY3Bbvj5mjwqqvubtu5Hfy2nuCeBfvNXL",
+		Amount:           coin.NewSendAmount("1"),
+		FeeTargetCode:    accounts.FeeTargetCodeCustom,
+		CustomFee:        "10",
+	})
+	require.NoError(t, err)
+}
@@ -39,6 +39,23 @@ type DBTxInfo struct {
 	TxHash chainhash.Hash `json:"-"`
 }
 
+// ScheduledTx is a signed transaction which can't be broadcast yet because of its locktime. It is
+// broadcast once the locktime has passed.
+type ScheduledTx struct {
+	Tx               *wire.MsgTx `json:"tx"`
+	Note             string      `json:"note"`
+	CreatedTimestamp time.Time   `json:"created"`
+}
+
+// SilentPaymentOutput is an output received through a silent payment (BIP-352), found by scanning
+// the blockchain.
+type SilentPaymentOutput struct {
+	// Tweak is added to the spend private key to spend the output.
+	Tweak []byte `json:"tweak"`
+	// Height is the height of the block containing the output.
+	Height int `json:"height"`
+}
+
 // DBTxInterface needs to be implemented to persist all wallet/transaction related data.
 type DBTxInterface interface {
 	// Commit closes the transaction, writing the changes.
@@ -105,6 +122,54 @@ type DBTxInterface interface {
 	// GapLimits returns the gap limit for receive and change addresses.
 	// If none have been stored before, the default zero value is returned.
 	GapLimits() (types.GapLimits, error)
+
+	// PutReplacement records that the transaction `replacement` replaces the unconfirmed
+	// transaction `replaced` by paying a higher fee (RBF).
+	PutReplacement(replacement chainhash.Hash, replaced chainhash.Hash) error
+
+	// Replacements returns all recorded replacements, mapping the hash of the replacement
+	// transaction to the hash of the replaced transaction.
+	Replacements() (map[chainhash.Hash]chainhash.Hash, error)
+
+	// PutCancellation records that the transaction `replacement` cancels the unconfirmed
+	// transaction `cancelled` by spending its inputs back to the wallet. The cancelled transaction
+	// is stored, as it is removed from the transactions once the replacement confirms.
+	PutCancellation(replacement chainhash.Hash, cancelled *DBTxInfo) error
+
+	// Cancellations returns all recorded cancellations, mapping the hash of the replacement
+	// transaction to the cancelled transaction.
+	Cancellations() (map[chainhash.Hash]*DBTxInfo, error)
+
+	// PutScheduledTx adds a signed transaction to the queue of transactions waiting for their
+	// locktime to pass.
+	PutScheduledTx(txHash chainhash.Hash, scheduledTx *ScheduledTx) error
+
+	// ScheduledTxs returns all transactions in the queue of transactions waiting for their locktime
+	// to pass.
+	ScheduledTxs() (map[chainhash.Hash]*ScheduledTx, error)
+
+	// DeleteScheduledTx removes a transaction from the queue of transactions waiting for their
+	// locktime to pass (nothing happens if not found).
+	DeleteScheduledTx(txHash chainhash.Hash) error
+
+	// PutSilentPaymentOutput stores an output received through a silent payment.
+	PutSilentPaymentOutput(outPoint wire.OutPoint, output *SilentPaymentOutput) error
+
+	// SilentPaymentOutputs returns all outputs received through silent payments.
+	SilentPaymentOutputs() (map[wire.OutPoint]*SilentPaymentOutput, error)
+
+	// PutSilentPaymentsScanHeight stores the height of the last block scanned for silent payments.
+	PutSilentPaymentsScanHeight(height int) error
+
+	// SilentPaymentsScanHeight returns the height of the last block scanned for silent payments, or
+	// 0 if no block was scanned yet.
+	SilentPaymentsScanHeight() (int, error)
+
+	// PutDustOutput records that an output was flagged as dust, so the user is only notified once.
+	PutDustOutput(outPoint wire.OutPoint) error
+
+	// DustOutputs returns all outputs which were flagged as dust.
+	DustOutputs() (map[wire.OutPoint]struct{}, error)
 }
 
 // DBInterface can be implemented by database backends to open database transactions.
@@ -27,6 +27,9 @@ var _ transactions.Interface = &InterfaceMock{}
 //			CloseFunc: func()  {
 //				panic("mock out the Close method")
 //			},
+//			ReceivedOutputsFunc: func() (map[blockchain.ScriptHashHex][]*transactions.ReceivedOutput, error) {
+//				panic("mock out the ReceivedOutputs method")
+//			},
 //			SpendableOutputsFunc: func() (map[wire.OutPoint]*transactions.SpendableOutput, error) {
 //				panic("mock out the SpendableOutputs method")
 //			},
@@ -49,6 +52,9 @@ type InterfaceMock struct {
 	// CloseFunc mocks the Close method.
 	CloseFunc func()
 
+	// ReceivedOutputsFunc mocks the ReceivedOutputs method.
+	ReceivedOutputsFunc func() (map[blockchain.ScriptHashHex][]*transactions.ReceivedOutput, error)
+
 	// SpendableOutputsFunc mocks the SpendableOutputs method.
 	SpendableOutputsFunc func() (map[wire.OutPoint]*transactions.SpendableOutput, error)
 
@@ -66,6 +72,9 @@ type InterfaceMock struct {
 		// Close holds details about calls to the Close method.
 		Close []struct {
 		}
+		// ReceivedOutputs holds details about calls to the ReceivedOutputs method.
+		ReceivedOutputs []struct {
+		}
 		// SpendableOutputs holds details about calls to the SpendableOutputs method.
 		SpendableOutputs []struct {
 		}
@@ -84,6 +93,7 @@ type InterfaceMock struct {
 	}
 	lockBalance              sync.RWMutex
 	lockClose                sync.RWMutex
+	lockReceivedOutputs      sync.RWMutex
 	lockSpendableOutputs     sync.RWMutex
 	lockTransactions         sync.RWMutex
 	lockUpdateAddressHistory sync.RWMutex
@@ -143,6 +153,33 @@ func (mock *InterfaceMock) CloseCalls() []struct {
 	return calls
 }
 
+// ReceivedOutputs calls ReceivedOutputsFunc.
+func (mock *InterfaceMock) ReceivedOutputs() (map[blockchain.ScriptHashHex][]*transactions.ReceivedOutput, error) {
+	if mock.ReceivedOutputsFunc == nil {
+		panic("InterfaceMock.ReceivedOutputsFunc: method is nil but Interface.ReceivedOutputs was just called")
+	}
+	callInfo := struct {
+	}{}
+	mock.lockReceivedOutputs.Lock()
+	mock.calls.ReceivedOutputs = append(mock.calls.ReceivedOutputs, callInfo)
+	mock.lockReceivedOutputs.Unlock()
+	return mock.ReceivedOutputsFunc()
+}
+
+// ReceivedOutputsCalls gets all the calls that were made to ReceivedOutputs.
+// Check the length with:
+//
+//	len(mockedInterface.ReceivedOutputsCalls())
+func (mock *InterfaceMock) ReceivedOutputsCalls() []struct {
+} {
+	var calls []struct {
+	}
+	mock.lockReceivedOutputs.RLock()
+	calls = mock.calls.ReceivedOutputs
+	mock.lockReceivedOutputs.RUnlock()
+	return calls
+}
+
 // SpendableOutputs calls SpendableOutputsFunc.
 func (mock *InterfaceMock) SpendableOutputs() (map[wire.OutPoint]*transactions.SpendableOutput, error) {
 	if mock.SpendableOutputsFunc == nil {
@@ -61,6 +61,10 @@ type Interface interface {
 	// ourselves.
 	SpendableOutputs() (map[wire.OutPoint]*SpendableOutput, error)
 
+	// ReceivedOutputs returns all outputs paying to the wallet, including spent ones, by the script
+	// hash of the address they pay to.
+	ReceivedOutputs() (map[blockchain.ScriptHashHex][]*ReceivedOutput, error)
+
 	// Transactions returns an ordered list of transactions.
 	Transactions(isChange func(blockchain.ScriptHashHex) bool) (accounts.OrderedTransactions, error)
 
@@ -244,6 +248,58 @@ func (transactions *Transactions) SpendableOutputs() (map[wire.OutPoint]*Spendab
 	})
 }
 
+// ReceivedOutput is an output paying to an address of the wallet.
+type ReceivedOutput struct {
+	OutPoint wire.OutPoint
+	TxOut    *wire.TxOut
+	// Height is the height of the transaction creating the output, 0 or -1 if it is unconfirmed.
+	Height int
+	// Unsolicited is true if none of the inputs of the transaction creating the output are ours,
+	// i.e. the output was sent to us by someone else.
+	Unsolicited bool
+	Spent       bool
+}
+
+// ReceivedOutputs returns all outputs paying to the wallet, including spent ones, by the script
+// hash of the address they pay to.
+func (transactions *Transactions) ReceivedOutputs() (map[blockchain.ScriptHashHex][]*ReceivedOutput, error) {
+	return DBView(transactions.db, func(dbTx DBTxInterface) (map[blockchain.ScriptHashHex][]*ReceivedOutput, error) {
+		outputs, err := dbTx.Outputs()
+		if err != nil {
+			return nil, err
+		}
+		result := map[blockchain.ScriptHashHex][]*ReceivedOutput{}
+		for outPoint, txOut := range outputs {
+			txInfo, err := dbTx.TxInfo(outPoint.Hash)
+			if err != nil {
+				return nil, err
+			}
+			scriptHashHex := getScriptHashHex(txOut)
+			result[scriptHashHex] = append(result[scriptHashHex], &ReceivedOutput{
+				OutPoint:    outPoint,
+				TxOut:       txOut,
+				Height:      txInfo.Height,
+				Unsolicited: !transactions.anyInputOurs(dbTx, txInfo.Tx),
+				Spent:       transactions.isInputSpent(dbTx, outPoint),
+			})
+		}
+		return result, nil
+	})
+}
+
+func (transactions *Transactions) anyInputOurs(dbTx DBTxInterface, transaction *wire.MsgTx) bool {
+	for _, txIn := range transaction.TxIn {
+		txOut, err := dbTx.Output(txIn.PreviousOutPoint)
+		if err != nil {
+			transactions.log.WithError(err).Panic("Failed to retrieve output")
+		}
+		if txOut != nil {
+			return true
+		}
+	}
+	return false
+}
+
 func (transactions *Transactions) isInputSpent(dbTx DBTxInterface, outPoint wire.OutPoint) bool {
 	input, err := dbTx.Input(outPoint)
 	if err != nil {
@@ -429,6 +485,11 @@ func (transactions *Transactions) txInfo(
 			// TODO
 			transactions.log.WithError(err).Panic("Output() failed")
 		}
+		if output == nil && isChange(blockchain.NewScriptHashHex(txOut.PkScript)) {
+			// The outputs of cancelled transactions are not indexed, but their change can still be
+			// identified.
+			output = txOut
+		}
 		addressAndAmount := accounts.AddressAndAmount{
 			Address: transactions.outputToAddress(txOut.PkScript),
 			Amount:  coin.NewAmountFromInt64(txOut.Value),
@@ -527,12 +588,67 @@ func (transactions *Transactions) Transactions(
 		if err != nil {
 			return nil, err
 		}
+		replacements, err := dbTx.Replacements()
+		if err != nil {
+			return nil, err
+		}
+		// Unconfirmed transactions which have been replaced are hidden as long as the replacement
+		// is known. Only one of them can confirm.
+		replacedTxs := map[chainhash.Hash]struct{}{}
+		for replacement, replaced := range replacements {
+			replacementInfo, err := dbTx.TxInfo(replacement)
+			if err != nil {
+				return nil, err
+			}
+			if replacementInfo != nil && replacementInfo.Tx != nil {
+				replacedTxs[replaced] = struct{}{}
+			}
+		}
 		for _, txHash := range txHashes {
 			txInfo, err := dbTx.TxInfo(txHash)
 			if err != nil {
 				return nil, err
 			}
-			txs = append(txs, transactions.txInfo(dbTx, txInfo, isChange))
+			if _, ok := replacedTxs[txHash]; ok && txInfo.Height <= 0 {
+				continue
+			}
+			txData := transactions.txInfo(dbTx, txInfo, isChange)
+			if replaced, ok := replacements[txHash]; ok {
+				txData.ReplacesTxID = replaced.String()
+			}
+			txs = append(txs, txData)
+		}
+		cancellations, err := dbTx.Cancellations()
+		if err != nil {
+			return nil, err
+		}
+		// Cancelled transactions are shown as failed once the cancelling transaction confirms.
+		for replacement, cancelled := range cancellations {
+			replacementInfo, err := dbTx.TxInfo(replacement)
+			if err != nil {
+				return nil, err
+			}
+			if replacementInfo == nil || replacementInfo.Tx == nil || replacementInfo.Height <= 0 {
+				continue
+			}
+			cancelledInfo, err := dbTx.TxInfo(cancelled.TxHash)
+			if err != nil {
+				return nil, err
+			}
+			if cancelledInfo != nil && cancelledInfo.Tx != nil && cancelledInfo.Height > 0 {
+				// Cannot happen, as only one of the two transactions can confirm.
+				continue
+			}
+			// The cancelled transaction is listed next to the transaction cancelling it.
+			cancelled.Height = replacementInfo.Height
+			cancelled.HeaderTimestamp = replacementInfo.HeaderTimestamp
+			txData := transactions.txInfo(dbTx, cancelled, isChange)
+			txData.Status = accounts.TxStatusFailed
+			// The fee was never paid.
+			txData.Fee = nil
+			txData.FeeRatePerKb = nil
+			txData.ReplacedByTxID = replacement.String()
+			txs = append(txs, txData)
 		}
 		return accounts.NewOrderedTransactions(txs), nil
 	})
@@ -16,6 +16,7 @@ package transactions_test
 
 import (
 	"os"
+	"sort"
 	"testing"
 
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
@@ -86,6 +87,7 @@ type transactionsSuite struct {
 	blockchainMock *BlockchainMock
 	headersMock    *headersMock.Interface
 	notifierMock   *accountsMock.Notifier
+	db             transactions.DBInterface
 	transactions   *transactions.Transactions
 
 	log *logrus.Entry
@@ -110,6 +112,7 @@ func (s *transactionsSuite) SetupTest() {
 	s.headersMock.On("SubscribeEvent", mock.AnythingOfType("func(headers.Event)")).Return(func() {})
 	s.headersMock.On("TipHeight").Return(15).Once()
 	s.notifierMock = &accountsMock.Notifier{}
+	s.db = db
 	s.transactions = transactions.NewTransactions(
 		s.net,
 		db,
@@ -257,6 +260,66 @@ func (s *transactionsSuite) TestSpendableOutputs() {
 	s.Require().Contains(spendableOutputs, wire.OutPoint{Hash: tx22Spend.TxHash(), Index: 0})
 }
 
+func (s *transactionsSuite) TestReceivedOutputs() {
+	receivedOutputs, err := s.transactions.ReceivedOutputs()
+	s.Require().NoError(err)
+	s.Require().Empty(receivedOutputs)
+	addresses, err := s.addressChain.EnsureAddresses()
+	s.Require().NoError(err)
+	address1 := addresses[0]
+	address2 := addresses[1]
+	otherAddress := addresses[2]
+	tx1 := newTx(chainhash.HashH(nil), 0, address1, 1000)
+	tx2 := newTx(chainhash.HashH(nil), 1, address1, 500)
+	s.blockchainMock.RegisterTxs(tx1, tx2)
+	s.headersMock.On("VerifiedHeaderByHeight", 10).Return(nil, nil).Once()
+	s.updateAddressHistory(address1, []*blockchainpkg.TxInfo{
+		{TXHash: blockchainpkg.TXHash(tx1.TxHash()), Height: 10},
+		{TXHash: blockchainpkg.TXHash(tx2.TxHash()), Height: 0},
+	})
+	// Spend the output of tx1 to address2 and an external address.
+	tx3 := newTx(tx1.TxHash(), 0, address2, 600)
+	tx3.AddTxOut(wire.NewTxOut(300, otherAddress.PubkeyScript()))
+	s.blockchainMock.RegisterTxs(tx3)
+	s.updateAddressHistory(address1, []*blockchainpkg.TxInfo{
+		{TXHash: blockchainpkg.TXHash(tx1.TxHash()), Height: 10},
+		{TXHash: blockchainpkg.TXHash(tx2.TxHash()), Height: 0},
+		{TXHash: blockchainpkg.TXHash(tx3.TxHash()), Height: 0},
+	})
+	s.updateAddressHistory(address2, []*blockchainpkg.TxInfo{
+		{TXHash: blockchainpkg.TXHash(tx3.TxHash()), Height: 0},
+	})
+
+	receivedOutputs, err = s.transactions.ReceivedOutputs()
+	s.Require().NoError(err)
+	s.Require().Len(receivedOutputs, 2)
+	outputs1 := receivedOutputs[address1.PubkeyScriptHashHex()]
+	s.Require().Len(outputs1, 2)
+	sort.Slice(outputs1, func(i, j int) bool { return outputs1[i].Height > outputs1[j].Height })
+	s.Require().Equal(&transactions.ReceivedOutput{
+		OutPoint:    wire.OutPoint{Hash: tx1.TxHash(), Index: 0},
+		TxOut:       tx1.TxOut[0],
+		Height:      10,
+		Unsolicited: true,
+		Spent:       true,
+	}, outputs1[0])
+	s.Require().Equal(&transactions.ReceivedOutput{
+		OutPoint:    wire.OutPoint{Hash: tx2.TxHash(), Index: 0},
+		TxOut:       tx2.TxOut[0],
+		Height:      0,
+		Unsolicited: true,
+		Spent:       false,
+	}, outputs1[1])
+	// Sent by ourselves.
+	s.Require().Equal([]*transactions.ReceivedOutput{{
+		OutPoint:    wire.OutPoint{Hash: tx3.TxHash(), Index: 0},
+		TxOut:       tx3.TxOut[0],
+		Height:      0,
+		Unsolicited: false,
+		Spent:       false,
+	}}, receivedOutputs[address2.PubkeyScriptHashHex()])
+}
+
 func (s *transactionsSuite) TestBalance() {
 	balance, err := s.transactions.Balance()
 	s.Require().NoError(err)
@@ -370,3 +433,108 @@ func (s *transactionsSuite) TestRemoveTransaction() {
 	s.Require().NoError(err)
 	s.Require().Len(transactions, 2)
 }
+
+// TestReplacedTransaction checks that an unconfirmed transaction is hidden once its replacement
+// (RBF) is known.
+func (s *transactionsSuite) TestReplacedTransaction() {
+	addresses, err := s.addressChain.EnsureAddresses()
+	s.Require().NoError(err)
+	address1 := addresses[0]
+	address2 := addresses[1]
+	tx1 := newTx(chainhash.HashH(nil), 0, address1, 1000)
+	tx2 := newTx(tx1.TxHash(), 0, address2, 900)
+	tx3 := newTx(tx1.TxHash(), 0, address2, 800)
+	s.blockchainMock.RegisterTxs(tx1, tx2, tx3)
+	s.headersMock.On("VerifiedHeaderByHeight", 10).Return(nil, nil).Once()
+	s.updateAddressHistory(address1, []*blockchainpkg.TxInfo{
+		{TXHash: blockchainpkg.TXHash(tx1.TxHash()), Height: 10},
+	})
+	s.updateAddressHistory(address2, []*blockchainpkg.TxInfo{
+		{TXHash: blockchainpkg.TXHash(tx2.TxHash()), Height: 0},
+		{TXHash: blockchainpkg.TXHash(tx3.TxHash()), Height: 0},
+	})
+	isChange := func(blockchainpkg.ScriptHashHex) bool { return false }
+	txs, err := s.transactions.Transactions(isChange)
+	s.Require().NoError(err)
+	s.Require().Len(txs, 3)
+
+	s.Require().NoError(transactions.DBUpdate(s.db, func(dbTx transactions.DBTxInterface) error {
+		return dbTx.PutReplacement(tx3.TxHash(), tx2.TxHash())
+	}))
+	txs, err = s.transactions.Transactions(isChange)
+	s.Require().NoError(err)
+	s.Require().Len(txs, 2)
+	for _, tx := range txs {
+		s.Require().NotEqual(tx2.TxHash().String(), tx.TxID)
+		if tx.TxID == tx3.TxHash().String() {
+			s.Require().Equal(tx2.TxHash().String(), tx.ReplacesTxID)
+		} else {
+			s.Require().Empty(tx.ReplacesTxID)
+		}
+	}
+}
+
+// TestCancelledTransaction checks that a cancelled transaction is listed as failed once the
+// transaction cancelling it confirms, even after it disappeared from the address histories.
+func (s *transactionsSuite) TestCancelledTransaction() {
+	addresses, err := s.addressChain.EnsureAddresses()
+	s.Require().NoError(err)
+	address1 := addresses[0]
+	address2 := addresses[1]
+	tx1 := newTx(chainhash.HashH(nil), 0, address1, 1000)
+	tx2 := newTx(tx1.TxHash(), 0, address2, 900)
+	// tx3 cancels tx2 by sending the coins back to address1.
+	tx3 := newTx(tx1.TxHash(), 0, address1, 800)
+	s.blockchainMock.RegisterTxs(tx1, tx2, tx3)
+	s.headersMock.On("VerifiedHeaderByHeight", mock.Anything).Return(nil, nil)
+	s.notifierMock.On("Delete", mock.Anything).Return(nil)
+	s.updateAddressHistory(address1, []*blockchainpkg.TxInfo{
+		{TXHash: blockchainpkg.TXHash(tx1.TxHash()), Height: 10},
+		{TXHash: blockchainpkg.TXHash(tx3.TxHash()), Height: 0},
+	})
+	s.updateAddressHistory(address2, []*blockchainpkg.TxInfo{
+		{TXHash: blockchainpkg.TXHash(tx2.TxHash()), Height: 0},
+	})
+	s.Require().NoError(transactions.DBUpdate(s.db, func(dbTx transactions.DBTxInterface) error {
+		txInfo, err := dbTx.TxInfo(tx2.TxHash())
+		if err != nil {
+			return err
+		}
+		if err := dbTx.PutReplacement(tx3.TxHash(), tx2.TxHash()); err != nil {
+			return err
+		}
+		return dbTx.PutCancellation(tx3.TxHash(), txInfo)
+	}))
+	isChange := func(blockchainpkg.ScriptHashHex) bool { return false }
+	// While the cancellation is unconfirmed, the cancelled transaction is hidden.
+	txs, err := s.transactions.Transactions(isChange)
+	s.Require().NoError(err)
+	s.Require().Len(txs, 2)
+
+	// The cancellation confirms and the cancelled transaction is dropped by the server.
+	s.updateAddressHistory(address1, []*blockchainpkg.TxInfo{
+		{TXHash: blockchainpkg.TXHash(tx1.TxHash()), Height: 10},
+		{TXHash: blockchainpkg.TXHash(tx3.TxHash()), Height: 11},
+	})
+	s.updateAddressHistory(address2, []*blockchainpkg.TxInfo{})
+	txInfo, err := transactions.DBView(s.db, func(dbTx transactions.DBTxInterface) (*transactions.DBTxInfo, error) {
+		return dbTx.TxInfo(tx2.TxHash())
+	})
+	s.Require().NoError(err)
+	s.Require().True(txInfo == nil || txInfo.Tx == nil)
+
+	txs, err = s.transactions.Transactions(isChange)
+	s.Require().NoError(err)
+	s.Require().Len(txs, 3)
+	var cancelled *accounts.TransactionData
+	for _, tx := range txs {
+		if tx.TxID == tx2.TxHash().String() {
+			cancelled = tx
+		}
+	}
+	s.Require().NotNil(cancelled)
+	s.Require().Equal(accounts.TxStatusFailed, cancelled.Status)
+	s.Require().Equal(tx3.TxHash().String(), cancelled.ReplacedByTxID)
+	s.Require().Equal(11, cancelled.Height)
+	s.Require().Nil(cancelled.Fee)
+}
@@ -0,0 +1,168 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package btc
+
+import (
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/addresses"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/blockchain"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/maketx"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/transactions"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/util"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// TxInputDetails describes an input of a transaction.
+type TxInputDetails struct {
+	PreviousOutPoint wire.OutPoint
+	Sequence         uint32
+	// PreviousOutput is the output spent by this input. It is nil if the previous transaction is not
+	// stored in the database, i.e. if it does not involve this account.
+	PreviousOutput *wire.TxOut
+	// Address is the address of the spent output. Empty if the previous output is unknown or has
+	// no address.
+	Address string
+	// AccountAddress is the address of this account the spent output belongs to, or nil if the input
+	// does not spend a coin of this account.
+	AccountAddress *addresses.AccountAddress
+}
+
+// TxOutputDetails describes an output of a transaction.
+type TxOutputDetails struct {
+	Index uint32
+	TxOut *wire.TxOut
+	// ScriptType is the type of the output script, e.g. "p2wpkh" or "opReturn". See
+	// outputScriptType().
+	ScriptType string
+	// Address is the address of the output. Empty if the output has no address, e.g. OP_RETURN.
+	Address string
+	// Ours is true if the output belongs to this account.
+	Ours bool
+	// IsChange is true if the output belongs to a change address of this account.
+	IsChange bool
+}
+
+// TxDetails contains the full details of a transaction of this account.
+type TxDetails struct {
+	Tx *wire.MsgTx
+	// Height is the block height of the transaction. 0 or -1 if it is unconfirmed.
+	Height int
+	// RBF is true if the transaction signals replaceability according to BIP-125.
+	RBF     bool
+	Inputs  []*TxInputDetails
+	Outputs []*TxOutputDetails
+}
+
+// outputScriptType returns the script type of an output script. The names of `signing.ScriptType`
+// are used where applicable.
+func outputScriptType(pkScript []byte) string {
+	switch txscript.GetScriptClass(pkScript) {
+	case txscript.PubKeyHashTy:
+		return "p2pkh"
+	case txscript.ScriptHashTy:
+		return "p2sh"
+	case txscript.WitnessV0PubKeyHashTy:
+		return "p2wpkh"
+	case txscript.WitnessV0ScriptHashTy:
+		return "p2wsh"
+	case txscript.WitnessV1TaprootTy:
+		return "p2tr"
+	case txscript.PubKeyTy:
+		return "p2pk"
+	case txscript.MultiSigTy:
+		return "multisig"
+	case txscript.NullDataTy:
+		return "opReturn"
+	default:
+		return "nonstandard"
+	}
+}
+
+// TxDetails returns the details of a transaction of this account, including all its inputs and
+// outputs, built from the transaction stored in the database. The previous outputs spent by the
+// inputs are only known if they are stored in the database too.
+func (account *Account) TxDetails(txID string) (*TxDetails, error) {
+	if !account.isInitialized() {
+		return nil, errp.New("account not initialized")
+	}
+	txHash, err := chainhash.NewHashFromStr(txID)
+	if err != nil {
+		return nil, errp.WithStack(err)
+	}
+	return transactions.DBView(account.db, func(dbTx transactions.DBTxInterface) (*TxDetails, error) {
+		txInfo, err := dbTx.TxInfo(*txHash)
+		if err != nil {
+			return nil, err
+		}
+		if txInfo == nil || txInfo.Tx == nil {
+			return nil, errp.New("Transaction not found")
+		}
+		details := &TxDetails{
+			Tx:      txInfo.Tx,
+			Height:  txInfo.Height,
+			RBF:     maketx.SignalsRBF(txInfo.Tx),
+			Inputs:  make([]*TxInputDetails, len(txInfo.Tx.TxIn)),
+			Outputs: make([]*TxOutputDetails, len(txInfo.Tx.TxOut)),
+		}
+		for index, txIn := range txInfo.Tx.TxIn {
+			input := &TxInputDetails{
+				PreviousOutPoint: txIn.PreviousOutPoint,
+				Sequence:         txIn.Sequence,
+			}
+			details.Inputs[index] = input
+			previousOutput, err := dbTx.Output(txIn.PreviousOutPoint)
+			if err != nil {
+				return nil, err
+			}
+			if previousOutput == nil {
+				// Not our coin, but the previous transaction might still be stored, e.g. if it
+				// also paid to this account.
+				previousTxInfo, err := dbTx.TxInfo(txIn.PreviousOutPoint.Hash)
+				if err != nil {
+					return nil, err
+				}
+				if previousTxInfo != nil && previousTxInfo.Tx != nil &&
+					txIn.PreviousOutPoint.Index < uint32(len(previousTxInfo.Tx.TxOut)) {
+					previousOutput = previousTxInfo.Tx.TxOut[txIn.PreviousOutPoint.Index]
+				}
+			}
+			if previousOutput == nil {
+				continue
+			}
+			input.PreviousOutput = previousOutput
+			if address, err := util.AddressFromPkScript(previousOutput.PkScript, account.coin.Net()); err == nil {
+				input.Address = address.String()
+			}
+			input.AccountAddress = account.GetAddress(blockchain.NewScriptHashHex(previousOutput.PkScript))
+		}
+		for index, txOut := range txInfo.Tx.TxOut {
+			scriptHashHex := blockchain.NewScriptHashHex(txOut.PkScript)
+			output := &TxOutputDetails{
+				Index:      uint32(index),
+				TxOut:      txOut,
+				ScriptType: outputScriptType(txOut.PkScript),
+				Ours:       account.GetAddress(scriptHashHex) != nil,
+				IsChange:   account.IsChange(scriptHashHex),
+			}
+			if address, err := util.AddressFromPkScript(txOut.PkScript, account.coin.Net()); err == nil {
+				output.Address = address.String()
+			}
+			details.Outputs[index] = output
+		}
+		return details, nil
+	})
+}
@@ -0,0 +1,90 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package btc
+
+import (
+	"testing"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/transactions"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxDetails(t *testing.T) {
+	account := testAccount(t, nil)
+	tx := putUnconfirmedTx(t, account, wire.MaxTxInSequenceNum-2)
+	changeAddresses, err := account.subaccounts[0].changeAddresses.GetUnused()
+	require.NoError(t, err)
+
+	details, err := account.TxDetails(tx.TxHash().String())
+	require.NoError(t, err)
+	require.Equal(t, tx, details.Tx)
+	require.Equal(t, 0, details.Height)
+	require.True(t, details.RBF)
+
+	require.Len(t, details.Inputs, 1)
+	input := details.Inputs[0]
+	require.Equal(t, *wire.NewOutPoint(&chainhash.Hash{}, 0), input.PreviousOutPoint)
+	require.Equal(t, uint32(wire.MaxTxInSequenceNum-2), input.Sequence)
+	require.Equal(t, int64(1000000000), input.PreviousOutput.Value)
+	require.Equal(t, changeAddresses[0].EncodeForHumans(), input.Address)
+	require.Equal(t, changeAddresses[0], input.AccountAddress)
+	require.Equal(t, "m/84'/1'/0'/1/0", input.AccountAddress.AbsoluteKeypath().Encode())
+
+	require.Len(t, details.Outputs, 2)
+	require.Equal(t, &TxOutputDetails{
+		Index:      0,
+		TxOut:      tx.TxOut[0],
+		ScriptType: "p2pkh",
+		Address:    "myY3Bbvj5mjwqqvubtu5Hfy2nuCeBfvNXL",
+	}, details.Outputs[0])
+	require.Equal(t, &TxOutputDetails{
+		Index:      1,
+		TxOut:      tx.TxOut[1],
+		ScriptType: "p2wpkh",
+		Address:    changeAddresses[0].EncodeForHumans(),
+		Ours:       true,
+		IsChange:   true,
+	}, details.Outputs[1])
+
+	// An incoming transaction spending a coin which is not stored.
+	incomingTx := wire.NewMsgTx(wire.TxVersion)
+	incomingTx.LockTime = 100
+	incomingTx.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: *wire.NewOutPoint(&chainhash.Hash{1}, 3),
+		Sequence:         wire.MaxTxInSequenceNum,
+	})
+	incomingTx.AddTxOut(wire.NewTxOut(5000, []byte{0x6a, 0x01, 0x01}))
+	require.NoError(t, transactions.DBUpdate(account.db, func(dbTx transactions.DBTxInterface) error {
+		return dbTx.PutTx(incomingTx.TxHash(), incomingTx, 20)
+	}))
+	details, err = account.TxDetails(incomingTx.TxHash().String())
+	require.NoError(t, err)
+	require.False(t, details.RBF)
+	require.Equal(t, 20, details.Height)
+	require.Equal(t, uint32(100), details.Tx.LockTime)
+	require.Nil(t, details.Inputs[0].PreviousOutput)
+	require.Nil(t, details.Inputs[0].AccountAddress)
+	require.Empty(t, details.Inputs[0].Address)
+	require.Equal(t, "opReturn", details.Outputs[0].ScriptType)
+	require.Empty(t, details.Outputs[0].Address)
+	require.False(t, details.Outputs[0].Ours)
+
+	_, err = account.TxDetails(chainhash.Hash{2}.String())
+	require.Error(t, err)
+	_, err = account.TxDetails("invalid")
+	require.Error(t, err)
+}
@@ -510,6 +510,15 @@ type TxProposal struct {
 }
 
 func (account *Account) newTx(args *accounts.TxProposalArgs) (*TxProposal, error) {
+	if len(args.AdditionalRecipients) != 0 {
+		return nil, errp.New("Multiple recipients are not supported")
+	}
+	if args.LockTime != 0 {
+		return nil, errp.New("Locktime is not supported")
+	}
+	if args.Payjoin != "" {
+		return nil, errp.New("Payjoin is not supported")
+	}
 	if !IsValidEthAddress(args.RecipientAddress) {
 		return nil, errp.WithStack(errors.ErrInvalidAddress)
 	}
@@ -0,0 +1,62 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eth
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// recoverSigner returns the public key which created the 65 byte [R || S || V] signature of the
+// hash. V can be 0/1 or 27/28.
+func recoverSigner(hash []byte, signature []byte) (*ecdsa.PublicKey, error) {
+	if len(signature) != crypto.SignatureLength {
+		return nil, errp.Newf("The signature must be %d bytes long", crypto.SignatureLength)
+	}
+	normalized := append([]byte{}, signature...)
+	if normalized[crypto.RecoveryIDOffset] >= 27 {
+		normalized[crypto.RecoveryIDOffset] -= 27
+	}
+	publicKey, err := crypto.SigToPub(hash, normalized)
+	if err != nil {
+		return nil, errp.WithStack(err)
+	}
+	return publicKey, nil
+}
+
+// RecoverMessageSigner returns the public key which created the EIP-191 personal signature
+// (`personal_sign`) of the message.
+func RecoverMessageSigner(message []byte, signature []byte) (*ecdsa.PublicKey, error) {
+	return recoverSigner(accounts.TextHash(message), signature)
+}
+
+// RecoverTypedMessageSigner returns the public key which created the EIP-712 signature
+// (`eth_signTypedData_v4`) of the typed data, given as JSON.
+func RecoverTypedMessageSigner(typedDataJSON []byte, signature []byte) (*ecdsa.PublicKey, error) {
+	var typedData apitypes.TypedData
+	if err := json.Unmarshal(typedDataJSON, &typedData); err != nil {
+		return nil, errp.Wrap(err, "Invalid typed data")
+	}
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return nil, errp.Wrap(err, "Invalid typed data")
+	}
+	return recoverSigner(hash, signature)
+}
@@ -0,0 +1,96 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eth
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecoverMessageSigner(t *testing.T) {
+	privateKey, err := crypto.HexToECDSA("4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+	require.NoError(t, err)
+	address := crypto.PubkeyToAddress(privateKey.PublicKey)
+	message := []byte("Some data")
+
+	signature, err := crypto.Sign(accounts.TextHash(message), privateKey)
+	require.NoError(t, err)
+	publicKey, err := RecoverMessageSigner(message, signature)
+	require.NoError(t, err)
+	require.Equal(t, address, crypto.PubkeyToAddress(*publicKey))
+
+	// V as returned by wallets.
+	signature[64] += 27
+	publicKey, err = RecoverMessageSigner(message, signature)
+	require.NoError(t, err)
+	require.Equal(t, address, crypto.PubkeyToAddress(*publicKey))
+
+	publicKey, err = RecoverMessageSigner([]byte("Other data"), signature)
+	require.NoError(t, err)
+	require.NotEqual(t, address, crypto.PubkeyToAddress(*publicKey))
+
+	_, err = RecoverMessageSigner(message, signature[:64])
+	require.Error(t, err)
+}
+
+func TestRecoverTypedMessageSigner(t *testing.T) {
+	// Example from https://eips.ethereum.org/EIPS/eip-712, signed by the private key keccak256("cow").
+	const typedData = `{
+  "types": {
+    "EIP712Domain": [
+      {"name": "name", "type": "string"},
+      {"name": "version", "type": "string"},
+      {"name": "chainId", "type": "uint256"},
+      {"name": "verifyingContract", "type": "address"}
+    ],
+    "Person": [
+      {"name": "name", "type": "string"},
+      {"name": "wallet", "type": "address"}
+    ],
+    "Mail": [
+      {"name": "from", "type": "Person"},
+      {"name": "to", "type": "Person"},
+      {"name": "contents", "type": "string"}
+    ]
+  },
+  "primaryType": "Mail",
+  "domain": {
+    "name": "Ether Mail",
+    "version": "1",
+    "chainId": 1,
+    "verifyingContract": "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"
+  },
+  "message": {
+    "from": {"name": "Cow", "wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"},
+    "to": {"name": "Bob", "wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},
+    "contents": "Hello, Bob!"
+  }
+}`
+	signature := hexutil.MustDecode("0x4355c47d63924e8a72e509b65029052eb6c299d53a04e167c5775fd466751c9d07299936d304c153f6443dfa05f40ff007d72911b6f72307f996231605b915621c")
+
+	publicKey, err := RecoverTypedMessageSigner([]byte(typedData), signature)
+	require.NoError(t, err)
+	require.Equal(t,
+		common.HexToAddress("0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"),
+		crypto.PubkeyToAddress(*publicKey))
+
+	_, err = RecoverTypedMessageSigner([]byte("Hello, Bob!"), signature)
+	require.Error(t, err)
+}
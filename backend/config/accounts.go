@@ -57,6 +57,56 @@ type Account struct {
 	// only applies to ETH, and the elements are ERC20 token codes (e.g. "eth-erc20-usdt",
 	// "eth-erc20-bat", etc).
 	ActiveTokens []string `json:"activeTokens,omitempty"`
+	// GapLimits are the minimum gap limits used to scan the addresses of the account. This only
+	// applies to BTC and LTC. If nil, the default gap limits of the coin are used.
+	GapLimits *GapLimits `json:"gapLimits,omitempty"`
+	// SilentPayments holds the keys to receive silent payments (BIP-352) if they are enabled for
+	// the account. This only applies to BTC.
+	SilentPayments *SilentPayments `json:"silentPayments,omitempty"`
+	// DustProtection configures the detection of dust attacks. This only applies to BTC and LTC. If
+	// nil, the default dust threshold is used and dust outputs are not quarantined.
+	DustProtection *DustProtection `json:"dustProtection,omitempty"`
+}
+
+// GapLimits are the numbers of consecutive unused receive and change addresses after which the
+// address scan of an account stops.
+type GapLimits struct {
+	Receive uint16 `json:"receive"`
+	Change  uint16 `json:"change"`
+}
+
+// DustProtection configures how tiny unsolicited outputs received on already used addresses are
+// handled. Such outputs are typically sent to link the addresses of a wallet when they are spent
+// together (dust attack).
+type DustProtection struct {
+	// Threshold is the value in satoshis below which such outputs are flagged as dust. If zero, the
+	// default threshold is used.
+	Threshold uint64 `json:"threshold"`
+	// Quarantine freezes flagged outputs, excluding them from automatic coin selection.
+	Quarantine bool `json:"quarantine"`
+}
+
+// SilentPayments holds the keys of an account to receive silent payments (BIP-352).
+type SilentPayments struct {
+	// Configuration is the taproot signing configuration of the extended spend key at
+	// m/352'/coin'/account'/0'.
+	Configuration *signing.Configuration `json:"configuration"`
+	// ScanKey is the private key at m/352'/coin'/account'/1'/0 used to detect incoming payments. It
+	// can't spend funds, but reveals all silent payments received by the account.
+	ScanKey jsonp.HexBytes `json:"scanKey"`
+	// BirthHeight is the chain tip height when silent payments were enabled. Only blocks after it
+	// are scanned, as no silent payments could have been received before.
+	BirthHeight int `json:"birthHeight"`
+}
+
+// IsMultisig returns true if this is a multisig account, whose coins are shared with cosigners.
+func (acct *Account) IsMultisig() bool {
+	for _, signingConfiguration := range acct.SigningConfigurations {
+		if signingConfiguration.BitcoinMultisig != nil {
+			return true
+		}
+	}
+	return false
 }
 
 // SetTokenActive activates/deactivates an token on an account. `tokenCode` must be an ERC20 token
@@ -96,6 +146,10 @@ type Keystore struct {
 	// this field yet but it may be helpful in the future if we want to remind users to connect
 	// their device, e.g. to check that they still know their device password.
 	LastConnected time.Time `json:"lastConnected"`
+	// Synthetic is true if the keystore was never connected, but was added with watch-only accounts
+	// created from an xpub or output descriptors. The accounts of a synthetic keystore can't sign.
+	// The flag is reset once a keystore with the same root fingerprint is registered.
+	Synthetic bool `json:"synthetic,omitempty"`
 }
 
 // AccountsConfig persists the list of accounts added to the app.
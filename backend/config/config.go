@@ -42,6 +42,9 @@ func (s *ServerInfo) String() string {
 // btcCoinConfig holds configurations specific to a btc-based coin.
 type btcCoinConfig struct {
 	ElectrumServers []*ServerInfo `json:"electrumServers"`
+	// TweakIndexURL is the URL of a BlindBit Oracle API used to scan for silent payments. Receiving
+	// silent payments is disabled if empty.
+	TweakIndexURL string `json:"tweakIndexURL,omitempty"`
 }
 
 // ETHTransactionsSource  where to get Ethereum transactions from. See the list of consts
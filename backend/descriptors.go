@@ -0,0 +1,172 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"bytes"
+	"strings"
+
+	accountsTypes "github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/types"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc"
+	coinpkg "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/config"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/keystore"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+)
+
+// sameExtendedPublicKey returns true if both extended keys have the same public key and chain code,
+// regardless of their version bytes.
+func sameExtendedPublicKey(key1, key2 *hdkeychain.ExtendedKey) (bool, error) {
+	publicKey1, err := key1.ECPubKey()
+	if err != nil {
+		return false, errp.WithStack(err)
+	}
+	publicKey2, err := key2.ECPubKey()
+	if err != nil {
+		return false, errp.WithStack(err)
+	}
+	return publicKey1.IsEqual(publicKey2) && bytes.Equal(key1.ChainCode(), key2.ChainCode()), nil
+}
+
+// parseDescriptors parses BIP-380 output descriptors into the signing configurations of an account.
+// Empty descriptors are skipped. The receive and change descriptors of a subaccount result in one
+// signing configuration.
+func parseDescriptors(descriptors []string) (signing.Configurations, error) {
+	var signingConfigurations signing.Configurations
+	seen := map[string]struct{}{}
+	for _, descriptor := range descriptors {
+		if strings.TrimSpace(descriptor) == "" {
+			continue
+		}
+		signingConfiguration, err := signing.ParseDescriptor(descriptor)
+		if err != nil {
+			return nil, err
+		}
+		key := signingConfiguration.String() + signingConfiguration.ExtendedPublicKey().String()
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		if signingConfigurations.FindScriptType(signingConfiguration.ScriptType()) != -1 {
+			return nil, errp.Newf("Multiple descriptors for script type %s", signingConfiguration.ScriptType())
+		}
+		signingConfigurations = append(signingConfigurations, signingConfiguration)
+	}
+	if len(signingConfigurations) == 0 {
+		return nil, errp.New("No descriptors")
+	}
+	return signingConfigurations, nil
+}
+
+// ImportDescriptors creates an account from BIP-380 output descriptors, e.g. exported from another
+// descriptor-based wallet, and returns the code of the new account. The descriptors must describe
+// the subaccounts of one account of the given keystore, which is verified by deriving the same
+// xpubs on the keystore.
+//
+// `name` is the account name, shown to the user. If empty, a default name will be set.
+func (backend *Backend) ImportDescriptors(
+	coinCode coinpkg.Code, descriptors []string, name string, keystore keystore.Keystore,
+) (accountsTypes.Code, error) {
+	coin, err := backend.Coin(coinCode)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := coin.(*btc.Coin); !ok {
+		return "", errp.Newf("Descriptors are not supported for %s", coinCode)
+	}
+	if !keystore.SupportsUnifiedAccounts() {
+		return "", errp.New("The keystore does not support importing descriptors")
+	}
+	rootFingerprint, err := keystore.RootFingerprint()
+	if err != nil {
+		return "", err
+	}
+	parsedConfigurations, err := parseDescriptors(descriptors)
+	if err != nil {
+		return "", err
+	}
+	accountNumber, err := parsedConfigurations[0].AccountNumber()
+	if err != nil {
+		return "", err
+	}
+	var signingConfigurations signing.Configurations
+	for _, parsedConfiguration := range parsedConfigurations {
+		if !bytes.Equal(parsedConfiguration.BitcoinSimple.KeyInfo.RootFingerprint, rootFingerprint) {
+			return "", errp.New("The descriptors belong to a different wallet")
+		}
+		parsedAccountNumber, err := parsedConfiguration.AccountNumber()
+		if err != nil {
+			return "", err
+		}
+		if parsedAccountNumber != accountNumber {
+			return "", errp.New("The descriptors belong to different accounts")
+		}
+		scriptType := parsedConfiguration.ScriptType()
+		if !keystore.SupportsAccount(coin, scriptType) {
+			return "", errp.Newf("The keystore does not support %s accounts for %s", scriptType, coinCode)
+		}
+		keypath := parsedConfiguration.AbsoluteKeypath()
+		extendedPublicKey, err := keystore.ExtendedPublicKey(coin, keypath)
+		if err != nil {
+			return "", err
+		}
+		same, err := sameExtendedPublicKey(extendedPublicKey, parsedConfiguration.ExtendedPublicKey())
+		if err != nil {
+			return "", err
+		}
+		if !same {
+			return "", errp.Newf("The xpub at keypath %s does not match the keystore", keypath.Encode())
+		}
+		// The xpub of the keystore is stored, so that it is encoded like the xpubs of other accounts.
+		signingConfigurations = append(signingConfigurations, signing.NewBitcoinConfiguration(
+			scriptType, rootFingerprint, keypath, extendedPublicKey))
+	}
+
+	if name == "" {
+		name = defaultAccountName(coin, accountNumber)
+	}
+	accountCode := regularAccountCode(rootFingerprint, coinCode, accountNumber)
+	err = backend.config.ModifyAccountsConfig(func(accountsConfig *config.AccountsConfig) error {
+		// An account which was added in the background by the accounts discovery is replaced.
+		if existing := accountsConfig.Lookup(accountCode); existing != nil && existing.HiddenBecauseUnused {
+			var remaining []*config.Account
+			for _, account := range accountsConfig.Accounts {
+				if account != existing {
+					remaining = append(remaining, account)
+				}
+			}
+			accountsConfig.Accounts = remaining
+		}
+		var accountWatch *bool
+		if accountsConfig.IsKeystoreWatchonly(rootFingerprint) {
+			t := true
+			accountWatch = &t
+		}
+		return backend.persistAccount(config.Account{
+			Watch:                 accountWatch,
+			CoinCode:              coinCode,
+			Name:                  name,
+			Code:                  accountCode,
+			SigningConfigurations: signingConfigurations,
+		}, accountsConfig)
+	})
+	if err != nil {
+		return "", err
+	}
+	backend.ReinitializeAccounts()
+	return accountCode, nil
+}
@@ -0,0 +1,117 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"testing"
+
+	coinpkg "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/config"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportDescriptors(t *testing.T) {
+	b := newBackend(t, testnetDisabled, regtestDisabled)
+	defer b.Close()
+	ks := makeBitBox02Multi()
+	b.registerKeystore(ks)
+
+	btcCoin, err := b.Coin(coinpkg.CodeBTC)
+	require.NoError(t, err)
+	descriptor := func(
+		xpubKeystore interface {
+			ExtendedPublicKey(coinpkg.Coin, signing.AbsoluteKeypath) (*hdkeychain.ExtendedKey, error)
+		},
+		scriptType signing.ScriptType, keypath string, change bool) string {
+		t.Helper()
+		xpub, err := xpubKeystore.ExtendedPublicKey(btcCoin, mustKeypath(keypath))
+		require.NoError(t, err)
+		descriptor, err := signing.NewBitcoinConfiguration(
+			scriptType, rootFingerprint1, mustKeypath(keypath), xpub).Descriptor(change)
+		require.NoError(t, err)
+		return descriptor
+	}
+
+	// The descriptors of the default account.
+	var exported []string
+	for _, signingConfiguration := range b.config.AccountsConfig().Lookup("v0-55555555-btc-0").SigningConfigurations {
+		for _, change := range []bool{false, true} {
+			descriptor, err := signingConfiguration.Descriptor(change)
+			require.NoError(t, err)
+			exported = append(exported, descriptor)
+		}
+	}
+	require.Equal(t, descriptor(keystoreHelper1(), signing.ScriptTypeP2WPKH, "m/84'/0'/0'", false), exported[0])
+
+	// Exported descriptors can't be imported again.
+	_, err = b.ImportDescriptors(coinpkg.CodeBTC, exported, "", ks)
+	require.Equal(t, errAccountAlreadyExists, errp.Cause(err))
+
+	// The xpub must match the keystore.
+	_, err = b.ImportDescriptors(coinpkg.CodeBTC, []string{
+		descriptor(keystoreHelper2(), signing.ScriptTypeP2WPKH, "m/84'/0'/3'", false),
+	}, "", ks)
+	require.ErrorContains(t, err, "does not match the keystore")
+
+	// All descriptors must belong to the same account.
+	_, err = b.ImportDescriptors(coinpkg.CodeBTC, []string{
+		descriptor(keystoreHelper1(), signing.ScriptTypeP2WPKH, "m/84'/0'/3'", false),
+		descriptor(keystoreHelper1(), signing.ScriptTypeP2TR, "m/86'/0'/4'", false),
+	}, "", ks)
+	require.ErrorContains(t, err, "different accounts")
+
+	_, err = b.ImportDescriptors(coinpkg.CodeBTC, []string{"", "\n"}, "", ks)
+	require.Error(t, err)
+
+	// An account added in the background by the accounts discovery is replaced.
+	accountCode, err := b.CreateAndPersistAccountConfig(coinpkg.CodeBTC, "", ks)
+	require.NoError(t, err)
+	require.Equal(t, "v0-55555555-btc-1", string(accountCode))
+	require.NoError(t, b.config.ModifyAccountsConfig(func(accountsConfig *config.AccountsConfig) error {
+		accountsConfig.Lookup(accountCode).HiddenBecauseUnused = true
+		return nil
+	}))
+	accountCode, err = b.ImportDescriptors(coinpkg.CodeBTC, []string{
+		descriptor(keystoreHelper1(), signing.ScriptTypeP2WPKH, "m/84'/0'/1'", false),
+	}, "", ks)
+	require.NoError(t, err)
+	require.Equal(t, "v0-55555555-btc-1", string(accountCode))
+	accountConfig := b.config.AccountsConfig().Lookup(accountCode)
+	require.False(t, accountConfig.HiddenBecauseUnused)
+	require.Len(t, accountConfig.SigningConfigurations, 1)
+	require.Equal(t, "Bitcoin 2", accountConfig.Name)
+
+	accountCode, err = b.ImportDescriptors(coinpkg.CodeBTC, []string{
+		descriptor(keystoreHelper1(), signing.ScriptTypeP2WPKH, "m/84'/0'/3'", false),
+		descriptor(keystoreHelper1(), signing.ScriptTypeP2WPKH, "m/84'/0'/3'", true),
+		"",
+		descriptor(keystoreHelper1(), signing.ScriptTypeP2TR, "m/86'/0'/3'", false),
+	}, "imported", ks)
+	require.NoError(t, err)
+	require.Equal(t, "v0-55555555-btc-3", string(accountCode))
+
+	accountConfig = b.config.AccountsConfig().Lookup(accountCode)
+	require.NotNil(t, accountConfig)
+	require.Equal(t, "imported", accountConfig.Name)
+	require.False(t, accountConfig.HiddenBecauseUnused)
+	require.Len(t, accountConfig.SigningConfigurations, 2)
+	require.Equal(t, signing.ScriptTypeP2WPKH, accountConfig.SigningConfigurations[0].ScriptType())
+	require.Equal(t, signing.ScriptTypeP2TR, accountConfig.SigningConfigurations[1].ScriptType())
+	require.Equal(t, "m/86'/0'/3'", accountConfig.SigningConfigurations[1].AbsoluteKeypath().Encode())
+	require.NotNil(t, b.Accounts().lookup(accountCode))
+}
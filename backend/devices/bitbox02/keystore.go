@@ -34,9 +34,11 @@ import (
 	"github.com/BitBoxSwiss/bitbox02-api-go/api/firmware"
 	"github.com/BitBoxSwiss/bitbox02-api-go/api/firmware/messages"
 	"github.com/BitBoxSwiss/bitbox02-api-go/util/semver"
+	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/btcutil/hdkeychain"
 	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
 	ethTypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/sirupsen/logrus"
@@ -105,6 +107,14 @@ func (keystore *keystore) SupportsAccount(coin coinpkg.Coin, meta interface{}) b
 	switch coin.(type) {
 	case *btc.Coin:
 		scriptType := meta.(signing.ScriptType)
+		if scriptType == signing.ScriptTypeP2WSH || scriptType == signing.ScriptTypeP2WSHP2SH {
+			switch coin.Code() {
+			case coinpkg.CodeBTC, coinpkg.CodeTBTC:
+				return keystore.device.Version().AtLeast(semver.NewSemVer(9, 2, 0))
+			default:
+				return false
+			}
+		}
 		if scriptType == signing.ScriptTypeP2TR {
 			// Taproot available since v9.10.0.
 			switch coin.Code() {
@@ -154,9 +164,9 @@ func (keystore *keystore) VerifyAddressBTC(
 	if !canVerifyAddress {
 		panic("CanVerifyAddress must be true")
 	}
-	msgScriptType, ok := btcMsgScriptTypeMap[accountConfiguration.ScriptType()]
-	if !ok {
-		panic("unsupported scripttype")
+	scriptConfig, err := btcMsgScriptConfig(accountConfiguration)
+	if err != nil {
+		return err
 	}
 	keypath := accountConfiguration.AbsoluteKeypath().
 		Child(derivation.SimpleChainIndex(), false).
@@ -164,7 +174,7 @@ func (keystore *keystore) VerifyAddressBTC(
 	_, err = keystore.device.BTCAddress(
 		btcMsgCoinMap[coin.Code()],
 		keypath.ToUInt32(),
-		firmware.NewBTCScriptConfigSimple(msgScriptType),
+		scriptConfig,
 		true,
 	)
 	if firmware.IsErrorAbort(err) {
@@ -304,10 +314,10 @@ func (keystore *keystore) signBTCTransaction(btcProposedTx *btc.ProposedTransact
 	// script type (e.g. p2wpkh, p2tr..) and the account keypath
 	scriptConfigs := []*messages.BTCScriptConfigWithKeypath{}
 	// addScriptConfig returns the index of the scriptConfig in scriptConfigs, adding it if it isn't
-	// present. Must be a Simple configuration.
+	// present.
 	addScriptConfig := func(scriptConfig *messages.BTCScriptConfigWithKeypath) int {
 		for i, sc := range scriptConfigs {
-			if sc.ScriptConfig.Config.(*messages.BTCScriptConfig_SimpleType_).SimpleType == scriptConfig.ScriptConfig.Config.(*messages.BTCScriptConfig_SimpleType_).SimpleType {
+			if sameBTCScriptConfig(sc, scriptConfig) {
 				return i
 			}
 		}
@@ -331,16 +341,21 @@ func (keystore *keystore) signBTCTransaction(btcProposedTx *btc.ProposedTransact
 		}
 
 		inputAddress := prevOut.Address
+		if inputAddress == nil {
+			// Inputs of other wallets, e.g. of the receiver of a payjoin transaction, can't be
+			// signed together with our inputs.
+			return errp.WithStack(keystorePkg.ErrUnsupportedFeature)
+		}
+		if inputAddress.SilentPaymentTweak() != nil {
+			// The device can't sign for outputs received through a silent payment.
+			return errp.WithStack(keystorePkg.ErrUnsupportedFeature)
+		}
 
-		accountConfiguration := inputAddress.AccountConfiguration
-		msgScriptType, ok := btcMsgScriptTypeMap[accountConfiguration.ScriptType()]
-		if !ok {
-			return errp.Newf("Unsupported script type %s", accountConfiguration.ScriptType())
+		scriptConfig, err := btcMsgScriptConfigWithKeypath(inputAddress.AccountConfiguration)
+		if err != nil {
+			return err
 		}
-		scriptConfigIndex := addScriptConfig(&messages.BTCScriptConfigWithKeypath{
-			ScriptConfig: firmware.NewBTCScriptConfigSimple(msgScriptType),
-			Keypath:      accountConfiguration.AbsoluteKeypath().ToUInt32(),
-		})
+		scriptConfigIndex := addScriptConfig(scriptConfig)
 
 		var bip352Pubkey []byte
 		if btcProposedTx.TXProposal.SilentPaymentAddress != "" {
@@ -369,10 +384,10 @@ func (keystore *keystore) signBTCTransaction(btcProposedTx *btc.ProposedTransact
 	// script type (e.g. p2wpkh, p2tr..) and the account keypath
 	outputScriptConfigs := []*messages.BTCScriptConfigWithKeypath{}
 	// addOutputScriptConfig returns the index of the scriptConfig in outputScriptConfigs, adding it if it isn't
-	// present. Must be a Simple configuration.
+	// present.
 	addOutputScriptConfig := func(scriptConfig *messages.BTCScriptConfigWithKeypath) uint32 {
 		for i, sc := range outputScriptConfigs {
-			if sc.ScriptConfig.Config.(*messages.BTCScriptConfig_SimpleType_).SimpleType == scriptConfig.ScriptConfig.Config.(*messages.BTCScriptConfig_SimpleType_).SimpleType {
+			if sameBTCScriptConfig(sc, scriptConfig) {
 				return uint32(i)
 			}
 		}
@@ -393,6 +408,11 @@ func (keystore *keystore) signBTCTransaction(btcProposedTx *btc.ProposedTransact
 				Address: btcProposedTx.TXProposal.SilentPaymentAddress,
 			}
 		} else {
+			// OP_RETURN outputs are used by BIP-322 message signatures, which are not supported
+			// by the BitBox02.
+			if txscript.GetScriptClass(txOut.PkScript) == txscript.NullDataTy {
+				return errp.WithStack(keystorePkg.ErrUnsupportedFeature)
+			}
 			outputAddress, err := util.AddressFromPkScript(txOut.PkScript, coin.Net())
 			if err != nil {
 				return err
@@ -437,24 +457,18 @@ func (keystore *keystore) signBTCTransaction(btcProposedTx *btc.ProposedTransact
 		var scriptConfigIndex int
 		var outputScriptConfigIndex *uint32
 		if isOurs {
-			accountConfiguration := outputAddress.AccountConfiguration
-			msgScriptType, ok := btcMsgScriptTypeMap[accountConfiguration.ScriptType()]
-			if !ok {
-				return errp.Newf("Unsupported script type %s", accountConfiguration.ScriptType())
+			scriptConfig, err := btcMsgScriptConfigWithKeypath(outputAddress.AccountConfiguration)
+			if err != nil {
+				return err
 			}
 			switch {
 			case sameAccount:
 				keypath = outputAddress.AbsoluteKeypath().ToUInt32()
-				scriptConfigIndex = addScriptConfig(&messages.BTCScriptConfigWithKeypath{
-					ScriptConfig: firmware.NewBTCScriptConfigSimple(msgScriptType),
-					Keypath:      accountConfiguration.AbsoluteKeypath().ToUInt32(),
-				})
-			case keystore.device.Version().AtLeast(semver.NewSemVer(9, 22, 0)):
+				scriptConfigIndex = addScriptConfig(scriptConfig)
+			case keystore.device.Version().AtLeast(semver.NewSemVer(9, 22, 0)) &&
+				outputAddress.AccountConfiguration.BitcoinMultisig == nil:
 				keypath = outputAddress.AbsoluteKeypath().ToUInt32()
-				outputScriptConfigIdx := addOutputScriptConfig(&messages.BTCScriptConfigWithKeypath{
-					ScriptConfig: firmware.NewBTCScriptConfigSimple(msgScriptType),
-					Keypath:      accountConfiguration.AbsoluteKeypath().ToUInt32(),
-				})
+				outputScriptConfigIdx := addOutputScriptConfig(scriptConfig)
 				outputScriptConfigIndex = &outputScriptConfigIdx
 			default:
 				isOurs = false
@@ -697,6 +711,47 @@ func (keystore *keystore) SupportsEIP1559() bool {
 	return keystore.device.Version().AtLeast(semver.NewSemVer(9, 16, 0))
 }
 
+// RegisterBTCMultisig implements keystore.Keystore. The user confirms the cosigners and the name on
+// the device.
+func (keystore *keystore) RegisterBTCMultisig(
+	coin coinpkg.Coin, configuration *signing.Configuration, name string) error {
+	if configuration.BitcoinMultisig == nil {
+		return errp.New("not a multisig configuration")
+	}
+	if !keystore.SupportsAccount(coin, configuration.ScriptType()) {
+		return errp.WithStack(keystorePkg.ErrUnsupportedFeature)
+	}
+	msgCoin, ok := btcMsgCoinMap[coin.Code()]
+	if !ok {
+		return errp.Newf("coin not supported: %s", coin.Code())
+	}
+	scriptConfig, err := btcMsgScriptConfig(configuration)
+	if err != nil {
+		return err
+	}
+	keypath := configuration.AbsoluteKeypath().ToUInt32()
+	registered, err := keystore.device.BTCIsScriptConfigRegistered(msgCoin, scriptConfig, keypath)
+	if err != nil {
+		return err
+	}
+	if registered {
+		return nil
+	}
+	err = keystore.device.BTCRegisterScriptConfig(msgCoin, scriptConfig, keypath, name)
+	if firmware.IsErrorAbort(err) {
+		return errp.WithStack(keystorePkg.ErrSigningAborted)
+	}
+	return err
+}
+
+// SilentPaymentKeys implements keystore.Keystore. The device does not export the silent payment
+// scan key.
+func (keystore *keystore) SilentPaymentKeys(
+	coin coinpkg.Coin, keypath signing.AbsoluteKeypath,
+) (*btcec.PrivateKey, *hdkeychain.ExtendedKey, error) {
+	return nil, nil, errp.WithStack(keystorePkg.ErrUnsupportedFeature)
+}
+
 // SupportsPaymentRequests implements keystore.Keystore.
 func (keystore *keystore) SupportsPaymentRequests() error {
 	if keystore.device.Version().AtLeast(semver.NewSemVer(9, 20, 0)) {
@@ -704,3 +759,9 @@ func (keystore *keystore) SupportsPaymentRequests() error {
 	}
 	return keystorePkg.ErrFirmwareUpgradeRequired
 }
+
+// SupportsForeignInputs implements keystore.Keystore. The device can only sign transactions whose
+// inputs all belong to the wallet.
+func (keystore *keystore) SupportsForeignInputs() bool {
+	return false
+}
@@ -334,7 +334,8 @@ func makeConfig(t *testing.T, device *Device, scriptType signing.ScriptType, key
 	return signing.NewBitcoinConfiguration(scriptType, rootFingerprint, keypath, xpub)
 }
 
-func makeTx(t *testing.T, device *Device, recipient *maketx.OutputInfo) *btc.ProposedTransaction {
+// makeTx makes a tx paying the recipients, splitting the output amount evenly.
+func makeTx(t *testing.T, device *Device, recipients ...*maketx.OutputInfo) *btc.ProposedTransaction {
 	t.Helper()
 
 	configurations := []*signing.Configuration{
@@ -399,15 +400,25 @@ func makeTx(t *testing.T, device *Device, recipient *maketx.OutputInfo) *btc.Pro
 		*wire.NewOutPoint(&prevTxHash, 1): maketx.UTXO{prevTx.TxOut[1], inputAddress1},
 		*wire.NewOutPoint(&prevTxHash, 2): maketx.UTXO{prevTx.TxOut[2], inputAddress2},
 	}
-	outputAmount := int64(250_000_000)
+	outputAmount := btcutil.Amount(250_000_000)
+	txRecipients := make([]*maketx.Recipient, len(recipients))
+	for i, recipient := range recipients {
+		txRecipients[i] = &maketx.Recipient{
+			OutputInfo: recipient,
+			Amount:     outputAmount / btcutil.Amount(len(recipients)),
+		}
+	}
 	feePerKb := btcutil.Amount(1000)
 	txProposal, err := maketx.NewTx(
 		coinBTC,
 		spendableOutputs,
-		recipient,
-		outputAmount,
+		txRecipients,
 		feePerKb,
+		maketx.DefaultLongTermFeePerKb,
 		changeAddress,
+		0,
+		0,
+		maketx.SecureSeed(),
 		log,
 	)
 	require.NoError(t, err)
@@ -491,6 +502,36 @@ func TestSimulatorSignBTCTransactionMixedInputs(t *testing.T) {
 	})
 }
 
+func TestSimulatorSignBTCTransactionMultipleRecipients(t *testing.T) {
+	testInitializedSimulators(t, func(t *testing.T, device *Device, stdOut *bytes.Buffer) {
+		t.Helper()
+
+		pkScript1, err := hex.DecodeString("76a91455ae51684c43435da751ac8d2173b2652eb6410588ac")
+		require.NoError(t, err)
+		pkScript2, err := hex.DecodeString("0014751e76e8199196d454941c45d1b3a323f1433bd6")
+		require.NoError(t, err)
+		proposedTransaction := makeTx(t, device,
+			maketx.NewOutputInfo(pkScript1), maketx.NewOutputInfo(pkScript2))
+		require.Len(t, proposedTransaction.TXProposal.Transaction.TxOut, 3)
+
+		require.NoError(t, device.Keystore().SignTransaction(proposedTransaction))
+		require.NoError(t, proposedTransaction.Finalize())
+		require.NoError(
+			t,
+			btc.TxValidityCheck(
+				proposedTransaction.TXProposal.Transaction,
+				proposedTransaction.TXProposal.PreviousOutputs,
+				proposedTransaction.TXProposal.SigHashes()))
+
+		// All recipients are confirmed on the device.
+		// Before simulator v9.20, address confirmation data was not written to stdout.
+		if device.Version().AtLeast(semver.NewSemVer(9, 20, 0)) {
+			require.Contains(t, stdOut.String(), "ADDRESS: 18p3G8gQ3oKy4U9EqnWs7UZswdqAMhE3r8")
+			require.Contains(t, stdOut.String(), "ADDRESS: bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4")
+		}
+	})
+}
+
 func TestSimulatorSignBTCTransactionSendSelfSameAccount(t *testing.T) {
 	testInitializedSimulators(t, func(t *testing.T, device *Device, stdOut *bytes.Buffer) {
 		t.Helper()
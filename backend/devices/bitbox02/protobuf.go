@@ -16,8 +16,13 @@
 package bitbox02
 
 import (
+	"bytes"
+	"slices"
+
 	coinpkg "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/BitBoxSwiss/bitbox02-api-go/api/firmware"
 	"github.com/BitBoxSwiss/bitbox02-api-go/api/firmware/messages"
 )
 
@@ -35,3 +40,77 @@ var btcMsgScriptTypeMap = map[signing.ScriptType]messages.BTCScriptConfig_Simple
 	signing.ScriptTypeP2WPKH:     messages.BTCScriptConfig_P2WPKH,
 	signing.ScriptTypeP2TR:       messages.BTCScriptConfig_P2TR,
 }
+
+var btcMsgMultisigScriptTypeMap = map[signing.ScriptType]messages.BTCScriptConfig_Multisig_ScriptType{
+	signing.ScriptTypeP2WSH:     messages.BTCScriptConfig_Multisig_P2WSH,
+	signing.ScriptTypeP2WSHP2SH: messages.BTCScriptConfig_Multisig_P2WSH_P2SH,
+}
+
+// btcMsgScriptConfig converts a Bitcoin account configuration to the script config of the account,
+// which can be a simple (single-sig) or a multisig script config.
+func btcMsgScriptConfig(configuration *signing.Configuration) (*messages.BTCScriptConfig, error) {
+	if multisig := configuration.BitcoinMultisig; multisig != nil {
+		msgScriptType, ok := btcMsgMultisigScriptTypeMap[multisig.ScriptType]
+		if !ok {
+			return nil, errp.Newf("Unsupported multisig script type %s", multisig.ScriptType)
+		}
+		xpubs := make([]string, len(multisig.KeyInfos))
+		for i, keyInfo := range multisig.KeyInfos {
+			xpubs[i] = keyInfo.ExtendedPublicKey.String()
+		}
+		scriptConfig, err := firmware.NewBTCScriptConfigMultisig(
+			uint32(multisig.Threshold), xpubs, uint32(multisig.OurKeyIndex))
+		if err != nil {
+			return nil, errp.WithStack(err)
+		}
+		scriptConfig.GetMultisig().ScriptType = msgScriptType
+		return scriptConfig, nil
+	}
+	msgScriptType, ok := btcMsgScriptTypeMap[configuration.ScriptType()]
+	if !ok {
+		return nil, errp.Newf("Unsupported script type %s", configuration.ScriptType())
+	}
+	return firmware.NewBTCScriptConfigSimple(msgScriptType), nil
+}
+
+// btcMsgScriptConfigWithKeypath is like btcMsgScriptConfig(), also including the account keypath.
+func btcMsgScriptConfigWithKeypath(
+	configuration *signing.Configuration) (*messages.BTCScriptConfigWithKeypath, error) {
+	scriptConfig, err := btcMsgScriptConfig(configuration)
+	if err != nil {
+		return nil, err
+	}
+	return &messages.BTCScriptConfigWithKeypath{
+		ScriptConfig: scriptConfig,
+		Keypath:      configuration.AbsoluteKeypath().ToUInt32(),
+	}, nil
+}
+
+// sameBTCScriptConfig returns true if both script configs belong to the same account.
+func sameBTCScriptConfig(a, b *messages.BTCScriptConfigWithKeypath) bool {
+	if !slices.Equal(a.Keypath, b.Keypath) {
+		return false
+	}
+	switch configA := a.ScriptConfig.Config.(type) {
+	case *messages.BTCScriptConfig_SimpleType_:
+		configB, ok := b.ScriptConfig.Config.(*messages.BTCScriptConfig_SimpleType_)
+		return ok && configA.SimpleType == configB.SimpleType
+	case *messages.BTCScriptConfig_Multisig_:
+		configB, ok := b.ScriptConfig.Config.(*messages.BTCScriptConfig_Multisig_)
+		return ok &&
+			configA.Multisig.ScriptType == configB.Multisig.ScriptType &&
+			configA.Multisig.Threshold == configB.Multisig.Threshold &&
+			configA.Multisig.OurXpubIndex == configB.Multisig.OurXpubIndex &&
+			slices.EqualFunc(configA.Multisig.Xpubs, configB.Multisig.Xpubs, sameXPub)
+	default:
+		return false
+	}
+}
+
+func sameXPub(a, b *messages.XPub) bool {
+	return bytes.Equal(a.Depth, b.Depth) &&
+		bytes.Equal(a.ParentFingerprint, b.ParentFingerprint) &&
+		a.ChildNum == b.ChildNum &&
+		bytes.Equal(a.ChainCode, b.ChainCode) &&
+		bytes.Equal(a.PublicKey, b.PublicKey)
+}
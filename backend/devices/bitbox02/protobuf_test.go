@@ -0,0 +1,64 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bitbox02
+
+import (
+	"testing"
+
+	"github.com/BitBoxSwiss/bitbox02-api-go/api/firmware/messages"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSameBTCScriptConfig(t *testing.T) {
+	keypath := []uint32{48 + 0x80000000, 0x80000000, 0x80000000, 2 + 0x80000000}
+	multisig := func(cosignerPublicKey byte) *messages.BTCScriptConfigWithKeypath {
+		xpub := func(publicKey byte) *messages.XPub {
+			return &messages.XPub{
+				Depth:             []byte{4},
+				ParentFingerprint: []byte{1, 2, 3, 4},
+				ChildNum:          2 + 0x80000000,
+				ChainCode:         make([]byte, 32),
+				PublicKey:         append(append([]byte{2}, make([]byte, 31)...), publicKey),
+			}
+		}
+		return &messages.BTCScriptConfigWithKeypath{
+			ScriptConfig: &messages.BTCScriptConfig{
+				Config: &messages.BTCScriptConfig_Multisig_{
+					Multisig: &messages.BTCScriptConfig_Multisig{
+						Threshold:    2,
+						Xpubs:        []*messages.XPub{xpub(0), xpub(cosignerPublicKey)},
+						OurXpubIndex: 0,
+						ScriptType:   messages.BTCScriptConfig_Multisig_P2WSH,
+					},
+				},
+			},
+			Keypath: keypath,
+		}
+	}
+	require.True(t, sameBTCScriptConfig(multisig(1), multisig(1)))
+	// Different multisig wallets at the same keypath.
+	require.False(t, sameBTCScriptConfig(multisig(1), multisig(2)))
+
+	simple := &messages.BTCScriptConfigWithKeypath{
+		ScriptConfig: &messages.BTCScriptConfig{
+			Config: &messages.BTCScriptConfig_SimpleType_{
+				SimpleType: messages.BTCScriptConfig_P2WPKH,
+			},
+		},
+		Keypath: keypath,
+	}
+	require.True(t, sameBTCScriptConfig(simple, simple))
+	require.False(t, sameBTCScriptConfig(simple, multisig(1)))
+}
@@ -33,6 +33,7 @@ import (
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/banners"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/bitsurance"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/blockchain"
 	accountHandlers "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/handlers"
 	coinpkg "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth"
@@ -48,6 +49,7 @@ import (
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/exchanges"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/keystore"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/rates"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
 	utilConfig "github.com/BitBoxSwiss/bitbox-wallet-app/util/config"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/util/jsonp"
@@ -100,13 +102,24 @@ type Backend interface {
 	SupportedCoins(keystore.Keystore) []coinpkg.Code
 	CanAddAccount(coinpkg.Code, keystore.Keystore) (string, bool)
 	CreateAndPersistAccountConfig(coinCode coinpkg.Code, name string, keystore keystore.Keystore) (accountsTypes.Code, error)
+	ImportDescriptors(coinCode coinpkg.Code, descriptors []string, name string, keystore keystore.Keystore) (accountsTypes.Code, error)
+	CreateWatchonlyAccount(coinCode coinpkg.Code, extendedKeyOrDescriptors string, scriptType signing.ScriptType, name string) (accountsTypes.Code, error)
+	MultisigKey(coinCode coinpkg.Code, scriptType signing.ScriptType, accountNumber uint16, keystore keystore.Keystore) (string, error)
+	CreateMultisigAccount(args *backend.MultisigAccountArgs, keystore keystore.Keystore) (accountsTypes.Code, error)
 	SetAccountActive(accountCode accountsTypes.Code, active bool) error
 	SetTokenActive(accountCode accountsTypes.Code, tokenCode string, active bool) error
 	RenameAccount(accountCode accountsTypes.Code, name string) error
+	SetAccountGapLimits(accountCode accountsTypes.Code, gapLimits *config.GapLimits) error
+	SetAccountSilentPayments(accountCode accountsTypes.Code, enabled bool) error
+	SetAccountDustProtection(accountCode accountsTypes.Code, dustProtection *config.DustProtection) error
 	AOPP() backend.AOPP
 	AOPPCancel()
 	AOPPApprove()
 	AOPPChooseAccount(code accountsTypes.Code)
+	PaymentURI() *backend.PaymentURI
+	PaymentURIClear()
+	PaymentURIChooseAccount(code accountsTypes.Code)
+	VerifyMessage(coinCode coinpkg.Code, address string, message string, signature string, typedData bool) (*backend.VerifiedMessage, error)
 	GetAccountFromCode(code accountsTypes.Code) (accounts.Interface, error)
 	HTTPClient() *http.Client
 	LookupInsuredAccounts(accountCode accountsTypes.Code) ([]bitsurance.AccountDetails, error)
@@ -213,6 +226,10 @@ func NewHandlers(
 	getAPIRouterNoError(apiRouter)("/testing", handlers.getTesting).Methods("GET")
 	getAPIRouterNoError(apiRouter)("/dev-servers", handlers.getDevServers).Methods("GET")
 	getAPIRouterNoError(apiRouter)("/account-add", handlers.postAddAccount).Methods("POST")
+	getAPIRouterNoError(apiRouter)("/account-import-descriptors", handlers.postImportDescriptors).Methods("POST")
+	getAPIRouterNoError(apiRouter)("/account-add-watchonly", handlers.postAddWatchonlyAccount).Methods("POST")
+	getAPIRouterNoError(apiRouter)("/multisig/key", handlers.postMultisigKey).Methods("POST")
+	getAPIRouterNoError(apiRouter)("/multisig/account-add", handlers.postAddMultisigAccount).Methods("POST")
 	getAPIRouterNoError(apiRouter)("/keystores", handlers.getKeystores).Methods("GET")
 	getAPIRouterNoError(apiRouter)("/accounts", handlers.getAccounts).Methods("GET")
 	getAPIRouterNoError(apiRouter)("/accounts/balance", handlers.getAccountsBalance).Methods("GET")
@@ -221,6 +238,9 @@ func NewHandlers(
 	getAPIRouterNoError(apiRouter)("/set-account-active", handlers.postSetAccountActive).Methods("POST")
 	getAPIRouterNoError(apiRouter)("/set-token-active", handlers.postSetTokenActive).Methods("POST")
 	getAPIRouterNoError(apiRouter)("/rename-account", handlers.postRenameAccount).Methods("POST")
+	getAPIRouterNoError(apiRouter)("/set-account-gap-limits", handlers.postSetAccountGapLimits).Methods("POST")
+	getAPIRouterNoError(apiRouter)("/set-account-silent-payments", handlers.postSetAccountSilentPayments).Methods("POST")
+	getAPIRouterNoError(apiRouter)("/set-account-dust-protection", handlers.postSetAccountDustProtection).Methods("POST")
 	getAPIRouterNoError(apiRouter)("/accounts/reinitialize", handlers.postAccountsReinitialize).Methods("POST")
 	getAPIRouterNoError(apiRouter)("/account-summary", handlers.getAccountSummary).Methods("GET")
 	getAPIRouterNoError(apiRouter)("/supported-coins", handlers.getSupportedCoins).Methods("GET")
@@ -232,6 +252,7 @@ func NewHandlers(
 	getAPIRouter(apiRouter)("/coins/tbtc/headers/status", handlers.getHeadersStatus(coinpkg.CodeTBTC)).Methods("GET")
 	getAPIRouter(apiRouter)("/coins/ltc/headers/status", handlers.getHeadersStatus(coinpkg.CodeLTC)).Methods("GET")
 	getAPIRouter(apiRouter)("/coins/btc/headers/status", handlers.getHeadersStatus(coinpkg.CodeBTC)).Methods("GET")
+	getAPIRouter(apiRouter)("/coins/{code}/electrum-servers", handlers.getElectrumServers).Methods("GET")
 	getAPIRouterNoError(apiRouter)("/coins/btc/set-unit", handlers.postBtcFormatUnit).Methods("POST")
 	getAPIRouterNoError(apiRouter)("/coins/btc/parse-external-amount", handlers.getBTCParseExternalAmount).Methods("GET")
 	getAPIRouterNoError(apiRouter)("/certs/download", handlers.postCertsDownload).Methods("POST")
@@ -251,6 +272,10 @@ func NewHandlers(
 	getAPIRouterNoError(apiRouter)("/aopp/cancel", handlers.postAOPPCancel).Methods("POST")
 	getAPIRouterNoError(apiRouter)("/aopp/approve", handlers.postAOPPApprove).Methods("POST")
 	getAPIRouter(apiRouter)("/aopp/choose-account", handlers.postAOPPChooseAccount).Methods("POST")
+	getAPIRouterNoError(apiRouter)("/payment-uri", handlers.getPaymentURI).Methods("GET")
+	getAPIRouter(apiRouter)("/payment-uri/choose-account", handlers.postPaymentURIChooseAccount).Methods("POST")
+	getAPIRouterNoError(apiRouter)("/payment-uri/clear", handlers.postPaymentURIClear).Methods("POST")
+	getAPIRouterNoError(apiRouter)("/verify-message", handlers.postVerifyMessage).Methods("POST")
 	getAPIRouterNoError(apiRouter)("/cancel-connect-keystore", handlers.postCancelConnectKeystore).Methods("POST")
 	getAPIRouterNoError(apiRouter)("/set-watchonly", handlers.postSetWatchonly).Methods("POST")
 	getAPIRouterNoError(apiRouter)("/on-auth-setting-changed", handlers.postOnAuthSettingChanged).Methods("POST")
@@ -574,6 +599,151 @@ func (handlers *Handlers) postAddAccount(r *http.Request) interface{} {
 	return response{Success: true, AccountCode: accountCode}
 }
 
+func (handlers *Handlers) postImportDescriptors(r *http.Request) interface{} {
+	var jsonBody struct {
+		CoinCode    coinpkg.Code `json:"coinCode"`
+		Descriptors []string     `json:"descriptors"`
+		Name        string       `json:"name"`
+	}
+
+	type response struct {
+		Success      bool               `json:"success"`
+		AccountCode  accountsTypes.Code `json:"accountCode,omitempty"`
+		ErrorMessage string             `json:"errorMessage,omitempty"`
+		ErrorCode    string             `json:"errorCode,omitempty"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&jsonBody); err != nil {
+		return response{Success: false, ErrorMessage: err.Error()}
+	}
+
+	keystore := handlers.backend.Keystore()
+	if keystore == nil {
+		return response{Success: false, ErrorMessage: "Keystore not found"}
+	}
+
+	accountCode, err := handlers.backend.ImportDescriptors(
+		jsonBody.CoinCode, jsonBody.Descriptors, jsonBody.Name, keystore)
+	if err != nil {
+		handlers.log.WithError(err).Error("Could not import descriptors")
+		if errCode, ok := errp.Cause(err).(errp.ErrorCode); ok {
+			return response{Success: false, ErrorCode: string(errCode)}
+		}
+		return response{Success: false, ErrorMessage: err.Error()}
+	}
+	return response{Success: true, AccountCode: accountCode}
+}
+
+func (handlers *Handlers) postAddWatchonlyAccount(r *http.Request) interface{} {
+	var jsonBody struct {
+		CoinCode                 coinpkg.Code       `json:"coinCode"`
+		ExtendedKeyOrDescriptors string             `json:"extendedKeyOrDescriptors"`
+		ScriptType               signing.ScriptType `json:"scriptType"`
+		Name                     string             `json:"name"`
+	}
+
+	type response struct {
+		Success      bool               `json:"success"`
+		AccountCode  accountsTypes.Code `json:"accountCode,omitempty"`
+		ErrorMessage string             `json:"errorMessage,omitempty"`
+		ErrorCode    string             `json:"errorCode,omitempty"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&jsonBody); err != nil {
+		return response{Success: false, ErrorMessage: err.Error()}
+	}
+
+	accountCode, err := handlers.backend.CreateWatchonlyAccount(
+		jsonBody.CoinCode, jsonBody.ExtendedKeyOrDescriptors, jsonBody.ScriptType, jsonBody.Name)
+	if err != nil {
+		handlers.log.WithError(err).Error("Could not add watch-only account")
+		if errCode, ok := errp.Cause(err).(errp.ErrorCode); ok {
+			return response{Success: false, ErrorCode: string(errCode)}
+		}
+		return response{Success: false, ErrorMessage: err.Error()}
+	}
+	return response{Success: true, AccountCode: accountCode}
+}
+
+func (handlers *Handlers) postMultisigKey(r *http.Request) interface{} {
+	var jsonBody struct {
+		CoinCode      coinpkg.Code       `json:"coinCode"`
+		ScriptType    signing.ScriptType `json:"scriptType"`
+		AccountNumber uint16             `json:"accountNumber"`
+	}
+
+	type response struct {
+		Success      bool   `json:"success"`
+		Key          string `json:"key,omitempty"`
+		ErrorMessage string `json:"errorMessage,omitempty"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&jsonBody); err != nil {
+		return response{Success: false, ErrorMessage: err.Error()}
+	}
+
+	keystore := handlers.backend.Keystore()
+	if keystore == nil {
+		return response{Success: false, ErrorMessage: "Keystore not found"}
+	}
+
+	key, err := handlers.backend.MultisigKey(
+		jsonBody.CoinCode, jsonBody.ScriptType, jsonBody.AccountNumber, keystore)
+	if err != nil {
+		handlers.log.WithError(err).Error("Could not get multisig key")
+		return response{Success: false, ErrorMessage: err.Error()}
+	}
+	return response{Success: true, Key: key}
+}
+
+func (handlers *Handlers) postAddMultisigAccount(r *http.Request) interface{} {
+	var jsonBody struct {
+		CoinCode      coinpkg.Code       `json:"coinCode"`
+		ScriptType    signing.ScriptType `json:"scriptType"`
+		AccountNumber uint16             `json:"accountNumber"`
+		Threshold     int                `json:"threshold"`
+		CosignerKeys  []string           `json:"cosignerKeys"`
+		Name          string             `json:"name"`
+	}
+
+	type response struct {
+		Success      bool               `json:"success"`
+		AccountCode  accountsTypes.Code `json:"accountCode,omitempty"`
+		Aborted      bool               `json:"aborted,omitempty"`
+		ErrorMessage string             `json:"errorMessage,omitempty"`
+		ErrorCode    string             `json:"errorCode,omitempty"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&jsonBody); err != nil {
+		return response{Success: false, ErrorMessage: err.Error()}
+	}
+
+	connectedKeystore := handlers.backend.Keystore()
+	if connectedKeystore == nil {
+		return response{Success: false, ErrorMessage: "Keystore not found"}
+	}
+
+	accountCode, err := handlers.backend.CreateMultisigAccount(&backend.MultisigAccountArgs{
+		CoinCode:      jsonBody.CoinCode,
+		ScriptType:    jsonBody.ScriptType,
+		AccountNumber: jsonBody.AccountNumber,
+		Threshold:     jsonBody.Threshold,
+		CosignerKeys:  jsonBody.CosignerKeys,
+		Name:          jsonBody.Name,
+	}, connectedKeystore)
+	if errp.Cause(err) == keystore.ErrSigningAborted {
+		return response{Success: false, Aborted: true}
+	}
+	if err != nil {
+		handlers.log.WithError(err).Error("Could not add multisig account")
+		if errCode, ok := errp.Cause(err).(errp.ErrorCode); ok {
+			return response{Success: false, ErrorCode: string(errCode)}
+		}
+		return response{Success: false, ErrorMessage: err.Error()}
+	}
+	return response{Success: true, AccountCode: accountCode}
+}
+
 func (handlers *Handlers) getKeystores(*http.Request) interface{} {
 	type json struct {
 		Type keystore.Type `json:"type"`
@@ -905,6 +1075,66 @@ func (handlers *Handlers) postRenameAccount(r *http.Request) interface{} {
 	return response{Success: true}
 }
 
+func (handlers *Handlers) postSetAccountGapLimits(r *http.Request) interface{} {
+	var jsonBody struct {
+		AccountCode accountsTypes.Code `json:"accountCode"`
+		GapLimits   *config.GapLimits  `json:"gapLimits"`
+	}
+
+	type response struct {
+		Success      bool   `json:"success"`
+		ErrorMessage string `json:"errorMessage,omitempty"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&jsonBody); err != nil {
+		return response{Success: false, ErrorMessage: err.Error()}
+	}
+	if err := handlers.backend.SetAccountGapLimits(jsonBody.AccountCode, jsonBody.GapLimits); err != nil {
+		return response{Success: false, ErrorMessage: err.Error()}
+	}
+	return response{Success: true}
+}
+
+func (handlers *Handlers) postSetAccountSilentPayments(r *http.Request) interface{} {
+	var jsonBody struct {
+		AccountCode accountsTypes.Code `json:"accountCode"`
+		Enabled     bool               `json:"enabled"`
+	}
+
+	type response struct {
+		Success      bool   `json:"success"`
+		ErrorMessage string `json:"errorMessage,omitempty"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&jsonBody); err != nil {
+		return response{Success: false, ErrorMessage: err.Error()}
+	}
+	if err := handlers.backend.SetAccountSilentPayments(jsonBody.AccountCode, jsonBody.Enabled); err != nil {
+		return response{Success: false, ErrorMessage: err.Error()}
+	}
+	return response{Success: true}
+}
+
+func (handlers *Handlers) postSetAccountDustProtection(r *http.Request) interface{} {
+	var jsonBody struct {
+		AccountCode    accountsTypes.Code     `json:"accountCode"`
+		DustProtection *config.DustProtection `json:"dustProtection"`
+	}
+
+	type response struct {
+		Success      bool   `json:"success"`
+		ErrorMessage string `json:"errorMessage,omitempty"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&jsonBody); err != nil {
+		return response{Success: false, ErrorMessage: err.Error()}
+	}
+	if err := handlers.backend.SetAccountDustProtection(jsonBody.AccountCode, jsonBody.DustProtection); err != nil {
+		return response{Success: false, ErrorMessage: err.Error()}
+	}
+	return response{Success: true}
+}
+
 func (handlers *Handlers) postAccountsReinitialize(*http.Request) interface{} {
 	handlers.backend.ReinitializeAccounts()
 	return nil
@@ -1049,6 +1279,56 @@ func (handlers *Handlers) getHeadersStatus(coinCode coinpkg.Code) func(*http.Req
 	}
 }
 
+// getElectrumServers returns the health status of each configured Electrum server of a coin.
+func (handlers *Handlers) getElectrumServers(r *http.Request) (interface{}, error) {
+	type serverStatusJSON struct {
+		Server          string                   `json:"server"`
+		Connected       bool                     `json:"connected"`
+		ServerVersion   string                   `json:"serverVersion"`
+		ProtocolVersion string                   `json:"protocolVersion"`
+		LatencyMs       int64                    `json:"latencyMs"`
+		TipHeight       int                      `json:"tipHeight"`
+		TipLag          int                      `json:"tipLag"`
+		Requests        int                      `json:"requests"`
+		Errors          int                      `json:"errors"`
+		LastError       string                   `json:"lastError"`
+		Score           int                      `json:"score"`
+		Issues          []blockchain.ServerIssue `json:"issues"`
+		Avoided         bool                     `json:"avoided"`
+	}
+	coin, err := handlers.backend.Coin(coinpkg.Code(mux.Vars(r)["code"]))
+	if err != nil {
+		return nil, err
+	}
+	btcCoin, ok := coin.(*btc.Coin)
+	if !ok {
+		return nil, errp.Newf("%s does not use Electrum servers", coin.Code())
+	}
+	result := []serverStatusJSON{}
+	if btcCoin.Blockchain() == nil {
+		// Not initialized yet.
+		return result, nil
+	}
+	for _, status := range btcCoin.Blockchain().ServerStatus() {
+		result = append(result, serverStatusJSON{
+			Server:          status.Server,
+			Connected:       status.Connected,
+			ServerVersion:   status.ServerVersion,
+			ProtocolVersion: status.ProtocolVersion,
+			LatencyMs:       status.Latency.Milliseconds(),
+			TipHeight:       status.TipHeight,
+			TipLag:          status.TipLag,
+			Requests:        status.Requests,
+			Errors:          status.Errors,
+			LastError:       status.LastError,
+			Score:           status.Score,
+			Issues:          status.Issues,
+			Avoided:         status.Avoided,
+		})
+	}
+	return result, nil
+}
+
 func (handlers *Handlers) postCertsDownload(r *http.Request) interface{} {
 	var server string
 	if err := json.NewDecoder(r.Body).Decode(&server); err != nil {
@@ -1529,6 +1809,53 @@ func (handlers *Handlers) postAOPPApprove(r *http.Request) interface{} {
 	return nil
 }
 
+func (handlers *Handlers) getPaymentURI(r *http.Request) interface{} {
+	return handlers.backend.PaymentURI()
+}
+
+func (handlers *Handlers) postPaymentURIChooseAccount(r *http.Request) (interface{}, error) {
+	var request struct {
+		AccountCode accountsTypes.Code `json:"accountCode"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return nil, errp.WithStack(err)
+	}
+
+	handlers.backend.PaymentURIChooseAccount(request.AccountCode)
+	return nil, nil
+}
+
+func (handlers *Handlers) postPaymentURIClear(r *http.Request) interface{} {
+	handlers.backend.PaymentURIClear()
+	return nil
+}
+
+func (handlers *Handlers) postVerifyMessage(r *http.Request) interface{} {
+	var jsonBody struct {
+		CoinCode  coinpkg.Code `json:"coinCode"`
+		Address   string       `json:"address"`
+		Message   string       `json:"message"`
+		Signature string       `json:"signature"`
+		TypedData bool         `json:"typedData"`
+	}
+
+	type response struct {
+		Success      bool                     `json:"success"`
+		Result       *backend.VerifiedMessage `json:"result,omitempty"`
+		ErrorMessage string                   `json:"errorMessage,omitempty"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&jsonBody); err != nil {
+		return response{Success: false, ErrorMessage: err.Error()}
+	}
+	result, err := handlers.backend.VerifyMessage(
+		jsonBody.CoinCode, jsonBody.Address, jsonBody.Message, jsonBody.Signature, jsonBody.TypedData)
+	if err != nil {
+		return response{Success: false, ErrorMessage: err.Error()}
+	}
+	return response{Success: true, Result: result}
+}
+
 func (handlers *Handlers) postCancelConnectKeystore(r *http.Request) interface{} {
 	handlers.backend.CancelConnectKeystore()
 	return nil
@@ -21,6 +21,7 @@ import (
 	btctypes "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/types"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
+	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/btcsuite/btcd/btcutil/hdkeychain"
 	"github.com/ethereum/go-ethereum/core/types"
 )
@@ -50,6 +51,9 @@ var (
 	ErrFirmwareUpgradeRequired = KeystoreError("firmwareUpgradeRequired")
 	// ErrUnsupportedFeature is returned when a certain feature is unsupported by the keystore.
 	ErrUnsupportedFeature = KeystoreError("unsupportedFeature")
+	// ErrCannotSign is returned when signing is requested for a watch-only account whose keystore
+	// was never connected, e.g. an account added from an xpub.
+	ErrCannotSign = KeystoreError("cannotSign")
 )
 
 // ErrSigningAborted is used when the user aborts a signing in process (e.g. abort on HW wallet).
@@ -146,4 +150,20 @@ type Keystore interface {
 
 	// SupportsPaymentRequests returns nil if the device supports silent payments, or an error indicating why it is not supported.
 	SupportsPaymentRequests() error
+
+	// SupportsForeignInputs returns true if the keystore can sign BTC/LTC transactions which also
+	// spend inputs of other wallets, like payjoin transactions (BIP-78).
+	SupportsForeignInputs() bool
+
+	// RegisterBTCMultisig registers a multisig account with the keystore under the given name, so
+	// that the keystore can verify its addresses and sign its transactions. Does nothing if the
+	// account is already registered.
+	RegisterBTCMultisig(coin coin.Coin, configuration *signing.Configuration, name string) error
+
+	// SilentPaymentKeys returns the silent payment (BIP-352) keys of the account with the keypath
+	// m/352'/coin'/account': the scan private key at m/352'/coin'/account'/1'/0, which is needed to
+	// detect incoming payments, and the extended public key at m/352'/coin'/account'/0', whose child
+	// 0 is the spend public key. Returns ErrUnsupportedFeature if the keystore can't export the scan
+	// key.
+	SilentPaymentKeys(coin coin.Coin, keypath signing.AbsoluteKeypath) (*btcec.PrivateKey, *hdkeychain.ExtendedKey, error)
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/keystore"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
+	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/btcsuite/btcd/btcutil/hdkeychain"
 	"github.com/ethereum/go-ethereum/core/types"
 	"sync"
@@ -38,6 +39,9 @@ var _ keystore.Keystore = &KeystoreMock{}
 //			NameFunc: func() (string, error) {
 //				panic("mock out the Name method")
 //			},
+//			RegisterBTCMultisigFunc: func(coinMoqParam coin.Coin, configuration *signing.Configuration, name string) error {
+//				panic("mock out the RegisterBTCMultisig method")
+//			},
 //			RootFingerprintFunc: func() ([]byte, error) {
 //				panic("mock out the RootFingerprint method")
 //			},
@@ -56,6 +60,9 @@ var _ keystore.Keystore = &KeystoreMock{}
 //			SignTransactionFunc: func(ifaceVal interface{}) error {
 //				panic("mock out the SignTransaction method")
 //			},
+//			SilentPaymentKeysFunc: func(coinMoqParam coin.Coin, keypath signing.AbsoluteKeypath) (*btcec.PrivateKey, *hdkeychain.ExtendedKey, error) {
+//				panic("mock out the SilentPaymentKeys method")
+//			},
 //			SupportsAccountFunc: func(coinInstance coin.Coin, meta interface{}) bool {
 //				panic("mock out the SupportsAccount method")
 //			},
@@ -65,6 +72,9 @@ var _ keystore.Keystore = &KeystoreMock{}
 //			SupportsEIP1559Func: func() bool {
 //				panic("mock out the SupportsEIP1559 method")
 //			},
+//			SupportsForeignInputsFunc: func() bool {
+//				panic("mock out the SupportsForeignInputs method")
+//			},
 //			SupportsMultipleAccountsFunc: func() bool {
 //				panic("mock out the SupportsMultipleAccounts method")
 //			},
@@ -108,6 +118,9 @@ type KeystoreMock struct {
 	// NameFunc mocks the Name method.
 	NameFunc func() (string, error)
 
+	// RegisterBTCMultisigFunc mocks the RegisterBTCMultisig method.
+	RegisterBTCMultisigFunc func(coinMoqParam coin.Coin, configuration *signing.Configuration, name string) error
+
 	// RootFingerprintFunc mocks the RootFingerprint method.
 	RootFingerprintFunc func() ([]byte, error)
 
@@ -126,6 +139,9 @@ type KeystoreMock struct {
 	// SignTransactionFunc mocks the SignTransaction method.
 	SignTransactionFunc func(ifaceVal interface{}) error
 
+	// SilentPaymentKeysFunc mocks the SilentPaymentKeys method.
+	SilentPaymentKeysFunc func(coinMoqParam coin.Coin, keypath signing.AbsoluteKeypath) (*btcec.PrivateKey, *hdkeychain.ExtendedKey, error)
+
 	// SupportsAccountFunc mocks the SupportsAccount method.
 	SupportsAccountFunc func(coinInstance coin.Coin, meta interface{}) bool
 
@@ -135,6 +151,9 @@ type KeystoreMock struct {
 	// SupportsEIP1559Func mocks the SupportsEIP1559 method.
 	SupportsEIP1559Func func() bool
 
+	// SupportsForeignInputsFunc mocks the SupportsForeignInputs method.
+	SupportsForeignInputsFunc func() bool
+
 	// SupportsMultipleAccountsFunc mocks the SupportsMultipleAccounts method.
 	SupportsMultipleAccountsFunc func() bool
 
@@ -181,6 +200,15 @@ type KeystoreMock struct {
 		// Name holds details about calls to the Name method.
 		Name []struct {
 		}
+		// RegisterBTCMultisig holds details about calls to the RegisterBTCMultisig method.
+		RegisterBTCMultisig []struct {
+			// CoinMoqParam is the coinMoqParam argument value.
+			CoinMoqParam coin.Coin
+			// Configuration is the configuration argument value.
+			Configuration *signing.Configuration
+			// Name is the name argument value.
+			Name string
+		}
 		// RootFingerprint holds details about calls to the RootFingerprint method.
 		RootFingerprint []struct {
 		}
@@ -223,6 +251,13 @@ type KeystoreMock struct {
 			// IfaceVal is the ifaceVal argument value.
 			IfaceVal interface{}
 		}
+		// SilentPaymentKeys holds details about calls to the SilentPaymentKeys method.
+		SilentPaymentKeys []struct {
+			// CoinMoqParam is the coinMoqParam argument value.
+			CoinMoqParam coin.Coin
+			// Keypath is the keypath argument value.
+			Keypath signing.AbsoluteKeypath
+		}
 		// SupportsAccount holds details about calls to the SupportsAccount method.
 		SupportsAccount []struct {
 			// CoinInstance is the coinInstance argument value.
@@ -238,6 +273,9 @@ type KeystoreMock struct {
 		// SupportsEIP1559 holds details about calls to the SupportsEIP1559 method.
 		SupportsEIP1559 []struct {
 		}
+		// SupportsForeignInputs holds details about calls to the SupportsForeignInputs method.
+		SupportsForeignInputs []struct {
+		}
 		// SupportsMultipleAccounts holds details about calls to the SupportsMultipleAccounts method.
 		SupportsMultipleAccounts []struct {
 		}
@@ -279,15 +317,18 @@ type KeystoreMock struct {
 	lockCanVerifyExtendedPublicKey      sync.RWMutex
 	lockExtendedPublicKey               sync.RWMutex
 	lockName                            sync.RWMutex
+	lockRegisterBTCMultisig             sync.RWMutex
 	lockRootFingerprint                 sync.RWMutex
 	lockSignBTCMessage                  sync.RWMutex
 	lockSignETHMessage                  sync.RWMutex
 	lockSignETHTypedMessage             sync.RWMutex
 	lockSignETHWalletConnectTransaction sync.RWMutex
 	lockSignTransaction                 sync.RWMutex
+	lockSilentPaymentKeys               sync.RWMutex
 	lockSupportsAccount                 sync.RWMutex
 	lockSupportsCoin                    sync.RWMutex
 	lockSupportsEIP1559                 sync.RWMutex
+	lockSupportsForeignInputs           sync.RWMutex
 	lockSupportsMultipleAccounts        sync.RWMutex
 	lockSupportsPaymentRequests         sync.RWMutex
 	lockSupportsUnifiedAccounts         sync.RWMutex
@@ -451,6 +492,46 @@ func (mock *KeystoreMock) NameCalls() []struct {
 	return calls
 }
 
+// RegisterBTCMultisig calls RegisterBTCMultisigFunc.
+func (mock *KeystoreMock) RegisterBTCMultisig(coinMoqParam coin.Coin, configuration *signing.Configuration, name string) error {
+	if mock.RegisterBTCMultisigFunc == nil {
+		panic("KeystoreMock.RegisterBTCMultisigFunc: method is nil but Keystore.RegisterBTCMultisig was just called")
+	}
+	callInfo := struct {
+		CoinMoqParam  coin.Coin
+		Configuration *signing.Configuration
+		Name          string
+	}{
+		CoinMoqParam:  coinMoqParam,
+		Configuration: configuration,
+		Name:          name,
+	}
+	mock.lockRegisterBTCMultisig.Lock()
+	mock.calls.RegisterBTCMultisig = append(mock.calls.RegisterBTCMultisig, callInfo)
+	mock.lockRegisterBTCMultisig.Unlock()
+	return mock.RegisterBTCMultisigFunc(coinMoqParam, configuration, name)
+}
+
+// RegisterBTCMultisigCalls gets all the calls that were made to RegisterBTCMultisig.
+// Check the length with:
+//
+//	len(mockedKeystore.RegisterBTCMultisigCalls())
+func (mock *KeystoreMock) RegisterBTCMultisigCalls() []struct {
+	CoinMoqParam  coin.Coin
+	Configuration *signing.Configuration
+	Name          string
+} {
+	var calls []struct {
+		CoinMoqParam  coin.Coin
+		Configuration *signing.Configuration
+		Name          string
+	}
+	mock.lockRegisterBTCMultisig.RLock()
+	calls = mock.calls.RegisterBTCMultisig
+	mock.lockRegisterBTCMultisig.RUnlock()
+	return calls
+}
+
 // RootFingerprint calls RootFingerprintFunc.
 func (mock *KeystoreMock) RootFingerprint() ([]byte, error) {
 	if mock.RootFingerprintFunc == nil {
@@ -666,6 +747,42 @@ func (mock *KeystoreMock) SignTransactionCalls() []struct {
 	return calls
 }
 
+// SilentPaymentKeys calls SilentPaymentKeysFunc.
+func (mock *KeystoreMock) SilentPaymentKeys(coinMoqParam coin.Coin, keypath signing.AbsoluteKeypath) (*btcec.PrivateKey, *hdkeychain.ExtendedKey, error) {
+	if mock.SilentPaymentKeysFunc == nil {
+		panic("KeystoreMock.SilentPaymentKeysFunc: method is nil but Keystore.SilentPaymentKeys was just called")
+	}
+	callInfo := struct {
+		CoinMoqParam coin.Coin
+		Keypath      signing.AbsoluteKeypath
+	}{
+		CoinMoqParam: coinMoqParam,
+		Keypath:      keypath,
+	}
+	mock.lockSilentPaymentKeys.Lock()
+	mock.calls.SilentPaymentKeys = append(mock.calls.SilentPaymentKeys, callInfo)
+	mock.lockSilentPaymentKeys.Unlock()
+	return mock.SilentPaymentKeysFunc(coinMoqParam, keypath)
+}
+
+// SilentPaymentKeysCalls gets all the calls that were made to SilentPaymentKeys.
+// Check the length with:
+//
+//	len(mockedKeystore.SilentPaymentKeysCalls())
+func (mock *KeystoreMock) SilentPaymentKeysCalls() []struct {
+	CoinMoqParam coin.Coin
+	Keypath      signing.AbsoluteKeypath
+} {
+	var calls []struct {
+		CoinMoqParam coin.Coin
+		Keypath      signing.AbsoluteKeypath
+	}
+	mock.lockSilentPaymentKeys.RLock()
+	calls = mock.calls.SilentPaymentKeys
+	mock.lockSilentPaymentKeys.RUnlock()
+	return calls
+}
+
 // SupportsAccount calls SupportsAccountFunc.
 func (mock *KeystoreMock) SupportsAccount(coinInstance coin.Coin, meta interface{}) bool {
 	if mock.SupportsAccountFunc == nil {
@@ -761,6 +878,33 @@ func (mock *KeystoreMock) SupportsEIP1559Calls() []struct {
 	return calls
 }
 
+// SupportsForeignInputs calls SupportsForeignInputsFunc.
+func (mock *KeystoreMock) SupportsForeignInputs() bool {
+	if mock.SupportsForeignInputsFunc == nil {
+		panic("KeystoreMock.SupportsForeignInputsFunc: method is nil but Keystore.SupportsForeignInputs was just called")
+	}
+	callInfo := struct {
+	}{}
+	mock.lockSupportsForeignInputs.Lock()
+	mock.calls.SupportsForeignInputs = append(mock.calls.SupportsForeignInputs, callInfo)
+	mock.lockSupportsForeignInputs.Unlock()
+	return mock.SupportsForeignInputsFunc()
+}
+
+// SupportsForeignInputsCalls gets all the calls that were made to SupportsForeignInputs.
+// Check the length with:
+//
+//	len(mockedKeystore.SupportsForeignInputsCalls())
+func (mock *KeystoreMock) SupportsForeignInputsCalls() []struct {
+} {
+	var calls []struct {
+	}
+	mock.lockSupportsForeignInputs.RLock()
+	calls = mock.calls.SupportsForeignInputs
+	mock.lockSupportsForeignInputs.RUnlock()
+	return calls
+}
+
 // SupportsMultipleAccounts calls SupportsMultipleAccountsFunc.
 func (mock *KeystoreMock) SupportsMultipleAccounts() bool {
 	if mock.SupportsMultipleAccountsFunc == nil {
@@ -23,6 +23,7 @@ import (
 	"math/big"
 
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/silentpayments"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/types"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth"
@@ -30,6 +31,7 @@ import (
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/util/logging"
+	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
 	"github.com/btcsuite/btcd/btcec/v2/schnorr"
 	"github.com/btcsuite/btcd/btcutil/hdkeychain"
@@ -128,7 +130,9 @@ func (keystore *Keystore) SupportsAccount(coin coin.Coin, meta interface{}) bool
 		return scriptType == signing.ScriptTypeP2PKH ||
 			scriptType == signing.ScriptTypeP2WPKHP2SH ||
 			scriptType == signing.ScriptTypeP2WPKH ||
-			scriptType == signing.ScriptTypeP2TR
+			scriptType == signing.ScriptTypeP2TR ||
+			scriptType == signing.ScriptTypeP2WSH ||
+			scriptType == signing.ScriptTypeP2WSHP2SH
 	case *eth.Coin:
 		return true
 	default:
@@ -187,6 +191,29 @@ func (keystore *Keystore) ExtendedPublicKey(
 	return extendedPrivateKey.Neuter()
 }
 
+// SilentPaymentKeys implements keystore.Keystore.
+func (keystore *Keystore) SilentPaymentKeys(
+	coin coin.Coin, keypath signing.AbsoluteKeypath,
+) (*btcec.PrivateKey, *hdkeychain.ExtendedKey, error) {
+	scanXprv, err := keypath.Child(1, signing.Hardened).Child(0, signing.NonHardened).Derive(keystore.master)
+	if err != nil {
+		return nil, nil, err
+	}
+	scanKey, err := scanXprv.ECPrivKey()
+	if err != nil {
+		return nil, nil, errp.WithStack(err)
+	}
+	spendXprv, err := keypath.Child(0, signing.Hardened).Derive(keystore.master)
+	if err != nil {
+		return nil, nil, err
+	}
+	spendXpub, err := spendXprv.Neuter()
+	if err != nil {
+		return nil, nil, errp.WithStack(err)
+	}
+	return scanKey, spendXpub, nil
+}
+
 func (keystore *Keystore) signBTCTransaction(btcProposedTx *btc.ProposedTransaction) error {
 	keystore.log.Info("Sign transaction.")
 	transaction := btcProposedTx.TXProposal.Transaction
@@ -198,6 +225,11 @@ func (keystore *Keystore) signBTCTransaction(btcProposedTx *btc.ProposedTransact
 			keystore.log.Error("There needs to be exactly one output being spent per input.")
 			return errp.New("There needs to be exactly one output being spent per input.")
 		}
+		if spentOutput.Address == nil {
+			// Inputs of other wallets, e.g. of the receiver of a payjoin transaction, are signed by
+			// their owner.
+			continue
+		}
 		address, _, err := btcProposedTx.GetKeystoreAddress(btcProposedTx.SendingAccount, spentOutput.Address.PubkeyScriptHashHex())
 		if err != nil {
 			return err
@@ -213,7 +245,14 @@ func (keystore *Keystore) signBTCTransaction(btcProposedTx *btc.ProposedTransact
 		}
 
 		if address.AccountConfiguration.ScriptType() == signing.ScriptTypeP2TR {
-			prv = txscript.TweakTaprootPrivKey(*prv, nil)
+			if tweak := address.SilentPaymentTweak(); tweak != nil {
+				prv, err = silentpayments.SpendPrivateKey(prv, tweak)
+				if err != nil {
+					return err
+				}
+			} else {
+				prv = txscript.TweakTaprootPrivKey(*prv, nil)
+			}
 			signatureHash, err := txscript.CalcTaprootSignatureHash(
 				sigHashes, txscript.SigHashDefault, transaction,
 				index, btcProposedTx.TXProposal.PreviousOutputs)
@@ -324,3 +363,16 @@ func (keystore *Keystore) SupportsEIP1559() bool {
 func (keystore *Keystore) SupportsPaymentRequests() error {
 	return keystorePkg.ErrUnsupportedFeature
 }
+
+// SupportsForeignInputs implements keystore.Keystore. Inputs of other wallets are skipped when
+// signing.
+func (keystore *Keystore) SupportsForeignInputs() bool {
+	return true
+}
+
+// RegisterBTCMultisig implements keystore.Keystore. There is nothing to register, as all keys are
+// derived from the master key.
+func (keystore *Keystore) RegisterBTCMultisig(
+	coin coin.Coin, configuration *signing.Configuration, name string) error {
+	return nil
+}
@@ -17,6 +17,7 @@ package software
 import (
 	"testing"
 
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
 	"github.com/btcsuite/btcd/btcutil/hdkeychain"
 	"github.com/stretchr/testify/require"
 )
@@ -32,3 +33,24 @@ func TestRootFingerprint(t *testing.T) {
 	// Verified by comparing to the root fingerprint produced by the BitBox02 and Electrum.
 	require.Equal(t, []byte{0xfb, 0x70, 0x89, 0xbd}, rootFingerprint)
 }
+
+func TestSilentPaymentKeys(t *testing.T) {
+	rootXprv, err := hdkeychain.NewKeyFromString("xprv9s21ZrQH143K3uDh9hiNXB3a9GVzcCujEmCwmZA9g8m4i5nUDVdLHJjsLMPzV26vj8Q7ceGrUhX119Y3XzGhJqq5K6LWP1h6gjv2cbkMEH1")
+	require.NoError(t, err)
+	keystore := NewKeystore(rootXprv)
+	keypath, err := signing.NewAbsoluteKeypath("m/352'/1'/0'")
+	require.NoError(t, err)
+	scanKey, spendXpub, err := keystore.SilentPaymentKeys(nil, keypath)
+	require.NoError(t, err)
+
+	scanXpub, err := keystore.ExtendedPublicKey(nil, keypath.Child(1, signing.Hardened).Child(0, signing.NonHardened))
+	require.NoError(t, err)
+	scanPublicKey, err := scanXpub.ECPubKey()
+	require.NoError(t, err)
+	require.True(t, scanPublicKey.IsEqual(scanKey.PubKey()))
+
+	require.False(t, spendXpub.IsPrivate())
+	expectedSpendXpub, err := keystore.ExtendedPublicKey(nil, keypath.Child(0, signing.Hardened))
+	require.NoError(t, err)
+	require.Equal(t, expectedSpendXpub.String(), spendXpub.String())
+}
@@ -0,0 +1,203 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"fmt"
+	"sort"
+
+	accountsTypes "github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/types"
+	coinpkg "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/config"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/keystore"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+)
+
+// maxMultisigNameLength is the maximum length of the name under which a multisig account is
+// registered on the keystore.
+const maxMultisigNameLength = 30
+
+// multisigKeypath returns the BIP-48 keypath of our key in a multisig wallet:
+// m/48'/coin'/account'/script', where script is 2' for P2WSH and 1' for P2SH-P2WSH.
+func multisigKeypath(
+	coinCode coinpkg.Code, scriptType signing.ScriptType, accountNumber uint16) (signing.AbsoluteKeypath, error) {
+	var bip44Coin uint32
+	switch coinCode {
+	case coinpkg.CodeBTC:
+		bip44Coin = hardenedKeystart
+	case coinpkg.CodeTBTC, coinpkg.CodeRBTC:
+		bip44Coin = 1 + hardenedKeystart
+	default:
+		return nil, errp.Newf("Multisig is not supported for %s", coinCode)
+	}
+	var scriptTypeNumber uint32
+	switch scriptType {
+	case signing.ScriptTypeP2WSH:
+		scriptTypeNumber = 2 + hardenedKeystart
+	case signing.ScriptTypeP2WSHP2SH:
+		scriptTypeNumber = 1 + hardenedKeystart
+	default:
+		return nil, errp.Newf("Unsupported multisig script type: %s", scriptType)
+	}
+	if accountNumber >= accountsHardLimit {
+		return nil, errp.WithStack(errAccountLimitReached)
+	}
+	return signing.NewAbsoluteKeypathFromUint32(
+		48+hardenedKeystart,
+		bip44Coin,
+		uint32(accountNumber)+hardenedKeystart,
+		scriptTypeNumber,
+	), nil
+}
+
+// multisigKeyInfo returns the key info of our key in a multisig wallet.
+func (backend *Backend) multisigKeyInfo(
+	coinCode coinpkg.Code,
+	scriptType signing.ScriptType,
+	accountNumber uint16,
+	keystore keystore.Keystore,
+) (*signing.KeyInfo, error) {
+	coin, err := backend.Coin(coinCode)
+	if err != nil {
+		return nil, err
+	}
+	if !keystore.SupportsAccount(coin, scriptType) {
+		return nil, errp.Newf("The keystore does not support %s multisig accounts for %s", scriptType, coinCode)
+	}
+	keypath, err := multisigKeypath(coinCode, scriptType, accountNumber)
+	if err != nil {
+		return nil, err
+	}
+	rootFingerprint, err := keystore.RootFingerprint()
+	if err != nil {
+		return nil, err
+	}
+	extendedPublicKey, err := keystore.ExtendedPublicKey(coin, keypath)
+	if err != nil {
+		return nil, err
+	}
+	return &signing.KeyInfo{
+		RootFingerprint:   rootFingerprint,
+		AbsoluteKeypath:   keypath,
+		ExtendedPublicKey: extendedPublicKey,
+	}, nil
+}
+
+// MultisigKey returns our key for a multisig wallet with the given script type, encoded with its
+// key origin, e.g. `[5555aaaa/48'/0'/0'/2']xpub...`. This is shared with the cosigners, who import
+// it into their wallets.
+func (backend *Backend) MultisigKey(
+	coinCode coinpkg.Code,
+	scriptType signing.ScriptType,
+	accountNumber uint16,
+	keystore keystore.Keystore,
+) (string, error) {
+	keyInfo, err := backend.multisigKeyInfo(coinCode, scriptType, accountNumber, keystore)
+	if err != nil {
+		return "", err
+	}
+	return keyInfo.Encode(), nil
+}
+
+// MultisigAccountArgs are the arguments of CreateMultisigAccount().
+type MultisigAccountArgs struct {
+	CoinCode   coinpkg.Code
+	ScriptType signing.ScriptType
+	// AccountNumber is the account number of our key, see MultisigKey().
+	AccountNumber uint16
+	// Threshold is the number of signatures needed to spend.
+	Threshold int
+	// CosignerKeys are the keys of the other cosigners, encoded with their key origin as returned
+	// by MultisigKey().
+	CosignerKeys []string
+	// Name is the account name. If empty, a default name is set.
+	Name string
+}
+
+// CreateMultisigAccount creates a k-of-n multisig account shared between our keystore and the
+// cosigners, registers it on the keystore and persists it. Transactions of the account are signed
+// by the cosigners by exchanging PSBTs.
+func (backend *Backend) CreateMultisigAccount(
+	args *MultisigAccountArgs, keystore keystore.Keystore) (accountsTypes.Code, error) {
+	ourKeyInfo, err := backend.multisigKeyInfo(args.CoinCode, args.ScriptType, args.AccountNumber, keystore)
+	if err != nil {
+		return "", err
+	}
+	keyInfos := []signing.KeyInfo{*ourKeyInfo}
+	for _, cosignerKey := range args.CosignerKeys {
+		keyInfo, err := signing.ParseKeyInfo(cosignerKey)
+		if err != nil {
+			return "", err
+		}
+		keyInfos = append(keyInfos, *keyInfo)
+	}
+	// The order of the keys does not matter for the addresses, which use sorted keys. We sort
+	// them so that the account code does not depend on the order in which they were entered.
+	ourXPub := ourKeyInfo.ExtendedPublicKey.String()
+	sort.Slice(keyInfos, func(i, j int) bool {
+		return keyInfos[i].ExtendedPublicKey.String() < keyInfos[j].ExtendedPublicKey.String()
+	})
+	ourKeyIndex := 0
+	for i, keyInfo := range keyInfos {
+		if keyInfo.ExtendedPublicKey.String() == ourXPub {
+			ourKeyIndex = i
+		}
+	}
+	signingConfiguration, err := signing.NewBitcoinMultisigConfiguration(
+		args.ScriptType, args.Threshold, keyInfos, ourKeyIndex)
+	if err != nil {
+		return "", err
+	}
+
+	name := args.Name
+	if name == "" {
+		name = fmt.Sprintf("%d-of-%d multisig", args.Threshold, len(keyInfos))
+	}
+	if len(name) > maxMultisigNameLength {
+		return "", errp.Newf("The account name must not be longer than %d characters", maxMultisigNameLength)
+	}
+	coin, err := backend.Coin(args.CoinCode)
+	if err != nil {
+		return "", err
+	}
+	// The keystore must know the cosigners to be able to verify receive addresses and to identify
+	// the change output when signing.
+	if err := keystore.RegisterBTCMultisig(coin, signingConfiguration, name); err != nil {
+		return "", err
+	}
+
+	accountCode := multisigAccountCode(
+		ourKeyInfo.RootFingerprint, args.CoinCode, signingConfiguration.BitcoinMultisig)
+	err = backend.config.ModifyAccountsConfig(func(accountsConfig *config.AccountsConfig) error {
+		var accountWatch *bool
+		if accountsConfig.IsKeystoreWatchonly(ourKeyInfo.RootFingerprint) {
+			t := true
+			accountWatch = &t
+		}
+		return backend.persistAccount(config.Account{
+			Watch:                 accountWatch,
+			CoinCode:              args.CoinCode,
+			Name:                  name,
+			Code:                  accountCode,
+			SigningConfigurations: signing.Configurations{signingConfiguration},
+		}, accountsConfig)
+	})
+	if err != nil {
+		return "", err
+	}
+	backend.ReinitializeAccounts()
+	return accountCode, nil
+}
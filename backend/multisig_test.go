@@ -0,0 +1,106 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"strings"
+	"testing"
+
+	coinpkg "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateMultisigAccount(t *testing.T) {
+	b := newBackend(t, testnetDisabled, regtestDisabled)
+	defer b.Close()
+
+	ks := makeBitBox02Multi()
+	var registeredNames []string
+	ks.RegisterBTCMultisigFunc = func(
+		coin coinpkg.Coin, configuration *signing.Configuration, name string) error {
+		require.NotNil(t, configuration.BitcoinMultisig)
+		registeredNames = append(registeredNames, name)
+		return nil
+	}
+	b.registerKeystore(ks)
+
+	ourKey, err := b.MultisigKey(coinpkg.CodeBTC, signing.ScriptTypeP2WSH, 0, ks)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(ourKey, "[55555555/48'/0'/0'/2']xpub"), ourKey)
+
+	btcCoin, err := b.Coin(coinpkg.CodeBTC)
+	require.NoError(t, err)
+	cosignerXPub, err := keystoreHelper2().ExtendedPublicKey(btcCoin, mustKeypath("m/48'/0'/0'/2'"))
+	require.NoError(t, err)
+	cosignerKey := signing.KeyInfo{
+		RootFingerprint:   rootFingerprint2,
+		AbsoluteKeypath:   mustKeypath("m/48'/0'/0'/2'"),
+		ExtendedPublicKey: cosignerXPub,
+	}.Encode()
+
+	_, err = b.CreateMultisigAccount(&MultisigAccountArgs{
+		CoinCode:     coinpkg.CodeBTC,
+		ScriptType:   signing.ScriptTypeP2WSH,
+		Threshold:    3,
+		CosignerKeys: []string{cosignerKey},
+	}, ks)
+	require.Error(t, err)
+
+	_, err = b.CreateMultisigAccount(&MultisigAccountArgs{
+		CoinCode:     coinpkg.CodeBTC,
+		ScriptType:   signing.ScriptTypeP2WSH,
+		Threshold:    2,
+		CosignerKeys: []string{ourKey},
+	}, ks)
+	require.Error(t, err)
+	require.Empty(t, registeredNames)
+
+	accountCode, err := b.CreateMultisigAccount(&MultisigAccountArgs{
+		CoinCode:     coinpkg.CodeBTC,
+		ScriptType:   signing.ScriptTypeP2WSH,
+		Threshold:    2,
+		CosignerKeys: []string{cosignerKey},
+	}, ks)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(accountCode), "v0-55555555-btc-multisig-"), accountCode)
+	require.Equal(t, []string{"2-of-2 multisig"}, registeredNames)
+
+	accountConfig := b.config.AccountsConfig().Lookup(accountCode)
+	require.NotNil(t, accountConfig)
+	require.True(t, accountConfig.IsMultisig())
+	require.Equal(t, "2-of-2 multisig", accountConfig.Name)
+	multisig := accountConfig.SigningConfigurations[0].BitcoinMultisig
+	require.Equal(t, 2, multisig.Threshold)
+	require.Len(t, multisig.KeyInfos, 2)
+	require.Equal(t, ourKey, multisig.OurKeyInfo().Encode())
+	require.NotNil(t, b.Accounts().lookup(accountCode))
+
+	// The same multisig wallet can't be added twice.
+	_, err = b.CreateMultisigAccount(&MultisigAccountArgs{
+		CoinCode:     coinpkg.CodeBTC,
+		ScriptType:   signing.ScriptTypeP2WSH,
+		Threshold:    2,
+		CosignerKeys: []string{cosignerKey},
+		Name:         "duplicate",
+	}, ks)
+	require.Equal(t, errAccountAlreadyExists, errp.Cause(err))
+
+	// Multisig accounts do not affect the account numbers of regular accounts.
+	accountCode, err = b.CreateAndPersistAccountConfig(coinpkg.CodeBTC, "bitcoin 2", ks)
+	require.NoError(t, err)
+	require.Equal(t, "v0-55555555-btc-1", string(accountCode))
+}
@@ -23,12 +23,15 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/notes"
 	accountsTypes "github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/types"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc"
+	ourbtcutil "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/util"
 	coinpkg "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/config"
@@ -54,8 +57,9 @@ type bip329BitBoxApp struct {
 type bip329Type string
 
 const (
-	bip329TypeTx   bip329Type = "tx"
-	bip329TypeXpub bip329Type = "xpub"
+	bip329TypeTx     bip329Type = "tx"
+	bip329TypeXpub   bip329Type = "xpub"
+	bip329TypeOutput bip329Type = "output"
 )
 
 // https://github.com/bitcoin/bips/blob/master/bip-0329.mediawiki#specification
@@ -64,6 +68,8 @@ type bip329Entry struct {
 	Type  bip329Type `json:"type"`
 	Ref   string     `json:"ref"`
 	Label string     `json:"label,omitempty"`
+	// Spendable is only used for outputs. We export frozen outputs as `"spendable": false`.
+	Spendable *bool `json:"spendable,omitempty"`
 
 	// We don't use the origin field currently, see the docstring of `bip329BitBoxApp` above for the
 	// reason why.
@@ -145,12 +151,33 @@ func (backend *Backend) exportNotes(writer io.Writer) error {
 				return err
 			}
 		}
+
+		frozenOutPoints := make([]string, 0, len(notesData.FrozenOutputs))
+		for outPoint := range notesData.FrozenOutputs {
+			frozenOutPoints = append(frozenOutPoints, outPoint)
+		}
+		sort.Strings(frozenOutPoints)
+		for _, outPoint := range frozenOutPoints {
+			spendable := false
+			entry := bip329Entry{
+				Type:      bip329TypeOutput,
+				Ref:       outPoint,
+				Spendable: &spendable,
+				BitBoxApp: &bip329BitBoxApp{
+					CoinCode:    account.Config().Config.CoinCode,
+					AccountCode: accountCode,
+				},
+			}
+			if err := json.NewEncoder(writer).Encode(entry); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
 
-// ExportNotes exports the transactions and accounts labels of all accounts of all
-// connected/remembered keystores. Deactivated accounts are included in the export, except for
+// ExportNotes exports the transactions and accounts labels as well as the frozen outputs of all
+// accounts of all connected/remembered keystores. Deactivated accounts are included in the export, except for
 // deactivated ERC-20 accounts. We export to a file using an extended version of BIP-329:
 // https://github.com/bitcoin/bips/blob/master/bip-0329.mediawiki
 func (backend *Backend) ExportNotes() error {
@@ -195,6 +222,8 @@ type ImportNotesResult struct {
 	AccountCount int `json:"accountCount"`
 	// TransactionCount is the number of transaction notes updated.
 	TransactionCount int `json:"transactionCount"`
+	// OutputCount is the number of outputs which were frozen or unfrozen.
+	OutputCount int `json:"outputCount"`
 }
 
 // ImportNotes imports notes from a jsonlines document according to BIP-329:
@@ -237,12 +266,15 @@ func (backend *Backend) ImportNotes(jsonLines []byte) (*ImportNotesResult, error
 
 		label := util.TruncateString(strings.TrimSpace(entry.Label), notes.MaxNoteLen)
 		ref := strings.TrimSpace(entry.Ref)
-		if label == "" || ref == "" {
+		if ref == "" {
 			continue
 		}
 
 		switch entry.Type {
 		case bip329TypeXpub:
+			if label == "" {
+				continue
+			}
 			// Import account name / label.
 			var accountCode accountsTypes.Code
 			if entry.BitBoxApp != nil {
@@ -281,6 +313,9 @@ func (backend *Backend) ImportNotes(jsonLines []byte) (*ImportNotesResult, error
 			}
 
 		case bip329TypeTx:
+			if label == "" {
+				continue
+			}
 			// Import transaction note.
 			var account accounts.Interface
 			if entry.BitBoxApp != nil {
@@ -310,6 +345,46 @@ func (backend *Backend) ImportNotes(jsonLines []byte) (*ImportNotesResult, error
 			if changed {
 				result.TransactionCount += 1
 			}
+
+		case bip329TypeOutput:
+			if entry.Spendable == nil {
+				// Output labels are not supported, only the spendable flag.
+				continue
+			}
+			outPoint, err := ourbtcutil.ParseOutPoint([]byte(ref))
+			if err != nil {
+				// Invalid output reference, skipping.
+				continue
+			}
+			var account accounts.Interface
+			if entry.BitBoxApp != nil {
+				account = backend.Accounts().lookup(entry.BitBoxApp.AccountCode)
+			} else {
+				acct, err := backend.Accounts().lookupByTransactionInternalID(outPoint.Hash.String())
+				if err != nil {
+					return nil, err
+				}
+				account = acct
+			}
+			if account == nil {
+				// Could not find account containing this output. Skipping.
+				continue
+			}
+			if _, ok := account.Coin().(*btc.Coin); !ok {
+				// Only Bitcoin-based accounts have outputs.
+				continue
+			}
+			// So `account.Notes()` is ready to use.
+			if err := account.Initialize(); err != nil {
+				return nil, err
+			}
+			changed, err := account.Notes().SetOutputFrozen(outPoint.String(), !*entry.Spendable)
+			if err != nil {
+				return nil, err
+			}
+			if changed {
+				result.OutputCount += 1
+			}
 		}
 	}
 
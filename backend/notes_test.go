@@ -34,6 +34,8 @@ import (
 	"github.com/stretchr/testify/suite"
 )
 
+const frozenTxID = "e7a8b8a7f3d4d9fc23a1e0fb5e3c37f2ea79f92e3f1b68c9e1f5d5c0a3b4c2d1"
+
 type notesTestSuite struct {
 	suite.Suite
 	backend *Backend
@@ -62,6 +64,9 @@ func (s *notesTestSuite) SetupTest() {
 					&accounts.TransactionData{
 						InternalID: "btc-tx-id",
 					},
+					&accounts.TransactionData{
+						InternalID: frozenTxID,
+					},
 				}, nil
 			case "v0-55555555-eth-0":
 				return accounts.OrderedTransactions{
@@ -135,6 +140,8 @@ func (s *notesTestSuite) TestExport() {
 	s.Require().NoError(err)
 	_, err = erc20Acct.Notes().SetTxNote("erc20-tx-id", "test erc20 note")
 	s.Require().NoError(err)
+	_, err = btcAcct.Notes().SetOutputFrozen(frozenTxID+":1", true)
+	s.Require().NoError(err)
 
 	var export bytes.Buffer
 	s.Require().NoError(s.backend.exportNotes(&export))
@@ -143,6 +150,7 @@ func (s *notesTestSuite) TestExport() {
 {"type":"xpub","ref":"xpub6CC9Tsi4eJvmRsGuXwKBfHDWUWN66voNeZFmXRJhYZS6yYgXKZmtz5qnxK9WL2FZP8uF3abyFZ29d7RfMks4FjCCu4LMh3edyeCoyEFuZLZ","label":"My BTC","bitboxapp":{"coinCode":"btc","code":"v0-55555555-btc-0"}}
 {"type":"xpub","ref":"xpub6CUmEcJb7juvnw7fFYybCwvCJuPSEdhTWZCep9X1DBznwB8RRKTYBUidbEPJ9L7ExjrXhem9S759cX3BpzSUSoP2rWh9vqumJ9MPSAbi98F","label":"My BTC","bitboxapp":{"coinCode":"btc","code":"v0-55555555-btc-0"}}
 {"type":"tx","ref":"btc-tx-id","label":"test btc note","bitboxapp":{"coinCode":"btc","code":"v0-55555555-btc-0"}}
+{"type":"output","ref":"e7a8b8a7f3d4d9fc23a1e0fb5e3c37f2ea79f92e3f1b68c9e1f5d5c0a3b4c2d1:1","spendable":false,"bitboxapp":{"coinCode":"btc","code":"v0-55555555-btc-0"}}
 {"type":"xpub","ref":"xpub6DReBHtKxgeZGBKTaaF1GjeBHa8dZwQpRfgYr3kxt782s8KKqio2pR6piBsiqHEPF7Rg3onMkwt9XrSxNTuW4N1VBjVbn6DQ3GPCBEUgtgP","label":"Litecoin","bitboxapp":{"coinCode":"ltc","code":"v0-55555555-ltc-0"}}
 {"type":"xpub","ref":"xpub6CrhULuXbYzo7gXNhSNZ6tzgfMWpwRFEisekvFfuWLtpXcV4jfvWf5yCuhRBvhZoisH4JCVp4ddGEi7XF2QE2S4N8pMkirJbp7N2TF5p5qQ","label":"Litecoin","bitboxapp":{"coinCode":"ltc","code":"v0-55555555-ltc-0"}}
 {"type":"xpub","ref":"xpub6GP83vJASH1kS7dQPWXFjVHDfYajopbG8U3j8peBH67CRCnb8QmDxZJfWpbgCQNHAzCDJ4MyVYjoh7Yv9yo7PQuZ9YyktgrtD9vmeo67Y4E","label":"My ETH","bitboxapp":{"coinCode":"eth","code":"v0-55555555-eth-0"}}
@@ -248,6 +256,39 @@ func (s *notesTestSuite) TestNotesImportWithoutBitBoxAppData() {
 	s.Require().Equal("My ETH", ethAcct.Config().Config.Name)
 }
 
+func (s *notesTestSuite) TestNotesImportSpendable() {
+	btcAcct := s.backend.Accounts().lookup("v0-55555555-btc-0")
+	s.Require().NotNil(btcAcct)
+	s.Require().NoError(btcAcct.Initialize())
+
+	_, err := btcAcct.Notes().SetOutputFrozen(frozenTxID+":2", true)
+	s.Require().NoError(err)
+
+	export := `{"type":"output","ref":"e7a8b8a7f3d4d9fc23a1e0fb5e3c37f2ea79f92e3f1b68c9e1f5d5c0a3b4c2d1:0","spendable":false,"bitboxapp":{"coinCode":"btc","code":"v0-55555555-btc-0"}}
+{"type":"output","ref":"e7a8b8a7f3d4d9fc23a1e0fb5e3c37f2ea79f92e3f1b68c9e1f5d5c0a3b4c2d1:1","spendable":false}
+{"type":"output","ref":"e7a8b8a7f3d4d9fc23a1e0fb5e3c37f2ea79f92e3f1b68c9e1f5d5c0a3b4c2d1:2","spendable":true}
+{"type":"output","ref":"e7a8b8a7f3d4d9fc23a1e0fb5e3c37f2ea79f92e3f1b68c9e1f5d5c0a3b4c2d1:3","label":"only a label"}
+{"type":"output","ref":"invalid-ref","spendable":false}
+{"type":"output","ref":"e7a8b8a7f3d4d9fc23a1e0fb5e3c37f2ea79f92e3f1b68c9e1f5d5c0a3b4c2d1:4","spendable":false,"bitboxapp":{"coinCode":"eth","code":"v0-55555555-eth-0"}}
+`
+	result, err := s.backend.ImportNotes([]byte(export))
+	s.Require().NoError(err)
+	s.Require().Equal(
+		&ImportNotesResult{
+			OutputCount: 3,
+		},
+		result)
+
+	s.Require().True(btcAcct.Notes().OutputFrozen(frozenTxID + ":0"))
+	s.Require().True(btcAcct.Notes().OutputFrozen(frozenTxID + ":1"))
+	s.Require().False(btcAcct.Notes().OutputFrozen(frozenTxID + ":2"))
+	s.Require().False(btcAcct.Notes().OutputFrozen(frozenTxID + ":3"))
+
+	ethAcct := s.backend.Accounts().lookup("v0-55555555-eth-0")
+	s.Require().NotNil(ethAcct)
+	s.Require().Empty(ethAcct.Notes().Data().FrozenOutputs)
+}
+
 func (s *notesTestSuite) TestNotesInvalidLine() {
 	export := `{"type":"xpub","ref":"xpub6Cxa67Bfe1Aw5VvLM1Ppua9x28CXH1zUYoAuBzFRjR6hWnA6aUcny84KYkeVcZWnWXxKSkxCEyMA8xic54ydBPWm5oziXpsXq6nX8FELMQn","label":"My BTC","bitboxapp":{"coinCode":"btc","code":"v0-55555555-btc-0"}}
 {"type":"xpub","ref":"xpub6CC9Tsi4eJvmRsGuXwKBfHDWUWN66voNeZFmXRJhYZS6yYgXKZmtz5qnxK9WL2FZP8uF3abyFZ29d7RfMks4FjCCu4LMh3edyeCoyEFuZLZ","label":"My BTC","bitboxapp":{"coinCode":"btc","code":"v0-55555555-btc-0"}}
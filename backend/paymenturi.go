@@ -0,0 +1,415 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"math/big"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
+	accountsTypes "github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/types"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc"
+	coinpkg "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/observable"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/observable/action"
+)
+
+const (
+	// errPaymentURIInvalid is returned when a payment URI can't be parsed.
+	errPaymentURIInvalid errp.ErrorCode = "paymentURIInvalid"
+	// errPaymentURIUnsupportedRequirement is returned when a payment URI contains a required
+	// parameter (`req-` prefix) which we don't understand.
+	errPaymentURIUnsupportedRequirement errp.ErrorCode = "paymentURIUnsupportedRequirement"
+	// errPaymentURIInvalidAddress is returned when the address of a payment URI is not valid for
+	// any of the accounts of the requested coin.
+	errPaymentURIInvalidAddress errp.ErrorCode = "paymentURIInvalidAddress"
+	// errPaymentURINoAccounts is returned when there is no account to pay the request from.
+	errPaymentURINoAccounts errp.ErrorCode = "paymentURINoAccounts"
+	// errPaymentURIUnsupportedChain is returned when an `ethereum:` URI is for a chain we don't
+	// support.
+	errPaymentURIUnsupportedChain errp.ErrorCode = "paymentURIUnsupportedChain"
+	// errPaymentURIUnsupportedToken is returned when an `ethereum:` URI is an ERC-20 transfer of a
+	// token we don't support.
+	errPaymentURIUnsupportedToken errp.ErrorCode = "paymentURIUnsupportedToken"
+)
+
+// bip21CoinCodes maps from the BIP-21 URI schemes to the codes of the coins which can pay them.
+// Which of the coins apply depends on the network of the address.
+var bip21CoinCodes = map[string][]coinpkg.Code{
+	"bitcoin":  {coinpkg.CodeBTC, coinpkg.CodeTBTC, coinpkg.CodeRBTC},
+	"litecoin": {coinpkg.CodeLTC, coinpkg.CodeTLTC},
+}
+
+// bip21AmountRegexp matches the decimal amounts allowed in BIP-21 URIs. Exponents, signs and
+// thousands separators are not allowed.
+var bip21AmountRegexp = regexp.MustCompile(`^([0-9]+(\.[0-9]*)?|\.[0-9]+)$`)
+
+// eip681NumberRegexp matches the numbers allowed in EIP-681 URIs, which can use scientific
+// notation, e.g. `2.014e18`.
+var eip681NumberRegexp = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?([eE][0-9]+)?$`)
+
+// PaymentURI holds a payment request of a `bitcoin:` or `litecoin:` (BIP-21) or `ethereum:`
+// (EIP-681) URI. The frontend uses it to open a prefilled send form.
+type PaymentURI struct {
+	// ErrorCode is a "paymentURI*" error code if the URI could not be handled. The other fields are
+	// empty in this case.
+	ErrorCode errp.ErrorCode `json:"errorCode,omitempty"`
+	// Accounts is the list of accounts the user can choose from to pay the request.
+	Accounts []account `json:"accounts"`
+	// AccountCode is the account to pay from. It is set if there is exactly one matching account,
+	// or once the user chose one of the accounts.
+	AccountCode accountsTypes.Code `json:"accountCode,omitempty"`
+	// Address is the recipient address.
+	Address string `json:"address"`
+	// Amount is the requested amount, formatted in the unit of the coin as expected by the send
+	// form. Empty if no amount is requested.
+	Amount string `json:"amount,omitempty"`
+	// Note is the label of a BIP-21 URI. It is used as the note of the transaction.
+	Note string `json:"note,omitempty"`
+	// Message describes the payment to the user.
+	Message string `json:"message,omitempty"`
+	// Lightning is a BOLT11 invoice which can be paid instead of the on-chain address.
+	Lightning string `json:"lightning,omitempty"`
+	// Payjoin is the BIP-78 payjoin endpoint of the receiver.
+	Payjoin string `json:"payjoin,omitempty"`
+}
+
+// bip21Request is a parsed BIP-21 URI.
+type bip21Request struct {
+	address string
+	// amount is in the smallest unit. Nil if no amount is requested.
+	amount    *coinpkg.Amount
+	label     string
+	message   string
+	lightning string
+	payjoin   string
+}
+
+// parseBIP21 parses a BIP-21 URI of the form `bitcoin:<address>[?amount=<amount>][&label=<label>]...`.
+// See https://github.com/bitcoin/bips/blob/master/bip-0021.mediawiki.
+func parseBIP21(uri *url.URL) (*bip21Request, error) {
+	// `bitcoin:address` is parsed as an opaque URI, but some wallets produce `bitcoin://address`.
+	address := uri.Opaque
+	if address == "" {
+		address = uri.Host
+	}
+	if address == "" {
+		return nil, errp.WithMessage(errPaymentURIInvalid, "missing address")
+	}
+	params, err := url.ParseQuery(uri.RawQuery)
+	if err != nil {
+		return nil, errp.WithMessage(errPaymentURIInvalid, err.Error())
+	}
+	request := &bip21Request{address: address}
+	for key, values := range params {
+		value := values[0]
+		switch key {
+		case "amount":
+			if !bip21AmountRegexp.MatchString(value) {
+				return nil, errp.WithMessage(errPaymentURIInvalid, "invalid amount")
+			}
+			amount, err := coinpkg.NewAmountFromString(value, big.NewInt(1e8))
+			if err != nil || amount.BigInt().Sign() <= 0 {
+				return nil, errp.WithMessage(errPaymentURIInvalid, "invalid amount")
+			}
+			request.amount = &amount
+		case "label":
+			request.label = value
+		case "message":
+			request.message = value
+		case "lightning":
+			request.lightning = value
+		case "pj":
+			payjoinURL, err := url.Parse(value)
+			if err != nil {
+				return nil, errp.WithMessage(errPaymentURIInvalid, "invalid payjoin endpoint")
+			}
+			// BIP-78: the endpoint must be encrypted, which is the case for https and onion
+			// services. Otherwise the payjoin is ignored and a regular payment is made.
+			if payjoinURL.Scheme == "https" ||
+				(payjoinURL.Scheme == "http" && strings.HasSuffix(payjoinURL.Hostname(), ".onion")) {
+				request.payjoin = value
+			}
+		default:
+			if strings.HasPrefix(key, "req-") {
+				return nil, errp.WithMessage(errPaymentURIUnsupportedRequirement, key)
+			}
+			// Unknown optional parameters are ignored.
+		}
+	}
+	return request, nil
+}
+
+// eip681Request is a parsed EIP-681 URI.
+type eip681Request struct {
+	// address is the recipient address.
+	address string
+	chainID uint64
+	// token is the token of an ERC-20 transfer, nil for ETH payments.
+	token *erc20Token
+	// amount is in the smallest unit of ETH or the token. Nil if no amount is requested.
+	amount *coinpkg.Amount
+}
+
+// parseEIP681Number parses a positive integer, which may be written in scientific notation.
+func parseEIP681Number(number string) (*coinpkg.Amount, error) {
+	if !eip681NumberRegexp.MatchString(number) {
+		return nil, errp.WithMessage(errPaymentURIInvalid, "invalid number")
+	}
+	rat, ok := new(big.Rat).SetString(number)
+	if !ok || !rat.IsInt() || rat.Sign() <= 0 {
+		return nil, errp.WithMessage(errPaymentURIInvalid, "invalid number")
+	}
+	amount := coinpkg.NewAmount(rat.Num())
+	return &amount, nil
+}
+
+// parseEIP681 parses an EIP-681 URI for ETH payments of the form
+// `ethereum:<address>[@<chainID>][?value=<wei>]`, or ERC-20 transfers of the form
+// `ethereum:<token>[@<chainID>]/transfer?address=<address>[&uint256=<amount>]`. Only ERC-20 tokens
+// we support can be transferred. See https://eips.ethereum.org/EIPS/eip-681.
+func parseEIP681(uri *url.URL) (*eip681Request, error) {
+	target, function, _ := strings.Cut(strings.TrimPrefix(uri.Opaque, "pay-"), "/")
+	target, chainID, hasChainID := strings.Cut(target, "@")
+	request := &eip681Request{chainID: 1}
+	if hasChainID {
+		var err error
+		request.chainID, err = strconv.ParseUint(chainID, 10, 64)
+		if err != nil {
+			return nil, errp.WithMessage(errPaymentURIInvalid, "invalid chain ID")
+		}
+	}
+	// ENS names are not supported.
+	if !strings.HasPrefix(target, "0x") || !eth.IsValidEthAddress(target) {
+		return nil, errp.WithMessage(errPaymentURIInvalidAddress, target)
+	}
+	params, err := url.ParseQuery(uri.RawQuery)
+	if err != nil {
+		return nil, errp.WithMessage(errPaymentURIInvalid, err.Error())
+	}
+	switch function {
+	case "":
+		request.address = target
+		if params.Has("value") {
+			request.amount, err = parseEIP681Number(params.Get("value"))
+			if err != nil {
+				return nil, err
+			}
+		}
+	case "transfer":
+		for i := range erc20Tokens {
+			if strings.EqualFold(erc20Tokens[i].token.ContractAddress().Hex(), target) {
+				request.token = &erc20Tokens[i]
+				break
+			}
+		}
+		if request.token == nil {
+			return nil, errp.WithMessage(errPaymentURIUnsupportedToken, target)
+		}
+		request.address = params.Get("address")
+		if !strings.HasPrefix(request.address, "0x") || !eth.IsValidEthAddress(request.address) {
+			return nil, errp.WithMessage(errPaymentURIInvalidAddress, request.address)
+		}
+		if params.Has("uint256") {
+			request.amount, err = parseEIP681Number(params.Get("uint256"))
+			if err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, errp.WithMessage(errPaymentURIInvalid, "unsupported function: "+function)
+	}
+	return request, nil
+}
+
+// PaymentURI returns the payment request of the last handled payment URI, or nil if there is none.
+func (backend *Backend) PaymentURI() *PaymentURI {
+	defer backend.accountsAndKeystoreLock.RLock()()
+	return backend.paymentURI
+}
+
+// PaymentURIClear clears the payment request, e.g. after it was paid or dismissed by the user.
+func (backend *Backend) PaymentURIClear() {
+	defer backend.accountsAndKeystoreLock.Lock()()
+	backend.paymentURI = nil
+	backend.notifyPaymentURI()
+}
+
+// PaymentURIChooseAccount sets the account to pay the payment request from, if there are several
+// accounts to choose from. The frontend then opens the send view of the account.
+func (backend *Backend) PaymentURIChooseAccount(code accountsTypes.Code) {
+	defer backend.accountsAndKeystoreLock.Lock()()
+	if backend.paymentURI == nil || backend.paymentURI.ErrorCode != "" {
+		return
+	}
+	for _, paymentAccount := range backend.paymentURI.Accounts {
+		if paymentAccount.Code == code {
+			backend.paymentURI.AccountCode = code
+			backend.notifyPaymentURI()
+			return
+		}
+	}
+	backend.log.WithField("accountCode", code).Error("Payment URI: could not find account")
+}
+
+// notifyPaymentURI sends the payment request to the frontend. `accountsAndKeystoreLock` must be
+// held when calling this function.
+func (backend *Backend) notifyPaymentURI() {
+	backend.Notify(observable.Event{
+		Subject: "payment-uri",
+		Action:  action.Replace,
+		Object:  backend.paymentURI,
+	})
+}
+
+// setPaymentURI sets the payment request, to be paid from one of the given accounts.
+// `amount` is the requested amount in the smallest unit of the coin of the accounts, or nil if no
+// amount is requested. `accountsAndKeystoreLock` must be held when calling this function.
+func (backend *Backend) setPaymentURI(
+	paymentURI *PaymentURI, paymentAccounts []accounts.Interface, amount *coinpkg.Amount) {
+	if len(paymentAccounts) == 0 {
+		backend.log.Error("No account to pay the payment request from")
+		backend.paymentURI = &PaymentURI{ErrorCode: errPaymentURINoAccounts}
+		return
+	}
+	for _, paymentAccount := range paymentAccounts {
+		paymentURI.Accounts = append(paymentURI.Accounts, account{
+			Name: paymentAccount.Config().Config.Name,
+			Code: paymentAccount.Config().Config.Code,
+		})
+	}
+	if amount != nil {
+		paymentURI.Amount = paymentAccounts[0].Coin().FormatAmount(*amount, false)
+	}
+	// Automatically use the account if there is only one, skipping the step where the user has to
+	// select it manually.
+	if len(paymentAccounts) == 1 {
+		paymentURI.AccountCode = paymentURI.Accounts[0].Code
+	}
+	backend.paymentURI = paymentURI
+}
+
+// setPaymentURIError sets the error code of a payment URI which could not be handled. `accountsAndKeystoreLock` must be held when calling this function.
+func (backend *Backend) setPaymentURIError(err error) {
+	backend.log.WithError(err).Error("Invalid payment URI")
+	errorCode, ok := errp.Cause(err).(errp.ErrorCode)
+	if !ok {
+		errorCode = errPaymentURIInvalid
+	}
+	backend.paymentURI = &PaymentURI{ErrorCode: errorCode}
+}
+
+// handleBIP21 handles a `bitcoin:` or `litecoin:` payment URI. The accounts which can pay the
+// request are looked up by the coin and the network of the address.
+func (backend *Backend) handleBIP21(uri url.URL) {
+	defer backend.accountsAndKeystoreLock.Lock()()
+	defer backend.notifyPaymentURI()
+
+	request, err := parseBIP21(&uri)
+	if err != nil {
+		backend.setPaymentURIError(err)
+		return
+	}
+
+	coinCodes := bip21CoinCodes[uri.Scheme]
+	var paymentAccounts []accounts.Interface
+	invalidAddress := false
+	for _, acct := range backend.accounts {
+		config := acct.Config().Config
+		if config.Inactive || config.HiddenBecauseUnused || !slices.Contains(coinCodes, acct.Coin().Code()) {
+			continue
+		}
+		btcCoin, ok := acct.Coin().(*btc.Coin)
+		if !ok {
+			continue
+		}
+		if _, err := btcCoin.AddressToPkScript(request.address); err != nil {
+			invalidAddress = true
+			continue
+		}
+		paymentAccounts = append(paymentAccounts, acct)
+	}
+	if len(paymentAccounts) == 0 && invalidAddress {
+		backend.setPaymentURIError(errp.WithMessage(errPaymentURIInvalidAddress, request.address))
+		return
+	}
+	backend.setPaymentURI(&PaymentURI{
+		Address:   request.address,
+		Note:      request.label,
+		Message:   request.message,
+		Lightning: request.lightning,
+		Payjoin:   request.payjoin,
+	}, paymentAccounts, request.amount)
+}
+
+// handleEIP681 handles an `ethereum:` payment URI. ETH payments are paid from the ETH accounts of the
+// requested chain, ERC-20 transfers from the token accounts of these ETH accounts, if the token is
+// enabled.
+func (backend *Backend) handleEIP681(uri url.URL) {
+	defer backend.accountsAndKeystoreLock.Lock()()
+	defer backend.notifyPaymentURI()
+
+	request, err := parseEIP681(&uri)
+	if err != nil {
+		backend.setPaymentURIError(err)
+		return
+	}
+	supportedChain := false
+	for _, coinCode := range []coinpkg.Code{coinpkg.CodeETH, coinpkg.CodeSEPETH} {
+		coin, err := backend.Coin(coinCode)
+		if err != nil {
+			backend.setPaymentURIError(err)
+			return
+		}
+		if coin.(*eth.Coin).ChainID() == request.chainID {
+			supportedChain = true
+		}
+	}
+	if !supportedChain {
+		backend.setPaymentURIError(errp.WithMessage(
+			errPaymentURIUnsupportedChain, strconv.FormatUint(request.chainID, 10)))
+		return
+	}
+
+	var paymentAccounts []accounts.Interface
+	for _, acct := range backend.accounts {
+		config := acct.Config().Config
+		if config.Inactive || config.HiddenBecauseUnused {
+			continue
+		}
+		ethCoin, ok := acct.Coin().(*eth.Coin)
+		if !ok || ethCoin.ERC20Token() != nil || ethCoin.ChainID() != request.chainID {
+			continue
+		}
+		if request.token == nil {
+			paymentAccounts = append(paymentAccounts, acct)
+			continue
+		}
+		tokenCode := string(request.token.code)
+		if !slices.Contains(config.ActiveTokens, tokenCode) {
+			continue
+		}
+		if tokenAccount := backend.accounts.lookup(Erc20AccountCode(config.Code, tokenCode)); tokenAccount != nil {
+			paymentAccounts = append(paymentAccounts, tokenAccount)
+		}
+	}
+	backend.setPaymentURI(&PaymentURI{Address: request.address}, paymentAccounts, request.amount)
+}
@@ -0,0 +1,206 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"net/url"
+	"testing"
+
+	coinpkg "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBIP21(t *testing.T) {
+	parse := func(uri string) (*bip21Request, error) {
+		t.Helper()
+		u, err := url.Parse(uri)
+		require.NoError(t, err)
+		return parseBIP21(u)
+	}
+
+	request, err := parse("bitcoin:175tWpb8K1S7NmH4Zx6rewF9WQrcZv245W")
+	require.NoError(t, err)
+	require.Equal(t, &bip21Request{address: "175tWpb8K1S7NmH4Zx6rewF9WQrcZv245W"}, request)
+
+	request, err = parse("bitcoin:175tWpb8K1S7NmH4Zx6rewF9WQrcZv245W?amount=20.3&label=Luke-Jr" +
+		"&message=Donation%20for%20project%20xyz&lightning=lnbc1&pj=https://example.com/pj&somethingyoudontunderstand=50")
+	require.NoError(t, err)
+	amount := coinpkg.NewAmountFromInt64(2030000000)
+	require.Equal(t, &bip21Request{
+		address:   "175tWpb8K1S7NmH4Zx6rewF9WQrcZv245W",
+		amount:    &amount,
+		label:     "Luke-Jr",
+		message:   "Donation for project xyz",
+		lightning: "lnbc1",
+		payjoin:   "https://example.com/pj",
+	}, request)
+
+	request, err = parse("bitcoin://175tWpb8K1S7NmH4Zx6rewF9WQrcZv245W?amount=.00000001")
+	require.NoError(t, err)
+	require.Equal(t, "175tWpb8K1S7NmH4Zx6rewF9WQrcZv245W", request.address)
+	require.Equal(t, coinpkg.NewAmountFromInt64(1), *request.amount)
+
+	// Unencrypted payjoin endpoints are ignored.
+	request, err = parse("bitcoin:175tWpb8K1S7NmH4Zx6rewF9WQrcZv245W?pj=http://example.com/pj")
+	require.NoError(t, err)
+	require.Empty(t, request.payjoin)
+	request, err = parse("bitcoin:175tWpb8K1S7NmH4Zx6rewF9WQrcZv245W?pj=http://example.onion/pj")
+	require.NoError(t, err)
+	require.Equal(t, "http://example.onion/pj", request.payjoin)
+
+	_, err = parse("bitcoin:175tWpb8K1S7NmH4Zx6rewF9WQrcZv245W?req-somethingyoudontunderstand=50")
+	require.Equal(t, errPaymentURIUnsupportedRequirement, errp.Cause(err))
+
+	for _, invalid := range []string{
+		"bitcoin:",
+		"bitcoin:?amount=1",
+		"bitcoin:175tWpb8K1S7NmH4Zx6rewF9WQrcZv245W?amount=1e3",
+		"bitcoin:175tWpb8K1S7NmH4Zx6rewF9WQrcZv245W?amount=-1",
+		"bitcoin:175tWpb8K1S7NmH4Zx6rewF9WQrcZv245W?amount=1,000",
+		"bitcoin:175tWpb8K1S7NmH4Zx6rewF9WQrcZv245W?amount=0",
+		"bitcoin:175tWpb8K1S7NmH4Zx6rewF9WQrcZv245W?amount=0.000000001",
+		"bitcoin:175tWpb8K1S7NmH4Zx6rewF9WQrcZv245W?label=%zz",
+	} {
+		_, err := parse(invalid)
+		require.Equal(t, errPaymentURIInvalid, errp.Cause(err), invalid)
+	}
+}
+
+func TestHandleBIP21(t *testing.T) {
+	b := newBackend(t, testnetDisabled, regtestDisabled)
+	defer b.Close()
+
+	require.Nil(t, b.PaymentURI())
+	b.HandleURI("bitcoin:175tWpb8K1S7NmH4Zx6rewF9WQrcZv245W")
+	require.Equal(t, &PaymentURI{ErrorCode: errPaymentURINoAccounts}, b.PaymentURI())
+
+	b.registerKeystore(makeBitBox02Multi())
+	btcAddress := b.Accounts().lookup("v0-55555555-btc-0").GetUnusedReceiveAddresses()[0].Addresses[0].EncodeForHumans()
+	ltcAddress := b.Accounts().lookup("v0-55555555-ltc-0").GetUnusedReceiveAddresses()[0].Addresses[0].EncodeForHumans()
+
+	b.HandleURI("BITCOIN:" + btcAddress + "?amount=0.5&label=Rent%20May&message=Thanks")
+	require.Equal(t, &PaymentURI{
+		Accounts:    []account{{Name: "Bitcoin", Code: "v0-55555555-btc-0"}},
+		AccountCode: "v0-55555555-btc-0",
+		Address:     btcAddress,
+		Amount:      "0.50000000",
+		Note:        "Rent May",
+		Message:     "Thanks",
+	}, b.PaymentURI())
+
+	// If there are several accounts, the user chooses one of them.
+	b.paymentURI.AccountCode = ""
+	b.PaymentURIChooseAccount("v0-55555555-ltc-0")
+	require.Empty(t, b.PaymentURI().AccountCode)
+	b.PaymentURIChooseAccount("v0-55555555-btc-0")
+	require.Equal(t, "v0-55555555-btc-0", string(b.PaymentURI().AccountCode))
+
+	b.HandleURI("litecoin:" + ltcAddress)
+	require.Equal(t, "v0-55555555-ltc-0", string(b.PaymentURI().AccountCode))
+
+	// The address must belong to the coin of the scheme.
+	b.HandleURI("litecoin:" + btcAddress)
+	require.Equal(t, &PaymentURI{ErrorCode: errPaymentURIInvalidAddress}, b.PaymentURI())
+
+	b.HandleURI("bitcoin:" + btcAddress + "?req-amount=1")
+	require.Equal(t, &PaymentURI{ErrorCode: errPaymentURIUnsupportedRequirement}, b.PaymentURI())
+
+	b.PaymentURIClear()
+	require.Nil(t, b.PaymentURI())
+}
+
+func TestParseEIP681(t *testing.T) {
+	parse := func(uri string) (*eip681Request, error) {
+		t.Helper()
+		u, err := url.Parse(uri)
+		require.NoError(t, err)
+		return parseEIP681(u)
+	}
+	const address = "0xfb6916095ca1df60bb79ce92ce3ea74c37c5d359"
+
+	request, err := parse("ethereum:" + address + "?value=2.014e18")
+	require.NoError(t, err)
+	amount := coinpkg.NewAmountFromInt64(2014000000000000000)
+	require.Equal(t, &eip681Request{address: address, chainID: 1, amount: &amount}, request)
+
+	request, err = parse("ethereum:pay-" + address + "@11155111")
+	require.NoError(t, err)
+	require.Equal(t, &eip681Request{address: address, chainID: 11155111}, request)
+
+	request, err = parse("ethereum:0xdAC17F958D2ee523a2206206994597C13D831ec7/transfer?address=" +
+		address + "&uint256=1000000")
+	require.NoError(t, err)
+	require.Equal(t, coinpkg.Code("eth-erc20-usdt"), request.token.code)
+	require.Equal(t, address, request.address)
+	require.Equal(t, coinpkg.NewAmountFromInt64(1000000), *request.amount)
+
+	_, err = parse("ethereum:0x0000000000000000000000000000000000000001/transfer?address=" + address)
+	require.Equal(t, errPaymentURIUnsupportedToken, errp.Cause(err))
+
+	for _, invalid := range []string{
+		"ethereum:" + address + "?value=1.5",
+		"ethereum:" + address + "?value=-1",
+		"ethereum:" + address + "?value=0",
+		"ethereum:" + address + "@x",
+		"ethereum:" + address + "/approve?address=" + address,
+	} {
+		_, err := parse(invalid)
+		require.Equal(t, errPaymentURIInvalid, errp.Cause(err), invalid)
+	}
+	for _, invalid := range []string{
+		"ethereum:vitalik.eth",
+		// Wrong EIP-55 checksum.
+		"ethereum:0xFB6916095ca1df60bb79ce92ce3ea74c37c5d359",
+		"ethereum:0xdac17f958d2ee523a2206206994597c13d831ec7/transfer?address=0x12",
+	} {
+		_, err := parse(invalid)
+		require.Equal(t, errPaymentURIInvalidAddress, errp.Cause(err), invalid)
+	}
+}
+
+func TestHandleEIP681(t *testing.T) {
+	b := newBackend(t, testnetDisabled, regtestDisabled)
+	defer b.Close()
+	b.registerKeystore(makeBitBox02Multi())
+	const address = "0xfb6916095ca1df60bb79ce92ce3ea74c37c5d359"
+
+	b.HandleURI("ethereum:" + address + "@1?value=1e17")
+	require.Equal(t, &PaymentURI{
+		Accounts:    []account{{Name: "Ethereum", Code: "v0-55555555-eth-0"}},
+		AccountCode: "v0-55555555-eth-0",
+		Address:     address,
+		Amount:      "0.1",
+	}, b.PaymentURI())
+
+	b.HandleURI("ethereum:" + address + "@5")
+	require.Equal(t, &PaymentURI{ErrorCode: errPaymentURIUnsupportedChain}, b.PaymentURI())
+
+	// No Sepolia accounts in mainnet mode.
+	b.HandleURI("ethereum:" + address + "@11155111")
+	require.Equal(t, &PaymentURI{ErrorCode: errPaymentURINoAccounts}, b.PaymentURI())
+
+	// The token must be enabled.
+	transferURI := "ethereum:0xdac17f958d2ee523a2206206994597c13d831ec7/transfer?address=" + address + "&uint256=2500000"
+	b.HandleURI(transferURI)
+	require.Equal(t, &PaymentURI{ErrorCode: errPaymentURINoAccounts}, b.PaymentURI())
+
+	require.NoError(t, b.SetTokenActive("v0-55555555-eth-0", "eth-erc20-usdt", true))
+	b.HandleURI(transferURI)
+	tokenAccountCode := Erc20AccountCode("v0-55555555-eth-0", "eth-erc20-usdt")
+	require.Equal(t, tokenAccountCode, b.PaymentURI().AccountCode)
+	require.Equal(t, address, b.PaymentURI().Address)
+	require.Equal(t, "2.5", b.PaymentURI().Amount)
+}
@@ -20,6 +20,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
 	"github.com/btcsuite/btcd/btcec/v2"
@@ -39,6 +40,52 @@ func (ki KeyInfo) String() string {
 	return fmt.Sprintf("keypath=%s", ki.AbsoluteKeypath.Encode())
 }
 
+// ParseKeyInfo parses an extended public key with key origin info, in the format used in output
+// descriptors: `[fingerprint/keypath]xpub`, e.g. `[f00dbabe/48'/0'/0'/2']xpub...`. Hardened keypath
+// elements can be denoted by `'` or `h`.
+func ParseKeyInfo(encoded string) (*KeyInfo, error) {
+	encoded = strings.TrimSpace(encoded)
+	if !strings.HasPrefix(encoded, "[") {
+		return nil, errp.New("missing key origin info")
+	}
+	end := strings.Index(encoded, "]")
+	if end == -1 {
+		return nil, errp.New("missing key origin info")
+	}
+	origin, xpub := encoded[1:end], encoded[end+1:]
+	fingerprintHex, keypath, _ := strings.Cut(origin, "/")
+	rootFingerprint, err := hex.DecodeString(fingerprintHex)
+	if err != nil || len(rootFingerprint) != 4 {
+		return nil, errp.Newf("invalid root fingerprint: %s", fingerprintHex)
+	}
+	keypath = strings.NewReplacer("h", hardenedKeySymbol, "H", hardenedKeySymbol).Replace(keypath)
+	absoluteKeypath, err := NewAbsoluteKeypath("m/" + keypath)
+	if err != nil {
+		return nil, err
+	}
+	extendedPublicKey, err := hdkeychain.NewKeyFromString(xpub)
+	if err != nil {
+		return nil, errp.Wrap(err, "Could not read an extended public key.")
+	}
+	if extendedPublicKey.IsPrivate() {
+		return nil, errp.New("An extended key is private! Only extended public keys are accepted.")
+	}
+	return &KeyInfo{
+		RootFingerprint:   rootFingerprint,
+		AbsoluteKeypath:   absoluteKeypath,
+		ExtendedPublicKey: extendedPublicKey,
+	}, nil
+}
+
+// Encode returns the extended public key with key origin info in the format parsed by
+// ParseKeyInfo().
+func (ki KeyInfo) Encode() string {
+	return fmt.Sprintf("[%x%s]%s",
+		ki.RootFingerprint,
+		strings.TrimSuffix(strings.TrimPrefix(ki.AbsoluteKeypath.Encode(), "m"), "/"),
+		ki.ExtendedPublicKey.String())
+}
+
 type keyInfoEncoding struct {
 	RootFingerprint string          `json:"rootFingerprint"`
 	Keypath         AbsoluteKeypath `json:"keypath"`
@@ -80,6 +127,27 @@ type BitcoinSimple struct {
 	ScriptType ScriptType `json:"scriptType"`
 }
 
+// MaxMultisigCosigners is the maximum number of cosigners of a multisig configuration, limited by
+// the standardness rules for P2WSH scripts.
+const MaxMultisigCosigners = 15
+
+// BitcoinMultisig represents a k-of-n multisig Bitcoin/Litecoin signing configuration. The public
+// keys in the scripts are sorted (BIP-67), like `sortedmulti()` in output descriptors.
+type BitcoinMultisig struct {
+	// Threshold is the number of signatures needed to spend (k).
+	Threshold int `json:"threshold"`
+	// KeyInfos are the account-level keys of all cosigners (n), including our own key.
+	KeyInfos []KeyInfo `json:"keyInfos"`
+	// OurKeyIndex is the index of our own key in KeyInfos.
+	OurKeyIndex int        `json:"ourKeyIndex"`
+	ScriptType  ScriptType `json:"scriptType"`
+}
+
+// OurKeyInfo returns the key info of our own key.
+func (multisig *BitcoinMultisig) OurKeyInfo() *KeyInfo {
+	return &multisig.KeyInfos[multisig.OurKeyIndex]
+}
+
 // EthereumSimple represents a simple (standard single-sig, no exotic signing methods) Ethereum
 // signing configuration.
 type EthereumSimple struct {
@@ -90,8 +158,9 @@ type EthereumSimple struct {
 type Configuration struct {
 	// Poor man's union type: only one of the below can be non-nil.
 
-	BitcoinSimple  *BitcoinSimple  `json:"bitcoinSimple,omitempty"`
-	EthereumSimple *EthereumSimple `json:"ethereumSimple,omitempty"`
+	BitcoinSimple   *BitcoinSimple   `json:"bitcoinSimple,omitempty"`
+	BitcoinMultisig *BitcoinMultisig `json:"bitcoinMultisig,omitempty"`
+	EthereumSimple  *EthereumSimple  `json:"ethereumSimple,omitempty"`
 }
 
 // NewBitcoinConfiguration creates a new configuration.
@@ -116,6 +185,50 @@ func NewBitcoinConfiguration(
 	}
 }
 
+// NewBitcoinMultisigConfiguration creates a new k-of-n multisig configuration. `keyInfos` contains
+// the keys of all cosigners, and `ourKeyIndex` is the index of our own key in it.
+func NewBitcoinMultisigConfiguration(
+	scriptType ScriptType,
+	threshold int,
+	keyInfos []KeyInfo,
+	ourKeyIndex int,
+) (*Configuration, error) {
+	if scriptType != ScriptTypeP2WSH && scriptType != ScriptTypeP2WSHP2SH {
+		return nil, errp.Newf("unsupported multisig script type: %s", scriptType)
+	}
+	if len(keyInfos) < 2 || len(keyInfos) > MaxMultisigCosigners {
+		return nil, errp.Newf("a multisig needs between 2 and %d cosigners", MaxMultisigCosigners)
+	}
+	if threshold < 1 || threshold > len(keyInfos) {
+		return nil, errp.Newf("invalid multisig threshold: %d-of-%d", threshold, len(keyInfos))
+	}
+	if ourKeyIndex < 0 || ourKeyIndex >= len(keyInfos) {
+		return nil, errp.Newf("invalid index of our key: %d", ourKeyIndex)
+	}
+	xpubs := map[string]struct{}{}
+	for _, keyInfo := range keyInfos {
+		if keyInfo.ExtendedPublicKey == nil {
+			return nil, errp.New("missing extended public key")
+		}
+		if keyInfo.ExtendedPublicKey.IsPrivate() {
+			return nil, errp.New("An extended key is private! Only extended public keys are accepted.")
+		}
+		xpub := keyInfo.ExtendedPublicKey.String()
+		if _, ok := xpubs[xpub]; ok {
+			return nil, errp.New("duplicate cosigner extended public key")
+		}
+		xpubs[xpub] = struct{}{}
+	}
+	return &Configuration{
+		BitcoinMultisig: &BitcoinMultisig{
+			Threshold:   threshold,
+			KeyInfos:    keyInfos,
+			OurKeyIndex: ourKeyIndex,
+			ScriptType:  scriptType,
+		},
+	}, nil
+}
+
 // NewEthereumConfiguration creates a new configuration.
 func NewEthereumConfiguration(
 	rootFingerprint []byte,
@@ -138,31 +251,50 @@ func NewEthereumConfiguration(
 
 // ScriptType returns the configuration's keypath.
 func (configuration *Configuration) ScriptType() ScriptType {
+	if configuration.BitcoinMultisig != nil {
+		return configuration.BitcoinMultisig.ScriptType
+	}
 	return configuration.BitcoinSimple.ScriptType
 }
 
-// AbsoluteKeypath returns the configuration's keypath.
-func (configuration *Configuration) AbsoluteKeypath() AbsoluteKeypath {
-	if configuration.BitcoinSimple != nil {
-		return configuration.BitcoinSimple.KeyInfo.AbsoluteKeypath
+// keyInfo returns the key info of our own key.
+func (configuration *Configuration) keyInfo() *KeyInfo {
+	switch {
+	case configuration.BitcoinSimple != nil:
+		return &configuration.BitcoinSimple.KeyInfo
+	case configuration.BitcoinMultisig != nil:
+		return configuration.BitcoinMultisig.OurKeyInfo()
+	default:
+		return &configuration.EthereumSimple.KeyInfo
 	}
-	return configuration.EthereumSimple.KeyInfo.AbsoluteKeypath
 }
 
-// ExtendedPublicKey returns the configuration's extended public key.
+// AbsoluteKeypath returns the configuration's keypath. For multisig configurations, this is the
+// keypath of our own key.
+func (configuration *Configuration) AbsoluteKeypath() AbsoluteKeypath {
+	return configuration.keyInfo().AbsoluteKeypath
+}
+
+// ExtendedPublicKey returns the configuration's extended public key. For multisig configurations,
+// this is our own extended public key.
 func (configuration *Configuration) ExtendedPublicKey() *hdkeychain.ExtendedKey {
-	if configuration.BitcoinSimple != nil {
-		return configuration.BitcoinSimple.KeyInfo.ExtendedPublicKey
-	}
-	return configuration.EthereumSimple.KeyInfo.ExtendedPublicKey
+	return configuration.keyInfo().ExtendedPublicKey
 }
 
 // AccountNumber returns the account number as present in the BIP44 keypath.
 // The configuration keypath must be a BIP44 keypath:
 // m/purpose'/coin'/account' for Bitcoin-based coins.
 // m/44'/coin'/0'/0/account for Ethereum.
+// Multisig configurations use BIP48 keypaths: m/48'/coin'/account'/script_type'.
 // For invalid keypaths, zero is returned for the account number, along with an error.
 func (configuration *Configuration) AccountNumber() (uint16, error) {
+	if configuration.BitcoinMultisig != nil {
+		keypath := configuration.BitcoinMultisig.OurKeyInfo().AbsoluteKeypath.ToUInt32()
+		if len(keypath) != 4 || keypath[2] < hdkeychain.HardenedKeyStart {
+			return 0, errp.Newf("unexpected bitcoin multisig keypath: %v", keypath)
+		}
+		return uint16(keypath[2] - hdkeychain.HardenedKeyStart), nil
+	}
 	if configuration.BitcoinSimple != nil {
 		keypath := configuration.BitcoinSimple.KeyInfo.AbsoluteKeypath.ToUInt32()
 		if len(keypath) != 3 || keypath[2] < hdkeychain.HardenedKeyStart {
@@ -195,6 +327,11 @@ func (configuration *Configuration) String() string {
 		return fmt.Sprintf("bitcoinSimple;scriptType=%s;%s",
 			configuration.BitcoinSimple.ScriptType, configuration.BitcoinSimple.KeyInfo)
 	}
+	if configuration.BitcoinMultisig != nil {
+		multisig := configuration.BitcoinMultisig
+		return fmt.Sprintf("bitcoinMultisig;scriptType=%s;%d-of-%d;%s",
+			multisig.ScriptType, multisig.Threshold, len(multisig.KeyInfos), multisig.OurKeyInfo())
+	}
 	return fmt.Sprintf("ethereumSimple;%s", configuration.EthereumSimple.KeyInfo)
 }
 
@@ -210,6 +347,9 @@ func (configs Configurations) RootFingerprint() ([]byte, error) {
 		if config.BitcoinSimple != nil {
 			return config.BitcoinSimple.KeyInfo.RootFingerprint, nil
 		}
+		if config.BitcoinMultisig != nil {
+			return config.BitcoinMultisig.OurKeyInfo().RootFingerprint, nil
+		}
 		if config.EthereumSimple != nil {
 			return config.EthereumSimple.KeyInfo.RootFingerprint, nil
 		}
@@ -225,6 +365,11 @@ func (configs Configurations) ContainsRootFingerprint(rootFingerprint []byte) bo
 				return true
 			}
 		}
+		if config.BitcoinMultisig != nil {
+			if bytes.Equal(config.BitcoinMultisig.OurKeyInfo().RootFingerprint, rootFingerprint) {
+				return true
+			}
+		}
 		if config.EthereumSimple != nil {
 			if bytes.Equal(config.EthereumSimple.KeyInfo.RootFingerprint, rootFingerprint) {
 				return true
@@ -241,6 +386,9 @@ func (configs Configurations) FindScriptType(scriptType ScriptType) int {
 		if config.BitcoinSimple != nil && config.BitcoinSimple.ScriptType == scriptType {
 			return idx
 		}
+		if config.BitcoinMultisig != nil && config.BitcoinMultisig.ScriptType == scriptType {
+			return idx
+		}
 	}
 	return -1
 }
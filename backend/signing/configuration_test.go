@@ -148,3 +148,98 @@ func TestAccountNumber(t *testing.T) {
 	require.Error(t, err)
 	require.Equal(t, uint16(0), num)
 }
+
+func TestBitcoinMultisigConfiguration(t *testing.T) {
+	keyInfos := make([]KeyInfo, 3)
+	for i := range keyInfos {
+		xpub, err := hdkeychain.NewMaster(bytes32(byte(i)), &chaincfg.TestNet3Params)
+		require.NoError(t, err)
+		xpub, err = xpub.Neuter()
+		require.NoError(t, err)
+		keyInfos[i] = KeyInfo{
+			RootFingerprint:   []byte{byte(i), 2, 3, 4},
+			AbsoluteKeypath:   mustKeypath("m/48'/1'/5'/2'"),
+			ExtendedPublicKey: xpub,
+		}
+	}
+
+	cfg, err := NewBitcoinMultisigConfiguration(ScriptTypeP2WSH, 2, keyInfos, 1)
+	require.NoError(t, err)
+	require.Equal(t, ScriptTypeP2WSH, cfg.ScriptType())
+	require.Equal(t, "m/48'/1'/5'/2'", cfg.AbsoluteKeypath().Encode())
+	require.Equal(t, keyInfos[1].ExtendedPublicKey, cfg.ExtendedPublicKey())
+	num, err := cfg.AccountNumber()
+	require.NoError(t, err)
+	require.Equal(t, uint16(5), num)
+	require.Equal(t, "bitcoinMultisig;scriptType=p2wsh;2-of-3;keypath=m/48'/1'/5'/2'", cfg.String())
+
+	configs := Configurations{cfg}
+	rootFingerprint, err := configs.RootFingerprint()
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 2, 3, 4}, rootFingerprint)
+	require.True(t, configs.ContainsRootFingerprint([]byte{1, 2, 3, 4}))
+	require.False(t, configs.ContainsRootFingerprint([]byte{0, 2, 3, 4}))
+	require.Equal(t, 0, configs.FindScriptType(ScriptTypeP2WSH))
+	require.Equal(t, -1, configs.FindScriptType(ScriptTypeP2WSHP2SH))
+
+	jsonBytes, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	var cfgDecoded Configuration
+	require.NoError(t, json.Unmarshal(jsonBytes, &cfgDecoded))
+	require.Nil(t, cfgDecoded.BitcoinSimple)
+	require.NotNil(t, cfgDecoded.BitcoinMultisig)
+	require.Equal(t, 2, cfgDecoded.BitcoinMultisig.Threshold)
+	require.Equal(t, 1, cfgDecoded.BitcoinMultisig.OurKeyIndex)
+	require.Len(t, cfgDecoded.BitcoinMultisig.KeyInfos, 3)
+	require.Equal(t, cfg.String(), cfgDecoded.String())
+
+	// Invalid configurations.
+	_, err = NewBitcoinMultisigConfiguration(ScriptTypeP2WPKH, 2, keyInfos, 1)
+	require.Error(t, err)
+	_, err = NewBitcoinMultisigConfiguration(ScriptTypeP2WSH, 4, keyInfos, 1)
+	require.Error(t, err)
+	_, err = NewBitcoinMultisigConfiguration(ScriptTypeP2WSH, 0, keyInfos, 1)
+	require.Error(t, err)
+	_, err = NewBitcoinMultisigConfiguration(ScriptTypeP2WSH, 1, keyInfos[:1], 0)
+	require.Error(t, err)
+	_, err = NewBitcoinMultisigConfiguration(ScriptTypeP2WSH, 2, keyInfos, 3)
+	require.Error(t, err)
+	_, err = NewBitcoinMultisigConfiguration(
+		ScriptTypeP2WSH, 2, []KeyInfo{keyInfos[0], keyInfos[1], keyInfos[0]}, 1)
+	require.Error(t, err)
+}
+
+func bytes32(b byte) []byte {
+	result := make([]byte, 32)
+	for i := range result {
+		result[i] = b
+	}
+	return result
+}
+
+func TestParseKeyInfo(t *testing.T) {
+	const xpub = "tpubDCxoQyC5JaGydxN3yprM6sgqgu65LruN3JBm1fnSmGxXR3AcuNwrE7J2CVaCvuLPJtJNySjshNsYbR96Y7yfEdcywYqWubzUQLVGh2b4mF9"
+	keyInfo, err := ParseKeyInfo("[f00dBABE/48h/1'/0H/2']" + xpub)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0xf0, 0x0d, 0xba, 0xbe}, keyInfo.RootFingerprint)
+	require.Equal(t, "m/48'/1'/0'/2'", keyInfo.AbsoluteKeypath.Encode())
+	require.Equal(t, xpub, keyInfo.ExtendedPublicKey.String())
+	require.Equal(t, "[f00dbabe/48'/1'/0'/2']"+xpub, keyInfo.Encode())
+
+	keyInfo, err = ParseKeyInfo(" [f00dbabe]" + xpub + " ")
+	require.NoError(t, err)
+	require.Empty(t, keyInfo.AbsoluteKeypath)
+	require.Equal(t, "[f00dbabe]"+xpub, keyInfo.Encode())
+
+	for _, invalid := range []string{
+		xpub,
+		"[f00dbabe/48'/1'/0'/2'" + xpub,
+		"[f00dba/48'/1'/0'/2']" + xpub,
+		"[f00dbabe/48'/x/0'/2']" + xpub,
+		"[f00dbabe/48'/1'/0'/2']" + xpub[:len(xpub)-1],
+		"[f00dbabe/48'/1'/0'/2']tprv8ZgxMBicQKsPd9TeAdPADNnSyH9SSUUbTVeFszDE23Ki6TBB5nCefAdHkK8Fm3qMQR6sHwA56zqRmKmxnHk37JkiFzvncDqoKmPWubu7hDF",
+	} {
+		_, err := ParseKeyInfo(invalid)
+		require.Error(t, err, invalid)
+	}
+}
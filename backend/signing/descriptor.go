@@ -0,0 +1,215 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signing
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+)
+
+// See https://github.com/bitcoin/bips/blob/master/bip-0380.mediawiki#checksum.
+const (
+	descriptorInputCharset = "0123456789()[],'/*abcdefgh@:$%{}" +
+		"IJKLMNOPQRSTUVWXYZ&+-.;<=>?!^_|~" +
+		"ijklmnopqrstuvwxyzABCDEFGH`#\"\\ "
+	descriptorChecksumCharset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+	descriptorChecksumLength  = 8
+)
+
+func descriptorPolymod(symbols []uint64) uint64 {
+	generator := [5]uint64{0xf5dee51989, 0xa9fdca3312, 0x1bab10e32d, 0x3706b1677a, 0x644d626ffd}
+	chk := uint64(1)
+	for _, value := range symbols {
+		top := chk >> 35
+		chk = (chk&0x7ffffffff)<<5 ^ value
+		for i := 0; i < 5; i++ {
+			if (top>>i)&1 == 1 {
+				chk ^= generator[i]
+			}
+		}
+	}
+	return chk
+}
+
+// DescriptorChecksum computes the BIP-380 checksum of an output descriptor without checksum.
+func DescriptorChecksum(descriptor string) (string, error) {
+	symbols := []uint64{}
+	groups := []uint64{}
+	for _, char := range descriptor {
+		position := strings.IndexRune(descriptorInputCharset, char)
+		if position == -1 {
+			return "", errp.Newf("invalid character in descriptor: %q", char)
+		}
+		symbols = append(symbols, uint64(position&31))
+		groups = append(groups, uint64(position>>5))
+		if len(groups) == 3 {
+			symbols = append(symbols, groups[0]*9+groups[1]*3+groups[2])
+			groups = groups[:0]
+		}
+	}
+	switch len(groups) {
+	case 1:
+		symbols = append(symbols, groups[0])
+	case 2:
+		symbols = append(symbols, groups[0]*3+groups[1])
+	}
+	symbols = append(symbols, make([]uint64, descriptorChecksumLength)...)
+	checksum := descriptorPolymod(symbols) ^ 1
+	result := make([]byte, descriptorChecksumLength)
+	for i := range result {
+		result[i] = descriptorChecksumCharset[(checksum>>(5*(7-i)))&31]
+	}
+	return string(result), nil
+}
+
+// Descriptor returns the BIP-380 output descriptor with checksum of the receive addresses, or of
+// the change addresses if `change` is true, e.g. `wpkh([f00dbabe/84'/0'/0']xpub.../0/*)#checksum`.
+func (configuration *Configuration) Descriptor(change bool) (string, error) {
+	chain := 0
+	if change {
+		chain = 1
+	}
+	keyExpression := func(keyInfo *KeyInfo) string {
+		return fmt.Sprintf("%s/%d/*", keyInfo.Encode(), chain)
+	}
+	var descriptor string
+	switch {
+	case configuration.BitcoinSimple != nil:
+		key := keyExpression(&configuration.BitcoinSimple.KeyInfo)
+		switch configuration.BitcoinSimple.ScriptType {
+		case ScriptTypeP2PKH:
+			descriptor = fmt.Sprintf("pkh(%s)", key)
+		case ScriptTypeP2WPKHP2SH:
+			descriptor = fmt.Sprintf("sh(wpkh(%s))", key)
+		case ScriptTypeP2WPKH:
+			descriptor = fmt.Sprintf("wpkh(%s)", key)
+		case ScriptTypeP2TR:
+			descriptor = fmt.Sprintf("tr(%s)", key)
+		default:
+			return "", errp.Newf("unsupported script type: %s", configuration.BitcoinSimple.ScriptType)
+		}
+	case configuration.BitcoinMultisig != nil:
+		multisig := configuration.BitcoinMultisig
+		keys := make([]string, len(multisig.KeyInfos))
+		for i := range multisig.KeyInfos {
+			keys[i] = keyExpression(&multisig.KeyInfos[i])
+		}
+		sortedMulti := fmt.Sprintf("sortedmulti(%d,%s)", multisig.Threshold, strings.Join(keys, ","))
+		switch multisig.ScriptType {
+		case ScriptTypeP2WSH:
+			descriptor = fmt.Sprintf("wsh(%s)", sortedMulti)
+		case ScriptTypeP2WSHP2SH:
+			descriptor = fmt.Sprintf("sh(wsh(%s))", sortedMulti)
+		default:
+			return "", errp.Newf("unsupported script type: %s", multisig.ScriptType)
+		}
+	default:
+		return "", errp.New("descriptors are only available for Bitcoin-based configurations")
+	}
+	checksum, err := DescriptorChecksum(descriptor)
+	if err != nil {
+		return "", err
+	}
+	return descriptor + "#" + checksum, nil
+}
+
+// ParseDescriptor parses a single-sig BIP-380 output descriptor of the form `pkh(KEY)`,
+// `sh(wpkh(KEY))`, `wpkh(KEY)` or `tr(KEY)`. The key must be followed by the receive (`/0/*`) or
+// change (`/1/*`) derivation, or both (`/<0;1>/*`). If the key has no key origin info, the key
+// itself is treated as the root key, i.e. the keypath is `m` and the root fingerprint is the
+// fingerprint of the key. The checksum is optional, but is verified if present.
+func ParseDescriptor(descriptor string) (*Configuration, error) {
+	descriptor = strings.TrimSpace(descriptor)
+	if body, checksum, ok := strings.Cut(descriptor, "#"); ok {
+		expectedChecksum, err := DescriptorChecksum(body)
+		if err != nil {
+			return nil, err
+		}
+		if checksum != expectedChecksum {
+			return nil, errp.Newf("invalid descriptor checksum: %s", checksum)
+		}
+		descriptor = body
+	}
+	var scriptType ScriptType
+	var key string
+	for _, candidate := range []struct {
+		prefix     string
+		suffix     string
+		scriptType ScriptType
+	}{
+		{"pkh(", ")", ScriptTypeP2PKH},
+		{"sh(wpkh(", "))", ScriptTypeP2WPKHP2SH},
+		{"wpkh(", ")", ScriptTypeP2WPKH},
+		{"tr(", ")", ScriptTypeP2TR},
+	} {
+		if strings.HasPrefix(descriptor, candidate.prefix) && strings.HasSuffix(descriptor, candidate.suffix) {
+			scriptType = candidate.scriptType
+			key = strings.TrimSuffix(strings.TrimPrefix(descriptor, candidate.prefix), candidate.suffix)
+			break
+		}
+	}
+	if scriptType == "" {
+		return nil, errp.Newf("unsupported descriptor: %s", descriptor)
+	}
+	origin := ""
+	if strings.HasPrefix(key, "[") {
+		originEnd := strings.Index(key, "]")
+		if originEnd == -1 {
+			return nil, errp.New("invalid key origin info")
+		}
+		origin, key = key[:originEnd+1], key[originEnd+1:]
+	}
+	xpub, derivation, _ := strings.Cut(key, "/")
+	switch derivation {
+	case "0/*", "1/*", "<0;1>/*":
+	default:
+		return nil, errp.Newf("unsupported key derivation: /%s", derivation)
+	}
+	if origin == "" {
+		extendedPublicKey, err := hdkeychain.NewKeyFromString(xpub)
+		if err != nil {
+			return nil, errp.Wrap(err, "Could not read an extended public key.")
+		}
+		if extendedPublicKey.IsPrivate() {
+			return nil, errp.New("An extended key is private! Only extended public keys are accepted.")
+		}
+		rootFingerprint, err := ExtendedKeyFingerprint(extendedPublicKey)
+		if err != nil {
+			return nil, err
+		}
+		return NewBitcoinConfiguration(
+			scriptType, rootFingerprint, NewEmptyAbsoluteKeypath(), extendedPublicKey), nil
+	}
+	keyInfo, err := ParseKeyInfo(origin + xpub)
+	if err != nil {
+		return nil, err
+	}
+	return NewBitcoinConfiguration(
+		scriptType, keyInfo.RootFingerprint, keyInfo.AbsoluteKeypath, keyInfo.ExtendedPublicKey), nil
+}
+
+// ExtendedKeyFingerprint returns the BIP-32 fingerprint of the extended key, the first 32 bits of
+// the hash160 of its public key. It is used as the root fingerprint of keys without key origin.
+func ExtendedKeyFingerprint(extendedKey *hdkeychain.ExtendedKey) ([]byte, error) {
+	publicKey, err := extendedKey.ECPubKey()
+	if err != nil {
+		return nil, errp.WithStack(err)
+	}
+	return btcutil.Hash160(publicKey.SerializeCompressed())[:4], nil
+}
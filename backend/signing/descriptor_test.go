@@ -0,0 +1,156 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signing
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/stretchr/testify/require"
+)
+
+const testDescriptorXPub = "xpub6DJ2dNUysrn5Vt36jH2KLBT2i1auw1tTSSomg8PhqNiUtx8QX2SvC9nrHu81fT41fvDUnhMjEzQgXnQjKEu3oaqMSzhSrHMxyyoEAmUHQbY"
+
+func TestDescriptorChecksum(t *testing.T) {
+	for descriptor, expected := range map[string]string{
+		"raw(deadbeef)": "89f8spxm",
+		"addr(mkmZxiEcEd8ZqjQWVZuC6so5dFMKEFpN2j)":                 "02wpgw69",
+		"wpkh([d34db33f/84h/0h/0h]" + testDescriptorXPub + "/0/*)": "cjjspncu",
+	} {
+		checksum, err := DescriptorChecksum(descriptor)
+		require.NoError(t, err)
+		require.Equal(t, expected, checksum, descriptor)
+	}
+	_, err := DescriptorChecksum("wpkh(é)")
+	require.Error(t, err)
+}
+
+func TestDescriptor(t *testing.T) {
+	xpub, err := hdkeychain.NewKeyFromString(testDescriptorXPub)
+	require.NoError(t, err)
+	rootFingerprint := []byte{0xd3, 0x4d, 0xb3, 0x3f}
+
+	cfg := NewBitcoinConfiguration(ScriptTypeP2WPKH, rootFingerprint, mustKeypath("m/84'/0'/0'"), xpub)
+	descriptor, err := cfg.Descriptor(false)
+	require.NoError(t, err)
+	require.Equal(t,
+		"wpkh([d34db33f/84'/0'/0']"+testDescriptorXPub+"/0/*)#trd0mf0l",
+		descriptor)
+	descriptor, err = cfg.Descriptor(true)
+	require.NoError(t, err)
+	require.Equal(t,
+		"wpkh([d34db33f/84'/0'/0']"+testDescriptorXPub+"/1/*)#6hgwxul8",
+		descriptor)
+
+	for scriptType, prefix := range map[ScriptType]string{
+		ScriptTypeP2PKH:      "pkh([d34db33f/84'/0'/0']",
+		ScriptTypeP2WPKHP2SH: "sh(wpkh([d34db33f/84'/0'/0']",
+		ScriptTypeP2WPKH:     "wpkh([d34db33f/84'/0'/0']",
+		ScriptTypeP2TR:       "tr([d34db33f/84'/0'/0']",
+	} {
+		cfg := NewBitcoinConfiguration(scriptType, rootFingerprint, mustKeypath("m/84'/0'/0'"), xpub)
+		descriptor, err := cfg.Descriptor(false)
+		require.NoError(t, err)
+		require.Regexp(t, `^\Q`+prefix+testDescriptorXPub+`/0/*\E\)+#[a-z0-9]{8}$`, descriptor)
+
+		// Round trip.
+		parsed, err := ParseDescriptor(descriptor)
+		require.NoError(t, err)
+		require.Equal(t, cfg.String(), parsed.String())
+		require.Equal(t, cfg.ExtendedPublicKey().String(), parsed.ExtendedPublicKey().String())
+		require.Equal(t, rootFingerprint, parsed.BitcoinSimple.KeyInfo.RootFingerprint)
+	}
+
+	_, err = NewEthereumConfiguration(rootFingerprint, mustKeypath("m/44'/60'/0'/0"), xpub).Descriptor(false)
+	require.Error(t, err)
+}
+
+func TestMultisigDescriptor(t *testing.T) {
+	keyInfos := make([]KeyInfo, 2)
+	for i := range keyInfos {
+		xpub, err := hdkeychain.NewMaster(bytes32(byte(i+1)), &chaincfg.MainNetParams)
+		require.NoError(t, err)
+		xpub, err = xpub.Neuter()
+		require.NoError(t, err)
+		keyInfos[i] = KeyInfo{
+			RootFingerprint:   []byte{byte(i + 1), 0, 0, 0},
+			AbsoluteKeypath:   mustKeypath("m/48'/0'/0'/2'"),
+			ExtendedPublicKey: xpub,
+		}
+	}
+	cfg, err := NewBitcoinMultisigConfiguration(ScriptTypeP2WSH, 2, keyInfos, 0)
+	require.NoError(t, err)
+	descriptor, err := cfg.Descriptor(false)
+	require.NoError(t, err)
+	require.Regexp(t,
+		`^\Qwsh(sortedmulti(2,`+keyInfos[0].Encode()+`/0/*,`+keyInfos[1].Encode()+`/0/*))\E#[a-z0-9]{8}$`,
+		descriptor)
+
+	cfg, err = NewBitcoinMultisigConfiguration(ScriptTypeP2WSHP2SH, 1, keyInfos, 1)
+	require.NoError(t, err)
+	descriptor, err = cfg.Descriptor(true)
+	require.NoError(t, err)
+	require.Regexp(t,
+		`^\Qsh(wsh(sortedmulti(1,`+keyInfos[0].Encode()+`/1/*,`+keyInfos[1].Encode()+`/1/*)))\E#[a-z0-9]{8}$`,
+		descriptor)
+}
+
+func TestParseDescriptor(t *testing.T) {
+	cfg, err := ParseDescriptor(
+		"  wpkh([d34db33f/84h/0h/0h]" + testDescriptorXPub + "/0/*)#cjjspncu\n")
+	require.NoError(t, err)
+	require.Equal(t, ScriptTypeP2WPKH, cfg.ScriptType())
+	require.Equal(t, "m/84'/0'/0'", cfg.AbsoluteKeypath().Encode())
+	require.Equal(t, []byte{0xd3, 0x4d, 0xb3, 0x3f}, cfg.BitcoinSimple.KeyInfo.RootFingerprint)
+	require.Equal(t, testDescriptorXPub, cfg.ExtendedPublicKey().String())
+
+	// The checksum is optional.
+	cfg, err = ParseDescriptor("sh(wpkh([d34db33f/49'/0'/0']" + testDescriptorXPub + "/<0;1>/*))")
+	require.NoError(t, err)
+	require.Equal(t, ScriptTypeP2WPKHP2SH, cfg.ScriptType())
+
+	// Without key origin info, the key is the root key.
+	cfg, err = ParseDescriptor("tr(" + testDescriptorXPub + "/0/*)")
+	require.NoError(t, err)
+	require.Equal(t, ScriptTypeP2TR, cfg.ScriptType())
+	require.Empty(t, cfg.AbsoluteKeypath())
+	rootFingerprint, err := ExtendedKeyFingerprint(cfg.ExtendedPublicKey())
+	require.NoError(t, err)
+	require.Equal(t, rootFingerprint, cfg.BitcoinSimple.KeyInfo.RootFingerprint)
+	_, err = cfg.AccountNumber()
+	require.Error(t, err)
+
+	for _, invalid := range []string{
+		// Wrong checksum.
+		"wpkh([d34db33f/84h/0h/0h]" + testDescriptorXPub + "/0/*)#cjjspncv",
+		// Invalid key origin.
+		"wpkh([d34db33f/84h/0h/0h" + testDescriptorXPub + "/0/*)",
+		"wpkh([d34db33f/84h/0x/0h]" + testDescriptorXPub + "/0/*)",
+		// Unsupported derivations.
+		"wpkh([d34db33f/84h/0h/0h]" + testDescriptorXPub + ")",
+		"wpkh([d34db33f/84h/0h/0h]" + testDescriptorXPub + "/2/*)",
+		"wpkh([d34db33f/84h/0h/0h]" + testDescriptorXPub + "/0/*h)",
+		// Unsupported script types.
+		"sh([d34db33f/84h/0h/0h]" + testDescriptorXPub + "/0/*)",
+		"wsh(sortedmulti(1,[d34db33f/84h/0h/0h]" + testDescriptorXPub + "/0/*))",
+		// Private key.
+		"wpkh([d34db33f/84h/0h/0h]tprv8ZgxMBicQKsPd9TeAdPADNnSyH9SSUUbTVeFszDE23Ki6TBB5nCefAdHkK8Fm3qMQR6sHwA56zqRmKmxnHk37JkiFzvncDqoKmPWubu7hDF/0/*)",
+		"",
+	} {
+		_, err := ParseDescriptor(invalid)
+		require.Error(t, err, invalid)
+	}
+}
@@ -14,7 +14,7 @@
 
 package signing
 
-// ScriptType indicates which type of output should be produced in case of singlesig.
+// ScriptType indicates which type of output should be produced.
 type ScriptType string
 
 const (
@@ -29,4 +29,10 @@ const (
 
 	// ScriptTypeP2TR is a BIP-86 segwit v1 PayToTaproot output.
 	ScriptTypeP2TR ScriptType = "p2tr"
+
+	// ScriptTypeP2WSH is a segwit v0 PayToScriptHash multisig output.
+	ScriptTypeP2WSH ScriptType = "p2wsh"
+
+	// ScriptTypeP2WSHP2SH is a segwit v0 PayToScriptHash multisig output wrapped in p2sh.
+	ScriptTypeP2WSHP2SH ScriptType = "p2wsh-p2sh"
 )
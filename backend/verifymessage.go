@@ -0,0 +1,137 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc"
+	coinpkg "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// MessageSignatureFormat is the format of a signed message.
+type MessageSignatureFormat string
+
+const (
+	// MessageSignatureFormatElectrum is the compact BTC/LTC message signature also used by
+	// Electrum and Bitcoin Core.
+	MessageSignatureFormatElectrum MessageSignatureFormat = "electrum"
+	// MessageSignatureFormatBIP322Simple is a BIP-322 signature in the simple format.
+	MessageSignatureFormatBIP322Simple MessageSignatureFormat = "bip322-simple"
+	// MessageSignatureFormatBIP322Full is a BIP-322 signature in the full format.
+	MessageSignatureFormatBIP322Full MessageSignatureFormat = "bip322-full"
+	// MessageSignatureFormatEIP191 is an Ethereum personal signature.
+	MessageSignatureFormatEIP191 MessageSignatureFormat = "eip191"
+	// MessageSignatureFormatEIP712 is an Ethereum typed data signature.
+	MessageSignatureFormatEIP712 MessageSignatureFormat = "eip712"
+)
+
+// VerifiedMessage is the result of VerifyMessage.
+type VerifiedMessage struct {
+	// Valid is true if the signature was created by the expected address.
+	Valid  bool                   `json:"valid"`
+	Format MessageSignatureFormat `json:"format"`
+	// RecoveredAddress is the address of the key that created the signature. If the signature is
+	// not valid, it differs from the expected address. BIP-322 signatures don't reveal the key, so
+	// it is the expected address for valid BIP-322 signatures and empty for invalid ones.
+	RecoveredAddress string `json:"recoveredAddress"`
+	// PublicKey is the hex encoded compressed public key that created the signature. Empty for
+	// BIP-322 signatures.
+	PublicKey string `json:"publicKey,omitempty"`
+	// ScriptType is the script type of the recovered BTC/LTC address.
+	ScriptType signing.ScriptType `json:"scriptType,omitempty"`
+}
+
+// VerifyMessage verifies a message signature created by `address`. Errors are returned for
+// malformed input, while signatures by a different key result in `Valid == false`.
+//
+// For BTC and LTC, the signature is base64 encoded and can be a compact Electrum-style signature of
+// any script type, or a BIP-322 signature. For ETH, the signature is hex encoded. If `typedData` is
+// true, the message is EIP-712 typed data in JSON, otherwise the message is verified as an EIP-191
+// personal message.
+func (backend *Backend) VerifyMessage(
+	coinCode coinpkg.Code, address string, message string, signature string, typedData bool,
+) (*VerifiedMessage, error) {
+	coin, err := backend.Coin(coinCode)
+	if err != nil {
+		return nil, err
+	}
+	switch specificCoin := coin.(type) {
+	case *btc.Coin:
+		decodedSignature, err := base64.StdEncoding.DecodeString(signature)
+		if err != nil {
+			return nil, errp.Wrap(err, "The signature is not base64 encoded")
+		}
+		if len(decodedSignature) != 65 {
+			bip322Format, err := btc.VerifyMessageBIP322(specificCoin, address, []byte(message), decodedSignature)
+			format := MessageSignatureFormatBIP322Simple
+			if bip322Format == btc.BIP322FormatFull {
+				format = MessageSignatureFormatBIP322Full
+			}
+			if errp.Cause(err) == btc.ErrBIP322InvalidSignature {
+				return &VerifiedMessage{Valid: false, Format: format}, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			return &VerifiedMessage{Valid: true, Format: format, RecoveredAddress: address}, nil
+		}
+		signer, err := btc.RecoverMessageSigner(specificCoin, address, []byte(message), decodedSignature)
+		if err != nil {
+			return nil, err
+		}
+		return &VerifiedMessage{
+			Valid:            signer.Address.EncodeAddress() == address,
+			Format:           MessageSignatureFormatElectrum,
+			RecoveredAddress: signer.Address.EncodeAddress(),
+			PublicKey:        hex.EncodeToString(signer.PublicKey.SerializeCompressed()),
+			ScriptType:       signer.ScriptType,
+		}, nil
+	case *eth.Coin:
+		if !eth.IsValidEthAddress(address) {
+			return nil, errp.New("Invalid address")
+		}
+		decodedSignature, err := hex.DecodeString(strings.TrimPrefix(signature, "0x"))
+		if err != nil {
+			return nil, errp.Wrap(err, "The signature is not hex encoded")
+		}
+		format := MessageSignatureFormatEIP191
+		recoverSigner := eth.RecoverMessageSigner
+		if typedData {
+			format = MessageSignatureFormatEIP712
+			recoverSigner = eth.RecoverTypedMessageSigner
+		}
+		publicKey, err := recoverSigner([]byte(message), decodedSignature)
+		if err != nil {
+			return nil, err
+		}
+		recoveredAddress := crypto.PubkeyToAddress(*publicKey)
+		return &VerifiedMessage{
+			Valid:            recoveredAddress == common.HexToAddress(address),
+			Format:           format,
+			RecoveredAddress: recoveredAddress.Hex(),
+			PublicKey:        hex.EncodeToString(crypto.CompressPubkey(publicKey)),
+		}, nil
+	default:
+		return nil, errp.Newf("Message signatures are not supported for %s", coinCode)
+	}
+}
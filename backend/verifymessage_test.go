@@ -0,0 +1,160 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/addresses"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/types"
+	coinpkg "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/logging"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBIP322SignAndVerify(t *testing.T) {
+	b := newBackend(t, testnetDisabled, regtestDisabled)
+	defer b.Close()
+	ks := keystoreHelper1()
+	log := logging.Get().WithGroup("verifymessage_test")
+	const message = "I own this address"
+
+	for _, coinCode := range []coinpkg.Code{coinpkg.CodeBTC, coinpkg.CodeLTC} {
+		coin, err := b.Coin(coinCode)
+		require.NoError(t, err)
+		btcCoin := coin.(*btc.Coin)
+		for _, test := range []struct {
+			scriptType signing.ScriptType
+			keypath    string
+		}{
+			{signing.ScriptTypeP2PKH, "m/44'/0'/0'"},
+			{signing.ScriptTypeP2WPKHP2SH, "m/49'/0'/0'"},
+			{signing.ScriptTypeP2WPKH, "m/84'/0'/0'"},
+			{signing.ScriptTypeP2TR, "m/86'/0'/0'"},
+		} {
+			if coinCode == coinpkg.CodeLTC && test.scriptType == signing.ScriptTypeP2TR {
+				continue
+			}
+			xpub, err := ks.ExtendedPublicKey(coin, mustKeypath(test.keypath))
+			require.NoError(t, err)
+			address := addresses.NewAccountAddress(
+				signing.NewBitcoinConfiguration(test.scriptType, rootFingerprint1, mustKeypath(test.keypath), xpub),
+				types.Derivation{Change: false, AddressIndex: 3},
+				btcCoin.Net(),
+				log,
+			)
+			for _, format := range []btc.BIP322Format{btc.BIP322FormatSimple, btc.BIP322FormatFull} {
+				signature, signedFormat, err := btc.SignMessageBIP322(ks, btcCoin, address, []byte(message), format)
+				require.NoError(t, err)
+				if test.scriptType == signing.ScriptTypeP2PKH {
+					// There is no witness for the simple format.
+					require.Equal(t, btc.BIP322FormatFull, signedFormat)
+				} else {
+					require.Equal(t, format, signedFormat)
+				}
+				encoded := base64.StdEncoding.EncodeToString(signature)
+
+				expectedFormat := MessageSignatureFormatBIP322Simple
+				if signedFormat == btc.BIP322FormatFull {
+					expectedFormat = MessageSignatureFormatBIP322Full
+				}
+
+				result, err := b.VerifyMessage(coinCode, address.EncodeForHumans(), message, encoded, false)
+				require.NoError(t, err, test.scriptType)
+				require.Equal(t, &VerifiedMessage{
+					Valid:            true,
+					Format:           expectedFormat,
+					RecoveredAddress: address.EncodeForHumans(),
+				}, result)
+
+				result, err = b.VerifyMessage(coinCode, address.EncodeForHumans(), message+".", encoded, false)
+				require.NoError(t, err)
+				require.Equal(t, &VerifiedMessage{Valid: false, Format: expectedFormat}, result)
+			}
+		}
+	}
+
+	// Malformed signatures are an error.
+	_, err := b.VerifyMessage(coinpkg.CodeBTC, "bc1qxp6xr63t098rl9udlynrktq00un6vqduzjgua3", message, "AA==", false)
+	require.Error(t, err)
+	_, err = b.VerifyMessage(coinpkg.CodeBTC, "bc1qxp6xr63t098rl9udlynrktq00un6vqduzjgua3", message, "not base64", false)
+	require.Error(t, err)
+}
+
+func TestVerifyMessage(t *testing.T) {
+	b := newBackend(t, testnetDisabled, regtestDisabled)
+	defer b.Close()
+	const message = "I own this address"
+
+	// BIP-322 signatures are detected automatically.
+	coin, err := b.Coin(coinpkg.CodeBTC)
+	require.NoError(t, err)
+	xpub, err := keystoreHelper1().ExtendedPublicKey(coin, mustKeypath("m/86'/0'/0'"))
+	require.NoError(t, err)
+	address := addresses.NewAccountAddress(
+		signing.NewBitcoinConfiguration(signing.ScriptTypeP2TR, rootFingerprint1, mustKeypath("m/86'/0'/0'"), xpub),
+		types.Derivation{Change: false, AddressIndex: 0},
+		coin.(*btc.Coin).Net(),
+		logging.Get().WithGroup("verifymessage_test"),
+	)
+	signature, _, err := btc.SignMessageBIP322(
+		keystoreHelper1(), coin.(*btc.Coin), address, []byte(message), btc.BIP322FormatFull)
+	require.NoError(t, err)
+	result, err := b.VerifyMessage(coinpkg.CodeBTC, address.EncodeForHumans(), message,
+		base64.StdEncoding.EncodeToString(signature), false)
+	require.NoError(t, err)
+	require.Equal(t, &VerifiedMessage{
+		Valid:            true,
+		Format:           MessageSignatureFormatBIP322Full,
+		RecoveredAddress: address.EncodeForHumans(),
+	}, result)
+
+	// EIP-191 personal signatures.
+	privateKey, err := crypto.HexToECDSA("4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+	require.NoError(t, err)
+	ethAddress := crypto.PubkeyToAddress(privateKey.PublicKey).Hex()
+	ethSignature, err := crypto.Sign(accounts.TextHash([]byte(message)), privateKey)
+	require.NoError(t, err)
+	result, err = b.VerifyMessage(coinpkg.CodeETH, ethAddress, message, hexutil.Encode(ethSignature), false)
+	require.NoError(t, err)
+	require.Equal(t, &VerifiedMessage{
+		Valid:            true,
+		Format:           MessageSignatureFormatEIP191,
+		RecoveredAddress: ethAddress,
+		PublicKey:        hex.EncodeToString(crypto.CompressPubkey(&privateKey.PublicKey)),
+	}, result)
+
+	// Signed by a different address.
+	result, err = b.VerifyMessage(coinpkg.CodeETH, "0xfb6916095ca1df60bb79ce92ce3ea74c37c5d359", message,
+		hexutil.Encode(ethSignature), false)
+	require.NoError(t, err)
+	require.False(t, result.Valid)
+	require.Equal(t, ethAddress, result.RecoveredAddress)
+
+	// The message is not typed data.
+	_, err = b.VerifyMessage(coinpkg.CodeETH, ethAddress, message, hexutil.Encode(ethSignature), true)
+	require.Error(t, err)
+	_, err = b.VerifyMessage(coinpkg.CodeETH, "0x12", message, hexutil.Encode(ethSignature), false)
+	require.Error(t, err)
+	_, err = b.VerifyMessage(coinpkg.CodeETH, ethAddress, message, "0xzz", false)
+	require.Error(t, err)
+}
@@ -0,0 +1,184 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"bytes"
+	"strings"
+
+	accountsTypes "github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/types"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc"
+	coinpkg "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/config"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// syntheticKeystoreName is the name of keystores which are added to the config for watch-only
+// accounts created from an xpub or descriptors.
+const syntheticKeystoreName = "Watch-only"
+
+// parseWatchonlyExtendedKey parses an xpub, ypub, zpub or tpub into a signing configuration. The
+// script type is derived from the version bytes for ypub and zpub. For xpub and tpub, which are used
+// for all script types, it must be provided. As the origin of the key is unknown, the key is used as
+// the root key.
+func parseWatchonlyExtendedKey(
+	coin *btc.Coin, extendedKey string, scriptType signing.ScriptType) (*signing.Configuration, error) {
+	extendedPublicKey, err := hdkeychain.NewKeyFromString(extendedKey)
+	if err != nil {
+		return nil, errp.Wrap(err, "Could not read an extended public key.")
+	}
+	if extendedPublicKey.IsPrivate() {
+		return nil, errp.New("An extended key is private! Only extended public keys are accepted.")
+	}
+	version := extendedPublicKey.Version()
+	if !bytes.Equal(version, coin.Net().HDPublicKeyID[:]) {
+		var versionScriptType signing.ScriptType
+		for _, candidate := range []signing.ScriptType{signing.ScriptTypeP2WPKHP2SH, signing.ScriptTypeP2WPKH} {
+			candidateVersion := btc.XPubVersionForScriptType(coin, candidate)
+			if bytes.Equal(version, candidateVersion[:]) {
+				versionScriptType = candidate
+			}
+		}
+		if versionScriptType == "" {
+			return nil, errp.Newf("The extended public key can't be used for %s", coin.Name())
+		}
+		if scriptType != "" && scriptType != versionScriptType {
+			return nil, errp.Newf("The extended public key is for %s, not %s", versionScriptType, scriptType)
+		}
+		scriptType = versionScriptType
+	}
+	switch scriptType {
+	case signing.ScriptTypeP2PKH, signing.ScriptTypeP2WPKHP2SH, signing.ScriptTypeP2WPKH, signing.ScriptTypeP2TR:
+	case "":
+		return nil, errp.New("The script type is required for this extended public key")
+	default:
+		return nil, errp.Newf("Unsupported script type: %s", scriptType)
+	}
+	rootFingerprint, err := signing.ExtendedKeyFingerprint(extendedPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	return signing.NewBitcoinConfiguration(
+		scriptType, rootFingerprint, signing.NewEmptyAbsoluteKeypath(), extendedPublicKey), nil
+}
+
+// parseWatchonlyDescriptors parses BIP-380 output descriptors into signing configurations. The
+// extended public keys must be xpubs on mainnet and tpubs on testnet.
+func parseWatchonlyDescriptors(coin *btc.Coin, descriptors []string) (signing.Configurations, error) {
+	parsedConfigurations, err := parseDescriptors(descriptors)
+	if err != nil {
+		return nil, err
+	}
+	for _, parsedConfiguration := range parsedConfigurations {
+		version := parsedConfiguration.ExtendedPublicKey().Version()
+		if !bytes.Equal(version, coin.Net().HDPublicKeyID[:]) {
+			return nil, errp.Newf("The extended public key can't be used for %s", coin.Name())
+		}
+	}
+	return parsedConfigurations, nil
+}
+
+// CreateWatchonlyAccount adds a watch-only account without a keystore, e.g. to follow the balance
+// of a wallet whose hardware wallet is not at hand. `extendedKeyOrDescriptors` is either an
+// xpub/ypub/zpub/tpub, or one or more BIP-380 output descriptors separated by newlines. The script
+// type is only used for xpubs and tpubs, as it can't be derived from them.
+//
+// If the keystore of the account is unknown, a synthetic keystore is added to the config. Such
+// accounts are always loaded, but can't sign until a keystore with the same root fingerprint is
+// connected.
+//
+// `name` is the account name, shown to the user. If empty, a default name will be set.
+func (backend *Backend) CreateWatchonlyAccount(
+	coinCode coinpkg.Code,
+	extendedKeyOrDescriptors string,
+	scriptType signing.ScriptType,
+	name string,
+) (accountsTypes.Code, error) {
+	coin, err := backend.Coin(coinCode)
+	if err != nil {
+		return "", err
+	}
+	btcCoin, ok := coin.(*btc.Coin)
+	if !ok {
+		return "", errp.Newf("Watch-only accounts are not supported for %s", coinCode)
+	}
+	var parsedConfigurations signing.Configurations
+	if strings.Contains(extendedKeyOrDescriptors, "(") {
+		parsedConfigurations, err = parseWatchonlyDescriptors(
+			btcCoin, strings.Split(extendedKeyOrDescriptors, "\n"))
+	} else {
+		var parsedConfiguration *signing.Configuration
+		parsedConfiguration, err = parseWatchonlyExtendedKey(
+			btcCoin, strings.TrimSpace(extendedKeyOrDescriptors), scriptType)
+		parsedConfigurations = signing.Configurations{parsedConfiguration}
+	}
+	if err != nil {
+		return "", err
+	}
+	rootFingerprint, err := parsedConfigurations.RootFingerprint()
+	if err != nil {
+		return "", err
+	}
+	for _, parsedConfiguration := range parsedConfigurations {
+		if !bytes.Equal(parsedConfiguration.BitcoinSimple.KeyInfo.RootFingerprint, rootFingerprint) {
+			return "", errp.New("The descriptors belong to different wallets")
+		}
+	}
+
+	// The extended public keys are stored with the same version bytes as the keys of other
+	// accounts.
+	signingConfigurations := make(signing.Configurations, len(parsedConfigurations))
+	for i, parsedConfiguration := range parsedConfigurations {
+		extendedPublicKey, err := parsedConfiguration.ExtendedPublicKey().CloneWithVersion(
+			chaincfg.MainNetParams.HDPublicKeyID[:])
+		if err != nil {
+			return "", errp.WithStack(err)
+		}
+		signingConfigurations[i] = signing.NewBitcoinConfiguration(
+			parsedConfiguration.ScriptType(),
+			parsedConfiguration.BitcoinSimple.KeyInfo.RootFingerprint,
+			parsedConfiguration.AbsoluteKeypath(),
+			extendedPublicKey)
+	}
+
+	if name == "" {
+		name = coin.Name() + " watch-only"
+	}
+	accountCode := watchonlyAccountCode(rootFingerprint, coinCode, signingConfigurations)
+	err = backend.config.ModifyAccountsConfig(func(accountsConfig *config.AccountsConfig) error {
+		if _, err := accountsConfig.LookupKeystore(rootFingerprint); err != nil {
+			keystoreConfig := accountsConfig.GetOrAddKeystore(rootFingerprint)
+			keystoreConfig.Name = syntheticKeystoreName
+			keystoreConfig.Watchonly = true
+			keystoreConfig.Synthetic = true
+		}
+		t := true
+		return backend.persistAccount(config.Account{
+			Watch:                 &t,
+			CoinCode:              coinCode,
+			Name:                  name,
+			Code:                  accountCode,
+			SigningConfigurations: signingConfigurations,
+		}, accountsConfig)
+	})
+	if err != nil {
+		return "", err
+	}
+	backend.ReinitializeAccounts()
+	return accountCode, nil
+}
@@ -0,0 +1,123 @@
+// Copyright 2026 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"strings"
+	"testing"
+
+	coinpkg "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/keystore"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateWatchonlyAccount(t *testing.T) {
+	b := newBackend(t, testnetDisabled, regtestDisabled)
+	defer b.Close()
+
+	btcCoin, err := b.Coin(coinpkg.CodeBTC)
+	require.NoError(t, err)
+	xpub, err := keystoreHelper2().ExtendedPublicKey(btcCoin, mustKeypath("m/84'/0'/0'"))
+	require.NoError(t, err)
+	zpub, err := xpub.CloneWithVersion([]byte{0x04, 0xb2, 0x47, 0x46})
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(zpub.String(), "zpub"))
+
+	// Invalid input.
+	_, err = b.CreateWatchonlyAccount(coinpkg.CodeBTC, "zpub123", "", "")
+	require.Error(t, err)
+	_, err = b.CreateWatchonlyAccount(coinpkg.CodeETH, zpub.String(), "", "")
+	require.Error(t, err)
+	// The script type of an xpub is ambiguous.
+	_, err = b.CreateWatchonlyAccount(coinpkg.CodeBTC, xpub.String(), "", "")
+	require.Error(t, err)
+	// The script type does not match the zpub.
+	_, err = b.CreateWatchonlyAccount(coinpkg.CodeBTC, zpub.String(), signing.ScriptTypeP2TR, "")
+	require.Error(t, err)
+
+	accountCode, err := b.CreateWatchonlyAccount(coinpkg.CodeBTC, " "+zpub.String()+"\n", "", "")
+	require.NoError(t, err)
+	rootFingerprint, err := signing.ExtendedKeyFingerprint(xpub)
+	require.NoError(t, err)
+	accountsConfig := b.config.AccountsConfig()
+	accountConfig := accountsConfig.Lookup(accountCode)
+	require.NotNil(t, accountConfig)
+	require.Equal(t, "Bitcoin watch-only", accountConfig.Name)
+	require.Len(t, accountConfig.SigningConfigurations, 1)
+	require.Equal(t, signing.ScriptTypeP2WPKH, accountConfig.SigningConfigurations[0].ScriptType())
+	require.Equal(t, rootFingerprint, accountConfig.SigningConfigurations[0].BitcoinSimple.KeyInfo.RootFingerprint)
+	// Stored like the xpubs of other accounts.
+	require.Equal(t, xpub.String(), accountConfig.SigningConfigurations[0].ExtendedPublicKey().String())
+	syntheticKeystore, err := accountsConfig.LookupKeystore(rootFingerprint)
+	require.NoError(t, err)
+	require.True(t, syntheticKeystore.Synthetic)
+	require.True(t, syntheticKeystore.Watchonly)
+
+	// The account is loaded without a keystore, but can't sign.
+	account := b.Accounts().lookup(accountCode)
+	require.NotNil(t, account)
+	_, err = account.Config().ConnectKeystore()
+	require.Equal(t, keystore.ErrCannotSign, errp.Cause(err))
+	require.Error(t, b.SetWatchonly(rootFingerprint, false))
+
+	// The same xpub can't be added twice.
+	_, err = b.CreateWatchonlyAccount(coinpkg.CodeBTC, xpub.String(), signing.ScriptTypeP2WPKH, "")
+	require.Equal(t, errAccountAlreadyExists, errp.Cause(err))
+
+	// Descriptors with key origin info belong to the keystore with the root fingerprint.
+	descriptors := make([]string, 2)
+	for i, change := range []bool{false, true} {
+		xpub, err := keystoreHelper1().ExtendedPublicKey(btcCoin, mustKeypath("m/84'/0'/2'"))
+		require.NoError(t, err)
+		descriptors[i], err = signing.NewBitcoinConfiguration(
+			signing.ScriptTypeP2WPKH, rootFingerprint1, mustKeypath("m/84'/0'/2'"), xpub).Descriptor(change)
+		require.NoError(t, err)
+	}
+	// Testnet keys can't be used for a mainnet account.
+	tpubDescriptors := make([]string, 2)
+	for i, change := range []bool{false, true} {
+		xpub, err := keystoreHelper1().ExtendedPublicKey(btcCoin, mustKeypath("m/84'/0'/2'"))
+		require.NoError(t, err)
+		tpub, err := xpub.CloneWithVersion(chaincfg.TestNet3Params.HDPublicKeyID[:])
+		require.NoError(t, err)
+		tpubDescriptors[i], err = signing.NewBitcoinConfiguration(
+			signing.ScriptTypeP2WPKH, rootFingerprint1, mustKeypath("m/84'/0'/2'"), tpub).Descriptor(change)
+		require.NoError(t, err)
+		require.Contains(t, tpubDescriptors[i], "tpub")
+	}
+	_, err = b.CreateWatchonlyAccount(coinpkg.CodeBTC, strings.Join(tpubDescriptors, "\n"), "", "")
+	require.Error(t, err)
+
+	accountCode, err = b.CreateWatchonlyAccount(
+		coinpkg.CodeBTC, strings.Join(descriptors, "\n"), "", "company wallet")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(accountCode), "v0-55555555-btc-watchonly-"), accountCode)
+	accountConfig = b.config.AccountsConfig().Lookup(accountCode)
+	require.Equal(t, "company wallet", accountConfig.Name)
+	require.Equal(t, "m/84'/0'/2'", accountConfig.SigningConfigurations[0].AbsoluteKeypath().Encode())
+	require.NotNil(t, b.Accounts().lookup(accountCode))
+
+	// Once the keystore is connected, it is not synthetic anymore.
+	b.registerKeystore(makeBitBox02Multi())
+	keystoreConfig, err := b.config.AccountsConfig().LookupKeystore(rootFingerprint1)
+	require.NoError(t, err)
+	require.False(t, keystoreConfig.Synthetic)
+	syntheticKeystore, err = b.config.AccountsConfig().LookupKeystore(rootFingerprint)
+	require.NoError(t, err)
+	require.True(t, syntheticKeystore.Synthetic)
+}
@@ -58,6 +58,7 @@ export type TKeystore = {
   name: string;
   lastConnected: string;
   connected: boolean;
+  synthetic?: boolean;
 };
 
 export interface IAccount {
@@ -272,6 +273,8 @@ export interface ITransaction {
     note: string;
     numConfirmations: number;
     numConfirmationsComplete: number;
+    replacesTxID: string;
+    replacedByTxID: string;
     size: number;
     status: TTransactionStatus;
     time: string | null;
@@ -303,6 +306,74 @@ export const getTransaction = (code: AccountCode, id: ITransaction['internalID']
   return apiGet(`account/${code}/transaction?id=${id}`);
 };
 
+export type TTransactionInput = {
+  previousOutPoint: string;
+  sequence: number;
+  value: TAmountWithConversions | null;
+  address: string;
+  ours: boolean;
+  keypath?: string;
+};
+
+export type TTransactionOutput = {
+  index: number;
+  scriptType: string;
+  address: string;
+  value: TAmountWithConversions;
+  ours: boolean;
+  isChange: boolean;
+};
+
+export type TTransactionDetails = {
+  txID: string;
+  rawTx: string;
+  version: number;
+  lockTime: number;
+  rbf: boolean;
+  height: number;
+  inputs: TTransactionInput[];
+  outputs: TTransactionOutput[];
+};
+
+export const getTransactionDetails = (
+  code: AccountCode,
+  txID: ITransaction['txID'],
+): Promise<TTransactionDetails> => {
+  return apiGet(`account/${code}/transaction-details?id=${txID}`);
+};
+
+export type TScheduledTransaction = {
+  txID: string;
+  rawTx: string;
+  lockTime: number;
+  note: string;
+  created: string;
+};
+
+export const getScheduledTransactions = (
+  code: AccountCode,
+): Promise<TScheduledTransaction[]> => {
+  return apiGet(`account/${code}/scheduled-transactions`);
+};
+
+export const removeScheduledTransaction = (
+  code: AccountCode,
+  txID: TScheduledTransaction['txID'],
+): Promise<null> => {
+  return apiPost(`account/${code}/scheduled-transactions/remove`, txID);
+};
+
+export type TSilentPayments = {
+  enabled: boolean;
+  address?: string;
+  scanHeight?: number;
+  outputs?: number;
+};
+
+export const getSilentPayments = (code: AccountCode): Promise<TSilentPayments> => {
+  return apiGet(`account/${code}/silent-payments`);
+};
+
 export interface IExport {
     success: boolean;
     path: string;
@@ -336,12 +407,26 @@ export const getReceiveAddressList = (code: AccountCode) => {
   };
 };
 
+export type TTxRecipient = {
+  address: string;
+  amount: string;
+  sendAll: 'yes' | 'no';
+};
+
 export type TTxInput = {
   address: string;
   amount: string;
   sendAll: 'yes' | 'no';
   selectedUTXOs: string[];
   paymentRequest: Slip24 | null;
+  // Paid in the same transaction (batch payment). At most one recipient can use sendAll.
+  additionalRecipients?: TTxRecipient[];
+  // Absolute locktime: a block height, or a Unix timestamp if it is 500000000 or higher. A signed
+  // transaction is broadcast automatically once the locktime has passed.
+  lockTime?: number;
+  // BIP-78 payjoin endpoint of the receiver, e.g. from the `payjoin` field of a parsed payment URI.
+  // If the payjoin fails, the original transaction is sent instead.
+  payjoin?: string;
 } & (
   {
     useHighestFee: false;
@@ -369,8 +454,116 @@ export const proposeTx = (
   return apiPost(`account/${accountCode}/tx-proposal`, txInput);
 };
 
+export type TFeeInput = {
+  useHighestFee: false;
+  customFee: string;
+  feeTarget: FeeTargetCode;
+} | {
+  useHighestFee: true;
+};
+
+export type TAccelerationInput = {
+  txID: string;
+} & TFeeInput;
+
+export const proposeFeeBump = (
+  accountCode: AccountCode,
+  input: TAccelerationInput,
+): Promise<TTxProposalResult> => {
+  return apiPost(`account/${accountCode}/fee-bump-proposal`, input);
+};
+
+export const proposeCancel = (
+  accountCode: AccountCode,
+  input: TAccelerationInput,
+): Promise<TTxProposalResult> => {
+  return apiPost(`account/${accountCode}/cancel-proposal`, input);
+};
+
+export type TCPFPProposalResult = {
+  amount: TAmountWithConversions;
+  fee: TAmountWithConversions;
+  packageFeeRatePerKb: TAmountWithConversions;
+  success: true;
+  total: TAmountWithConversions;
+} | {
+  errorCode: string;
+  success: false;
+};
+
+export const proposeCPFP = (
+  accountCode: AccountCode,
+  input: TAccelerationInput,
+): Promise<TCPFPProposalResult> => {
+  return apiPost(`account/${accountCode}/cpfp-proposal`, input);
+};
+
+export type TConsolidationInput = TFeeInput & {
+  maxFeeRate: number;
+  futureFeeRate?: number;
+  scriptType?: ScriptType;
+  maxValue?: number;
+  address?: string;
+};
+
+export type TConsolidationProposalResult = {
+  amount: TAmountWithConversions;
+  coinCount: number;
+  fee: TAmountWithConversions;
+  futureFeeSavings: TAmountWithConversions;
+  netSavings: TAmountWithConversions;
+  savedVSize: number;
+  success: true;
+  total: TAmountWithConversions;
+} | {
+  errorCode: string;
+  success: false;
+};
+
+export const proposeConsolidation = (
+  accountCode: AccountCode,
+  input: TConsolidationInput,
+): Promise<TConsolidationProposalResult> => {
+  return apiPost(`account/${accountCode}/consolidation-proposal`, input);
+};
+
+export type TSweepInput = TFeeInput & {
+  keys: string[];
+  passphrase?: string;
+  scriptType?: ScriptType;
+};
+
+export type TSweepProposalResult = {
+  address: string;
+  amount: TAmountWithConversions;
+  coinCount: number;
+  fee: TAmountWithConversions;
+  success: true;
+  total: TAmountWithConversions;
+} | {
+  errorCode: string;
+  success: false;
+};
+
+export const proposeSweep = (
+  accountCode: AccountCode,
+  input: TSweepInput,
+): Promise<TSweepProposalResult> => {
+  return apiPost(`account/${accountCode}/sweep-proposal`, input);
+};
+
+export const sendSweep = (
+  code: AccountCode,
+  note: string,
+): Promise<TSendPSBT> => {
+  return apiPost(`account/${code}/sweep`, { note });
+};
+
 export type TSendTx = {
   success: true;
+  // Whether the payment was made as a payjoin, if the tx input had a payjoin endpoint. If false,
+  // a regular transaction was sent instead.
+  payjoin?: boolean;
 } | {
   success: false;
   aborted: true;
@@ -387,6 +580,69 @@ export const sendTx = (
   return apiPost(`account/${code}/sendtx`, txNote);
 };
 
+export type TDescriptors = {
+  success: true;
+  descriptors: string[];
+} | {
+  success: false;
+  errorMessage: string;
+};
+
+export const getDescriptors = (
+  code: AccountCode,
+): Promise<TDescriptors> => {
+  return apiGet(`account/${code}/descriptors`);
+};
+
+export type TTxProposalPSBT = {
+  success: true;
+  psbt: string;
+} | {
+  success: false;
+  errorMessage: string;
+};
+
+export const getTxProposalPSBT = (
+  code: AccountCode,
+): Promise<TTxProposalPSBT> => {
+  return apiGet(`account/${code}/tx-proposal/psbt`);
+};
+
+export type TSignPSBT = {
+  success: true;
+  psbt: string;
+} | {
+  success: false;
+  aborted: true;
+} | {
+  success: false;
+  errorMessage: string;
+  errorCode?: 'cannotSign';
+};
+
+export const signPSBT = (
+  code: AccountCode,
+  psbt: string,
+): Promise<TSignPSBT> => {
+  return apiPost(`account/${code}/sign-psbt`, { psbt });
+};
+
+export type TSendPSBT = {
+  success: true;
+  txID: string;
+} | {
+  success: false;
+  errorMessage: string;
+};
+
+export const sendPSBT = (
+  code: AccountCode,
+  psbt: string,
+  note: string,
+): Promise<TSendPSBT> => {
+  return apiPost(`account/${code}/send-psbt`, { psbt, note });
+};
+
 export type FeeTargetCode = 'custom' | 'low' | 'economy' | 'normal' | 'high';
 
 export interface IProposeTxData {
@@ -405,9 +661,14 @@ export interface IProposeTx {
     errorMessage?: string;
 }
 
+export type TFeeEstimateConfidence = 'high' | 'medium' | 'low';
+
 export interface IFeeTarget {
     code: FeeTargetCode;
     feeRateInfo: string;
+    blocks?: number;
+    etaMinutes?: number;
+    confidence?: TFeeEstimateConfidence;
 }
 
 export interface IFeeTargetList {
@@ -419,6 +680,18 @@ export const getFeeTargetList = (code: AccountCode): Promise<IFeeTargetList> =>
   return apiGet(`account/${code}/fee-targets`);
 };
 
+export type TFeeEstimateResponse = {
+  success: true;
+  feeTarget: IFeeTarget;
+} | {
+  success: false;
+  errorMessage: string;
+};
+
+export const getFeeEstimate = (code: AccountCode, blocks: number): Promise<TFeeEstimateResponse> => {
+  return apiGet(`account/${code}/fee-estimate?blocks=${blocks}`);
+};
+
 export const verifyAddress = (code: AccountCode, addressID: string): Promise<boolean> => {
   return apiPost(`account/${code}/verify-address`, addressID);
 };
@@ -433,12 +706,58 @@ export type TUTXO = {
   scriptType: ScriptType;
   addressReused: boolean;
   isChange: boolean;
+  frozen: boolean;
 };
 
 export const getUTXOs = (code: AccountCode): Promise<TUTXO[]> => {
   return apiGet(`account/${code}/utxos`);
 };
 
+export const setUTXOFrozen = (
+  code: AccountCode,
+  outPoint: TUTXO['outPoint'],
+  frozen: boolean,
+): Promise<null> => {
+  return apiPost(`account/${code}/utxos/frozen`, { outPoint, frozen });
+};
+
+export type TGapLimits = {
+  receive: number;
+  change: number;
+};
+
+export const deepRescan = (
+  code: AccountCode,
+  gapLimits: TGapLimits,
+): Promise<TGapLimits> => {
+  return apiPost(`account/${code}/deep-rescan`, gapLimits);
+};
+
+export type TDustProtection = {
+  threshold: number;
+  quarantine: boolean;
+};
+
+export type TPrivacyReport = {
+  reusedAddresses: {
+    address: string;
+    scriptType: ScriptType;
+    txCount: number;
+  }[];
+  dustOutputs: {
+    outPoint: string;
+    txId: string;
+    address: string;
+    amount: TAmountWithConversions;
+    spent: boolean;
+    frozen: boolean;
+  }[];
+};
+
+export const getPrivacyReport = (code: AccountCode): Promise<TPrivacyReport> => {
+  return apiGet(`account/${code}/privacy-report`);
+};
+
 type TSecureOutput = {
     hasSecureOutput: boolean;
     optional: boolean;
@@ -474,6 +793,77 @@ export const addAccount = (coinCode: string, name: string): Promise<TAddAccount>
   });
 };
 
+export const importDescriptors = (
+  coinCode: string,
+  descriptors: string[],
+  name: string,
+): Promise<TAddAccount> => {
+  return apiPost('account-import-descriptors', {
+    coinCode,
+    descriptors,
+    name,
+  });
+};
+
+export const addWatchonlyAccount = (
+  coinCode: string,
+  extendedKeyOrDescriptors: string,
+  scriptType: ScriptType | '',
+  name: string,
+): Promise<TAddAccount> => {
+  return apiPost('account-add-watchonly', {
+    coinCode,
+    extendedKeyOrDescriptors,
+    scriptType,
+    name,
+  });
+};
+
+export type TMultisigScriptType = 'p2wsh' | 'p2wsh-p2sh';
+
+export type TMultisigKey = {
+  success: true;
+  key: string;
+} | {
+  success: false;
+  errorMessage: string;
+};
+
+export const getMultisigKey = (
+  coinCode: string,
+  scriptType: TMultisigScriptType,
+  accountNumber: number,
+): Promise<TMultisigKey> => {
+  return apiPost('multisig/key', {
+    coinCode,
+    scriptType,
+    accountNumber,
+  });
+};
+
+export type TAddMultisigAccountInput = {
+  coinCode: string;
+  scriptType: TMultisigScriptType;
+  accountNumber: number;
+  threshold: number;
+  cosignerKeys: string[];
+  name: string;
+};
+
+export type TAddMultisigAccount = {
+  success: boolean;
+  accountCode?: string;
+  aborted?: boolean;
+  errorCode?: 'accountAlreadyExists' | 'accountLimitReached';
+  errorMessage?: string;
+};
+
+export const addMultisigAccount = (
+  input: TAddMultisigAccountInput,
+): Promise<TAddMultisigAccount> => {
+  return apiPost('multisig/account-add', input);
+};
+
 export const connectKeystore = (code: AccountCode): Promise<{ success: boolean; }> => {
   return apiPost(`account/${code}/connect-keystore`);
 };
@@ -502,16 +892,28 @@ export const ethSignWalletConnectTx = (code: AccountCode, send: boolean, chainId
   return apiPost(`account/${code}/eth-sign-wallet-connect-tx`, { send, chainId, tx });
 };
 
+export type TBIP322Format = 'simple' | 'full';
+
 export type AddressSignResponse = {
   success: true;
   signature: string;
   address: string;
+  bip322?: TBIP322Format;
 } | {
   success: false;
   errorMessage?: string;
-  errorCode?: 'userAbort' | 'wrongKeystore';
+  errorCode?: 'userAbort' | 'wrongKeystore' | 'unsupportedFeature' | 'cannotSign';
 }
 
-export const signAddress = (format: ScriptType | '', msg: string, code: AccountCode): Promise<AddressSignResponse> => {
-  return apiPost(`account/${code}/sign-address`, { format, msg, code });
+/**
+ * Signs a message with an unused address of the account. If `bip322` is set, a BIP-322 signature
+ * is created instead of a legacy signature. P2TR addresses are always signed using BIP-322.
+ */
+export const signAddress = (
+  format: ScriptType | '',
+  msg: string,
+  code: AccountCode,
+  bip322?: TBIP322Format,
+): Promise<AddressSignResponse> => {
+  return apiPost(`account/${code}/sign-address`, { format, msg, code, bip322 });
 };
@@ -14,7 +14,7 @@
  * limitations under the License.
  */
 
-import type { AccountCode, CoinCode, ERC20CoinCode } from './account';
+import type { AccountCode, CoinCode, ERC20CoinCode, TDustProtection, TGapLimits } from './account';
 import type { FailResponse, SuccessResponse } from './response';
 import { apiGet, apiPost } from '@/utils/request';
 import { TSubscriptionCallback, subscribeEndpoint } from './subscribe';
@@ -52,6 +52,27 @@ export const renameAccount = (accountCode: AccountCode, name: string): Promise<I
   return apiPost('rename-account', { accountCode, name });
 };
 
+export const setAccountGapLimits = (
+  accountCode: AccountCode,
+  gapLimits: TGapLimits | null,
+): Promise<ISuccess> => {
+  return apiPost('set-account-gap-limits', { accountCode, gapLimits });
+};
+
+export const setAccountDustProtection = (
+  accountCode: AccountCode,
+  dustProtection: TDustProtection | null,
+): Promise<ISuccess> => {
+  return apiPost('set-account-dust-protection', { accountCode, dustProtection });
+};
+
+export const setAccountSilentPayments = (
+  accountCode: AccountCode,
+  enabled: boolean,
+): Promise<ISuccess> => {
+  return apiPost('set-account-silent-payments', { accountCode, enabled });
+};
+
 export const reinitializeAccounts = (): Promise<null> => {
   return apiPost('accounts/reinitialize');
 };
@@ -148,6 +169,7 @@ export const exportNotes = (): Promise<(FailResponse & { aborted: boolean; }) |
 export type TImportNotes = {
   accountCount: number;
   transactionCount: number;
+  outputCount: number;
 };
 
 export const importNotes = (fileContents: ArrayBuffer): Promise<FailResponse | (SuccessResponse & { data: TImportNotes; })> => {
@@ -33,6 +33,28 @@ export const subscribeCoinHeaders = (coinCode: CoinCode) => (
   )
 );
 
+export type TElectrumServerIssue = 'unreachable' | 'tipLag' | 'errors' | 'latency';
+
+export type TElectrumServerStatus = {
+  server: string;
+  connected: boolean;
+  serverVersion: string;
+  protocolVersion: string;
+  latencyMs: number;
+  tipHeight: number;
+  tipLag: number;
+  requests: number;
+  errors: number;
+  lastError: string;
+  score: number;
+  issues: TElectrumServerIssue[];
+  avoided: boolean;
+};
+
+export const getElectrumServers = (coinCode: CoinCode): Promise<TElectrumServerStatus[]> => {
+  return apiGet(`coins/${coinCode}/electrum-servers`);
+};
+
 export type TSetBtcUnitResponse = {
   success: boolean;
 };
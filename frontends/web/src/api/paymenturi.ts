@@ -0,0 +1,57 @@
+/**
+ * Copyright 2026 Shift Crypto AG
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import { AccountCode } from './account';
+import { apiGet, apiPost } from '@/utils/request';
+import type { TUnsubscribe } from '@/utils/transport-common';
+import { subscribeEndpoint } from './subscribe';
+
+export type TPaymentURIAccount = {
+  name: string;
+  code: AccountCode;
+};
+
+export type TPaymentURI = null | {
+  errorCode: 'paymentURIInvalid' | 'paymentURIUnsupportedRequirement' | 'paymentURIInvalidAddress' | 'paymentURINoAccounts' | 'paymentURIUnsupportedChain' | 'paymentURIUnsupportedToken';
+} | {
+  errorCode: undefined;
+  accounts: TPaymentURIAccount[];
+  accountCode?: AccountCode;
+  address: string;
+  amount?: string;
+  note?: string;
+  message?: string;
+  lightning?: string;
+  payjoin?: string;
+};
+
+export const getPaymentURI = (): Promise<TPaymentURI> => {
+  return apiGet('payment-uri');
+};
+
+export const chooseAccount = (accountCode: AccountCode): Promise<null> => {
+  return apiPost('payment-uri/choose-account', { accountCode });
+};
+
+export const clearPaymentURI = (): Promise<null> => {
+  return apiPost('payment-uri/clear');
+};
+
+export const subscribePaymentURI = (
+  cb: (paymentURI: TPaymentURI) => void
+): TUnsubscribe => {
+  return subscribeEndpoint('payment-uri', cb);
+};
@@ -15,6 +15,7 @@
  */
 
 import { TUnsubscribe } from '@/utils/transport-common';
+import type { AccountCode } from './account';
 import { TSubscriptionCallback, subscribeEndpoint } from './subscribe';
 
 export type TNewTxs = {
@@ -25,3 +26,14 @@ export type TNewTxs = {
 export const syncNewTxs = (cb: TSubscriptionCallback<TNewTxs>): TUnsubscribe => {
   return subscribeEndpoint('new-txs', cb);
 };
+
+export type TDustOutputs = {
+  count: number,
+  accountName: string,
+  accountCode: AccountCode,
+  quarantined: boolean,
+};
+
+export const syncDustOutputs = (cb: TSubscriptionCallback<TDustOutputs>): TUnsubscribe => {
+  return subscribeEndpoint('dust-outputs', cb);
+};
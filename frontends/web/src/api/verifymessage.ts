@@ -0,0 +1,50 @@
+/**
+ * Copyright 2026 Shift Crypto AG
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import type { CoinCode, ScriptType } from './account';
+import { apiPost } from '@/utils/request';
+
+export type TMessageSignatureFormat = 'electrum' | 'bip322-simple' | 'bip322-full' | 'eip191' | 'eip712';
+
+export type TVerifiedMessage = {
+  valid: boolean;
+  format: TMessageSignatureFormat;
+  recoveredAddress: string;
+  publicKey?: string;
+  scriptType?: ScriptType;
+};
+
+export type TVerifyMessage = {
+  success: false;
+  errorMessage: string;
+} | {
+  success: true;
+  result: TVerifiedMessage;
+};
+
+/**
+ * Verifies a signed message. BTC/LTC signatures are base64 encoded, ETH signatures hex encoded.
+ * For ETH, `typedData` indicates that the message is EIP-712 typed data in JSON.
+ */
+export const verifyMessage = (
+  coinCode: CoinCode,
+  address: string,
+  message: string,
+  signature: string,
+  typedData: boolean = false,
+): Promise<TVerifyMessage> => {
+  return apiPost('verify-message', { coinCode, address, message, signature, typedData });
+};
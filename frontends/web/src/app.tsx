@@ -29,11 +29,12 @@ import { getAccounts } from './api/account';
 import { syncAccountsList } from './api/accountsync';
 import { getDeviceList } from './api/devices';
 import { syncDeviceList } from './api/devicessync';
-import { syncNewTxs } from './api/transactions';
+import { syncDustOutputs, syncNewTxs } from './api/transactions';
 import { notifyUser } from './api/system';
 import { ConnectedApp } from './connected';
 import { Alert } from './components/alert/Alert';
 import { Aopp } from './components/aopp/aopp';
+import { PaymentURI } from './components/paymenturi/paymenturi';
 import { Confirm } from './components/confirm/Confirm';
 import { KeystoreConnectPrompt } from './components/keystoreconnectprompt';
 import { Sidebar } from './components/sidebar/sidebar';
@@ -65,6 +66,15 @@ export const App = () => {
     });
   }, [t]);
 
+  useEffect(() => {
+    return syncDustOutputs((meta) => {
+      notifyUser(t(meta.quarantined ? 'notification.dustOutputsQuarantined' : 'notification.dustOutputs', {
+        count: meta.count,
+        accountName: meta.accountName,
+      }));
+    });
+  }, [t]);
+
   const maybeRoute = useCallback(() => {
     const currentURL = window.location.hash.replace(/^#/, '');
     const isIndex = currentURL === '' || currentURL === '/';
@@ -182,6 +192,7 @@ export const App = () => {
           <div className={`${styles.appContent} ${showBottomNavigation ? styles.hasBottomNavigation : ''}`}>
             <WCSigningRequest />
             <Aopp />
+            <PaymentURI />
             <KeystoreConnectPrompt />
             {
               Object.entries(devices).map(([deviceID, productName]) => {
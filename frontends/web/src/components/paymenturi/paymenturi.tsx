@@ -0,0 +1,117 @@
+/**
+ * Copyright 2026 Shift Crypto AG
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import React, { useEffect, useState } from 'react';
+import { useTranslation } from 'react-i18next';
+import { useNavigate } from 'react-router-dom';
+import type { AccountCode } from '@/api/account';
+import * as paymentURIAPI from '@/api/paymenturi';
+import { View, ViewHeader, ViewContent, ViewButtons } from '@/components/view/view';
+import { Message } from '@/components/message/message';
+import { Button, Select } from '@/components/forms';
+
+/**
+ * Handles payment URIs (e.g. `bitcoin:` links) opened with the app. The send view of the account
+ * to pay from is opened, which prefills the send form with the payment request, see `Send`.
+ */
+export const PaymentURI = () => {
+  const { t } = useTranslation();
+  const navigate = useNavigate();
+
+  const [paymentURI, setPaymentURI] = useState<paymentURIAPI.TPaymentURI>(null);
+  const [accountCode, setAccountCode] = useState<AccountCode>('');
+
+  useEffect(() => {
+    paymentURIAPI.getPaymentURI().then(setPaymentURI);
+    return paymentURIAPI.subscribePaymentURI(setPaymentURI);
+  }, []);
+
+  useEffect(() => {
+    if (!paymentURI || paymentURI.errorCode) {
+      return;
+    }
+    if (paymentURI.accountCode) {
+      navigate(`/account/${paymentURI.accountCode}/send`);
+    } else if (paymentURI.accounts.length) {
+      setAccountCode(paymentURI.accounts[0].code);
+    }
+  }, [paymentURI, navigate]);
+
+  const chooseAccount = (e: React.SyntheticEvent) => {
+    if (accountCode) {
+      paymentURIAPI.chooseAccount(accountCode);
+    }
+    e.preventDefault();
+  };
+
+  if (!paymentURI) {
+    return null;
+  }
+  if (paymentURI.errorCode) {
+    return (
+      <View
+        fullscreen
+        textCenter
+        verticallyCentered
+        width="580px">
+        <ViewHeader title={t('paymentURI.title')} />
+        <ViewContent>
+          <Message type="error">
+            {t(`error.${paymentURI.errorCode}`)}
+          </Message>
+        </ViewContent>
+        <ViewButtons>
+          <Button danger onClick={paymentURIAPI.clearPaymentURI}>{t('button.dismiss')}</Button>
+        </ViewButtons>
+      </View>
+    );
+  }
+  if (paymentURI.accountCode) {
+    // The send view of the account is opened and takes over the payment request.
+    return null;
+  }
+  const options = paymentURI.accounts.map(account => {
+    return {
+      text: account.name,
+      value: account.code,
+    };
+  });
+  return (
+    <form onSubmit={chooseAccount}>
+      <View
+        fullscreen
+        textCenter
+        verticallyCentered
+        width="580px">
+        <ViewHeader title={t('paymentURI.title')}>
+          <p>{paymentURI.address}</p>
+        </ViewHeader>
+        <ViewContent>
+          <Select
+            label={t('paymentURI.selectAccount')}
+            options={options}
+            value={accountCode}
+            onChange={e => setAccountCode((e.target as HTMLSelectElement)?.value)}
+            id="account" />
+        </ViewContent>
+        <ViewButtons>
+          <Button primary type="submit">{t('button.next')}</Button>
+          <Button secondary onClick={paymentURIAPI.clearPaymentURI}>{t('dialog.cancel')}</Button>
+        </ViewButtons>
+      </View>
+    </form>
+  );
+};
@@ -19,6 +19,7 @@ import { Component } from 'react';
 import * as accountApi from '@/api/account';
 import { syncdone } from '@/api/accountsync';
 import { convertFromCurrency, convertToCurrency, parseExternalBtcAmount } from '@/api/coins';
+import { clearPaymentURI, getPaymentURI, subscribePaymentURI, TPaymentURI } from '@/api/paymenturi';
 import { View, ViewContent } from '@/components/view/view';
 import { alertUser } from '@/components/alert/Alert';
 import { Balance } from '@/components/balance/balance';
@@ -67,11 +68,14 @@ export type State = {
     isUpdatingProposal: boolean;
     errorHandling: TProposalError;
     note: string;
+    // BIP-78 payjoin endpoint of the payment URI the form was prefilled with.
+    payjoin?: string;
 }
 
 class Send extends Component<Props, State> {
   private selectedUTXOs: TSelectedUTXOs = {};
   private unsubscribe?: () => void;
+  private unsubscribePaymentURI?: () => void;
 
   // in case there are multiple parallel tx proposals we can ignore all other but the last one
   private lastProposal: Promise<accountApi.TTxProposalResult> | null = null;
@@ -108,14 +112,40 @@ class Send extends Component<Props, State> {
         updateBalance(currentCode);
       }
     });
+
+    getPaymentURI().then(this.applyPaymentURI).catch(console.error);
+    this.unsubscribePaymentURI = subscribePaymentURI(this.applyPaymentURI);
   }
 
   public componentWillUnmount() {
     if (this.unsubscribe) {
       this.unsubscribe();
     }
+    if (this.unsubscribePaymentURI) {
+      this.unsubscribePaymentURI();
+    }
   }
 
+  // Prefills the form with a payment request (e.g. a `bitcoin:` link opened with the app) which is
+  // to be paid from this account. The label of the payment request becomes the transaction note.
+  private applyPaymentURI = (paymentURI: TPaymentURI) => {
+    if (!paymentURI || paymentURI.errorCode || paymentURI.accountCode !== this.props.account.code) {
+      return;
+    }
+    clearPaymentURI().catch(console.error);
+    this.setState({
+      recipientAddress: paymentURI.address,
+      amount: paymentURI.amount || '',
+      fiatAmount: '',
+      sendAll: false,
+      note: paymentURI.note || '',
+      payjoin: paymentURI.payjoin,
+    }, () => {
+      this.convertToFiat(this.state.amount);
+      this.validateAndDisplayFee(true);
+    });
+  };
+
   private reset = () => {
     this.setState({
       sendAll: false,
@@ -128,6 +158,7 @@ class Send extends Component<Props, State> {
       amount: '',
       note: '',
       customFee: '',
+      payjoin: undefined,
     });
     this.selectedUTXOs = {};
   };
@@ -151,7 +182,7 @@ class Send extends Component<Props, State> {
     }
   };
 
-  private getValidTxInputData = (): Required<accountApi.TTxInput> | false => {
+  private getValidTxInputData = (): accountApi.TTxInput | false => {
     if (
       !this.state.recipientAddress
       || this.state.feeTarget === undefined
@@ -168,6 +199,7 @@ class Send extends Component<Props, State> {
       sendAll: (this.state.sendAll ? 'yes' : 'no'),
       selectedUTXOs: Object.keys(this.selectedUTXOs),
       paymentRequest: null,
+      payjoin: this.state.payjoin,
       useHighestFee: false
     };
   };
@@ -311,7 +343,8 @@ class Send extends Component<Props, State> {
     let updateState = {
       recipientAddress: address,
       sendAll: false,
-      fiatAmount: ''
+      fiatAmount: '',
+      payjoin: undefined,
     } as Pick<State, keyof State>;
 
     const coinCode = this.props.account.coinCode;
@@ -337,7 +370,8 @@ class Send extends Component<Props, State> {
   };
 
   private onReceiverAddressInputChange = (recipientAddress: string) => {
-    this.setState({ recipientAddress }, () => {
+    // The payjoin endpoint belongs to the address of the payment request.
+    this.setState({ recipientAddress, payjoin: undefined }, () => {
       this.validateAndDisplayFee(true);
     });
   };
@@ -390,6 +424,7 @@ class Send extends Component<Props, State> {
       isUpdatingProposal,
       errorHandling,
       note,
+      payjoin,
     } = this.state;
 
     const waitDialogTransactionDetails = {
@@ -523,6 +558,9 @@ class Send extends Component<Props, State> {
                   {(proposedAmount && proposedAmount.conversions && proposedAmount.conversions[activeCurrency]) ? (
                     <FiatValue baseCurrencyUnit={activeCurrency} amount={proposedAmount.conversions[activeCurrency] || ''} />
                   ) : null}
+                  {(payjoin && sendResult.success && !sendResult.payjoin) ? (
+                    <p>{t('send.payjoinSkipped')}</p>
+                  ) : null}
                 </SendResult>
               )}
             </View>
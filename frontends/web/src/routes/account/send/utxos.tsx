@@ -20,6 +20,7 @@ import { useTranslation } from 'react-i18next';
 import {
   allScriptTypes,
   getUTXOs,
+  setUTXOFrozen,
   AccountCode,
   ScriptType,
   TUTXO,
@@ -96,6 +97,11 @@ export const UTXOs = ({
     onChange(proposedUTXOs);
   };
 
+  const handleFrozenChange = async (utxo: TUTXO) => {
+    await setUTXOFrozen(accountCode, utxo.outPoint, !utxo.frozen);
+    setUtxos(await getUTXOs(accountCode));
+  };
+
   const renderUTXOs = (scriptType: ScriptType) => {
     const filteredUTXOs = utxos.filter(utxo => utxo.scriptType === scriptType);
     if (filteredUTXOs.length === 0) {
@@ -158,6 +164,17 @@ export const UTXOs = ({
                           :
                           null
                         }
+                        {utxo.frozen ? (
+                          <>
+                            <Badge type="warning">
+                              {t('send.coincontrol.frozen')}
+                            </Badge>
+                            {' '}
+                          </>
+                        )
+                          :
+                          null
+                        }
                       </div>
                     </div>
                     <div className={style.transaction}>
@@ -168,6 +185,14 @@ export const UTXOs = ({
                         {utxo.txId}
                       </span>:{utxo.txOutput}
                     </div>
+                    <Button
+                      transparent
+                      onClick={event => {
+                        event.preventDefault();
+                        handleFrozenChange(utxo);
+                      }}>
+                      {utxo.frozen ? t('send.coincontrol.unfreeze') : t('send.coincontrol.freeze')}
+                    </Button>
                   </div>
                   <A
                     className={style.utxoExplorer}
@@ -53,12 +53,15 @@ export const NotesImport = () => {
 
             const result = await importNotes(await file.arrayBuffer());
             if (result.success) {
-              const { accountCount, transactionCount } = result.data;
+              const { accountCount, transactionCount, outputCount } = result.data;
               alertUser(`${t('settings.notes.import.accountNames', {
                 count: accountCount
               })}
     ${t('settings.notes.import.transactionNotes', {
       count: transactionCount
+    })}
+    ${t('settings.notes.import.outputs', {
+      count: outputCount
     })}`);
               fileInput.value = '';
             } else if (result.message) {